@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/kataras/iris"
+)
+
+// ErrReadOnlyAPIKey happens when a read-only API key is used on a mutating
+// route
+var ErrReadOnlyAPIKey = models.NewKindError(models.ErrForbidden,
+	"Clé d'API en lecture seule")
+
+// apiKeyReq is used to decode the creation of an API key
+type apiKeyReq struct {
+	Name     string    `json:"Name"`
+	ReadOnly bool      `json:"ReadOnly"`
+	Expires  time.Time `json:"Expires"`
+}
+
+// apiKeyResp is sent back after the creation of a key, which is the only time
+// the key is known
+type apiKeyResp struct {
+	APIKey models.APIKey `json:"APIKey"`
+	Key    string        `json:"Key"`
+}
+
+// apiKeyToUser checks the API key, records its use and returns claims holding
+// the current rights of its user. Read-only keys are rejected on the mutating
+// routes.
+func apiKeyToUser(ctx iris.Context, key string) (*customClaims, error) {
+	db, ok := ctx.Values().Get("db").(*sql.DB)
+	if !ok {
+		return nil, ErrBadToken
+	}
+	var k models.APIKey
+	rights, err := k.Authenticate(db, key)
+	if err != nil {
+		return nil, err
+	}
+	if k.ReadOnly && ctx.Method() != http.MethodGet {
+		return nil, ErrReadOnlyAPIKey
+	}
+	ctx.Values().Set("userID", int(k.UserID))
+	ctx.Values().Set("apiKeyID", k.ID)
+	ctx.Values().Set("rights", rights)
+	return &customClaims{Rights: rights, StandardClaims: jwt.StandardClaims{
+		Subject: strconv.FormatInt(k.UserID, 10)}}, nil
+}
+
+// GetAPIKeys handles the get request of the connected user to fetch their API
+// keys
+func GetAPIKeys(ctx iris.Context) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des clés d'API, user ID : ", err)
+		return
+	}
+	var resp models.APIKeys
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = resp.Get(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des clés d'API, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// CreateAPIKey handles the post request of the connected user to create an
+// API key and sends it back
+func CreateAPIKey(ctx iris.Context) {
+	var req apiKeyReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Création de clé d'API, décodage : ", err)
+		return
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Création de clé d'API, user ID : ", err)
+		return
+	}
+	k := models.APIKey{UserID: userID, Name: req.Name, ReadOnly: req.ReadOnly,
+		Expires: req.Expires}
+	if err = k.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Création de clé d'API : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	key, err := k.Create(db)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Création de clé d'API, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusCreated)
+	ctx.JSON(apiKeyResp{APIKey: k, Key: key})
+}
+
+// DeleteAPIKey handles the delete request of the connected user to revoke one
+// of their API keys
+func DeleteAPIKey(ctx iris.Context) {
+	ID, err := ctx.Params().GetInt64("ID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Suppression de clé d'API, paramètre : ", err)
+		return
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression de clé d'API, user ID : ", err)
+		return
+	}
+	k, db := models.APIKey{ID: ID}, ctx.Values().Get("db").(*sql.DB)
+	if err = k.Delete(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression de clé d'API, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Clé d'API supprimée"})
+}
+
+// GetAllAPIKeys handles the get request of an admin to fetch the API keys of
+// all users
+func GetAllAPIKeys(ctx iris.Context) {
+	var resp models.APIKeys
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err := resp.Get(db, 0); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des clés d'API, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// RevokeAPIKey handles the delete request of an admin to revoke the API key
+// of any user
+func RevokeAPIKey(ctx iris.Context) {
+	ID, err := ctx.Params().GetInt64("ID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Révocation de clé d'API, paramètre : ", err)
+		return
+	}
+	k, db := models.APIKey{ID: ID}, ctx.Values().Get("db").(*sql.DB)
+	if err = k.Delete(db, 0); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Révocation de clé d'API, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Clé d'API révoquée"})
+}
@@ -0,0 +1,242 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testAPIKey is the entry point for testing the personal API keys
+func testAPIKey(t *testing.T, c *TestContext) {
+	t.Run("APIKey", func(t *testing.T) {
+		key, readOnlyKey, ID := testCreateAPIKey(t, c)
+		if key == "" || readOnlyKey == "" {
+			t.Error("APIKey : création impossible")
+			return
+		}
+		testUseAPIKey(t, c, key, readOnlyKey)
+		testGetAPIKeys(t, c)
+		testGetAllAPIKeys(t, c)
+		testDeleteAPIKey(t, c, ID)
+		testRevokeAPIKey(t, c, key)
+	})
+}
+
+// testCreateAPIKey checks the keys are created with a valid name and expiry
+// date and returns a read-write key, a read-only key and the ID of the latter
+func testCreateAPIKey(t *testing.T, c *TestContext) (string, string, int) {
+	expires := time.Now().AddDate(0, 6, 0).Format(time.RFC3339)
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Création de clé d'API, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Name":"","Expires":"` + expires + `"}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Création de clé d'API : Champ Name vide ou trop long`},
+			StatusCode:   http.StatusBadRequest}, // 1 : empty name
+		{
+			Sent:         []byte(`{"Name":"Exports","Expires":"2019-01-01T00:00:00Z"}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Création de clé d'API : Champ Expires hors de l'année à venir`},
+			StatusCode:   http.StatusBadRequest}, // 2 : expired
+		{
+			Sent: []byte(`{"Name":"Exports","Expires":"` +
+				time.Now().AddDate(2, 0, 0).Format(time.RFC3339) + `"}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Création de clé d'API : Champ Expires hors de l'année à venir`},
+			StatusCode:   http.StatusBadRequest}, // 3 : too long
+		{
+			Sent:         []byte(`{"Name":"Exports","Expires":"` + expires + `"}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`"Name":"Exports"`, `"ReadOnly":false`, `"Key":"plg_`},
+			StatusCode:   http.StatusCreated}, // 4 : ok
+		{
+			Sent:         []byte(`{"Name":"Exports","Expires":"` + expires + `"}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Création de clé d'API, requête : Nom de clé déjà utilisé`},
+			StatusCode:   http.StatusConflict}, // 5 : name already used
+		{
+			Sent:         []byte(`{"Name":"Lecture","ReadOnly":true,"Expires":"` + expires + `"}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`"Name":"Lecture"`, `"ReadOnly":true`, `"Key":"plg_`},
+			StatusCode:   http.StatusCreated}, // 6 : read-only ok
+	}
+	var (
+		keys []string
+		ID   int
+	)
+	f := func(tc TestCase) *httpexpect.Response {
+		response := c.E.POST("/api/user/api_key").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+		if response.Raw().StatusCode == http.StatusCreated {
+			var resp struct {
+				APIKey struct{ ID int }
+				Key    string
+			}
+			json.Unmarshal(response.Content, &resp)
+			keys, ID = append(keys, resp.Key), resp.APIKey.ID
+		}
+		return response
+	}
+	for _, r := range chkFactory(tcc, f, "CreateAPIKey") {
+		t.Error(r)
+	}
+	if len(keys) != 2 {
+		return "", "", 0
+	}
+	return keys[0], keys[1], ID
+}
+
+// testUseAPIKey checks the keys are accepted in place of a session token,
+// except for the account routes, and that read-only keys can't modify
+func testUseAPIKey(t *testing.T, c *TestContext, key string, readOnlyKey string) {
+	tcc := []TestCase{
+		{
+			Token:        "plg_faux",
+			RespContains: []string{`Clé d'API invalide ou expirée`},
+			StatusCode:   http.StatusUnauthorized}, // 0 : bad key
+		{
+			Token:        key,
+			RespContains: []string{`"BudgetAction"`},
+			StatusCode:   http.StatusOK}, // 1 : ok
+		{
+			Token:        readOnlyKey,
+			RespContains: []string{`"BudgetAction"`},
+			StatusCode:   http.StatusOK}, // 2 : read-only ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/budget_actions").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UseAPIKey") {
+		t.Error(r)
+	}
+	tcc = []TestCase{
+		{
+			Token:        readOnlyKey,
+			RespContains: []string{`Clé d'API en lecture seule`},
+			StatusCode:   http.StatusForbidden}, // 0 : read-only key
+		{
+			Token:        key,
+			RespContains: []string{sessionOnlyMessage},
+			StatusCode:   http.StatusUnauthorized}, // 1 : admin route
+	}
+	f = func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/copro").WithBytes([]byte(`{}`)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UseAPIKey, modification") {
+		t.Error(r)
+	}
+	c.E.GET("/api/user/api_keys").WithHeader("Authorization", "Bearer "+key).
+		Expect().Status(http.StatusUnauthorized).Body().Contains(sessionOnlyMessage)
+}
+
+// testGetAPIKeys checks the keys of the user are sent back with their last
+// use
+func testGetAPIKeys(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.UserCheckTestCase, // 0 : token empty
+		{
+			Token: c.Config.Users.User.Token,
+			RespContains: []string{`"APIKey":[`, `"Name":"Exports"`,
+				`"Name":"Lecture"`, `"Prefix":"plg_`, `"LastUsed":"`},
+			Count:         2,
+			CountItemName: `"Prefix"`,
+			StatusCode:    http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/user/api_keys").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetAPIKeys") {
+		t.Error(r)
+	}
+}
+
+// testGetAllAPIKeys checks if route is admin protected and the keys of all
+// users are sent back
+func testGetAllAPIKeys(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:         c.Config.Users.Admin.Token,
+			RespContains:  []string{`"APIKey":[`, `"Name":"Exports"`, `"UserName":"`},
+			Count:         2,
+			CountItemName: `"Prefix"`,
+			StatusCode:    http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/api_keys").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetAllAPIKeys") {
+		t.Error(r)
+	}
+}
+
+// testDeleteAPIKey checks a user can only delete their own keys
+func testDeleteAPIKey(t *testing.T, c *TestContext, ID int) {
+	tcc := []TestCase{
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           ID,
+			RespContains: []string{`Suppression de clé d'API, requête : Clé d'API introuvable`},
+			StatusCode:   http.StatusNotFound}, // 0 : key of another user
+		{
+			Token:        c.Config.Users.User.Token,
+			ID:           ID,
+			RespContains: []string{`Clé d'API supprimée`},
+			StatusCode:   http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.DELETE("/api/user/api_key/"+strconv.Itoa(tc.ID)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "DeleteAPIKey") {
+		t.Error(r)
+	}
+}
+
+// testRevokeAPIKey checks if route is admin protected and a revoked key is
+// rejected
+func testRevokeAPIKey(t *testing.T, c *TestContext, key string) {
+	var keys struct{ APIKey []struct{ ID int } }
+	response := c.E.GET("/api/api_keys").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.Admin.Token).Expect()
+	if err := json.Unmarshal(response.Content, &keys); err != nil ||
+		len(keys.APIKey) != 1 {
+		t.Errorf("RevokeAPIKey : clé attendue %s", string(response.Content))
+		return
+	}
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           0,
+			RespContains: []string{`Révocation de clé d'API, requête : Clé d'API introuvable`},
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           keys.APIKey[0].ID,
+			RespContains: []string{`Clé d'API révoquée`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.DELETE("/api/api_key/"+strconv.Itoa(tc.ID)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "RevokeAPIKey") {
+		t.Error(r)
+	}
+	c.E.GET("/api/budget_actions").WithHeader("Authorization", "Bearer "+key).
+		Expect().Status(http.StatusUnauthorized).Body().
+		Contains(`Clé d'API invalide ou expirée`)
+}
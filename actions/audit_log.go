@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// setUserDB replaces the database of the context by a handle logging the
+// changes with the connected user for the requests that modify the database.
+// The returned function closes the handle.
+func setUserDB(ctx iris.Context) (func(), error) {
+	if ctx.Method() == http.MethodGet {
+		return func() {}, nil
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db := models.AuditedDB(ctx.Values().Get("db").(*sql.DB), userID)
+	ctx.Values().Set("db", db)
+	return func() { db.Close() }, nil
+}
+
+// GetAuditLogs handles the get request to fetch the audit log filtered by
+// entity, entity ID, user and date range using paginated format. Dates use
+// the YYYY-MM-DD format and the end date is included.
+func GetAuditLogs(ctx iris.Context) {
+	var (
+		q   models.AuditQuery
+		err error
+	)
+	if q.Page, err = ctx.URLParamInt64("Page"); err != nil {
+		q.Page = 1
+	}
+	q.Entity = ctx.URLParam("Entity")
+	q.EntityID = ctx.URLParam("EntityID")
+	if ctx.URLParamExists("UserID") {
+		if q.UserID.Int64, err = ctx.URLParamInt64("UserID"); err != nil {
+			sendError(ctx, http.StatusBadRequest, "Journal d'audit, décodage UserID : ", err)
+			return
+		}
+		q.UserID.Valid = true
+	}
+	if b := ctx.URLParam("Begin"); b != "" {
+		if q.Begin.Time, err = time.Parse("2006-01-02", b); err != nil {
+			sendError(ctx, http.StatusBadRequest, "Journal d'audit, décodage Begin : ", err)
+			return
+		}
+		q.Begin.Valid = true
+	}
+	if e := ctx.URLParam("End"); e != "" {
+		if q.End.Time, err = time.Parse("2006-01-02", e); err != nil {
+			sendError(ctx, http.StatusBadRequest, "Journal d'audit, décodage End : ", err)
+			return
+		}
+		q.End.Time, q.End.Valid = q.End.Time.AddDate(0, 0, 1), true
+	}
+	var resp models.PaginatedAuditLogs
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = resp.Get(db, &q); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Journal d'audit, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testAuditLog is the entry point for testing all audit log requests
+func testAuditLog(t *testing.T, c *TestContext) {
+	t.Run("AuditLog", func(t *testing.T) {
+		testGetAuditLogs(t, c)
+	})
+}
+
+// testGetAuditLogs checks route is admin protected and changes made by the
+// previous tests are logged with their author but without the secrets
+func testGetAuditLogs(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "UserID=fake",
+			RespContains: []string{`Journal d'audit, décodage UserID : `},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad user ID
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "Begin=2019-13-45",
+			RespContains: []string{`Journal d'audit, décodage Begin : `},
+			StatusCode:   http.StatusBadRequest}, // 2 : bad begin date
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "Entity=department&Page=1",
+			RespContains: []string{`"AuditLog":[{"ID":`, `"Entity":"department"`,
+				`"UserName":"Christophe Saintillan"`, `"Operation":"INSERT"`,
+				`"Operation":"UPDATE"`, `"Operation":"DELETE"`, `"Before":{"id":`,
+				`"Page":1`},
+			StatusCode: http.StatusOK}, // 3 : ok
+		{
+			Token:         c.Config.Users.Admin.Token,
+			Params:        "Entity=department&End=2000-01-01",
+			RespContains:  []string{`"AuditLog":[]`, `"ItemsCount":0`},
+			StatusCode:    http.StatusOK,
+			CountItemName: `"ID"`,
+			Count:         0}, // 4 : empty date range
+		{
+			Token:         c.Config.Users.Admin.Token,
+			Params:        "Entity=users",
+			RespContains:  []string{`"Entity":"users"`},
+			StatusCode:    http.StatusOK,
+			CountItemName: `"totp_secret"`,
+			Count:         0}, // 5 : secrets not logged
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "Entity=commitment",
+			RespContains: []string{`"Entity":"commitment"`, `"UserName":`,
+				`"Operation":"INSERT"`},
+			StatusCode: http.StatusOK}, // 6 : batch import logged
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/audit").WithQueryString(tc.Params).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetAuditLogs") {
+		t.Error(r)
+	}
+}
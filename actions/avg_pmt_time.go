@@ -14,8 +14,8 @@ func GetAvgPmtTimes(ctx iris.Context) {
 	var resp models.AvgPmtTimes
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Durée moyenne de paiement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Durée moyenne de paiement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
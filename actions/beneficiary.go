@@ -17,8 +17,7 @@ func GetBeneficiaries(ctx iris.Context) {
 	var resp models.Beneficiaries
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -33,16 +32,19 @@ func GetPaginatedBeneficiaries(ctx iris.Context) {
 	var err error
 	req.Page, err = ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de bénéficiaires, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page de bénéficiaires, décodage Page : ", err)
 		return
 	}
 	req.Search = ctx.URLParam("Search")
+	if err = readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Page de bénéficiaires, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedBeneficiaries
 	if err = resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Page de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -53,19 +55,16 @@ func GetPaginatedBeneficiaries(ctx iris.Context) {
 func CreateBeneficiary(ctx iris.Context) {
 	var req beneficiaryReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de bénéficiaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de bénéficiaire, décodage : ", err)
 		return
 	}
 	if err := req.Beneficiary.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de bénéficiaire : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de bénéficiaire : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Beneficiary.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -76,19 +75,17 @@ func CreateBeneficiary(ctx iris.Context) {
 func UpdateBeneficiary(ctx iris.Context) {
 	var req beneficiaryReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de bénéficiaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de bénéficiaire, décodage : ", err)
 		return
 	}
 	if err := req.Beneficiary.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de bénéficiaire : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de bénéficiaire : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Beneficiary.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -99,15 +96,14 @@ func UpdateBeneficiary(ctx iris.Context) {
 func DeleteBeneficiary(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de bénéficiaire, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Suppression de bénéficiaire, paramètre : ", err)
 		return
 	}
 	b := models.Beneficiary{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -14,20 +14,20 @@ import (
 func GetPaginatedBeneficiaryDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données bénéficiaire, erreur ID : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page de données bénéficiaire, erreur ID : ", err)
 		return
 	}
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données bénéficiaire, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page de données bénéficiaire, décodage Year : ", err)
 		return
 	}
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données bénéficiaire, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page de données bénéficiaire, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
@@ -35,8 +35,8 @@ func GetPaginatedBeneficiaryDatas(ctx iris.Context) {
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedBeneficiaryDatas
 	if err := resp.Get(db, &req, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page de données bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -48,14 +48,14 @@ func GetPaginatedBeneficiaryDatas(ctx iris.Context) {
 func GetExportBeneficiaryDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export données bénéficiaire, erreur ID : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Export données bénéficiaire, erreur ID : ", err)
 		return
 	}
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export données bénéficiaire, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Export données bénéficiaire, décodage Year : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
@@ -63,8 +63,8 @@ func GetExportBeneficiaryDatas(ctx iris.Context) {
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.BeneficiaryDatas
 	if err := resp.GetAll(db, &req, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export données bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Export données bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
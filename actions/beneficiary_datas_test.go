@@ -27,7 +27,7 @@ func testGetBeneficiaryDatas(t *testing.T, c *TestContext) {
 			RespContains: []string{`Page de données bénéficiaire, décodage Year :`},
 			Count:        1,
 			ID:           3,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad param query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad param query
 		{
 			Token:        c.Config.Users.User.Token,
 			Sent:         []byte(`Page=2&Year=2010&Search=savigny`),
@@ -68,7 +68,7 @@ func testGetExportBeneficiaryDatas(t *testing.T, c *TestContext) {
 			RespContains: []string{`Export données bénéficiaire, décodage Year :`},
 			Count:        1,
 			ID:           3,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad param query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad param query
 		{
 			Token:        c.Config.Users.User.Token,
 			Sent:         []byte(`Year=2010&Search=savigny`),
@@ -16,19 +16,19 @@ type beneficiaryGroupReq struct {
 func CreateBeneficiaryGroup(ctx iris.Context) {
 	var req beneficiaryGroupReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de groupe de bénéficiaires, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de groupe de bénéficiaires, décodage : ", err)
 		return
 	}
 	if err := req.BeneficiaryGroup.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de groupe de bénéficiaires, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de groupe de bénéficiaires, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.BeneficiaryGroup.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +39,19 @@ func CreateBeneficiaryGroup(ctx iris.Context) {
 func UpdateBeneficiaryGroup(ctx iris.Context) {
 	var req beneficiaryGroupReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de groupe de bénéficiaires, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de groupe de bénéficiaires, décodage : ", err)
 		return
 	}
 	if err := req.BeneficiaryGroup.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de groupe de bénéficiaires, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de groupe de bénéficiaires, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.BeneficiaryGroup.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +62,15 @@ func UpdateBeneficiaryGroup(ctx iris.Context) {
 func DeleteBeneficiaryGroup(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de groupe de bénéficiaires, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de groupe de bénéficiaires, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.BeneficiaryGroup{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -86,27 +86,27 @@ type beneficiaryBelongReq struct {
 func SetBeneficiaryGroup(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de groupe de bénéficiaires, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de groupe de bénéficiaires, paramètre : ", err)
 		return
 	}
 	var req beneficiaryBelongReq
 	if err = ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de groupe de bénéficiaires, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de groupe de bénéficiaires, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.BeneficiaryGroup{ID: ID}
 	if err := b.Set(req.BeneficiaryIDs, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Fixation de groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	var resp models.Beneficiaries
 	if err := resp.GroupGet(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Fixation de groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -118,8 +118,8 @@ func GetBeneficiaryGroups(ctx iris.Context) {
 	var resp models.BeneficiaryGroups
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des groupes de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des groupes de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -131,15 +131,15 @@ func GetBeneficiaryGroups(ctx iris.Context) {
 func GetBeneficiaryGroupItems(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Liste des bénéficiaires d'un groupe, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Liste des bénéficiaires d'un groupe, décodage : ", err)
 		return
 	}
 	var resp models.Beneficiaries
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GroupGet(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des bénéficiaires d'un groupe, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des bénéficiaires d'un groupe, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -14,20 +14,20 @@ import (
 func GetPaginatedBeneficiaryGroupDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données groupe de bénéficiaires, erreur ID : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page de données groupe de bénéficiaires, erreur ID : ", err)
 		return
 	}
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données groupe de bénéficiaires, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page de données groupe de bénéficiaires, décodage Year : ", err)
 		return
 	}
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données groupe de bénéficiaires, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page de données groupe de bénéficiaires, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
@@ -35,8 +35,8 @@ func GetPaginatedBeneficiaryGroupDatas(ctx iris.Context) {
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedBeneficiaryGroupDatas
 	if err := resp.Get(db, &req, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de données bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page de données bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -48,14 +48,14 @@ func GetPaginatedBeneficiaryGroupDatas(ctx iris.Context) {
 func GetExportBeneficiaryGroupDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export données groupe de bénéficiaires, erreur ID : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Export données groupe de bénéficiaires, erreur ID : ", err)
 		return
 	}
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export données groupe de bénéficiaires, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Export données groupe de bénéficiaires, décodage Year : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
@@ -63,8 +63,8 @@ func GetExportBeneficiaryGroupDatas(ctx iris.Context) {
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.BeneficiaryGroupDatas
 	if err := resp.GetAll(db, &req, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export données groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Export données groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
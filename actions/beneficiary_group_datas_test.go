@@ -26,7 +26,7 @@ func testGetBeneficiaryGroupDatas(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Page=2&Year=a&Search=savigny`),
 			RespContains: []string{`Page de données groupe de bénéficiaires, décodage Year :`},
 			ID:           c.BeneficiaryGroupID,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad param query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad param query
 		{
 			Token:        c.Config.Users.User.Token,
 			Sent:         []byte(`Page=2&Year=2010&Search=savigny`),
@@ -62,7 +62,7 @@ func testGetExportBeneficiaryGroupDatas(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Year=a&Search=savigny`),
 			RespContains: []string{`Export données groupe de bénéficiaires, décodage Year :`},
 			ID:           c.BeneficiaryGroupID,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad param query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad param query
 		{
 			Token:        c.Config.Users.User.Token,
 			Sent:         []byte(`Year=2010&Search=savigny`),
@@ -203,7 +203,7 @@ func testDeleteBeneficiaryGroup(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de groupe de bénéficiaires, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
@@ -13,15 +13,15 @@ import (
 func GetBeneficiaryPayments(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Paiement d'un bénéficiaire, erreur ID : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Paiement d'un bénéficiaire, erreur ID : ", err)
 		return
 	}
 	var resp models.BeneficiaryPayments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.GetAll(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Paiement d'un bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Paiement d'un bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
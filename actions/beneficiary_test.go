@@ -154,7 +154,7 @@ func testDeleteBeneficiary(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Suppression de bénéficiaire, requête : bénéficiaire introuvable`},
 			ID:           0,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Bénéficiaire supprimé`},
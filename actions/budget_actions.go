@@ -18,13 +18,13 @@ func GetBudgetActions(ctx iris.Context) {
 	var resp budgetActionsResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des actions budgétaires, actions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des actions budgétaires, actions : ", err)
 		return
 	}
 	if err := resp.BudgetSectors.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des actions budgétaires, secteurs : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des actions budgétaires, secteurs : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -39,19 +39,18 @@ type budgetActionReq struct {
 func CreateBudgetAction(ctx iris.Context) {
 	var req budgetActionReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'action budgétaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création d'action budgétaire, décodage : ", err)
 		return
 	}
 	if err := req.BudgetAction.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'action budgétaire : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'action budgétaire : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.BudgetAction.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'action budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création d'action budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -62,19 +61,18 @@ func CreateBudgetAction(ctx iris.Context) {
 func UpdateBudgetAction(ctx iris.Context) {
 	var req budgetActionReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'action budgétaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification d'action budgétaire, décodage : ", err)
 		return
 	}
 	if err := req.BudgetAction.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification d'action budgétaire : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'action budgétaire : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.BudgetAction.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'action budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification d'action budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -85,15 +83,15 @@ func UpdateBudgetAction(ctx iris.Context) {
 func DeleteBudgetAction(ctx iris.Context) {
 	baID, err := ctx.Params().GetInt64("baID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'action budgétaire, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'action budgétaire, paramètre : ", err)
 		return
 	}
 	budgetAction := models.BudgetAction{ID: baID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = budgetAction.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'action budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'action budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
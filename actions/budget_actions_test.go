@@ -32,7 +32,7 @@ func testCreateBudgetAction(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création d'action budgétaire, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : code empty
+			StatusCode:   http.StatusBadRequest}, // 1 : code empty
 		{
 			Sent:         []byte(`{"BudgetAction":{"Code":0,"Name":"Action"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -89,7 +89,7 @@ func testUpdateBudgetAction(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification d'action budgétaire, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : code empty
+			StatusCode:   http.StatusBadRequest}, // 1 : code empty
 		{
 			Sent:         []byte(`{"BudgetAction":{"ID":` + strconv.Itoa(ID) + `,"Code":0,"Name":"Action"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -128,7 +128,7 @@ func testDeleteBudgetAction(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression d'action budgétaire, requête : Action budgétaire introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
@@ -17,19 +17,18 @@ type BudgetSectorReq struct {
 func CreateBudgetSector(ctx iris.Context) {
 	var req BudgetSectorReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de secteur budgétaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de secteur budgétaire, décodage : ", err)
 		return
 	}
 	if err := req.BudgetSector.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de secteur budgétaire : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de secteur budgétaire : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.BudgetSector.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de secteur budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de secteur budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +39,18 @@ func CreateBudgetSector(ctx iris.Context) {
 func UpdateBudgetSector(ctx iris.Context) {
 	var req BudgetSectorReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de secteur budgétaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de secteur budgétaire, décodage : ", err)
 		return
 	}
 	if err := req.BudgetSector.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de secteur budgétaire : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de secteur budgétaire : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.BudgetSector.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de secteur budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de secteur budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +61,16 @@ func UpdateBudgetSector(ctx iris.Context) {
 func GetBudgetSector(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de secteur budgétaire, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de secteur budgétaire, paramètre : ", err)
 		return
 	}
 	var resp BudgetSectorReq
 	resp.BudgetSector.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.BudgetSector.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de secteur budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de secteur budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +82,7 @@ func GetBudgetSectors(ctx iris.Context) {
 	var resp models.BudgetSectors
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des iDs, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des iDs, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +93,15 @@ func GetBudgetSectors(ctx iris.Context) {
 func DeleteBudgetSector(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de secteur budgétaire, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de secteur budgétaire, paramètre : ", err)
 		return
 	}
 	resp := models.BudgetSector{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de secteur budgétaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de secteur budgétaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
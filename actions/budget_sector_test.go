@@ -33,7 +33,7 @@ func testCreateBudgetSector(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de secteur budgétaire, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"BudgetSector":{"Name":"","FullName":"Essai"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -65,7 +65,7 @@ func testUpdateBudgetSector(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de secteur budgétaire, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"BudgetSector":{"Name":""}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -75,7 +75,7 @@ func testUpdateBudgetSector(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"BudgetSector":{"ID":0,"Name":"Essai2","FullName":null}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de secteur budgétaire, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 3 : bad ID
+			StatusCode:   http.StatusNotFound}, // 3 : bad ID
 		{
 			Sent:         []byte(`{"BudgetSector":{"ID":` + strconv.Itoa(ID) + `,"Name":"Essai2","FullName":null}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -0,0 +1,17 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// GetCacheStats handles the get request to fetch the metrics of the cached
+// aggregates
+func GetCacheStats(ctx iris.Context) {
+	var resp models.CacheStats
+	resp.Get()
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
@@ -0,0 +1,41 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testCache is the entry point for testing the cache requests
+func testCache(t *testing.T, c *TestContext) {
+	t.Run("Cache", func(t *testing.T) {
+		testGetCacheStats(t, c)
+	})
+}
+
+// testGetCacheStats checks route is admin protected and the aggregates of the
+// home page are cached, the second request being a hit
+func testGetCacheStats(t *testing.T, c *TestContext) {
+	for i := 0; i < 2; i++ {
+		c.E.GET("/api/home").
+			WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+			Expect().Status(http.StatusOK)
+	}
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"CacheStat":[`,
+				`{"Name":"csf_week_trend","Entries":1,"Hits":`,
+				`{"Name":"two_years_commitments","Entries":1,"Hits":`, `"HitRate":`},
+			StatusCode: http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/cache").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetCacheStats") {
+		t.Error(r)
+	}
+}
@@ -17,19 +17,16 @@ type CityReq struct {
 func CreateCity(ctx iris.Context) {
 	var req CityReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de ville, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de ville, décodage : ", err)
 		return
 	}
 	if err := req.City.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de ville : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de ville : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.City.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +37,16 @@ func CreateCity(ctx iris.Context) {
 func UpdateCity(ctx iris.Context) {
 	var req CityReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de ville, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de ville, décodage : ", err)
 		return
 	}
 	if err := req.City.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de ville : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de ville : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.City.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Modification de ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +57,14 @@ func UpdateCity(ctx iris.Context) {
 func GetCity(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de ville, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Récupération de ville, paramètre : ", err)
 		return
 	}
 	var resp CityReq
 	resp.City.InseeCode = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.City.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Récupération de ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +76,7 @@ func GetCities(ctx iris.Context) {
 	var resp models.Cities
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des villes, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des villes, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +87,13 @@ func GetCities(ctx iris.Context) {
 func DeleteCity(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de ville, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Suppression de ville, paramètre : ", err)
 		return
 	}
 	resp := models.City{InseeCode: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Suppression de ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -114,21 +103,22 @@ func DeleteCity(ctx iris.Context) {
 // BatchCities handle the post request to update and insert a batch of cities into the database
 func BatchCities(ctx iris.Context) {
 	var b models.CityBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Villes, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de Villes, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Villes, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Villes, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	var resp models.Cities
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Villes, get all : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Batch de Villes, get all : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -143,16 +133,14 @@ func GetPaginatedCities(ctx iris.Context) {
 	var err error
 	req.Page, err = ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de villes, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Page de villes, décodage Page : ", err)
 		return
 	}
 	req.Search = ctx.URLParam("Search")
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedCities
 	if err = resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de villes, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Page de villes, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
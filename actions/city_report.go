@@ -13,27 +13,23 @@ import (
 func GetCityReport(ctx iris.Context) {
 	firstYear, err := ctx.URLParamInt64("firstYear")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport par commune, décodage firstYear : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Rapport par commune, décodage firstYear : ", err)
 		return
 	}
 	lastYear, err := ctx.URLParamInt64("lastYear")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport par commune, décodage lastYear : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Rapport par commune, décodage lastYear : ", err)
 		return
 	}
 	inseeCode, err := ctx.URLParamInt64("inseeCode")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport par commune, décodage inseeCode : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Rapport par commune, décodage inseeCode : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.CityReport
 	if err = resp.GetAll(db, inseeCode, firstYear, lastYear); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Rapport par commune, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Rapport par commune, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -35,7 +35,7 @@ func testCreateCity(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de ville, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"City":{"InseeCode":0,"Name":"Essai","CommunityID":1,` +
 				`"QPV":true}}`),
@@ -77,7 +77,7 @@ func testUpdateCity(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de ville, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"City":{"InseeCode":0,"Name":"Essai2","CommunityID":null,` +
 				`"QPV":false}}`),
@@ -95,7 +95,7 @@ func testUpdateCity(t *testing.T, c *TestContext, ID int) {
 				`"CommunityID":null,"QPV":false}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de ville, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent: []byte(`{"City":{"InseeCode":` + strconv.Itoa(ID) +
 				`,"Name":"Essai2","CommunityID":3,"QPV":false}}`),
@@ -199,8 +199,9 @@ func testBatchCities(t *testing.T, c *TestContext) {
 				`"247700123","QPV":true},
 			{"InseeCode":78146,"Name":"CHATOU","CommunityCode":"200058519.78",` +
 				`"QPV":false}]}`),
-			RespContains: []string{"Batch de Villes, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : name empty
+			RespContains: []string{"Batch de Villes, requête : lignes incorrectes",
+				`{"Line":1,"Field":"Name","Message":"nom vide"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : name empty
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"City":[{"InseeCode":75101,"Name":"PARIS 1",` +
@@ -223,6 +224,23 @@ func testBatchCities(t *testing.T, c *TestContext) {
 	for _, r := range chkFactory(tcc, f, "BatchCity") {
 		t.Error(r)
 	}
+	tcc = []TestCase{
+		{
+			Token: c.Config.Users.Admin.Token,
+			Sent: []byte(`{"City":[{"InseeCode":75101,"Name":"PARIS 1 BIS",` +
+				`"CommunityCode":"217500016","QPV":false}]}`),
+			RespContains: []string{`"BatchReport":{"DryRun":true,"Lines":1,` +
+				`"Inserted":0,"Updated":1,"Deleted":0,"Unchanged":0,"Errors":[]}`},
+			StatusCode: http.StatusOK}, // 0 : dry run
+	}
+	f = func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/cities").WithQuery("dryRun", true).
+			WithBytes(tc.Sent).WithHeader("Authorization", "Bearer "+tc.Token).
+			Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "BatchCityDryRun") {
+		t.Error(r)
+	}
 }
 
 // testGetPaginatedCities checks if route is user protected and Cities correctly sent back
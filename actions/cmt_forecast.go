@@ -14,8 +14,7 @@ func GetCmtForecasts(ctx iris.Context) {
 	var resp models.CmtForecasts
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Prévisions d'engagement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Prévisions d'engagement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
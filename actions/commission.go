@@ -17,19 +17,16 @@ type CommissionReq struct {
 func CreateCommission(ctx iris.Context) {
 	var req CommissionReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de commission, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de commission, décodage : ", err)
 		return
 	}
 	if err := req.Commission.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de commission : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de commission : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Commission.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de commission, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de commission, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +37,18 @@ func CreateCommission(ctx iris.Context) {
 func UpdateCommission(ctx iris.Context) {
 	var req CommissionReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de commission, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de commission, décodage : ", err)
 		return
 	}
 	if err := req.Commission.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de commission : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de commission : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Commission.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de commission, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de commission, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +59,16 @@ func UpdateCommission(ctx iris.Context) {
 func GetCommission(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de commission, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de commission, paramètre : ", err)
 		return
 	}
 	var resp CommissionReq
 	resp.Commission.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Commission.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de commission, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de commission, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +80,7 @@ func GetCommissions(ctx iris.Context) {
 	var resp models.Commissions
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des commissions, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des commissions, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +91,15 @@ func GetCommissions(ctx iris.Context) {
 func DeleteCommission(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de commission, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de commission, paramètre : ", err)
 		return
 	}
 	resp := models.Commission{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de commission, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de commission, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -46,7 +46,7 @@ func testCreateCommission(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de commission, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Commission":{}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -78,7 +78,7 @@ func testUpdateCommission(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de commission, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Commission":{}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -88,7 +88,7 @@ func testUpdateCommission(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"Commission":{"ID":0,"Name":"Essai2","Date":null}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de commission, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 3 : bad ID
+			StatusCode:   http.StatusNotFound}, // 3 : bad ID
 		{
 			Sent:         []byte(`{"Commission":{"ID":` + strconv.Itoa(ID) + `,"Name":"Essai2","Date":null}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -13,8 +13,7 @@ func GetCommitments(ctx iris.Context) {
 	var resp models.Commitments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des engagements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des engagements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -27,23 +26,25 @@ func GetCommitments(ctx iris.Context) {
 func GetPaginatedCommitments(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page d'engagements, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Page d'engagements, décodage Year : ", err)
 		return
 	}
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page d'engagements, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Page d'engagements, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
-	req := models.PaginatedQuery{Year: year, Page: page, Search: search}
+	req := models.PaginatedQuery{Year: year, Page: page, Search: search,
+		Scope: userScope(ctx)}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Page d'engagements, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedCommitments
 	if err := resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page d'engagements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Page d'engagements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -56,63 +57,98 @@ func GetPaginatedCommitments(ctx iris.Context) {
 func GetUnlinkedCommitments(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page d'engagements non liés, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page d'engagements non liés, décodage Year : ", err)
 		return
 	}
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page d'engagements non liés, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page d'engagements non liés, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
-	req := models.PaginatedQuery{Year: year, Page: page, Search: search}
+	req := models.PaginatedQuery{Year: year, Page: page, Search: search,
+		Scope: userScope(ctx)}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Page d'engagements non liés, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedCommitments
 	if err := resp.GetUnlinked(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page d'engagements non liés, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page d'engagements non liés, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(resp)
 }
 
-// ExportCommitments handles the get request to fetch all commitments that
-// match the given pattern and return a list of commitments with full names
-func ExportCommitments(ctx iris.Context) {
+// GetCursorCommitments handles the get request to fetch the page of
+// commitments following the cursor that match the given pattern and returns
+// them with the cursor of the next page
+func GetCursorCommitments(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export d'engagements, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Engagements par curseur, décodage Year : ", err)
+		return
+	}
+	req := models.PaginatedQuery{Year: year, Search: ctx.URLParam("Search"),
+		Cursor: ctx.URLParam("Cursor"), Scope: userScope(ctx)}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Engagements par curseur, décodage : ", err)
 		return
 	}
-	search := ctx.URLParam("Search")
-	req := models.ExportQuery{Year: year, Search: search}
 	db := ctx.Values().Get("db").(*sql.DB)
-	var resp models.ExportedCommitments
+	var resp models.CursorCommitments
 	if err := resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export d'engagements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements par curseur, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(resp)
 }
 
+// ExportCommitments handles the get request to fetch all commitments that
+// match the given pattern and return a list of commitments with full names
+func ExportCommitments(ctx iris.Context) {
+	year, err := ctx.URLParamInt64("Year")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Export d'engagements, décodage Year : ", err)
+		return
+	}
+	search := ctx.URLParam("Search")
+	req := models.ExportQuery{Year: year, Search: search, Scope: userScope(ctx)}
+	db := ctx.Values().Get("db").(*sql.DB)
+	var resp models.ExportedCommitments
+	sendExport(ctx, "Export d'engagements, ", "ExportedCommitment", "engagements",
+		models.ExportedCommitment{}, func(w *models.ExportWriter) error {
+			return resp.Stream(db, &req, func(row *models.ExportedCommitment) error {
+				return w.WriteRow(row)
+			})
+		})
+}
+
 // BatchCommitments handle the post request to update and insert a batch of commitments into the database
 func BatchCommitments(ctx iris.Context) {
 	var b models.CommitmentBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Engagements, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de Engagements, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Engagements, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Engagements, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -13,19 +13,16 @@ import (
 func LinkCommitment(ctx iris.Context) {
 	var req models.CommitmentLink
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liens d'engagements, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Liens d'engagements, décodage : ", err)
 		return
 	}
 	if err := req.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Liens d'engagements, format : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Liens d'engagements, format : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Set(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liens d'engagements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liens d'engagements, requête : ", err)
 		return
 	}
 	switch req.Type {
@@ -46,14 +43,14 @@ func LinkCommitment(ctx iris.Context) {
 func UnlinkCommitment(ctx iris.Context) {
 	var req models.CommitmentUnlink
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de liens d'engagements, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de liens d'engagements, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Set(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de liens d'engagements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de liens d'engagements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -23,7 +23,7 @@ func testLinkCommitment(t *testing.T, c *TestContext) {
 			Token:        c.Config.Users.Admin.Token,
 			Sent:         []byte(`{`),
 			RespContains: []string{"Liens d'engagements, décodage : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad payload
+			StatusCode:   http.StatusBadRequest}, // 1 : bad payload
 		{
 			Token:        c.Config.Users.Admin.Token,
 			Sent:         []byte(`{"DestID":0,"IDs":[1],"Type":"Copro"}`),
@@ -83,7 +83,7 @@ func testUnlinkCommitment(t *testing.T, c *TestContext) {
 			Token:        c.Config.Users.Admin.Token,
 			Sent:         []byte(`{`),
 			RespContains: []string{"Suppression de liens d'engagements, décodage : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad payload
+			StatusCode:   http.StatusBadRequest}, // 1 : bad payload
 		{
 			Token:        c.Config.Users.Admin.Token,
 			Sent:         []byte(`{"IDs":[2,3,5]}`),
@@ -1,8 +1,12 @@
 package actions
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/iris-contrib/httpexpect"
@@ -12,6 +16,7 @@ import (
 func testCommitment(t *testing.T, c *TestContext) {
 	t.Run("Commitment", func(t *testing.T) {
 		testBatchCommitments(t, c)
+		testUploadCommitments(t, c)
 		testGetCommitments(t, c)
 		testGetPaginatedCommitments(t, c)
 		testGetUnlinkedCommitments(t, c)
@@ -28,10 +33,11 @@ func testBatchCommitments(t *testing.T, c *TestContext) {
 	tcc := []TestCase{
 		*c.AdminCheckTestCase, // 0 : user unauthorized
 		{
-			Token:        c.Config.Users.Admin.Token,
-			Sent:         []byte(`{"Commitment":[{"Year":2010}]}`),
-			RespContains: []string{"Batch de Engagements, requête : Ligne 1 : champs incorrects"},
-			StatusCode:   http.StatusInternalServerError}, // 1 : validation error
+			Token: c.Config.Users.Admin.Token,
+			Sent:  []byte(`{"Commitment":[{"Year":2010}]}`),
+			RespContains: []string{"Batch de Engagements, requête : lignes incorrectes",
+				`{"Line":1,"Field":"Number","Message":"numéro nul"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : validation error
 		{
 			Token:        c.Config.Users.Admin.Token,
 			Sent:         correctBatch,
@@ -48,6 +54,101 @@ func testBatchCommitments(t *testing.T, c *TestContext) {
 	// the testGetCommitments is used to check datas have been correctly imported
 }
 
+// xlsxContent returns an Excel file whose first sheet contains the rows,
+// numbers without leading zero being stored as such and other cells as shared
+// strings
+func xlsxContent(rows [][]string) []byte {
+	var sheet, sst bytes.Buffer
+	count := 0
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			ref := string(rune('A'+j%26)) + strconv.Itoa(i+1)
+			if j >= 26 {
+				ref = string(rune('A'+j/26-1)) + ref
+			}
+			if _, err := strconv.ParseFloat(cell, 64); err == nil && cell[0] != '0' {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, cell)
+				continue
+			}
+			fmt.Fprintf(&sheet, `<c r="%s" t="s"><v>%d</v></c>`, ref, count)
+			fmt.Fprintf(&sst, `<si><t>%s</t></si>`, cell)
+			count++
+		}
+		sheet.WriteString(`</row>`)
+	}
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8"?><workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Engagements" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/sharedStrings.xml", `<?xml version="1.0" encoding="UTF-8"?><sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sst.String() + `</sst>`},
+		{"xl/worksheets/sheet1.xml", `<?xml version="1.0" encoding="UTF-8"?><worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheet.String() + `</sheetData></worksheet>`},
+	}
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for _, f := range files {
+		w, _ := z.Create(f.name)
+		w.Write([]byte(f.content))
+	}
+	z.Close()
+	return b.Bytes()
+}
+
+// testUploadCommitments check that batch import accepts XLSX and CSV files
+// using aliases, French number format and dates
+func testUploadCommitments(t *testing.T, c *TestContext) {
+	header := []string{"Exercice", "Code", "Numéro", "Ligne", "Date de création",
+		"Date de modification", "Date de caducité", "Objet", "Montant", "Soldé",
+		"Code bénéficiaire", "Bénéficiaire", "Code IRIS", "Secteur", "Code action",
+		"Action"}
+	csv := []byte("\xef\xbb\xbfExercice;Code;Numéro;Ligne;Date de création;" +
+		"Date de modification;Date de caducité;Objet;Montant;Soldé;" +
+		"Code bénéficiaire;Bénéficiaire;Code IRIS;Secteur;Code action;Action\n" +
+		"2010;IRIS;277678;1;02/02/2010;02/02/2010;02/02/2013;09000660 - 1;" +
+		"2 371 100;O;20186;SCA FONCIERE HABITAT ET HUMANISME;09000660;LO;" +
+		"15400202;Aide à la création de logements locatifs sociaux\n")
+	xlsx := xlsxContent([][]string{header, {"2010", "IRIS", "277678", "1",
+		"40211", "40211", "41307", "09000660 - 1", "2371100", "O", "20186",
+		"SCA FONCIERE HABITAT ET HUMANISME", "09000660", "LO", "15400202",
+		"Aide à la création de logements locatifs sociaux"}})
+	badDate := bytes.Replace(csv, []byte("02/02/2013"), []byte("31/31/2013"), 1)
+	tcc := []TestCase{
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "engagements.pdf",
+			Sent:         csv,
+			RespContains: []string{"Batch de Engagements, décodage : format de fichier"},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad extension
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "engagements.csv",
+			Sent:   badDate,
+			RespContains: []string{"Batch de Engagements, décodage : ligne 2, " +
+				"colonne Date de caducité"},
+			StatusCode: http.StatusBadRequest}, // 1 : bad date
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "engagements.csv",
+			Sent:         csv,
+			RespContains: []string{`"BatchReport":{"DryRun":true,"Lines":1,`},
+			StatusCode:   http.StatusOK}, // 2 : csv ok
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "engagements.xlsx",
+			Sent:         xlsx,
+			RespContains: []string{`"BatchReport":{"DryRun":true,"Lines":1,`},
+			StatusCode:   http.StatusOK}, // 3 : xlsx ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/commitments").WithQuery("dryRun", true).
+			WithMultipart().WithFileBytes("file", tc.Params, tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UploadCommitment") {
+		t.Error(r)
+	}
+}
+
 // testGetCommitments checks if route is user protected and Commitments correctly sent back
 func testGetCommitments(t *testing.T, c *TestContext) {
 	tcc := []TestCase{
@@ -85,7 +186,7 @@ func testGetPaginatedCommitments(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Page=2&Year=a&Search=savigny`),
 			RespContains: []string{`Page d'engagements, décodage Year :`},
 			Count:        1,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad params query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad params query
 		{
 			Token: c.Config.Users.User.Token,
 			Sent:  []byte(`Page=2&Year=2010&Search=savigny`),
@@ -119,7 +220,7 @@ func testGetUnlinkedCommitments(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Page=2&Year=a&Search=savigny`),
 			RespContains: []string{`Page d'engagements non liés, décodage Year :`},
 			Count:        1,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad params query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad params query
 		{
 			Token: c.Config.Users.User.Token,
 			Sent:  []byte(`Page=2&Year=2010&Search=savigny`),
@@ -153,7 +254,7 @@ func testExportedCommitments(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Year=a&Search=savigny`),
 			RespContains: []string{`Export d'engagements, décodage Year :`},
 			Count:        1,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad params query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad params query
 		{
 			Token: c.Config.Users.User.Token,
 			Sent:  []byte(`Year=2010&Search=savigny`),
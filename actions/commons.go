@@ -1,11 +1,96 @@
 package actions
 
-// jsonError is used to embed JSON response for an error message
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// jsonError is the envelope of the errors sent back. Error is the localised
+// message, Code a stable code identifying the kind of error, Field the field
+// of a validation error and RequestID the ID of the request to find it in the
+// logs.
 type jsonError struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	Code      string `json:"Code"`
+	Field     string `json:"Field,omitempty"`
+	RequestID string `json:"RequestID,omitempty"`
 }
 
 // jsonMessage is used to embed JSON response for a message
 type jsonMessage struct {
 	Message string `json:"Message"`
 }
+
+// batchReportResp is used to send back the report of a dry run batch import
+type batchReportResp struct {
+	BatchReport *models.BatchReport `json:"BatchReport"`
+}
+
+// batchErrorResp is used to send back the errors of the lines of a batch
+type batchErrorResp struct {
+	jsonError
+	BatchReport *models.BatchReport `json:"BatchReport"`
+}
+
+// isDryRun returns true if the dryRun query parameter of a batch request is
+// set, the batch being only checked and not saved
+func isDryRun(ctx iris.Context) bool {
+	dryRun, _ := ctx.URLParamBool("dryRun")
+	return dryRun
+}
+
+// batchOptions returns the options of a batch request, the file name being
+// the one of the uploaded file stored by readBatch
+func batchOptions(ctx iris.Context) models.BatchOptions {
+	return models.BatchOptions{DryRun: isDryRun(ctx),
+		FileName: ctx.Values().GetString("batchFileName")}
+}
+
+// readBatch decodes a batch either from the JSON body of the request or from
+// the XLSX or CSV file sent in the "file" field of a multipart form. The other
+// fields of the form give the parameters of the batch mapping.
+func readBatch(ctx iris.Context, b models.UploadableBatch) error {
+	if !strings.HasPrefix(ctx.GetContentTypeRequested(), "multipart/form-data") {
+		return ctx.ReadJSON(b)
+	}
+	file, header, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	ctx.Values().Set("batchFileName", header.Filename)
+	params := make(map[string]string)
+	for _, p := range b.Mapping().Params {
+		params[p.Field] = ctx.FormValue(p.Field)
+	}
+	return models.DecodeBatchFile(header.Filename, file, header.Size, params, b)
+}
+
+// sendBatchError sends the error of a batch import using the prefix of the
+// request. If some lines are incorrect, the report is sent with their errors.
+func sendBatchError(ctx iris.Context, prefix string, r *models.BatchReport, err error) {
+	if err != models.ErrBatchInvalid {
+		sendError(ctx, http.StatusInternalServerError, prefix, err)
+		return
+	}
+	ctx.Values().Set("error", prefix+err.Error())
+	ctx.StatusCode(http.StatusBadRequest)
+	ctx.JSON(batchErrorResp{BatchReport: r, jsonError: jsonError{
+		Error:     prefix + err.Error(),
+		Code:      codeBatchInvalid,
+		RequestID: requestID(ctx)}})
+}
+
+// sendDryRunReport sends the report if the batch import is a dry run and
+// returns true in that case
+func sendDryRunReport(ctx iris.Context, r *models.BatchReport) bool {
+	if !r.DryRun {
+		return false
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(batchReportResp{r})
+	return true
+}
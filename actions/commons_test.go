@@ -56,6 +56,7 @@ type TestContext struct {
 	RPCheckTestCase             *TestCase
 	RPPreProgCheckTestCase      *TestCase
 	ReservationFeeCheckTestCase *TestCase
+	Mails                       *fakeSMTP
 }
 
 // TestAll embeddes all test functions and is the only test entry point
@@ -64,6 +65,7 @@ type TestContext struct {
 func TestAll(t *testing.T) {
 	cfg := initializeTests(t)
 	testUser(t, cfg)
+	testUserSession(t, cfg)
 	testHomeMessage(t, cfg)
 	testDepartment(t, cfg)
 	testCommunity(t, cfg)
@@ -125,6 +127,25 @@ func TestAll(t *testing.T) {
 	testAvgPmtTime(t, cfg)
 	testPaymentDemands(t, cfg)
 	testPaymentDelays(t, cfg)
+	testAuditLog(t, cfg)
+	testImportRun(t, cfg)
+	testCache(t, cfg)
+	testRoutePermissions(t, cfg)
+	testRole(t, cfg)
+	testPassword(t, cfg)
+	testSecurityLog(t, cfg)
+	testTOTP(t, cfg)
+	testAPIKey(t, cfg)
+	testOIDC(t, cfg)
+	testOpenAPI(t, cfg)
+	testErrors(t, cfg)
+	testRequestLog(t, cfg)
+	testMetrics(t, cfg)
+	testHealth(t, cfg)
+	testPaginatedQuery(t, cfg)
+	testExports(t, cfg)
+	testIngestion(t, cfg)
+	testMigrations(t, cfg)
 }
 
 func initializeTests(t *testing.T) *TestContext {
@@ -144,13 +165,17 @@ func initializeTests(t *testing.T) *TestContext {
 	}
 	testCtx.App.Logger().Infof("Lancement des tests\n")
 	testCtx.Config = cfg
-	testCtx.DB, err = config.InitDatabase(cfg, testCtx.App, true, false)
+	testCtx.DB, err = config.InitDatabase(cfg, testCtx.App, true, true)
 	if err != nil {
 		t.Error("Erreur de connexion à postgres : " + err.Error())
 		t.FailNow()
 		return nil
 	}
 	createUsers(t, testCtx.DB, testCtx.Config)
+	SetTokenStore(NewDBTokenStore(testCtx.DB))
+	SetTokenPolicy(config.NewTokenPolicy(cfg))
+	testCtx.Mails = startFakeSMTP(t)
+	SetMailer(testCtx.Mails.mailer())
 	SetRoutes(testCtx.App, testCtx.Config.Users.SuperAdmin.Email, testCtx.DB)
 	testCtx.E = httptest.New(t, testCtx.App)
 	fetchTokens(t, testCtx)
@@ -159,6 +184,11 @@ func initializeTests(t *testing.T) *TestContext {
 }
 
 func createUsers(t *testing.T, db *sql.DB, cfg *config.PreLoRuGoConf) {
+	if cfg.Users.ObserverUser.Email == "" {
+		cfg.Users.ObserverUser = config.Credentials{
+			Email:    "observateur@iledefrance.fr",
+			Password: "observateur"}
+	}
 	users := []models.User{
 		{
 			Name:     "Christophe Saintillan",
@@ -205,6 +235,11 @@ func createUsers(t *testing.T, db *sql.DB, cfg *config.PreLoRuGoConf) {
 			Email:    cfg.Users.ReservationFeeUser.Email,
 			Password: cfg.Users.ReservationFeeUser.Password,
 			Rights:   models.ActiveReservationMask},
+		{
+			Name:     "Utilisateur observateur",
+			Email:    cfg.Users.ObserverUser.Email,
+			Password: cfg.Users.ObserverUser.Password,
+			Rights:   models.ActiveObserverMask | models.CoproBit},
 	}
 	for _, u := range users {
 		if err := createUser(&u, db); err != nil {
@@ -239,6 +274,7 @@ func fetchTokens(t *testing.T, ctx *TestContext) {
 		&ctx.Config.Users.HousingUser,
 		&ctx.Config.Users.HousingPreProgUser,
 		&ctx.Config.Users.ReservationFeeUser,
+		&ctx.Config.Users.ObserverUser,
 	} {
 		c := fmt.Sprintf(`{"Email":"%s","Password":"%s"}`, u.Email, u.Password)
 		response := ctx.E.POST("/api/user/login").WithBytes([]byte(c)).Expect()
@@ -260,7 +296,7 @@ func createTestCases(ctx *TestContext) {
 	ctx.UserCheckTestCase = &TestCase{
 		Token:        "",
 		RespContains: []string{`Token absent`},
-		StatusCode:   http.StatusInternalServerError,
+		StatusCode:   http.StatusUnauthorized,
 	}
 	ctx.CoproCheckTestCase = &TestCase{
 		Token:        ctx.Config.Users.User.Token,
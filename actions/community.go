@@ -17,19 +17,16 @@ type CommunityReq struct {
 func CreateCommunity(ctx iris.Context) {
 	var req CommunityReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'interco, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'interco, décodage : ", err)
 		return
 	}
 	if err := req.Community.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'interco : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'interco : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Community.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'interco, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création d'interco, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +37,16 @@ func CreateCommunity(ctx iris.Context) {
 func UpdateCommunity(ctx iris.Context) {
 	var req CommunityReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'interco, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'interco, décodage : ", err)
 		return
 	}
 	if err := req.Community.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification d'interco : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'interco : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Community.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'interco, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Modification d'interco, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +57,14 @@ func UpdateCommunity(ctx iris.Context) {
 func GetCommunity(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'interco, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Récupération d'interco, paramètre : ", err)
 		return
 	}
 	var resp CommunityReq
 	resp.Community.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Community.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'interco, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Récupération d'interco, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +76,7 @@ func GetCommunities(ctx iris.Context) {
 	var resp models.Communities
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des intercos, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des intercos, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +87,13 @@ func GetCommunities(ctx iris.Context) {
 func DeleteCommunity(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'interco, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Suppression d'interco, paramètre : ", err)
 		return
 	}
 	resp := models.Community{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'interco, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Suppression d'interco, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -114,21 +103,22 @@ func DeleteCommunity(ctx iris.Context) {
 // BatchCommunities handle the post request to update and insert a batch of communities into the database
 func BatchCommunities(ctx iris.Context) {
 	var b models.CommunityBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Intercos, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de Intercos, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Intercos, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Intercos, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	var resp models.Communities
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Intercos, get all : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Batch de Intercos, get all : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
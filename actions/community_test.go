@@ -34,7 +34,7 @@ func testCreateCommunity(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création d'interco, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Community":{"Code":"","Name":"Essai"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -71,7 +71,7 @@ func testUpdateCommunity(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification d'interco, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 
 		{Sent: []byte(`{"Community":{"ID":` + strconv.Itoa(ID) + `,"Code":"","Name":"Essai2"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -86,7 +86,7 @@ func testUpdateCommunity(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"Community":{"ID":0,"Code":"Essai2","Name":"Essai2"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification d'interco, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent:  []byte(`{"Community":{"ID":` + strconv.Itoa(ID) + `,"Code":"Essai2","Name":"Essai2"}}`),
 			Token: c.Config.Users.Admin.Token,
@@ -183,8 +183,9 @@ func testBatchCommunities(t *testing.T, c *TestContext) {
 				` RIVES DE LA SEINE (DISSOUTE AU 01/01/2016)","DepartmentCode":78},
 			{"Code":"","Name":"VILLE DE PARIS (EPT1)","DepartmentCode":75},
 			{"Code":"200058519.78","Name":"CA SAINT GERMAIN BOUCLES DE SEINE (78-YVELINES)"}]}`),
-			RespContains: []string{"Batch de Intercos, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : code empty
+			RespContains: []string{"Batch de Intercos, requête : lignes incorrectes",
+				`{"Line":2,"Field":"Code","Message":"code vide"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : code empty
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"Community":[{"Code":"200000321","Name":"(EX78) CC DES DEUX` +
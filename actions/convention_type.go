@@ -16,19 +16,17 @@ type conventionTypeReq struct {
 func CreateConventionType(ctx iris.Context) {
 	var req conventionTypeReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de type de convention, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de type de convention, décodage : ", err)
 		return
 	}
 	if err := req.ConventionType.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de type de convention, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de type de convention, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.ConventionType.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de type de convention, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de type de convention, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +37,19 @@ func CreateConventionType(ctx iris.Context) {
 func UpdateConventionType(ctx iris.Context) {
 	var req conventionTypeReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de type de convention, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de type de convention, décodage : ", err)
 		return
 	}
 	if err := req.ConventionType.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de type de convention, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de type de convention, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.ConventionType.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de type de convention, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de type de convention, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +60,15 @@ func UpdateConventionType(ctx iris.Context) {
 func DeleteConventionType(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de type de convention, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de type de convention, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.ConventionType{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de type de convention, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de type de convention, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +80,8 @@ func GetConventionTypes(ctx iris.Context) {
 	var resp models.ConventionTypes
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des types de convention, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des types de convention, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -79,7 +79,7 @@ func testUpdateConventionType(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"ConventionType":{"ID":0,"Name":"PLS"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de type de convention, requête : Type de convention introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"ConventionType":{"ID":` + strconv.Itoa(ID) + `,"Name":""}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -130,7 +130,7 @@ func testDeleteConventionType(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de type de convention, requête : Type de convention introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
@@ -23,40 +23,38 @@ type getCoprosResp struct {
 func GetCopros(ctx iris.Context) {
 	var resp getCoprosResp
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := resp.Copros.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, copros : " + err.Error()})
+	if err := resp.Copros.GetInScope(db, userScope(ctx)); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des copropriétés, copros : ", err)
 		return
 	}
 	if err := resp.Cities.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, communes : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des copropriétés, communes : ", err)
 		return
 	}
 	if err := resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des copropriétés, commissions : ", err)
 		return
 	}
 	if err := resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, actions budgétaires : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des copropriétés, actions budgétaires : ", err)
 		return
 	}
 	year := (int64)(time.Now().Year())
 	if err := resp.FcPreProgs.GetAllOfKind(year, models.KindCopro, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, requête préprogrammation : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des copropriétés, requête préprogrammation : ", err)
 		return
 	}
 	if err := resp.CoproEventTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, requête événements types : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des copropriétés, requête événements types : ", err)
 		return
 	}
 	if err := resp.CoproReports.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des copropriétés, requête rapport : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des copropriétés, requête rapport : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -72,19 +70,16 @@ type coproReq struct {
 func CreateCopro(ctx iris.Context) {
 	var c coproReq
 	if err := ctx.ReadJSON(&c); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de copropriété, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de copropriété, décodage : ", err)
 		return
 	}
 	if err := c.Copro.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de copropriété : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de copropriété : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := c.Copro.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de copropriété, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de copropriété, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -109,56 +104,61 @@ type coproDatasResp struct {
 func GetCoproDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
+	if err = userScope(ctx).CheckCopro(db, ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, périmètre : ", err)
+		return
+	}
 	var resp coproDatasResp
 	resp.Copro.ID = ID
 	if err = resp.Copro.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête copro : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête copro : ", err)
 		return
 	}
 	if err = resp.CoproLinkedCommitments.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête commitment : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête commitment : ", err)
 		return
 	}
 	if err = resp.Payments.GetLinkedToCopro(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête payment : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête payment : ", err)
 		return
 	}
 	if err = resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête commissions : ", err)
 		return
 	}
 	if err = resp.CoproForecasts.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête forecasts : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête forecasts : ", err)
 		return
 	}
 	if err = resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête actions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête actions : ", err)
 		return
 	}
 	if err = resp.CoproEventTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête événements types : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête événements types : ", err)
 		return
 	}
 	if err = resp.FullCoproEvents.GetLinked(db, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête événements : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête événements : ", err)
 		return
 	}
 	if err = resp.CoproDocs.GetAll(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'une copropriété, requête documents : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'une copropriété, requête documents : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -178,13 +178,13 @@ func GetCoproCmtAndPmt(ID int64, ctx iris.Context) {
 	var resp coproCmtPmResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.CoproLinkedCommitments.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Engagements paiements d'une copropriété, requête commitment : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements paiements d'une copropriété, requête commitment : ", err)
 		return
 	}
 	if err := resp.Payments.GetLinkedToCopro(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Engagements paiements d'une copropriété, requête payment : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements paiements d'une copropriété, requête payment : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -195,19 +195,18 @@ func GetCoproCmtAndPmt(ID int64, ctx iris.Context) {
 func ModifyCopro(ctx iris.Context) {
 	var c coproReq
 	if err := ctx.ReadJSON(&c); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de copropriété, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de copropriété, décodage : ", err)
 		return
 	}
 	if err := c.Copro.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de copropriété : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de copropriété : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := c.Copro.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de copropriété, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de copropriété, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -219,14 +218,14 @@ func DeleteCopro(ctx iris.Context) {
 	var c models.Copro
 	var err error
 	if c.ID, err = ctx.Params().GetInt64("CoproID"); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de copropriété, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de copropriété, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = c.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de copropriété, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de copropriété, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -236,15 +235,17 @@ func DeleteCopro(ctx iris.Context) {
 // BatchCopros handle the post request to update and insert a batch of copros into the database
 func BatchCopros(ctx iris.Context) {
 	var c models.CoproBatch
-	if err := ctx.ReadJSON(&c); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de copropriétés, décodage : " + err.Error()})
+	if err := readBatch(ctx, &c); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de copropriétés, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := c.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de copropriétés, requête : " + err.Error()})
+	report, err := c.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de copropriétés, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
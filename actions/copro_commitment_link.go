@@ -11,15 +11,18 @@ import (
 // LinkCommitmentsCopros handles the post request of a batch of CoproCommiment
 func LinkCommitmentsCopros(ctx iris.Context) {
 	var l models.CoproCommitmentBatch
-	if err := ctx.ReadJSON(&l); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liens engagements copros, décodage : " + err.Error()})
+	if err := readBatch(ctx, &l); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Liens engagements copros, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := l.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liens engagements copros, requête : " + err.Error()})
+	report, err := l.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Liens engagements copros, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -22,13 +22,13 @@ func testLinkCommitmentsCopros(t *testing.T, c *TestContext) {
 		{
 			Token:        c.Config.Users.CoproUser.Token,
 			Sent:         []byte(`{"CoproCommitmentBatch":[{"Reference":"","IRISCode":"13021233"}]}`),
-			RespContains: []string{`Ligne 1, Reference vide`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : reference null
+			RespContains: []string{`{"Line":1,"Field":"Reference","Message":"référence vide"}`},
+			StatusCode:   http.StatusBadRequest}, // 1 : reference null
 		{
 			Token:        c.Config.Users.CoproUser.Token,
 			Sent:         []byte(`{"CoproCommitmentBatch":[{"Reference":"Essai3","IRISCode":""}]}`),
-			RespContains: []string{`Ligne 1, IRISCode vide`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : IRIS code empty
+			RespContains: []string{`{"Line":1,"Field":"IRISCode","Message":"code IRIS vide"}`},
+			StatusCode:   http.StatusBadRequest}, // 2 : IRIS code empty
 		{
 			Token:        c.Config.Users.CoproUser.Token,
 			Sent:         []byte(`{"CoproCommitmentBatch":[{"Reference":"CO004","IRISCode":"13021233"}]}`),
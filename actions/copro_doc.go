@@ -13,15 +13,13 @@ import (
 func GetCoproDocs(ctx iris.Context) {
 	CoproID, err := ctx.Params().GetInt64("CoproID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Documents d'une copro, erreur CoproID : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Documents d'une copro, erreur CoproID : ", err)
 		return
 	}
 	var resp models.CoproDocs
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.GetAll(CoproID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Documents d'une copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Documents d'une copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -36,21 +34,19 @@ type coproDocResp struct {
 func CreateCoproDoc(ctx iris.Context) {
 	CoproID, err := ctx.Params().GetInt64("CoproID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'un document copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'un document copro, paramètre : ", err)
 		return
 	}
 	var resp coproDocResp
 	if err = ctx.ReadJSON(&resp); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'un document copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'un document copro, décodage : ", err)
 		return
 	}
 	resp.CoproDoc.CoproID = CoproID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.CoproDoc.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'un document copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création d'un document copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -61,14 +57,13 @@ func CreateCoproDoc(ctx iris.Context) {
 func UpdateCoproDoc(ctx iris.Context) {
 	var resp coproDocResp
 	if err := ctx.ReadJSON(&resp); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification d'un document copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'un document copro, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.CoproDoc.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'un document copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification d'un document copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -79,15 +74,14 @@ func UpdateCoproDoc(ctx iris.Context) {
 func DeleteCoproDoc(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression d'un document copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Suppression d'un document copro, paramètre : ", err)
 		return
 	}
 	coproDoc := models.CoproDoc{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = coproDoc.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'un document copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'un document copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -37,12 +37,12 @@ func testCreateCoproDoc(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`{"CoproDoc":{"Name":null,"Link":"lien de document"}}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Création d'un document copro, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 2 : name null
+			StatusCode:   http.StatusBadRequest}, // 2 : name null
 		{
 			Sent:         []byte(`{"CoproDoc":{"Name":"nom de document","Link":null}}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Création d'un document copro, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : link null
+			StatusCode:   http.StatusBadRequest}, // 3 : link null
 		{
 			Sent:   []byte(`{"CoproDoc":{"Name":"nom de document","Link":"lien de document"}}`),
 			Token:  c.Config.Users.CoproUser.Token,
@@ -76,19 +76,19 @@ func testUpdateCoproDoc(t *testing.T, c *TestContext, ID int) {
 				`"QPV":false}}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification d'un document copro, requête : link vide`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : code nul
+			StatusCode:   http.StatusBadRequest}, // 2 : code nul
 		{
 			Sent: []byte(`{"CoproDoc":{"InseeCode":2000000,"Name":"","CommunityID":null,` +
 				`"QPV":false}}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification d'un document copro, requête : nom vide`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : name empty
+			StatusCode:   http.StatusBadRequest}, // 3 : name empty
 		{
 			Sent: []byte(`{"CoproDoc":{"InseeCode":2000000,"Name":"Essai2",` +
 				`"CommunityID":null,"QPV":false}}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification d'un document copro, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent: []byte(`{"CoproDoc":{"ID":` + strconv.Itoa(ID) +
 				`,"CoproID":` + strconv.FormatInt(c.CoproID, 10) + `,"Name":"nom2 de doc","Link":"lien2 de doc"}}`),
@@ -18,19 +18,18 @@ type coproEventResp struct {
 func CreateCoproEvent(ctx iris.Context) {
 	var req coproEventResp
 	if err := ctx.ReadJSON(&req.C); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'événement Copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création d'événement Copro, décodage : ", err)
 		return
 	}
 	if err := req.C.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'événement Copro : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'événement Copro : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.C.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -41,19 +40,18 @@ func CreateCoproEvent(ctx iris.Context) {
 func UpdateCoproEvent(ctx iris.Context) {
 	var req coproEventResp
 	if err := ctx.ReadJSON(&req.C); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'événement Copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification d'événement Copro, décodage : ", err)
 		return
 	}
 	if err := req.C.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification d'événement Copro : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'événement Copro : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.C.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -64,15 +62,15 @@ func UpdateCoproEvent(ctx iris.Context) {
 func DeleteCoproEvent(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'événement Copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'événement Copro, paramètre : ", err)
 		return
 	}
 	r := models.CoproEvent{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := r.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,16 +82,16 @@ func DeleteCoproEvent(ctx iris.Context) {
 func GetCoproEvent(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'événement Copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'événement Copro, paramètre : ", err)
 		return
 	}
 	var resp coproEventResp
 	resp.C.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.C.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -105,8 +103,8 @@ func GetCoproEvents(ctx iris.Context) {
 	var resp models.CoproEvents
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'événements Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'événements Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -33,7 +33,7 @@ func testCreateCoproEvent(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Création d'événement Copro, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"CoproID":0,"CoproEventTypeID":` + strconv.FormatInt(c.CoproEventTypeID, 10) +
 				`,"Date":"2015-04-13T00:00:00Z","Comment":"Commentaire"}`),
@@ -77,7 +77,7 @@ func testUpdateCoproEvent(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification d'événement Copro, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"ID":` + strconv.Itoa(ID) + `,"CoproID":0` +
 				`,"CoproEventTypeID":` + strconv.FormatInt(c.CoproEventTypeID, 10) +
@@ -98,7 +98,7 @@ func testUpdateCoproEvent(t *testing.T, c *TestContext, ID int) {
 				`,"Date":"2016-04-13T00:00:00Z","Comment":null}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification d'événement Copro, requête : Événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent: []byte(`{"ID":` + strconv.Itoa(ID) + `,"CoproID":` +
 				strconv.FormatInt(c.CoproEventTypeID, 10) +
@@ -128,7 +128,7 @@ func testGetCoproEvent(t *testing.T, c *TestContext, ID int) {
 		{ID: 0,
 			Token:        c.Config.Users.User.Token,
 			RespContains: []string{`Récupération d'événement Copro, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{ID: ID,
 			Token: c.Config.Users.User.Token,
 			RespContains: []string{`{"ID":` + strconv.Itoa(ID) + `,"CoproID":` +
@@ -185,7 +185,7 @@ func testDeleteCoproEvent(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.CoproUser.Token,
 			ID:           0,
 			RespContains: []string{`Suppression d'événement Copro, requête : Événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.CoproUser.Token,
 			ID:           ID,
@@ -18,19 +18,18 @@ type coproEventTypeResp struct {
 func CreateCoproEventType(ctx iris.Context) {
 	var req coproEventTypeResp
 	if err := ctx.ReadJSON(&req.C); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de type d'événement Copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de type d'événement Copro, décodage : ", err)
 		return
 	}
 	if err := req.C.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de type d'événement Copro : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de type d'événement Copro : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.C.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de type d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de type d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -41,19 +40,18 @@ func CreateCoproEventType(ctx iris.Context) {
 func UpdateCoproEventType(ctx iris.Context) {
 	var req coproEventTypeResp
 	if err := ctx.ReadJSON(&req.C); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de type d'événement Copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de type d'événement Copro, décodage : ", err)
 		return
 	}
 	if err := req.C.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de type d'événement Copro : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de type d'événement Copro : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.C.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de type d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de type d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -64,15 +62,15 @@ func UpdateCoproEventType(ctx iris.Context) {
 func DeleteCoproEventType(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de type d'événement Copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de type d'événement Copro, paramètre : ", err)
 		return
 	}
 	r := models.CoproEventType{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := r.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de type d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de type d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,16 +82,16 @@ func DeleteCoproEventType(ctx iris.Context) {
 func GetCoproEventType(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de type d'événement Copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de type d'événement Copro, paramètre : ", err)
 		return
 	}
 	var resp coproEventTypeResp
 	resp.C.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.C.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de type d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de type d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -105,8 +103,8 @@ func GetCoproEventTypes(ctx iris.Context) {
 	var resp models.CoproEventTypes
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération des types d'événement Copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération des types d'événement Copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -36,7 +36,7 @@ func testCreateCoproEventType(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Création de type d'événement Copro, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Name":""}`),
 			Token:        c.Config.Users.CoproUser.Token,
@@ -68,7 +68,7 @@ func testUpdateCoproEventType(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification de type d'événement Copro, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Name":""}`),
 			Token:        c.Config.Users.CoproUser.Token,
@@ -78,7 +78,7 @@ func testUpdateCoproEventType(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"ID":0,"Name":"Comité d'engagement"}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification de type d'événement Copro, requête : Type d'événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : bad ID
+			StatusCode:   http.StatusNotFound}, // 3 : bad ID
 		{
 			Sent:         []byte(`{"ID":` + strconv.Itoa(ID) + `,"Name":"Comité d'engagement"}`),
 			Token:        c.Config.Users.CoproUser.Token,
@@ -103,7 +103,7 @@ func testGetCoproEventType(t *testing.T, c *TestContext, ID int) {
 			ID:           0,
 			Token:        c.Config.Users.User.Token,
 			RespContains: []string{`Récupération de type d'événement Copro, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			ID:           ID,
 			Token:        c.Config.Users.User.Token,
@@ -155,7 +155,7 @@ func testDeleteCoproEventType(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.CoproUser.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de type d'événement Copro, requête : Type d'événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.CoproUser.Token,
 			ID:           ID,
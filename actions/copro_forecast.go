@@ -17,19 +17,24 @@ type CoproForecastReq struct {
 func CreateCoproForecast(ctx iris.Context) {
 	var req CoproForecastReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de prévision copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de prévision copro, décodage : ", err)
 		return
 	}
 	if err := req.CoproForecast.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de prévision copro : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de prévision copro : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
+	if err := userScope(ctx).CheckCoproForecastLinks(db, req.CoproForecast.CoproID,
+		req.CoproForecast.ActionID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de prévision copro, périmètre : ", err)
+		return
+	}
 	if err := req.CoproForecast.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de prévision copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de prévision copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +45,29 @@ func CreateCoproForecast(ctx iris.Context) {
 func UpdateCoproForecast(ctx iris.Context) {
 	var req CoproForecastReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de prévision copro, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de prévision copro, décodage : ", err)
 		return
 	}
 	if err := req.CoproForecast.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de prévision copro : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de prévision copro : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
+	scope := userScope(ctx)
+	err := scope.CheckCoproForecast(db, req.CoproForecast.ID)
+	if err == nil {
+		err = scope.CheckCoproForecastLinks(db, req.CoproForecast.CoproID,
+			req.CoproForecast.ActionID)
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de prévision copro, périmètre : ", err)
+		return
+	}
 	if err := req.CoproForecast.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de prévision copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de prévision copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +78,21 @@ func UpdateCoproForecast(ctx iris.Context) {
 func GetCoproForecast(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de prévision copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision copro, paramètre : ", err)
 		return
 	}
 	var resp CoproForecastReq
 	resp.CoproForecast.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
+	if err = userScope(ctx).CheckCoproForecast(db, ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision copro, périmètre : ", err)
+		return
+	}
 	if err := resp.CoproForecast.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de prévision copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -83,9 +103,9 @@ func GetCoproForecast(ctx iris.Context) {
 func GetCoproForecasts(ctx iris.Context) {
 	var resp models.CoproForecasts
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des prévision copros, requête : " + err.Error()})
+	if err := resp.GetInScope(db, userScope(ctx)); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des prévision copros, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +116,20 @@ func GetCoproForecasts(ctx iris.Context) {
 func DeleteCoproForecast(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de prévision copro, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision copro, paramètre : ", err)
 		return
 	}
 	resp := models.CoproForecast{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
+	if err = userScope(ctx).CheckCoproForecast(db, ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision copro, périmètre : ", err)
+		return
+	}
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de prévision copro, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision copro, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -115,15 +140,18 @@ func DeleteCoproForecast(ctx iris.Context) {
 // of CoproForecasts into the database
 func BatchCoproForecasts(ctx iris.Context) {
 	var b models.CoproForecastBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Prévision copros, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Batch de Prévision copros, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Prévision copros, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Prévision copros, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
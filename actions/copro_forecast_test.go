@@ -35,7 +35,7 @@ func testCreateCoproForecast(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Création de prévision copro, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"CoproForecast":{"CommissionID":0,"Value":1000000,"Comment":"Essai","CoproID":` +
 				strconv.Itoa(int(c.CoproID)) + "}}"),
@@ -94,7 +94,7 @@ func testUpdateCoproForecast(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification de prévision copro, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"CoproForecast":{"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":0,"Comment":"Essai2","CoproID":` +
@@ -127,7 +127,7 @@ func testUpdateCoproForecast(t *testing.T, c *TestContext, ID int) {
 				strconv.Itoa(int(c.CoproID)) + `,"ActionID":3}}`),
 			Token:        c.Config.Users.CoproUser.Token,
 			RespContains: []string{`Modification de prévision copro, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 6 : bad ID
+			StatusCode:   http.StatusNotFound}, // 6 : bad ID
 		{
 			Sent: []byte(`{"CoproForecast":{"ID":` + strconv.Itoa(ID) + `,"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":2000000,"Project":"projet copro","Comment":"Essai2","CoproID":` +
@@ -235,8 +235,9 @@ func testBatchCoproForecasts(t *testing.T, c *TestContext) {
 				strconv.Itoa(int(c.CoproID)) + `,"ActionCode":15400203},{"ID":0,"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":200,"Project":"projet copro 2","Comment":"Batch2","CoproID":` +
 				strconv.Itoa(int(c.CoproID)) + `,"ActionCode":15400203}]}`),
-			RespContains: []string{"Batch de Prévision copros, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : value nul
+			RespContains: []string{"Batch de Prévision copros, requête : lignes incorrectes",
+				`{"Line":1,"Field":"Value","Message":"montant nul"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : value nul
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"CoproForecast":[{"ID":0,"CommissionID":` +
@@ -13,8 +13,8 @@ func GetCoproReport(ctx iris.Context) {
 	var resp models.CoproReports
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"rapport sur les copropriétés, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"rapport sur les copropriétés, requête : ", err)
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(resp)
@@ -112,7 +112,7 @@ func testModifyCopro(t *testing.T, c *TestContext, ID int) {
 				`"Budget":2000000}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de copropriété, requête : Copro introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : bad ID
+			StatusCode:   http.StatusNotFound}, // 3 : bad ID
 		{
 			Sent: []byte(`{"Copro":{"ID":` + strconv.Itoa(ID) + `,"Reference":"CO002",` +
 				`"Name":"Copro2","Address":"adresse2","ZipCode":77001,` +
@@ -187,7 +187,7 @@ func testDeleteCopro(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de copropriété, requête : Copro introuvable`},
 			ID:           0,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Copropriété supprimée`},
@@ -213,16 +213,18 @@ func testBatchCopros(t *testing.T, c *TestContext) {
 				`"ZipCode":77001,"LabelDate":null,"Budget":null},
 			{"Reference":"CO004","Name":"copro4","Address":"adresse4","ZipCode":75000,` +
 				`"LabelDate":42461,"Budget":3000000}]}`),
-			RespContains: []string{`Batch de copropriétés, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 1 : reference empty
+			RespContains: []string{`Batch de copropriétés, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"Reference","Message":"référence vide"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : reference empty
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"Copro":[{"Reference":"","Name":"copro3","Address":"adresse3",` +
 				`"ZipCode":77001,"LabelDate":null,"Budget":null},
 			{"Reference":"CO004","Name":"copro4","Address":"adresse4","ZipCode":75000,` +
 				`"LabelDate":42461,"Budget":3000000}]}`),
-			RespContains: []string{`Batch de copropriétés, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad zip code
+			RespContains: []string{`Batch de copropriétés, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"Reference","Message":"référence vide"}`},
+			StatusCode: http.StatusBadRequest}, // 2 : bad zip code
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"Copro":[{"Reference":"CO003","Name":"copro3",` +
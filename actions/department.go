@@ -17,19 +17,16 @@ type DepartmentReq struct {
 func CreateDepartment(ctx iris.Context) {
 	var req DepartmentReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de département, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de département, décodage : ", err)
 		return
 	}
 	if err := req.Department.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de département : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de département : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Department.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de département, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de département, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +37,18 @@ func CreateDepartment(ctx iris.Context) {
 func UpdateDepartment(ctx iris.Context) {
 	var req DepartmentReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de département, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de département, décodage : ", err)
 		return
 	}
 	if err := req.Department.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de département : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de département : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Department.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de département, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de département, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +59,16 @@ func UpdateDepartment(ctx iris.Context) {
 func GetDepartment(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de département, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de département, paramètre : ", err)
 		return
 	}
 	var resp DepartmentReq
 	resp.Department.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Department.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de département, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de département, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +80,7 @@ func GetDepartments(ctx iris.Context) {
 	var resp models.Departments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des départements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des départements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +91,15 @@ func GetDepartments(ctx iris.Context) {
 func DeleteDepartment(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de département, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de département, paramètre : ", err)
 		return
 	}
 	resp := models.Department{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de département, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de département, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -33,7 +33,7 @@ func testCreateDepartment(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de département, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Department":{"Code":0,"Name":"Essai"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -70,7 +70,7 @@ func testUpdateDepartment(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de département, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Department":{"ID":` + strconv.Itoa(ID) + `,"Code":0,"Name":"Essai2"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -85,7 +85,7 @@ func testUpdateDepartment(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"Department":{"ID":0,"Code":77,"Name":"Essai2"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de département, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent:         []byte(`{"Department":{"ID":` + strconv.Itoa(ID) + `,"Code":77,"Name":"Essai2"}}`),
 			Token:        c.Config.Users.Admin.Token,
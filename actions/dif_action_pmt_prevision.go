@@ -15,8 +15,8 @@ func GetDifActionPaymentPrevisions(ctx iris.Context) {
 	var resp models.DifActionPmtPrevisions
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Prévisions de paiement par action, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Prévisions de paiement par action, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
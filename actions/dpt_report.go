@@ -13,21 +13,19 @@ import (
 func GetDptReport(ctx iris.Context) {
 	firstYear, err := ctx.URLParamInt64("firstYear")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport par département, décodage firstYear : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Rapport par département, décodage firstYear : ", err)
 		return
 	}
 	lastYear, err := ctx.URLParamInt64("lastYear")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport par département, décodage lastYear : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Rapport par département, décodage lastYear : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.DptReport
 	if err = resp.GetAll(db, firstYear, lastYear); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Rapport par département, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Rapport par département, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// The stable codes of the errors sent back, the frontend using them instead
+// of the messages to tell the errors apart
+const (
+	codeValidation      = "validation"
+	codeBadRequest      = "bad_request"
+	codeUnauthorized    = "unauthorized"
+	codeForbidden       = "forbidden"
+	codeNotFound        = "not_found"
+	codeConflict        = "conflict"
+	codeTooManyRequests = "too_many_requests"
+	codeUnavailable     = "unavailable"
+	codeInternal        = "internal"
+	codeBatchInvalid    = "batch_invalid"
+)
+
+// statusCodes gives the code of the errors which are not domain errors
+var statusCodes = map[int]string{
+	http.StatusBadRequest:         codeBadRequest,
+	http.StatusUnauthorized:       codeUnauthorized,
+	http.StatusForbidden:          codeForbidden,
+	http.StatusNotFound:           codeNotFound,
+	http.StatusConflict:           codeConflict,
+	http.StatusTooManyRequests:    codeTooManyRequests,
+	http.StatusServiceUnavailable: codeUnavailable,
+}
+
+// requestIDHeader is the header giving the ID of a request
+const requestIDHeader = "X-Request-ID"
+
+// requestIDMiddleware uses the ID sent by the client or a random one to
+// identify the request and sends it back in the response header
+func requestIDMiddleware(ctx iris.Context) {
+	id := ctx.GetHeader(requestIDHeader)
+	if id == "" || len(id) > 64 {
+		b := make([]byte, 16)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	ctx.Values().Set("requestID", id)
+	ctx.Header(requestIDHeader, id)
+	ctx.Next()
+}
+
+// requestID returns the ID of the current request
+func requestID(ctx iris.Context) string {
+	return ctx.Values().GetString("requestID")
+}
+
+// errorEnvelope returns the envelope of the error whose message is the prefix
+// followed by the error if any. The domain errors of the models give the
+// status and the code, otherwise the status is used and a code derived from
+// it.
+func errorEnvelope(ctx iris.Context, status int, prefix string,
+	err error) (int, jsonError) {
+	resp := jsonError{Error: prefix, RequestID: requestID(ctx)}
+	if err == nil {
+		return status, withStatusCode(status, resp)
+	}
+	resp.Error += err.Error()
+	var v *models.ValidationError
+	switch {
+	case errors.As(err, &v):
+		resp.Code, resp.Field = codeValidation, v.Field
+		return http.StatusBadRequest, resp
+	case errors.Is(err, models.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		status = http.StatusNotFound
+	case errors.Is(err, models.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, models.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, models.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	}
+	return status, withStatusCode(status, resp)
+}
+
+// withStatusCode sets the code of the envelope according to the status
+func withStatusCode(status int, resp jsonError) jsonError {
+	var ok bool
+	if resp.Code, ok = statusCodes[status]; !ok {
+		resp.Code = codeInternal
+	}
+	return resp
+}
+
+// sendError sends back the error envelope with its status. The message is the
+// prefix, giving the context of the error, followed by the error if not nil.
+// It's kept in the context to be logged with the request.
+func sendError(ctx iris.Context, status int, prefix string, err error) {
+	status, resp := errorEnvelope(ctx, status, prefix, err)
+	ctx.Values().Set("error", resp.Error)
+	ctx.StatusCode(status)
+	ctx.JSON(resp)
+}
@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testErrors is the entry point for testing the error envelope
+func testErrors(t *testing.T, c *TestContext) {
+	t.Run("Errors", func(t *testing.T) {
+		testErrorEnvelope(t, c)
+		testRequestID(t, c)
+	})
+}
+
+// testErrorEnvelope checks the status and the code of the domain errors
+func testErrorEnvelope(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			RespContains: []string{`"error":"Token absent"`, `"Code":"unauthorized"`},
+			StatusCode:   http.StatusUnauthorized}, // 0 : no token
+		{
+			Sent:  []byte(`fake`),
+			Token: c.Config.Users.User.Token,
+			RespContains: []string{`"error":"Création de clé d'API, décodage :`,
+				`"Code":"internal"`},
+			StatusCode: http.StatusBadRequest}, // 1 : bad request
+		{
+			Sent:  []byte(`{"Name":"","Expires":"2030-01-01T00:00:00Z"}`),
+			Token: c.Config.Users.User.Token,
+			RespContains: []string{`Champ Name vide ou trop long`,
+				`"Code":"validation"`, `"Field":"Name"`},
+			StatusCode: http.StatusBadRequest}, // 2 : validation error
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/api_key").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "ErrorEnvelope") {
+		t.Error(r)
+	}
+	c.E.DELETE("/api/user/api_key/0").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusNotFound).Body().
+		Contains(`"Code":"not_found"`).Contains(`Clé d'API introuvable`)
+}
+
+// testRequestID checks the request ID is sent back in the header and in the
+// error envelope
+func testRequestID(t *testing.T, c *TestContext) {
+	response := c.E.GET("/api/user/api_keys").
+		WithHeader(requestIDHeader, "test-request").Expect()
+	response.Status(http.StatusUnauthorized)
+	response.Header(requestIDHeader).Equal("test-request")
+	response.Body().Contains(`"RequestID":"test-request"`)
+	if c.E.GET("/api/openapi.json").Expect().Header(requestIDHeader).
+		Raw() == "" {
+		t.Error("RequestID : identifiant de requête absent")
+	}
+}
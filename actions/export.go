@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// sendExport streams the rows sent by stream in the format given by the
+// Format URL parameter, JSON by default. Name is the key of the rows in the
+// JSON format, file the name of the downloaded file and row a row giving the
+// columns. An error occurring once the response has begun can't be sent back
+// and is only logged, the export being truncated.
+func sendExport(ctx iris.Context, prefix, name, file string, row interface{},
+	stream func(w *models.ExportWriter) error) {
+	w, err := models.NewExportWriter(ctx.URLParamDefault("Format",
+		models.JSONExport), name, row, ctx.ResponseWriter())
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, prefix+"décodage Format : ", err)
+		return
+	}
+	// Set directly as ContentType takes the XLSX one for a file name
+	ctx.Header("Content-Type", w.ContentType())
+	if w.Format() != models.JSONExport {
+		ctx.Header("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s.%s"`, file, w.Format()))
+	}
+	ctx.StatusCode(http.StatusOK)
+	if err = stream(w); err == nil {
+		err = w.Close()
+	}
+	switch {
+	case err == nil:
+		return
+	case !w.Started():
+		ctx.ResponseWriter().Header().Del("Content-Disposition")
+		sendError(ctx, http.StatusInternalServerError, prefix+"requête : ", err)
+	default:
+		ctx.Values().Set("error", prefix+"envoi interrompu : "+err.Error())
+	}
+}
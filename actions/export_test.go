@@ -0,0 +1,164 @@
+package actions
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testExports is the entry point for testing the streamed exports and the
+// keyset pagination
+func testExports(t *testing.T, c *TestContext) {
+	t.Run("Exports", func(t *testing.T) {
+		testStreamedPaymentsExport(t, c)
+		testXLSXCommitmentsExport(t, c)
+		testCursorPayments(t, c)
+		testCursorCommitments(t, c)
+	})
+}
+
+// testStreamedPaymentsExport checks the formats of the payments export
+func testStreamedPaymentsExport(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Year=2010&Search=cld&Format=pdf`),
+			RespContains: []string{`Export de paiements, décodage Format : format`, `"Field":"Format"`},
+			StatusCode:   http.StatusBadRequest}, // 0 : unknown format
+		{
+			Token: c.Config.Users.User.Token,
+			Sent:  []byte(`Year=2010&Search=cld&Format=ndjson`),
+			//cSpell: disable
+			RespContains: []string{`{"ID":`, `"Year":2016,"CreationDate":"2016-09-12T00:00:00Z","ModificationDate":"2016-09-19T00:00:00Z","Number":141103,"Value":2392,`, "}\n"},
+			//cSpell: enable
+			Count:         1,
+			CountItemName: `"ID"`,
+			StatusCode:    http.StatusOK}, // 1 : NDJSON
+		{
+			Token: c.Config.Users.User.Token,
+			Sent:  []byte(`Year=2010&Search=cld&Format=csv`),
+			//cSpell: disable
+			RespContains: []string{"ID;Year;CreationDate;ModificationDate;Number;Value;", ";2016;2016-09-12;2016-09-19;141103;2392;2014;", "CLD IMMOBILIER"},
+			//cSpell: enable
+			StatusCode: http.StatusOK}, // 2 : CSV
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/payments/export").WithQueryString(string(tc.Sent)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "StreamedPaymentsExport") {
+		t.Error(r)
+	}
+}
+
+// testXLSXCommitmentsExport checks the commitments export is a workbook whose
+// sheet contains the commitments
+func testXLSXCommitmentsExport(t *testing.T, c *TestContext) {
+	resp := c.E.GET("/api/commitments/export").WithQuery("Year", 2010).
+		WithQuery("Search", "savigny").WithQuery("Format", "xlsx").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK)
+	resp.Header("Content-Disposition").Equal(`attachment; filename="engagements.xlsx"`)
+	body := []byte(resp.Body().Raw())
+	z, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Errorf("XLSXCommitmentsExport : archive incorrecte %v", err)
+		return
+	}
+	for _, f := range z.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Errorf("XLSXCommitmentsExport : ouverture %v", err)
+			return
+		}
+		defer r.Close()
+		sheet, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("XLSXCommitmentsExport : lecture %v", err)
+			return
+		}
+		//cSpell: disable
+		for _, s := range []string{`<t xml:space="preserve">BeneficiaryName</t>`,
+			`<c s="1"><v>`, "SAVIGNY"} {
+			//cSpell: enable
+			if !strings.Contains(string(sheet), s) {
+				t.Errorf("XLSXCommitmentsExport : %s attendu dans la feuille", s)
+			}
+		}
+		return
+	}
+	t.Error("XLSXCommitmentsExport : feuille absente")
+}
+
+// testCursorPayments checks the errors of the keyset pagination of the
+// payments and that the second page follows the first one
+func testCursorPayments(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Year=2010&Cursor=***`),
+			RespContains: []string{`Paiements par curseur, requête : curseur incorrect`, `"Field":"Cursor"`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad cursor
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Year=2010&Sort=Value`),
+			RespContains: []string{`tri incompatible avec la pagination par curseur`, `"Field":"Sort"`},
+			StatusCode:   http.StatusBadRequest}, // 1 : sort with a cursor
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/payments/cursor").WithQueryString(string(tc.Sent)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "CursorPayments") {
+		t.Error(r)
+	}
+	first := c.E.GET("/api/payments/cursor").WithQuery("Year", 2010).
+		WithQuery("PageSize", 2).
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK).JSON().Object()
+	first.Value("Payments").Array().Length().Equal(2)
+	cursor := first.Value("NextCursor").String().NotEmpty().Raw()
+	last := first.Value("Payments").Array().Last().Object()
+	second := c.E.GET("/api/payments/cursor").WithQuery("Year", 2010).
+		WithQuery("PageSize", 2).WithQuery("Cursor", cursor).
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK).JSON().Object()
+	next := second.Value("Payments").Array().First().Object()
+	y1, y2 := last.Value("Year").Number().Raw(), next.Value("Year").Number().Raw()
+	n1, n2 := last.Value("Number").Number().Raw(), next.Value("Number").Number().Raw()
+	if y2 < y1 || (y1 == y2 && n2 < n1) ||
+		last.Value("ID").Number().Raw() == next.Value("ID").Number().Raw() {
+		t.Errorf("CursorPayments : %v suit %v", next.Raw(), last.Raw())
+	}
+}
+
+// testCursorCommitments checks the commitments are fetched in the order of
+// their IDs and that the last page has no cursor
+func testCursorCommitments(t *testing.T, c *TestContext) {
+	first := c.E.GET("/api/commitments/cursor").WithQuery("Year", 2010).
+		WithQuery("PageSize", 3).
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK).JSON().Object()
+	cursor := first.Value("NextCursor").String().NotEmpty().Raw()
+	lastID := first.Value("Commitment").Array().Last().Object().Value("ID").
+		Number().Raw()
+	second := c.E.GET("/api/commitments/cursor").WithQuery("Year", 2010).
+		WithQuery("PageSize", 3).WithQuery("Cursor", cursor).
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK).JSON().Object()
+	second.Value("Commitment").Array().First().Object().Value("ID").Number().
+		Gt(lastID)
+	c.E.GET("/api/commitments/cursor").WithQuery("Year", 2010).
+		WithQuery("Search", "savigny").WithQuery("PageSize", 100).
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK).JSON().Object().
+		Value("NextCursor").String().Empty()
+}
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris"
+)
+
+// readinessCheck checks the state of the database schema, which isn't
+// checked if nil
+var readinessCheck func(db *sql.DB) error
+
+// SetReadinessCheck sets the check of the database schema used by the
+// readiness probe, nil to only check the connectivity
+func SetReadinessCheck(f func(db *sql.DB) error) {
+	readinessCheck = f
+}
+
+// draining is set once the server is stopping so that the readiness probe
+// fails and no new request is sent to the instance
+var draining int32
+
+// SetDraining sets or clears the stopping state of the server
+func SetDraining(d bool) {
+	var v int32
+	if d {
+		v = 1
+	}
+	atomic.StoreInt32(&draining, v)
+}
+
+// readinessTimeout limits the duration of the database checks of the
+// readiness probe
+const readinessTimeout = 2 * time.Second
+
+// GetHealth handles the get request of the liveness probe which only checks
+// the server answers
+func GetHealth(ctx iris.Context) {
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"OK"})
+}
+
+// GetReadiness handles the get request of the readiness probe checking the
+// server isn't stopping, the database can be reached and its schema is up
+// to date
+func GetReadiness(ctx iris.Context) {
+	if atomic.LoadInt32(&draining) != 0 {
+		sendError(ctx, http.StatusServiceUnavailable, "Arrêt en cours", nil)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	c, cancel := context.WithTimeout(ctx.Request().Context(), readinessTimeout)
+	defer cancel()
+	if err := db.PingContext(c); err != nil {
+		sendError(ctx, http.StatusServiceUnavailable,
+			"Base de données inaccessible : ", err)
+		return
+	}
+	if readinessCheck != nil {
+		if err := readinessCheck(db); err != nil {
+			sendError(ctx, http.StatusServiceUnavailable, "Migrations : ", err)
+			return
+		}
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"OK"})
+}
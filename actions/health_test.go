@@ -0,0 +1,45 @@
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Iledant/PreLoRUGo/config"
+)
+
+// testHealth is the entry point for testing the liveness and readiness
+// probes
+func testHealth(t *testing.T, c *TestContext) {
+	t.Run("Health", func(t *testing.T) {
+		testGetHealth(t, c)
+		testGetReadiness(t, c)
+	})
+}
+
+// testGetHealth checks the liveness probe
+func testGetHealth(t *testing.T, c *TestContext) {
+	c.E.GET("/healthz").Expect().Status(http.StatusOK).JSON().Object().
+		ValueEqual("Message", "OK")
+}
+
+// testGetReadiness checks the readiness probe with the migrations check, a
+// failing check and while stopping
+func testGetReadiness(t *testing.T, c *TestContext) {
+	SetReadinessCheck(config.CheckMigrations)
+	defer SetReadinessCheck(nil)
+	c.E.GET("/readyz").Expect().Status(http.StatusOK).JSON().Object().
+		ValueEqual("Message", "OK")
+	SetReadinessCheck(func(db *sql.DB) error {
+		return errors.New("migration 12 (test) : non appliquée")
+	})
+	c.E.GET("/readyz").Expect().Status(http.StatusServiceUnavailable).Body().
+		Contains(`"Code":"unavailable"`).
+		Contains("Migrations : migration 12 (test) : non appliquée")
+	SetReadinessCheck(nil)
+	SetDraining(true)
+	defer SetDraining(false)
+	c.E.GET("/readyz").Expect().Status(http.StatusServiceUnavailable).Body().
+		Contains("Arrêt en cours")
+}
@@ -27,53 +27,43 @@ func GetHome(ctx iris.Context) {
 	var resp homeResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Commitment.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête engagement : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête engagement : ", err)
 		return
 	}
 	if err := resp.Payment.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête paiement : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête paiement : ", err)
 		return
 	}
 	if err := resp.CumulatedProgrammation.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête programmation : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête programmation : ", err)
 		return
 	}
 	if err := resp.ImportLogs.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête logs : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête logs : ", err)
 		return
 	}
 	if err := resp.PaymentCreditSum.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête payment credit sum : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête payment credit sum : ", err)
 		return
 	}
 	if err := resp.HomeMessage.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête home message : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête home message : ", err)
 		return
 	}
 	if err := resp.AveragePayments.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête payments moyens : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête payments moyens : ", err)
 		return
 	}
 	if err := resp.CsfWeekTrend.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête stock CSF : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête stock CSF : ", err)
 		return
 	}
 	if err := resp.FlowStockDelays.Get(90, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête délai de paiement : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête délai de paiement : ", err)
 		return
 	}
 	if err := resp.PaymentRate.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Home requête taux de paiement : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Home requête taux de paiement : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -12,14 +12,13 @@ import (
 func SetHomeMessage(ctx iris.Context) {
 	var req models.HomeMessage
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation du message d'accueil, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Fixation du message d'accueil, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Set(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation du message d'accueil, décodage : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Fixation du message d'accueil, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -17,19 +17,16 @@ type housingReq struct {
 func CreateHousing(ctx iris.Context) {
 	var req housingReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de logement, décodage : ", err)
 		return
 	}
 	if err := req.Housing.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de logement : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de logement : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Housing.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +37,17 @@ func CreateHousing(ctx iris.Context) {
 func UpdateHousing(ctx iris.Context) {
 	var req housingReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de logement, décodage : ", err)
 		return
 	}
 	if err := req.Housing.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de logement : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de logement : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Housing.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Modification de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +58,16 @@ func UpdateHousing(ctx iris.Context) {
 func GetHousing(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'un logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'un logement, paramètre : ", err)
 		return
 	}
 	var resp housingReq
 	resp.Housing.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Housing.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'un logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'un logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +79,7 @@ func GetHousings(ctx iris.Context) {
 	resp := models.Housings{}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des logements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des logements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -109,40 +103,39 @@ func GetHousingsDatas(ctx iris.Context) {
 	var resp HousingsDatasResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Cities.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête cities : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Données logement, requête cities : ", err)
 		return
 	}
 	if err := resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête budget actions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données logement, requête budget actions : ", err)
 		return
 	}
 	if err := resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données logement, requête commissions : ", err)
 		return
 	}
 	if err := resp.HousingForecasts.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête housings forecasts : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données logement, requête housings forecasts : ", err)
 		return
 	}
 	if err := resp.HousingTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête housings types : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données logement, requête housings types : ", err)
 		return
 	}
 	var req models.PaginatedQuery
 	if err := resp.PaginatedHousings.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête paginated housings : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données logement, requête paginated housings : ", err)
 		return
 	}
 	year := (int64)(time.Now().Year())
 	if err := resp.FcPreProgs.GetAllOfKind(year, models.KindHousing, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données logement, requête préprogrammation : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données logement, requête préprogrammation : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -153,15 +146,14 @@ func GetHousingsDatas(ctx iris.Context) {
 func DeleteHousing(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de logement, paramètre : ", err)
 		return
 	}
 	resp := models.Housing{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Suppression de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -171,15 +163,17 @@ func DeleteHousing(ctx iris.Context) {
 // BatchHousings handle the post request to update and insert a batch of housings into the database
 func BatchHousings(ctx iris.Context) {
 	var b models.HousingBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Logements, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de Logements, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Logements, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Logements, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -194,16 +188,19 @@ func GetPaginatedHousings(ctx iris.Context) {
 	var err error
 	req.Page, err = ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de logements, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Page de logements, décodage Page : ", err)
 		return
 	}
 	req.Search = ctx.URLParam("Search")
+	req.Scope = userScope(ctx)
+	if err = readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Page de logements, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedHousings
 	if err = resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de logements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Page de logements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -221,26 +218,25 @@ type housingDatasResp struct {
 func GetHousingDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'un logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Données d'un logement, paramètre : ", err)
 		return
 	}
 	var resp housingDatasResp
 	resp.Housing.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.Housing.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'un logement, housing get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'un logement, housing get : ", err)
 		return
 	}
 	if err = resp.HousingLinkedCommitments.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'un logement, commitments get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'un logement, commitments get : ", err)
 		return
 	}
 	if err = resp.Payments.GetLinkedToHousing(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données d'un logement, payments get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données d'un logement, payments get : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -258,13 +254,13 @@ func GetHousingCmtAndPmt(ID int64, ctx iris.Context) {
 	var resp housingCmtPmtResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.HousingLinkedCommitments.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Engagements paiements d'un logement, commitments get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements paiements d'un logement, commitments get : ", err)
 		return
 	}
 	if err := resp.Payments.GetLinkedToHousing(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Engagements paiements d'un logement, payments get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements paiements d'un logement, payments get : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -16,19 +16,19 @@ type housingCommentReq struct {
 func CreateHousingComment(ctx iris.Context) {
 	var req housingCommentReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de commentaire de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de commentaire de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingComment.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de commentaire de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de commentaire de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingComment.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de commentaire de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de commentaire de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +39,19 @@ func CreateHousingComment(ctx iris.Context) {
 func UpdateHousingComment(ctx iris.Context) {
 	var req housingCommentReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de commentaire de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de commentaire de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingComment.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de commentaire de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de commentaire de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingComment.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de commentaire de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de commentaire de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +62,15 @@ func UpdateHousingComment(ctx iris.Context) {
 func DeleteHousingComment(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de commentaire de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de commentaire de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.HousingComment{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de commentaire de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de commentaire de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +82,8 @@ func GetHousingComments(ctx iris.Context) {
 	var resp models.HousingComments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des commentaires de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des commentaires de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
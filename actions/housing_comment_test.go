@@ -79,7 +79,7 @@ func testUpdateHousingComment(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"HousingComment":{"ID":0,"Name":"type modifié de commentaire"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de commentaire de logement, requête : Commentaire introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"HousingComment":{"ID":` + strconv.Itoa(ID) + `,"Name":""}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -130,7 +130,7 @@ func testDeleteHousingComment(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de commentaire de logement, requête : Commentaire introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
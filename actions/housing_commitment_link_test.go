@@ -22,13 +22,13 @@ func testLinkCommitmentsHousings(t *testing.T, c *TestContext) {
 		{
 			Token:        c.Config.Users.HousingUser.Token,
 			Sent:         []byte(`{"HousingCommitmentBach":[{"Reference":"","IRISCode":"14004240"}]}`),
-			RespContains: []string{`Ligne 1, Reference vide`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : reference null
+			RespContains: []string{`{"Line":1,"Field":"Reference","Message":"référence vide"}`},
+			StatusCode:   http.StatusBadRequest}, // 1 : reference null
 		{
 			Token:        c.Config.Users.HousingUser.Token,
 			Sent:         []byte(`{"HousingCommitmentBach":[{"Reference":"Essai3","IRISCode":""}]}`),
-			RespContains: []string{`Ligne 1, IRISCode vide`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : IRISCode null
+			RespContains: []string{`{"Line":1,"Field":"IRISCode","Message":"code IRIS vide"}`},
+			StatusCode:   http.StatusBadRequest}, // 2 : IRISCode null
 		{
 			Token:        c.Config.Users.HousingUser.Token,
 			Sent:         []byte(`{"HousingCommitmentBach":[{"Reference":"Essai3","IRISCode":"14004240"}]}`),
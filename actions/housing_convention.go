@@ -16,19 +16,19 @@ type housingConventionReq struct {
 func CreateHousingConvention(ctx iris.Context) {
 	var req housingConventionReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de convention de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de convention de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingConvention.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de convention de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de convention de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingConvention.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de convention de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de convention de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +39,19 @@ func CreateHousingConvention(ctx iris.Context) {
 func UpdateHousingConvention(ctx iris.Context) {
 	var req housingConventionReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de convention de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de convention de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingConvention.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de convention de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de convention de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingConvention.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de convention de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de convention de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +62,15 @@ func UpdateHousingConvention(ctx iris.Context) {
 func DeleteHousingConvention(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de convention de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de convention de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.HousingConvention{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de convention de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de convention de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +82,8 @@ func GetHousingConventions(ctx iris.Context) {
 	var resp models.HousingConventions
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des conventions de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des conventions de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
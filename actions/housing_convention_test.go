@@ -79,7 +79,7 @@ func testUpdateHousingConvention(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"HousingConvention":{"ID":0,"Name":"T4"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de convention de logement, requête : Convention introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"HousingConvention":{"ID":` + strconv.Itoa(ID) + `,"Name":""}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -130,7 +130,7 @@ func testDeleteHousingConvention(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de convention de logement, requête : Convention introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
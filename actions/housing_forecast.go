@@ -17,19 +17,18 @@ type HousingForecastReq struct {
 func CreateHousingForecast(ctx iris.Context) {
 	var req HousingForecastReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de prévision logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de prévision logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingForecast.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de prévision logement : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de prévision logement : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingForecast.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de prévision logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de prévision logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +39,18 @@ func CreateHousingForecast(ctx iris.Context) {
 func UpdateHousingForecast(ctx iris.Context) {
 	var req HousingForecastReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de prévision logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de prévision logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingForecast.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de prévision logement : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de prévision logement : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingForecast.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de prévision logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de prévision logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +61,16 @@ func UpdateHousingForecast(ctx iris.Context) {
 func GetHousingForecast(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de prévision logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision logement, paramètre : ", err)
 		return
 	}
 	var resp HousingForecastReq
 	resp.HousingForecast.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.HousingForecast.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de prévision logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +82,8 @@ func GetHousingForecasts(ctx iris.Context) {
 	var resp models.HousingForecasts
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des prévision logements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des prévision logements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +94,15 @@ func GetHousingForecasts(ctx iris.Context) {
 func DeleteHousingForecast(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de prévision logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision logement, paramètre : ", err)
 		return
 	}
 	resp := models.HousingForecast{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de prévision logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -115,15 +113,18 @@ func DeleteHousingForecast(ctx iris.Context) {
 // of HousingForecasts into the database
 func BatchHousingForecasts(ctx iris.Context) {
 	var b models.HousingForecastBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Prévision logements, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Batch de Prévision logements, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Prévision logements, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Prévision logements, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -36,7 +36,7 @@ func testCreateHousingForecast(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.HousingUser.Token,
 			RespContains: []string{`Création de prévision logement, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"HousingForecast":{"CommissionID":0,"Value":1000000,"Comment":"Essai","HousingID":` +
 				strconv.Itoa(int(c.HousingID)) + "}}"),
@@ -94,7 +94,7 @@ func testUpdateHousingForecast(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.HousingUser.Token,
 			RespContains: []string{`Modification de prévision logement, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"HousingForecast":{"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":0,"Comment":"Essai2","HousingID":` +
@@ -119,7 +119,7 @@ func testUpdateHousingForecast(t *testing.T, c *TestContext, ID int) {
 				`"Value":2000000,"Comment":null,"ActionID":2000000}}`),
 			Token:        c.Config.Users.HousingUser.Token,
 			RespContains: []string{`Modification de prévision logement, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 5 : bad ID
+			StatusCode:   http.StatusNotFound}, // 5 : bad ID
 		{
 			Sent: []byte(`{"HousingForecast":{"ID":` + strconv.Itoa(ID) + `,"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":2000000,"Comment":"Essai2","ActionID":10}}`),
@@ -228,8 +228,9 @@ func testBatchHousingForecasts(t *testing.T, c *TestContext) {
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":0,"Comment":"Batch1","ActionID":3},` +
 				`{"ID":0,"CommissionID":` + strconv.Itoa(int(c.CommissionID)) +
 				`,"Value":200,"Comment":"Batch2","ActionID":4}]}`),
-			RespContains: []string{"Batch de Prévision logements, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : value nul
+			RespContains: []string{"Batch de Prévision logements, requête : lignes incorrectes",
+				`{"Line":1,"Field":"Value","Message":"montant nul"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : value nul
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"HousingForecast":[{"ID":0,"CommissionID":` +
@@ -12,15 +12,17 @@ import (
 // summary lines
 func BatchHousingSummary(ctx iris.Context) {
 	var req models.HousingSummary
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de bilan logements, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de bilan logements, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de bilan logements, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de bilan logements, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -39,34 +39,39 @@ func testBatchHousingSummary(t *testing.T, c *TestContext) {
 			RespContains: []string{`Batch de bilan logements, décodage :`},
 		}, // 1 : token empty
 		{
-			Token:        c.Config.Users.HousingUser.Token,
-			Sent:         tcSentBH2,
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de bilan logements, requête : line 1 InseeCode nul`},
+			Token:      c.Config.Users.HousingUser.Token,
+			Sent:       tcSentBH2,
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de bilan logements, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"InseeCode"`},
 		}, // 2 : inseecode nul
 		{
-			Token:        c.Config.Users.HousingUser.Token,
-			Sent:         tcSentBH3,
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de bilan logements, requête : line 1 Address nul`},
+			Token:      c.Config.Users.HousingUser.Token,
+			Sent:       tcSentBH3,
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de bilan logements, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"Address"`},
 		}, // 3 : address nul
 		{
-			Token:        c.Config.Users.HousingUser.Token,
-			Sent:         tcSentBH4,
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de bilan logements, requête : line 1 PLS PLAI and PLUS nul`},
+			Token:      c.Config.Users.HousingUser.Token,
+			Sent:       tcSentBH4,
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de bilan logements, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"PLS"`},
 		}, // 4 : address nul
 		{
-			Token:        c.Config.Users.HousingUser.Token,
-			Sent:         tcSentBH5,
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de bilan logements, requête : line 1 IrisCode nul`},
+			Token:      c.Config.Users.HousingUser.Token,
+			Sent:       tcSentBH5,
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de bilan logements, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"IRISCode"`},
 		}, // 5 : IrisCode nul
 		{
-			Token:        c.Config.Users.HousingUser.Token,
-			Sent:         tcSentBH6,
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de bilan logements, requête : line 1 ReferenceCode nul`},
+			Token:      c.Config.Users.HousingUser.Token,
+			Sent:       tcSentBH6,
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de bilan logements, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"ReferenceCode"`},
 		}, // 6 : ReferenceCode nul
 		{
 			Token:        c.Config.Users.HousingUser.Token,
@@ -102,16 +107,18 @@ func testBatchIRISHousingType(t *testing.T, c *TestContext) {
 			RespContains: []string{`Batch de lien IRIS / type de logement, requête :`},
 		}, // 1 : bad payload
 		{
-			Token:        c.Config.Users.Admin.Token,
-			Sent:         []byte(`{"IRISHousingType":[{"IRISCode":"","HousingTypeShortName":"LF"}]}`),
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de lien IRIS / type de logement, requête :`},
+			Token:      c.Config.Users.Admin.Token,
+			Sent:       []byte(`{"IRISHousingType":[{"IRISCode":"","HousingTypeShortName":"LF"}]}`),
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de lien IRIS / type de logement, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"IRISCode"`},
 		}, // 2 : IRISCode empty
 		{
-			Token:        c.Config.Users.Admin.Token,
-			Sent:         []byte(`{"IRISHousingType":[{"IRISCode":"EX000001","HousingTypeShortName":""}]}`),
-			StatusCode:   http.StatusInternalServerError,
-			RespContains: []string{`Batch de lien IRIS / type de logement, requête :`},
+			Token:      c.Config.Users.Admin.Token,
+			Sent:       []byte(`{"IRISHousingType":[{"IRISCode":"EX000001","HousingTypeShortName":""}]}`),
+			StatusCode: http.StatusBadRequest,
+			RespContains: []string{`Batch de lien IRIS / type de logement, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"HousingTypeShortName"`},
 		}, // 3 : HousingTypeShortName empty
 		{
 			Token:        c.Config.Users.Admin.Token,
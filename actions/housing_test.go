@@ -53,7 +53,7 @@ func testCreateHousing(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de logement, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"Housing":{"Reference":"","Address":"Essai","ZipCode":75101,` +
 				`"PLAI":1000000,"PLUS":1000000,"PLS":1000000,"ANRU":true}}`),
@@ -89,7 +89,7 @@ func testUpdateHousing(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de logement, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"Housing":{"ID":` + strconv.Itoa(ID) + `,"Reference":"",` +
 				`"Address":null,"ZipCode":null,"PLAI":2000000,"PLUS":2000000,` +
@@ -102,7 +102,7 @@ func testUpdateHousing(t *testing.T, c *TestContext, ID int) {
 				`"ZipCode":null,"PLAI":2000000,"PLUS":2000000,"PLS":2000000,"ANRU":false}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de logement, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 3 : bad ID
+			StatusCode:   http.StatusNotFound}, // 3 : bad ID
 		{
 			Sent: []byte(`{"Housing":{"ID":` + strconv.Itoa(ID) +
 				`,"Reference":"Essai2","Address":null,"ZipCode":null,"PLAI":2000000,` +
@@ -123,7 +123,7 @@ func testUpdateHousing(t *testing.T, c *TestContext, ID int) {
 }
 
 // testGetHousing checks if route is user protected and Housing correctly
-// sent back
+//sent back
 func testGetHousing(t *testing.T, c *TestContext, ID int) {
 	tcc := []TestCase{
 		*c.UserCheckTestCase, // 0 : token empty
@@ -131,7 +131,7 @@ func testGetHousing(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.User.Token,
 			ID:           0,
 			RespContains: []string{`Récupération d'un logement, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token: c.Config.Users.User.Token,
 			ID:    ID,
@@ -150,7 +150,7 @@ func testGetHousing(t *testing.T, c *TestContext, ID int) {
 }
 
 // testGetHousingDatas checks if route is user protected and Housing correctly
-// sent back
+//sent back
 func testGetHousingDatas(t *testing.T, c *TestContext, ID int) {
 	tcc := []TestCase{
 		*c.UserCheckTestCase, // 0 : token empty
@@ -158,7 +158,7 @@ func testGetHousingDatas(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.User.Token,
 			ID:           0,
 			RespContains: []string{`Données d'un logement, housing get :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token: c.Config.Users.User.Token,
 			ID:    ID,
@@ -178,7 +178,7 @@ func testGetHousingDatas(t *testing.T, c *TestContext, ID int) {
 }
 
 // testGetHousings checks if route is user protected and Housings correctly
-// sent back
+//sent back
 func testGetHousings(t *testing.T, c *TestContext) {
 	tcc := []TestCase{
 		*c.UserCheckTestCase, // 0 : token empty
@@ -235,8 +235,9 @@ func testBatchHousings(t *testing.T, c *TestContext) {
 				`"ZipCode":null,"PLAI":1,"PLUS":2,"PLS":3,"ANRU":false},
 			{"Reference":"","Address":"Adresse","ZipCode":77001,"PLAI":4,"PLUS":5,` +
 				`"PLS":6,"ANRU":true}]}`),
-			RespContains: []string{"Batch de Logements, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : validation error
+			RespContains: []string{"Batch de Logements, requête : lignes incorrectes",
+				`{"Line":2,"Field":"Reference","Message":"référence vide"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : validation error
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"Housing":[{"Reference":"Essai2","Address":null,` +
@@ -286,7 +287,7 @@ func testGetPaginatedHousings(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Page=a&Search=essai3`),
 			RespContains: []string{`Page de logements, décodage Page :`},
 			Count:        1,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad parameter
+			StatusCode:   http.StatusBadRequest}, // 1 : bad parameter
 		{
 			Token: c.Config.Users.User.Token,
 			Sent:  []byte(`Page=2&Search=essai3`),
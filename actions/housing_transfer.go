@@ -16,19 +16,19 @@ type housingTransferReq struct {
 func CreateHousingTransfer(ctx iris.Context) {
 	var req housingTransferReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de transfert de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de transfert de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingTransfer.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de transfert de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de transfert de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingTransfer.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de transfert de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de transfert de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +39,19 @@ func CreateHousingTransfer(ctx iris.Context) {
 func UpdateHousingTransfer(ctx iris.Context) {
 	var req housingTransferReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de transfert de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de transfert de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingTransfer.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de transfert de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de transfert de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingTransfer.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de transfert de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de transfert de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +62,15 @@ func UpdateHousingTransfer(ctx iris.Context) {
 func DeleteHousingTransfer(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de transfert de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de transfert de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.HousingTransfer{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de transfert de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de transfert de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +82,8 @@ func GetHousingTransfers(ctx iris.Context) {
 	var resp models.HousingTransfers
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des transferts de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des transferts de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
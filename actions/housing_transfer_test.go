@@ -79,7 +79,7 @@ func testUpdateHousingTransfer(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"HousingTransfer":{"ID":0,"Name":"type modifié de transfert"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de transfert de logement, requête : Transfert introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"HousingTransfer":{"ID":` + strconv.Itoa(ID) + `,"Name":""}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -130,7 +130,7 @@ func testDeleteHousingTransfer(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de transfert de logement, requête : Transfert introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
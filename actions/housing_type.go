@@ -16,19 +16,17 @@ type housingTypeReq struct {
 func CreateHousingType(ctx iris.Context) {
 	var req housingTypeReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de type de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de type de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingType.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de type de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de type de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingType.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de type de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de type de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +37,18 @@ func CreateHousingType(ctx iris.Context) {
 func UpdateHousingType(ctx iris.Context) {
 	var req housingTypeReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de type de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de type de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingType.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de type de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de type de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingType.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de type de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de type de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +59,14 @@ func UpdateHousingType(ctx iris.Context) {
 func DeleteHousingType(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de type de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Suppression de type de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.HousingType{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de type de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de type de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +78,8 @@ func GetHousingTypes(ctx iris.Context) {
 	var resp models.HousingTypes
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des types de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des types de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -94,15 +90,18 @@ func GetHousingTypes(ctx iris.Context) {
 // between iris_code and housing types in order to update housing table
 func BatchIRISHousingType(ctx iris.Context) {
 	var req models.IRISHousingTypes
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de lien IRIS / type de logement, requête :" + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Batch de lien IRIS / type de logement, requête :", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de lien IRIS / type de logement, requête :" + err.Error()})
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de lien IRIS / type de logement, requête :", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -79,7 +79,7 @@ func testUpdateHousingType(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"HousingType":{"ID":0,"ShortName":"TML","LongName":"type modifié de logement"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de type de logement, requête : Type introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"HousingType":{"ID":` + strconv.Itoa(ID) + `,"ShortName":"","LongName":"type modifié de logement"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -130,7 +130,7 @@ func testDeleteHousingType(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de type de logement, requête : Type introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
@@ -16,19 +16,19 @@ type housingTypologyReq struct {
 func CreateHousingTypology(ctx iris.Context) {
 	var req housingTypologyReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de typologie de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de typologie de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingTypology.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de typologie de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de typologie de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingTypology.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de typologie de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de typologie de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +39,19 @@ func CreateHousingTypology(ctx iris.Context) {
 func UpdateHousingTypology(ctx iris.Context) {
 	var req housingTypologyReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de typologie de logement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de typologie de logement, décodage : ", err)
 		return
 	}
 	if err := req.HousingTypology.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de typologie de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de typologie de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.HousingTypology.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de typologie de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de typologie de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +62,15 @@ func UpdateHousingTypology(ctx iris.Context) {
 func DeleteHousingTypology(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de typologie de logement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de typologie de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.HousingTypology{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de typologie de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de typologie de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +82,8 @@ func GetHousingTypologies(ctx iris.Context) {
 	var resp models.HousingTypologies
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des typologies de logement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des typologies de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -79,7 +79,7 @@ func testUpdateHousingTypology(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"HousingTypology":{"ID":0,"Name":"T4"}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de typologie de logement, requête : Typologie introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"HousingTypology":{"ID":` + strconv.Itoa(ID) + `,"Name":""}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -130,7 +130,7 @@ func testDeleteHousingTypology(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de typologie de logement, requête : Typologie introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
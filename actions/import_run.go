@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// importRunResp embeddes the reverted import run for json export
+type importRunResp struct {
+	ImportRun models.ImportRun `json:"ImportRun"`
+}
+
+// GetImportRuns handles the get request to fetch the history of the batch
+// imports, optionally filtered by kind, using paginated format
+func GetImportRuns(ctx iris.Context) {
+	page, err := ctx.URLParamInt64("Page")
+	if err != nil {
+		page = 1
+	}
+	var resp models.PaginatedImportRuns
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = resp.Get(db, page, ctx.URLParam("Kind")); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des imports, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// GetImportRunRows handles the get request to fetch the rows changed by an
+// import run using paginated format
+func GetImportRunRows(ctx iris.Context) {
+	ID, err := ctx.Params().GetInt64("ID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Détail d'un import, paramètre : ", err)
+		return
+	}
+	page, err := ctx.URLParamInt64("Page")
+	if err != nil {
+		page = 1
+	}
+	var resp models.PaginatedImportRunRows
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = resp.Get(db, ID, page); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Détail d'un import, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// RevertLastImport handles the post request to revert the last import run of
+// a kind and sends back the reverted run
+func RevertLastImport(ctx iris.Context) {
+	kind := ctx.Params().Get("Kind")
+	revertable := false
+	for _, k := range models.RevertableImports {
+		revertable = revertable || k == kind
+	}
+	if !revertable {
+		sendError(ctx, http.StatusBadRequest,
+			"Annulation d'import, paramètre : import "+kind+" non annulable", nil)
+		return
+	}
+	var resp importRunResp
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err := resp.ImportRun.RevertLastImport(db, kind); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Annulation d'import, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
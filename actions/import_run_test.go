@@ -0,0 +1,159 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testImportRun is the entry point for testing all import run requests
+func testImportRun(t *testing.T, c *TestContext) {
+	t.Run("ImportRun", func(t *testing.T) {
+		testGetImportRuns(t, c)
+		testGetImportRunRows(t, c)
+		testRevertLastImport(t, c)
+		testRevertReinsertedRow(t, c)
+	})
+}
+
+// testGetImportRuns checks route is admin protected and the batches of the
+// previous tests are listed
+func testGetImportRuns(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "Kind=commitment&Page=1",
+			RespContains: []string{`"ImportRun":[{"ID":`, `"Kind":"commitment"`,
+				`"UserName":"Christophe Saintillan"`, `"Page":1`},
+			StatusCode: http.StatusOK}, // 1 : ok
+		{
+			Token:         c.Config.Users.Admin.Token,
+			Params:        "Kind=fake",
+			RespContains:  []string{`"ImportRun":[]`, `"ItemsCount":0`},
+			StatusCode:    http.StatusOK,
+			CountItemName: `"ID"`,
+			Count:         0}, // 2 : unknown kind
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/import_runs").WithQueryString(tc.Params).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetImportRuns") {
+		t.Error(r)
+	}
+}
+
+// testGetImportRunRows checks route is admin protected and the changed rows
+// of a run are sent back
+func testGetImportRunRows(t *testing.T, c *TestContext) {
+	var ID int
+	if err := c.DB.QueryRow(`SELECT max(id) FROM import_run
+	WHERE kind='commitment'`).Scan(&ID); err != nil {
+		t.Errorf("GetImportRunRows, select run : %v", err)
+		return
+	}
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           ID,
+			RespContains: []string{`"ImportRunRow":[{"ID":`, `"Entity":"commitment"`},
+			StatusCode:   http.StatusOK}, // 1 : ok
+		{
+			Token:         c.Config.Users.Admin.Token,
+			ID:            0,
+			RespContains:  []string{`"ImportRunRow":[]`, `"ItemsCount":0`},
+			StatusCode:    http.StatusOK,
+			CountItemName: `"ID"`,
+			Count:         0}, // 2 : unknown run
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/import_run/"+strconv.Itoa(tc.ID)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetImportRunRows") {
+		t.Error(r)
+	}
+}
+
+// testRevertLastImport checks route is admin protected, only some kinds can
+// be reverted and a run can't be reverted twice
+func testRevertLastImport(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Token:        c.Config.Users.User.Token,
+			Params:       "payment_demands",
+			RespContains: []string{`Droits administrateur requis`},
+			StatusCode:   http.StatusUnauthorized}, // 0 : user unauthorized
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "city",
+			RespContains: []string{
+				`Annulation d'import, paramètre : import city non annulable`},
+			StatusCode: http.StatusBadRequest}, // 1 : kind not revertable
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "payment_demands",
+			RespContains: []string{`"ImportRun":{"ID":`,
+				`"Kind":"payment_demands"`, `"Reverted":"`},
+			StatusCode: http.StatusOK}, // 2 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/import_runs/"+tc.Params+"/revert").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "RevertLastImport") {
+		t.Error(r)
+	}
+}
+
+// testRevertReinsertedRow checks a row deleted and then inserted again with
+// the same ID by an import is restored by the revert
+func testRevertReinsertedRow(t *testing.T, c *TestContext) {
+	var ID, value int64
+	if err := c.DB.QueryRow(`SELECT id,value FROM payment ORDER BY id LIMIT 1`).
+		Scan(&ID, &value); err != nil {
+		t.Errorf("RevertReinsertedRow, select : %v", err)
+		return
+	}
+	tx, err := c.DB.Begin()
+	if err != nil {
+		t.Errorf("RevertReinsertedRow, tx : %v", err)
+		return
+	}
+	for i, q := range []string{
+		`WITH run AS (INSERT INTO import_run (kind,created)
+		VALUES('payment',now()) RETURNING id)
+		SELECT set_config('preloru.import_run_id',id::text,true) FROM run`,
+		`CREATE TEMP TABLE reinserted_payment ON COMMIT DROP AS
+		SELECT * FROM payment WHERE id=` + strconv.FormatInt(ID, 10),
+		`UPDATE reinserted_payment SET value=value+1`,
+		`DELETE FROM payment WHERE id=` + strconv.FormatInt(ID, 10),
+		`INSERT INTO payment SELECT * FROM reinserted_payment`,
+	} {
+		if _, err = tx.Exec(q); err != nil {
+			tx.Rollback()
+			t.Errorf("RevertReinsertedRow, requête %d : %v", i, err)
+			return
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("RevertReinsertedRow, commit : %v", err)
+		return
+	}
+	c.E.POST("/api/import_runs/payment/revert").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.Admin.Token).
+		Expect().Status(http.StatusOK)
+	var reverted int64
+	if err = c.DB.QueryRow(`SELECT value FROM payment WHERE id=$1`, ID).
+		Scan(&reverted); err != nil {
+		t.Errorf("RevertReinsertedRow : ligne non restaurée %v", err)
+		return
+	}
+	if reverted != value {
+		t.Errorf("RevertReinsertedRow : valeur %d attendue, reçu %d", value, reverted)
+	}
+}
@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// testIngestion is the entry point for testing the automatic ingestion of the
+// files dropped in a directory
+func testIngestion(t *testing.T, c *TestContext) {
+	t.Run("Ingestion", func(t *testing.T) {
+		testIngestorImport(t, c)
+	})
+}
+
+// testIngestorImport checks an imported file is archived, a file with errors
+// is moved to the error directory and that the outcome of both is stored in
+// import_logs
+func testIngestorImport(t *testing.T, c *TestContext) {
+	dir, err := ioutil.TempDir("", "ingestion")
+	if err != nil {
+		t.Errorf("Ingestion, répertoire temporaire : %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	i := &models.Ingestor{Dir: dir, ArchiveDir: filepath.Join(dir, "archive"),
+		ErrorDir: filepath.Join(dir, "erreur"), DB: c.DB, Logger: c.App.Logger()}
+	for _, d := range []string{i.ArchiveDir, i.ErrorDir} {
+		if err = os.Mkdir(d, 0755); err != nil {
+			t.Errorf("Ingestion, création de %s : %v", d, err)
+			return
+		}
+	}
+	defer c.DB.Exec(`DELETE FROM placement WHERE iris_code='INGEST01'`)
+	tcc := []struct {
+		name, content, dir, status, err string
+	}{
+		{"stages.csv", "Code IRIS;Nombre\nINGEST01;2\n", i.ArchiveDir, "ok", ""},
+		{"stages_ko.csv", "Code IRIS;Nombre\n;2\n", i.ErrorDir, "erreur",
+			"code IRIS vide"},
+	}
+	for _, tc := range tcc {
+		if err = ioutil.WriteFile(filepath.Join(dir, tc.name),
+			[]byte(tc.content), 0644); err != nil {
+			t.Errorf("Ingestion, écriture de %s : %v", tc.name, err)
+			return
+		}
+		i.Scan()
+		files, err := ioutil.ReadDir(tc.dir)
+		if err != nil {
+			t.Errorf("Ingestion, lecture de %s : %v", tc.dir, err)
+			return
+		}
+		moved := false
+		for _, f := range files {
+			moved = moved || strings.HasSuffix(f.Name(), "_"+tc.name)
+		}
+		if !moved {
+			t.Errorf("Ingestion de %s : fichier absent de %s", tc.name, tc.dir)
+		}
+		var (
+			fileName, status string
+			errMsg           sql.NullString
+		)
+		if err = c.DB.QueryRow(`SELECT file_name,status,error FROM import_logs
+		WHERE kind=$1`, models.PlacementImport).Scan(&fileName, &status,
+			&errMsg); err != nil {
+			t.Errorf("Ingestion de %s, import_logs : %v", tc.name, err)
+			continue
+		}
+		if fileName != tc.name || status != tc.status ||
+			!strings.Contains(errMsg.String, tc.err) {
+			t.Errorf("Ingestion de %s : import_logs incorrect %s %s %s", tc.name,
+				fileName, status, errMsg.String)
+		}
+	}
+}
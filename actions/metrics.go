@@ -0,0 +1,324 @@
+package actions
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// metricsToken is the bearer token required by the metrics route, which is
+// disabled if empty
+var metricsToken string
+
+// SetMetricsToken sets the token required to fetch the metrics, an empty one
+// disabling the route
+func SetMetricsToken(token string) {
+	metricsToken = token
+}
+
+// latencyBuckets are the upper bounds in seconds of the buckets of the
+// latency histograms
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// routeKey identifies a route by its method and its template
+type routeKey struct {
+	method string
+	route  string
+}
+
+// statusKey identifies the responses of a route with the same status
+type statusKey struct {
+	routeKey
+	status int
+}
+
+// histogram counts the latencies of a route per bucket, the last count being
+// the one of the latencies above the last bound
+type histogram struct {
+	counts []int64
+	sum    float64
+	count  int64
+}
+
+// httpMetrics stores the number of requests per route and status and the
+// latency histogram of each route
+type httpMetrics struct {
+	mutex     sync.Mutex
+	requests  map[statusKey]int64
+	latencies map[routeKey]*histogram
+}
+
+// requestMetrics is used by the middleware of the API routes
+var requestMetrics = &httpMetrics{requests: make(map[statusKey]int64),
+	latencies: make(map[routeKey]*histogram)}
+
+// observe records a handled request
+func (m *httpMetrics) observe(method, route string, status int,
+	latency time.Duration) {
+	key := routeKey{method: method, route: route}
+	seconds := latency.Seconds()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests[statusKey{routeKey: key, status: status}]++
+	h, ok := m.latencies[key]
+	if !ok {
+		h = &histogram{counts: make([]int64, len(latencyBuckets)+1)}
+		m.latencies[key] = h
+	}
+	i := sort.SearchFloat64s(latencyBuckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// httpMetricsMiddleware records the status and the latency of the request
+// once handled. The route template is used to avoid a series per ID.
+func httpMetricsMiddleware(ctx iris.Context) {
+	start := time.Now()
+	ctx.Next()
+	route := "unknown"
+	if r := ctx.GetCurrentRoute(); r != nil {
+		route = r.Path()
+	}
+	requestMetrics.observe(ctx.Method(), route, ctx.GetStatusCode(),
+		time.Since(start))
+}
+
+// metricsWriter writes the metrics in the Prometheus text format
+type metricsWriter struct {
+	strings.Builder
+}
+
+// labelEscaper escapes the values of the labels
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// header writes the help and the type of a metric
+func (w *metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a value of a metric, the labels being given as name and value
+// pairs
+func (w *metricsWriter) sample(name string, value float64, labels ...string) {
+	w.WriteString(name)
+	for i := 0; i+1 < len(labels); i += 2 {
+		sep := ","
+		if i == 0 {
+			sep = "{"
+		}
+		fmt.Fprintf(w, `%s%s="%s"`, sep, labels[i],
+			labelEscaper.Replace(labels[i+1]))
+	}
+	if len(labels) > 1 {
+		w.WriteString("}")
+	}
+	fmt.Fprintf(w, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// writeTo writes the request counters and the latency histograms sorted by
+// route
+func (m *httpMetrics) writeTo(w *metricsWriter) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	statuses := make([]statusKey, 0, len(m.requests))
+	for k := range m.requests {
+		statuses = append(statuses, k)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.routeKey != b.routeKey {
+			return lessRoute(a.routeKey, b.routeKey)
+		}
+		return a.status < b.status
+	})
+	w.header("prelorugo_http_requests_total", "counter",
+		"Nombre de requêtes par route et par statut")
+	for _, k := range statuses {
+		w.sample("prelorugo_http_requests_total", float64(m.requests[k]),
+			"method", k.method, "route", k.route, "status", strconv.Itoa(k.status))
+	}
+	routes := make([]routeKey, 0, len(m.latencies))
+	for k := range m.latencies {
+		routes = append(routes, k)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return lessRoute(routes[i], routes[j])
+	})
+	w.header("prelorugo_http_request_duration_seconds", "histogram",
+		"Durée de traitement des requêtes par route")
+	for _, k := range routes {
+		h := m.latencies[k]
+		var cumulated int64
+		for i, c := range h.counts {
+			cumulated += c
+			le := "+Inf"
+			if i < len(latencyBuckets) {
+				le = strconv.FormatFloat(latencyBuckets[i], 'g', -1, 64)
+			}
+			w.sample("prelorugo_http_request_duration_seconds_bucket",
+				float64(cumulated), "method", k.method, "route", k.route, "le", le)
+		}
+		w.sample("prelorugo_http_request_duration_seconds_sum", h.sum,
+			"method", k.method, "route", k.route)
+		w.sample("prelorugo_http_request_duration_seconds_count",
+			float64(h.count), "method", k.method, "route", k.route)
+	}
+}
+
+// lessRoute orders the routes by template and method
+func lessRoute(a, b routeKey) bool {
+	if a.route != b.route {
+		return a.route < b.route
+	}
+	return a.method < b.method
+}
+
+// writeDBMetrics writes the statistics of the connection pool
+func writeDBMetrics(w *metricsWriter, s sql.DBStats) {
+	gauges := []struct {
+		name, help string
+		value      int
+	}{
+		{"prelorugo_db_max_open_connections", "Nombre maximal de connexions",
+			s.MaxOpenConnections},
+		{"prelorugo_db_open_connections", "Nombre de connexions ouvertes",
+			s.OpenConnections},
+		{"prelorugo_db_in_use_connections", "Nombre de connexions utilisées",
+			s.InUse},
+		{"prelorugo_db_idle_connections", "Nombre de connexions inactives",
+			s.Idle},
+	}
+	for _, g := range gauges {
+		w.header(g.name, "gauge", g.help)
+		w.sample(g.name, float64(g.value))
+	}
+	counters := []struct {
+		name, help string
+		value      float64
+	}{
+		{"prelorugo_db_wait_count_total", "Nombre d'attentes d'une connexion",
+			float64(s.WaitCount)},
+		{"prelorugo_db_wait_duration_seconds_total",
+			"Durée cumulée des attentes d'une connexion", s.WaitDuration.Seconds()},
+		{"prelorugo_db_max_idle_closed_total",
+			"Nombre de connexions fermées car trop de connexions inactives",
+			float64(s.MaxIdleClosed)},
+		{"prelorugo_db_max_lifetime_closed_total",
+			"Nombre de connexions fermées car trop anciennes",
+			float64(s.MaxLifetimeClosed)},
+	}
+	for _, c := range counters {
+		w.header(c.name, "counter", c.help)
+		w.sample(c.name, c.value)
+	}
+}
+
+// writeCacheMetrics writes the counters and the hit ratio of each cached
+// aggregate
+func writeCacheMetrics(w *metricsWriter) {
+	var stats models.CacheStats
+	stats.Get()
+	metrics := []struct {
+		name, kind, help string
+		value            func(s *models.CacheStat) float64
+	}{
+		{"prelorugo_cache_entries", "gauge", "Nombre d'entrées en cache",
+			func(s *models.CacheStat) float64 { return float64(s.Entries) }},
+		{"prelorugo_cache_hits_total", "counter", "Nombre de lectures en cache",
+			func(s *models.CacheStat) float64 { return float64(s.Hits) }},
+		{"prelorugo_cache_misses_total", "counter", "Nombre de calculs",
+			func(s *models.CacheStat) float64 { return float64(s.Misses) }},
+		{"prelorugo_cache_hit_ratio", "gauge", "Taux de lecture en cache",
+			func(s *models.CacheStat) float64 { return s.HitRate }},
+	}
+	for _, m := range metrics {
+		w.header(m.name, m.kind, m.help)
+		for i := range stats.Lines {
+			w.sample(m.name, m.value(&stats.Lines[i]), "cache", stats.Lines[i].Name)
+		}
+	}
+}
+
+// importKindNames are the labels of the import kinds
+var importKindNames = map[int64]string{
+	models.CommitmentImport:    "commitment",
+	models.PaymentImport:       "payment",
+	models.PaymentDemandImport: "payment_demand",
+	models.PlacementImport:     "placement",
+}
+
+// writeBusinessMetrics writes the payment demands stock, the payment demands
+// without CSF and the time elapsed since the last import of each kind. The
+// counts use the cached aggregates of the home page.
+func writeBusinessMetrics(w *metricsWriter, db *sql.DB) error {
+	var delays models.FlowStockDelays
+	if err := delays.Get(90, db); err != nil {
+		return err
+	}
+	var csf models.CsfWeekTrend
+	if err := csf.Get(db); err != nil {
+		return err
+	}
+	var logs models.ImportLogs
+	if err := logs.GetAll(db); err != nil {
+		return err
+	}
+	w.header("prelorugo_payment_demand_stock", "gauge",
+		"Nombre de demandes de paiement non traitées")
+	w.sample("prelorugo_payment_demand_stock",
+		float64(delays.ActualStockCount.Int64))
+	w.header("prelorugo_payment_demand_unprocessed_csf", "gauge",
+		"Nombre de demandes de paiement sans CSF")
+	w.sample("prelorugo_payment_demand_unprocessed_csf",
+		float64(csf.ThisWeekCount.Int64))
+	w.header("prelorugo_import_age_seconds", "gauge",
+		"Temps écoulé depuis le dernier import par type")
+	for _, l := range logs.Logs {
+		if !l.Date.Valid {
+			continue
+		}
+		name, ok := importKindNames[l.Kind]
+		if !ok {
+			name = strconv.FormatInt(l.Kind, 10)
+		}
+		w.sample("prelorugo_import_age_seconds",
+			time.Since(l.Date.Time).Seconds(), "kind", name)
+	}
+	return nil
+}
+
+// GetMetrics handles the get request to fetch the metrics in the Prometheus
+// text format, the configured token being required as bearer token
+func GetMetrics(ctx iris.Context) {
+	if metricsToken == "" {
+		sendError(ctx, http.StatusNotFound, "Métriques désactivées", nil)
+		return
+	}
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(metricsToken)) != 1 {
+		sendError(ctx, http.StatusUnauthorized, "Token de métriques invalide", nil)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	var w metricsWriter
+	requestMetrics.writeTo(&w)
+	writeDBMetrics(&w, db.Stats())
+	writeCacheMetrics(&w)
+	if err := writeBusinessMetrics(&w, db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Métriques, requête : ", err)
+		return
+	}
+	// Set directly as ContentType takes the version for a file extension
+	ctx.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	ctx.StatusCode(http.StatusOK)
+	ctx.WriteString(w.String())
+}
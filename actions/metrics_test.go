@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testMetrics is the entry point for testing the metrics route
+func testMetrics(t *testing.T, c *TestContext) {
+	t.Run("Metrics", func(t *testing.T) {
+		testGetMetrics(t, c)
+	})
+}
+
+// testGetMetrics checks the token protection and the content of the metrics
+func testGetMetrics(t *testing.T, c *TestContext) {
+	c.E.GET("/metrics").WithHeader("Authorization", "Bearer fake").Expect().
+		Status(http.StatusNotFound).Body().Contains("Métriques désactivées")
+	SetMetricsToken("metrics-token")
+	defer SetMetricsToken("")
+	c.E.GET("/metrics").WithHeader("Authorization", "Bearer fake").Expect().
+		Status(http.StatusUnauthorized).Body().
+		Contains("Token de métriques invalide")
+	c.E.GET("/api/home").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK)
+	response := c.E.GET("/metrics").
+		WithHeader("Authorization", "Bearer metrics-token").Expect()
+	response.Status(http.StatusOK)
+	response.Header("Content-Type").Equal("text/plain; version=0.0.4; charset=utf-8")
+	body := response.Body()
+	for _, s := range []string{
+		"# TYPE prelorugo_http_requests_total counter",
+		`prelorugo_http_requests_total{method="GET",route="/api/home",status="200"} `,
+		`prelorugo_http_request_duration_seconds_bucket{method="GET",route="/api/home",le="+Inf"} `,
+		"prelorugo_db_open_connections ",
+		`prelorugo_cache_hit_ratio{cache="flow_stock_delays"} `,
+		"prelorugo_payment_demand_stock ",
+		"prelorugo_payment_demand_unprocessed_csf ",
+		`prelorugo_import_age_seconds{kind="commitment"} `} {
+		body.Contains(s)
+	}
+}
@@ -19,18 +19,37 @@ func setDBMiddleware(db *sql.DB, superAdminEmail string) func(iris.Context) {
 
 // RightHandler is used by RightsMiddleWare to handle permissions
 // If none of the Masks matches with the user's rights, the Messages is used
-// to send en error back
+// to send en error back. Name identifies the handler in the route permission
+// table. Observers can only use the GET routes unless ObserverWrite is set
+// and can't read the routes if AdminOnly is set, a role being then unable to
+// grant them. API keys are rejected if
+// SessionOnly is set.
 type RightHandler struct {
-	Masks   []int64
-	Message string
+	Name          string
+	Masks         []int64
+	Message       string
+	ObserverWrite bool
+	SessionOnly   bool
+	AdminOnly     bool
 }
 
+// observerMessage is sent back when an observer uses a mutating route
+const observerMessage = "Droits en écriture requis, utilisateur observateur"
+
+// sessionOnlyMessage is sent back when an API key is used on a route
+// requiring a session
+const sessionOnlyMessage = "Session requise, clé d'API refusée"
+
 var admHandler = RightHandler{
-	Masks:   []int64{models.SuperAdminBit, models.ActiveAdminMask},
-	Message: "Droits administrateur requis",
+	Name:        "admin",
+	Masks:       []int64{models.SuperAdminBit, models.ActiveAdminMask},
+	Message:     "Droits administrateur requis",
+	AdminOnly:   true,
+	SessionOnly: true,
 }
 
 var coproHandler = RightHandler{
+	Name: "copro",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -40,6 +59,7 @@ var coproHandler = RightHandler{
 }
 
 var coproPreProgHandler = RightHandler{
+	Name: "copro_pre_prog",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -49,6 +69,7 @@ var coproPreProgHandler = RightHandler{
 }
 
 var rpHandler = RightHandler{
+	Name: "renew_project",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -58,6 +79,7 @@ var rpHandler = RightHandler{
 }
 
 var rpPreProgHandler = RightHandler{
+	Name: "renew_project_pre_prog",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -67,6 +89,7 @@ var rpPreProgHandler = RightHandler{
 }
 
 var housingHandler = RightHandler{
+	Name: "housing",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -76,6 +99,7 @@ var housingHandler = RightHandler{
 }
 
 var housingPreProgHandler = RightHandler{
+	Name: "housing_pre_prog",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -85,11 +109,23 @@ var housingPreProgHandler = RightHandler{
 }
 
 var userHandler = RightHandler{
+	Name:    "user",
 	Masks:   []int64{models.SuperAdminBit, models.ActiveBit},
 	Message: "Connexion requise",
 }
 
+// accountHandler is used by the routes where connected users, observers
+// included, manage their own account, which can't be done with an API key
+var accountHandler = RightHandler{
+	Name:          "account",
+	Masks:         []int64{models.SuperAdminBit, models.ActiveBit},
+	Message:       "Connexion requise",
+	ObserverWrite: true,
+	SessionOnly:   true,
+}
+
 var reservationHandler = RightHandler{
+	Name: "reservation",
 	Masks: []int64{
 		models.SuperAdminBit,
 		models.ActiveAdminMask,
@@ -97,30 +133,112 @@ var reservationHandler = RightHandler{
 	Message: "Droits sur les réservations requis",
 }
 
+// isObserver returns true if the rights are those of an active observer,
+// whatever the other bits
+func isObserver(rights int64) bool {
+	return rights&models.ActiveObserverMask == models.ActiveObserverMask
+}
+
+// isAdmin returns true if the rights are those of an active admin or of the
+// super admin
+func isAdmin(rights int64) bool {
+	return rights&models.SuperAdminBit != 0 ||
+		rights&models.ActiveAdminMask == models.ActiveAdminMask
+}
+
+// userScope returns the scope limiting the rows fetched by the user, nil if
+// the rows aren't limited
+func userScope(ctx iris.Context) *models.Scope {
+	scope, _ := ctx.Values().Get("scope").(*models.Scope)
+	return scope
+}
+
+// errUnscopedRoute is returned when a role limited to a scope grants a route
+// whose rows can't be limited to this scope
+var errUnscopedRoute = models.NewKindError(models.ErrForbidden,
+	"route non accessible à un rôle limité à un périmètre")
+
+// roleScope checks if a role of an active user grants the permission of the
+// current route and stores the scope of the granting roles in the context. A
+// scope is refused on the routes whose handler doesn't apply it.
+func roleScope(ctx iris.Context, rights int64) (granted bool, err error) {
+	permission := routeRolePermissions[ctx.GetCurrentRoute().Name()]
+	if permission == "" || rights&models.ActiveBit == 0 || isAdmin(rights) {
+		return false, nil
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	scope, granted, err := models.GetUserScope(db, userID, permission)
+	if err != nil || !granted {
+		return false, err
+	}
+	if scope != nil {
+		if !scopedRoutes[ctx.GetCurrentRoute().Name()] {
+			return false, errUnscopedRoute
+		}
+		ctx.Values().Set("scope", scope)
+	}
+	return true, nil
+}
+
 // RightsMiddleWare checks if the user attached to the token match with the bit
-// rights sent
+// rights sent or has a role granting the permission of the route. Observers
+// are granted the GET routes not reserved to the admins and rejected on the
+// mutating ones. The roles are only checked if the rights don't match and
+// the rows fetched by a user whose roles grant the route are then limited to
+// the scope of these roles.
 func RightsMiddleWare(r *RightHandler) func(iris.Context) {
 	return func(ctx iris.Context) {
 		u, err := bearerToUser(ctx)
 		if err != nil {
-			ctx.StatusCode(http.StatusInternalServerError)
-			ctx.JSON(jsonError{err.Error()})
+			sendError(ctx, http.StatusInternalServerError, "", err)
+			ctx.StopExecution()
+			return
+		}
+		if r.SessionOnly && ctx.Values().Get("apiKeyID") != nil {
+			sendError(ctx, http.StatusUnauthorized, sessionOnlyMessage, nil)
+			ctx.StopExecution()
+			return
+		}
+		observer := isObserver(u.Rights)
+		if observer && ctx.Method() != http.MethodGet && !r.ObserverWrite {
+			sendError(ctx, http.StatusUnauthorized, observerMessage, nil)
 			ctx.StopExecution()
 			return
 		}
-		rights := true
-		for _, mask := range r.Masks {
+		rights, masks := true, r.Masks
+		if observer && ctx.Method() == http.MethodGet && !r.AdminOnly {
+			masks = userHandler.Masks
+		}
+		for _, mask := range masks {
 			rights = u.Rights&mask == mask
 			if rights {
 				break
 			}
 		}
 		if !rights {
-			ctx.StatusCode(http.StatusUnauthorized)
-			ctx.JSON(jsonError{r.Message})
+			granted, err := roleScope(ctx, u.Rights)
+			if err != nil {
+				sendError(ctx, http.StatusInternalServerError, "Rôles utilisateur : ", err)
+				ctx.StopExecution()
+				return
+			}
+			if !granted {
+				sendError(ctx, http.StatusUnauthorized, r.Message, nil)
+				ctx.StopExecution()
+				return
+			}
+		}
+		closeDB, err := setUserDB(ctx)
+		if err != nil {
+			sendError(ctx, http.StatusInternalServerError, "Connexion utilisateur : ", err)
 			ctx.StopExecution()
 			return
 		}
+		defer closeDB()
 		ctx.Next()
 	}
 }
@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Iledant/PreLoRUGo/config"
+)
+
+// testMigrations is the entry point for testing the migrations. It reverts
+// the whole schema and must be the last test.
+func testMigrations(t *testing.T, c *TestContext) {
+	t.Run("Migrations", func(t *testing.T) {
+		testMigrationChecksum(t, c)
+		testMigrationReplay(t, c)
+	})
+}
+
+// testMigrationChecksum checks a migration applied with another checksum is
+// reported by MigrationStatus and VerifyMigrations
+func testMigrationChecksum(t *testing.T, c *TestContext) {
+	states, err := config.MigrationStatus(c.DB)
+	if err != nil || len(states) == 0 {
+		t.Errorf("MigrationStatus : %v", err)
+		return
+	}
+	last := states[len(states)-1]
+	if _, err = c.DB.Exec(`UPDATE migration SET checksum='modifiée'
+	WHERE version=$1`, last.Version); err != nil {
+		t.Errorf("Checksum, update : %v", err)
+		return
+	}
+	defer c.DB.Exec(`UPDATE migration SET checksum=$1 WHERE version=$2`,
+		last.Checksum, last.Version)
+	if states, err = config.MigrationStatus(c.DB); err != nil {
+		t.Errorf("MigrationStatus : %v", err)
+		return
+	}
+	if s := states[len(states)-1]; s.Valid || s.Applied == nil {
+		t.Errorf("MigrationStatus : migration %d invalide attendue", s.Version)
+	}
+	err = config.VerifyMigrations(c.DB)
+	if err == nil || !strings.Contains(err.Error(), "somme de contrôle différente") {
+		t.Errorf("VerifyMigrations : somme de contrôle différente attendue, reçu %v", err)
+	}
+}
+
+// testMigrationReplay reverts every migration and applies them again
+func testMigrationReplay(t *testing.T, c *TestContext) {
+	if err := config.VerifyMigrations(c.DB); err != nil {
+		t.Errorf("VerifyMigrations : %v", err)
+		return
+	}
+	states, err := config.MigrationStatus(c.DB)
+	if err != nil {
+		t.Errorf("MigrationStatus : %v", err)
+		return
+	}
+	count, err := config.MigrateDown(c.DB, len(states))
+	if err != nil || count != len(states) {
+		t.Errorf("MigrateDown : %d migrations annulées attendues, reçu %d %v",
+			len(states), count, err)
+		return
+	}
+	if states, err = config.MigrationStatus(c.DB); err != nil {
+		t.Errorf("MigrationStatus : %v", err)
+		return
+	}
+	for _, s := range states {
+		if s.Applied != nil {
+			t.Errorf("MigrateDown : migration %d toujours appliquée", s.Version)
+		}
+	}
+	err = config.VerifyMigrations(c.DB)
+	if err == nil || !strings.Contains(err.Error(), "non appliquée") {
+		t.Errorf("VerifyMigrations : non appliquée attendue, reçu %v", err)
+	}
+	if count, err = config.MigrateUp(c.DB); err != nil || count != len(states) {
+		t.Errorf("MigrateUp : %d migrations attendues, reçu %d %v", len(states),
+			count, err)
+		return
+	}
+	if err = config.VerifyMigrations(c.DB); err != nil {
+		t.Errorf("VerifyMigrations : %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package actions
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// oidcProvider is the identity provider used for the single sign-on, nil if
+// not configured
+var oidcProvider *models.OIDCProvider
+
+// SetOIDCProvider sets the identity provider used for the single sign-on
+func SetOIDCProvider(p *models.OIDCProvider) {
+	oidcProvider = p
+}
+
+// oidcStateCookie is the name of the cookie binding the state of a single
+// sign-on to the browser which started it
+const oidcStateCookie = "oidc_state"
+
+// oidcCookiePath restricts the state cookie to the single sign-on routes
+const oidcCookiePath = "/api/user/oidc"
+
+// oidcLoginResp gives the address of the identity provider where the frontend
+// sends the user
+type oidcLoginResp struct {
+	URL string `json:"URL"`
+}
+
+// oidcCallbackReq is used to decode the parameters sent back by the identity
+// provider to the frontend
+type oidcCallbackReq struct {
+	Code  string `json:"Code"`
+	State string `json:"State"`
+}
+
+// OIDCLogin handles the request of the frontend to start a single sign-on and
+// sends back the address of the identity provider
+func OIDCLogin(ctx iris.Context) {
+	if oidcProvider == nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC : authentification unique non configurée", nil)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	state, nonce, verifier, err := models.CreateOIDCLogin(db)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, requête : ", err)
+		return
+	}
+	u, err := oidcProvider.AuthURL(state, nonce, verifier)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, fournisseur : ", err)
+		return
+	}
+	ctx.SetCookie(&http.Cookie{Name: oidcStateCookie, Value: state,
+		Path: oidcCookiePath, MaxAge: int(models.OIDCLoginTTL.Seconds()),
+		HttpOnly: true, Secure: ctx.Request().TLS != nil,
+		SameSite: http.SameSiteLaxMode})
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(oidcLoginResp{u})
+}
+
+// oidcUser fetches the user matching the e-mail of the identity, creating it
+// if allowed, and synchronizes its rights with the groups if a mapping is
+// configured, keeping the super admin and the active bits so that a
+// deactivation by an administrator is not overridden. It sends back an error
+// and returns false if the user can't log in.
+func oidcUser(ctx iris.Context, db *sql.DB, id *models.OIDCIdentity,
+	user *models.User) bool {
+	rights, mapped := int64(0), len(oidcProvider.GroupRights) > 0
+	if mapped {
+		var ok bool
+		if rights, ok = oidcProvider.Rights(id.Groups); !ok {
+			sendError(ctx, http.StatusUnauthorized, "Login OIDC : aucun groupe autorisé", nil)
+			return false
+		}
+	}
+	err := user.GetByEmailFold(id.Email, db)
+	if err == sql.ErrNoRows {
+		if !oidcProvider.Provision {
+			sendError(ctx, http.StatusUnauthorized, "Login OIDC : utilisateur inconnu", nil)
+			return false
+		}
+		if mapped {
+			rights |= models.ActiveBit
+		}
+		return oidcCreateUser(ctx, db, id, rights, user)
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, get : ", err)
+		return false
+	}
+	rights |= user.Rights & (models.SuperAdminBit | models.ActiveBit)
+	if !mapped || rights == user.Rights {
+		return true
+	}
+	user.Rights = rights
+	formerVersion := user.RightsVersion
+	if err = user.Update(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, droits : ", err)
+		return false
+	}
+	if user.RightsVersion != formerVersion {
+		if err = tokens.SetRights(user.ID, user.Rights, user.RightsVersion); err != nil {
+			sendError(ctx, http.StatusInternalServerError, "Login OIDC, sessions : ", err)
+			return false
+		}
+	}
+	return true
+}
+
+// oidcCreateUser creates the user of the identity with an unusable random
+// password. The e-mail is used as name if the name is missing or already used.
+func oidcCreateUser(ctx iris.Context, db *sql.DB, id *models.OIDCIdentity,
+	rights int64, user *models.User) bool {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, mot de passe : ", err)
+		return false
+	}
+	*user = models.User{Name: id.Name, Email: id.Email,
+		Password: hex.EncodeToString(b), Rights: rights}
+	if user.Name == "" || user.Exists(db) != nil {
+		user.Name = id.Email
+	}
+	if err := user.CryptPwd(); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, cryptage : ", err)
+		return false
+	}
+	if err := user.Create(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, création : ", err)
+		return false
+	}
+	return true
+}
+
+// OIDCCallback handles the authorization code sent back by the identity
+// provider to the frontend. The state must match the cookie set when the
+// login started so that a login can't be forged from another browser. The
+// code is exchanged with the PKCE verifier for
+// an ID token whose e-mail gives the user. As for a login with a password,
+// a locked account is rejected, a pre-authentication token is sent back if a
+// second factor is required and otherwise the token of a new session.
+func OIDCCallback(ctx iris.Context) {
+	if oidcProvider == nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC : authentification unique non configurée", nil)
+		return
+	}
+	var req oidcCallbackReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Login OIDC, décodage : ", err)
+		return
+	}
+	if req.Code == "" || req.State == "" {
+		sendError(ctx, http.StatusBadRequest, "Login OIDC : code et état requis", nil)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(ctx.GetCookie(oidcStateCookie)),
+		[]byte(req.State)) != 1 {
+		sendError(ctx, http.StatusUnauthorized, "Login OIDC : état non lié au navigateur", nil)
+		return
+	}
+	ctx.SetCookie(&http.Cookie{Name: oidcStateCookie, Path: oidcCookiePath,
+		MaxAge: -1, HttpOnly: true, Secure: ctx.Request().TLS != nil,
+		SameSite: http.SameSiteLaxMode})
+	db := ctx.Values().Get("db").(*sql.DB)
+	nonce, verifier, err := models.ConsumeOIDCLogin(db, req.State)
+	if err == models.ErrBadOIDCState {
+		sendError(ctx, http.StatusUnauthorized, "Login OIDC : ", err)
+		return
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, état : ", err)
+		return
+	}
+	idToken, err := oidcProvider.Exchange(req.Code, verifier)
+	if err != nil {
+		sendError(ctx, http.StatusUnauthorized, "Login OIDC, échange du code : ", err)
+		return
+	}
+	id, err := oidcProvider.VerifyIDToken(idToken, nonce)
+	if err != nil {
+		sendError(ctx, http.StatusUnauthorized, "Login OIDC, vérification : ", err)
+		return
+	}
+	if id.Email == "" || !id.EmailVerified {
+		sendError(ctx, http.StatusUnauthorized, "Login OIDC : e-mail absent ou non vérifié", nil)
+		return
+	}
+	var user models.User
+	if !oidcUser(ctx, db, id, &user) {
+		return
+	}
+	var state models.LoginState
+	if !checkAccountState(ctx, db, &user, &state) {
+		return
+	}
+	var tf models.TwoFactor
+	if err = tf.Get(db, user.ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, double authentification : ", err)
+		return
+	}
+	if tf.Enabled || twoFactorPolicy.RequiredFor(user.Rights) {
+		sendPreAuthToken(ctx, db, &user, tf.Enabled)
+		return
+	}
+	if err = logSecurityEvent(ctx, db, models.LoginSuccessEvent, user.ID,
+		user.Email); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login OIDC, journal : ", err)
+		return
+	}
+	token, err := setToken(ctx, &user)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(signInResp{token, user})
+}
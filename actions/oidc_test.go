@@ -0,0 +1,277 @@
+package actions
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/iris-contrib/httpexpect"
+)
+
+const (
+	mockClientID     = "preloru"
+	mockClientSecret = "secret"
+)
+
+// mockIdP is a local OpenID Connect identity provider. The test registers the
+// claims of the ID token sent back for an authorization code along with the
+// PKCE challenge of the login.
+type mockIdP struct {
+	server     *httptest.Server
+	key        *rsa.PrivateKey
+	mutex      sync.Mutex
+	challenges map[string]string
+	claims     map[string]jwt.MapClaims
+}
+
+// newMockIdP launches the identity provider with a new signing key
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("OIDC : génération de clé %v", err)
+	}
+	m := &mockIdP{key: key, challenges: make(map[string]string),
+		claims: make(map[string]jwt.MapClaims)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+// register sets the claims of the ID token of the code for the login
+func (m *mockIdP) register(code string, challenge string, claims jwt.MapClaims) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	claims["iss"] = m.server.URL
+	claims["aud"] = []string{mockClientID}
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	m.challenges[code], m.claims[code] = challenge, claims
+}
+
+func (m *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 m.server.URL,
+		"authorization_endpoint": m.server.URL + "/authorize",
+		"token_endpoint":         m.server.URL + "/token",
+		"jwks_uri":               m.server.URL + "/jwks"})
+}
+
+func (m *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	e := big.NewInt(int64(m.key.E)).Bytes()
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test",
+		"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(e)}}})
+}
+
+// token checks the client credentials, the code and the PKCE verifier before
+// sending back the signed ID token
+func (m *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != mockClientID || secret != mockClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+	r.ParseForm()
+	code := r.PostForm.Get("code")
+	h := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	m.mutex.Lock()
+	challenge, claims := m.challenges[code], m.claims[code]
+	delete(m.challenges, code)
+	delete(m.claims, code)
+	m.mutex.Unlock()
+	if claims == nil || base64.RawURLEncoding.EncodeToString(h[:]) != challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+}
+
+// testOIDC is the entry point for testing the single sign-on against a mock
+// identity provider
+func testOIDC(t *testing.T, c *TestContext) {
+	t.Run("OIDC", func(t *testing.T) {
+		c.E.GET("/api/user/oidc/login").Expect().
+			Status(http.StatusInternalServerError).Body().
+			Contains("Login OIDC : authentification unique non configurée")
+		m := newMockIdP(t)
+		defer m.server.Close()
+		SetOIDCProvider(&models.OIDCProvider{
+			Issuer:       m.server.URL,
+			ClientID:     mockClientID,
+			ClientSecret: mockClientSecret,
+			RedirectURL:  "http://localhost/sso",
+			Scopes:       "openid email profile groups",
+			GroupsClaim:  "groups",
+			GroupRights:  map[string]int64{"preloru-copro": models.CoproBit},
+			Provision:    true})
+		defer SetOIDCProvider(nil)
+		testOIDCCallback(t, c, m)
+	})
+}
+
+// oidcLogin starts a single sign-on and returns the state, the nonce and the
+// PKCE challenge sent to the identity provider
+func oidcLogin(t *testing.T, c *TestContext) (string, string, string) {
+	var resp oidcLoginResp
+	response := c.E.GET("/api/user/oidc/login").Expect()
+	if err := json.Unmarshal(response.Content, &resp); err != nil {
+		t.Errorf("OIDCLogin : décodage %v %s", err, string(response.Content))
+		return "", "", ""
+	}
+	u, err := url.Parse(resp.URL)
+	if err != nil || !strings.HasSuffix(u.Path, "/authorize") {
+		t.Errorf("OIDCLogin : adresse %s invalide", resp.URL)
+		return "", "", ""
+	}
+	q := u.Query()
+	if q.Get("client_id") != mockClientID || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("OIDCLogin : paramètres %s invalides", resp.URL)
+	}
+	if cookie := response.Cookie(oidcStateCookie).Raw(); cookie.Value != q.Get("state") ||
+		!cookie.HttpOnly || cookie.Path != oidcCookiePath {
+		t.Errorf("OIDCLogin : cookie d'état %v invalide", cookie)
+	}
+	return q.Get("state"), q.Get("nonce"), q.Get("code_challenge")
+}
+
+// testOIDCCallback checks the code exchange, the matching or the creation of
+// the user by e-mail, the mapping of the groups to rights, the binding of the
+// state to the browser, the rejection of a locked account and that a
+// deactivated account is not reactivated
+func testOIDCCallback(t *testing.T, c *TestContext, m *mockIdP) {
+	email := "sso.user@iledefrance.fr"
+	states := make([]string, 10)
+	for i := range states {
+		state, nonce, challenge := oidcLogin(t, c)
+		claims := jwt.MapClaims{"sub": "sso", "nonce": nonce,
+			"email": email, "email_verified": true, "name": "SSO User",
+			"groups": []string{"preloru-copro", "autre"}}
+		switch i {
+		case 2:
+			claims["groups"] = []string{"autre"}
+		case 3:
+			claims["nonce"] = "faux"
+		case 4:
+			claims["email_verified"] = false
+		case 5:
+			claims["email"] = strings.ToUpper(c.Config.Users.CoproUser.Email)
+		case 7:
+			delete(claims, "email_verified")
+		}
+		m.register(state, challenge, claims)
+		states[i] = state
+	}
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			RespContains: []string{`Login OIDC, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Code":"","State":""}`),
+			RespContains: []string{`Login OIDC : code et état requis`},
+			StatusCode:   http.StatusBadRequest}, // 1 : empty fields
+		{
+			Sent:         []byte(`{"Code":"` + states[0] + `","State":"faux"}`),
+			RespContains: []string{`Login OIDC : état invalide ou expiré`},
+			StatusCode:   http.StatusUnauthorized}, // 2 : bad state
+		{
+			Sent:         []byte(`{"Code":"faux","State":"` + states[1] + `"}`),
+			RespContains: []string{`Login OIDC, échange du code :`, `invalid_grant`},
+			StatusCode:   http.StatusUnauthorized}, // 3 : bad code
+		{
+			Sent: []byte(`{"Code":"` + states[0] + `","State":"` + states[0] + `"}`),
+			RespContains: []string{`"Token"`, `"Email":"` + email + `"`,
+				`"Name":"SSO User"`, `"Rights":9`},
+			StatusCode: http.StatusOK}, // 4 : ok, user created
+		{
+			Sent:         []byte(`{"Code":"` + states[0] + `","State":"` + states[0] + `"}`),
+			RespContains: []string{`Login OIDC : état invalide ou expiré`},
+			StatusCode:   http.StatusUnauthorized}, // 5 : state already used
+		{
+			Sent:         []byte(`{"Code":"` + states[2] + `","State":"` + states[2] + `"}`),
+			RespContains: []string{`Login OIDC : aucun groupe autorisé`},
+			StatusCode:   http.StatusUnauthorized}, // 6 : no mapped group
+		{
+			Sent:         []byte(`{"Code":"` + states[3] + `","State":"` + states[3] + `"}`),
+			RespContains: []string{`Login OIDC, vérification : id_token : nonce invalide`},
+			StatusCode:   http.StatusUnauthorized}, // 7 : bad nonce
+		{
+			Sent:         []byte(`{"Code":"` + states[4] + `","State":"` + states[4] + `"}`),
+			RespContains: []string{`Login OIDC : e-mail absent ou non vérifié`},
+			StatusCode:   http.StatusUnauthorized}, // 8 : e-mail not verified
+		{
+			Sent: []byte(`{"Code":"` + states[5] + `","State":"` + states[5] + `"}`),
+			RespContains: []string{`"Token"`,
+				`"Email":"` + c.Config.Users.CoproUser.Email + `"`},
+			StatusCode: http.StatusOK}, // 9 : existing user matched by e-mail
+		{
+			Sent:         []byte(`{"Code":"` + states[7] + `","State":"` + states[7] + `"}`),
+			RespContains: []string{`Login OIDC : e-mail absent ou non vérifié`},
+			StatusCode:   http.StatusUnauthorized}, // 10 : e-mail_verified missing
+	}
+	// The cookie set by the login is the state sent back unless otherwise tested
+	f := func(tc TestCase) *httpexpect.Response {
+		var req oidcCallbackReq
+		json.Unmarshal(tc.Sent, &req)
+		return c.E.POST("/api/user/oidc/callback").
+			WithCookie(oidcStateCookie, req.State).WithBytes(tc.Sent).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "OIDCCallback") {
+		t.Error(r)
+	}
+	var count int64
+	if err := c.DB.QueryRow(`SELECT count(1) FROM users WHERE email=$1`,
+		email).Scan(&count); err != nil || count != 1 {
+		t.Errorf("OIDCCallback : utilisateur créé introuvable %v", err)
+	}
+	if _, err := c.DB.Exec(`UPDATE users SET locked_until=now()+interval '1 hour'
+	WHERE email=$1`, email); err != nil {
+		t.Errorf("OIDCCallback : verrouillage %v", err)
+		return
+	}
+	c.E.POST("/api/user/oidc/callback").WithCookie(oidcStateCookie, states[6]).
+		WithBytes([]byte(`{"Code":"` + states[6] + `","State":"` + states[6] + `"}`)).
+		Expect().Status(http.StatusTooManyRequests).Body().
+		Contains("Compte temporairement verrouillé")
+	if _, err := c.DB.Exec(`UPDATE users SET locked_until=NULL WHERE email=$1`,
+		email); err != nil {
+		t.Errorf("OIDCCallback : déverrouillage %v", err)
+	}
+	c.E.POST("/api/user/oidc/callback").WithCookie(oidcStateCookie, "faux").
+		WithBytes([]byte(`{"Code":"` + states[8] + `","State":"` + states[8] + `"}`)).
+		Expect().Status(http.StatusUnauthorized).Body().
+		Contains("Login OIDC : état non lié au navigateur")
+	if _, err := c.DB.Exec(`UPDATE users SET rights=rights&~$1::int
+	WHERE email=$2`, models.ActiveBit, email); err != nil {
+		t.Errorf("OIDCCallback : désactivation %v", err)
+		return
+	}
+	c.E.POST("/api/user/oidc/callback").WithCookie(oidcStateCookie, states[9]).
+		WithBytes([]byte(`{"Code":"` + states[9] + `","State":"` + states[9] + `"}`)).
+		Expect().Status(http.StatusOK).Body().Contains(`"Rights":8`)
+}
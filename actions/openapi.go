@@ -0,0 +1,333 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// routeSchema describes the request and the response of a route for the
+// OpenAPI document. Request and Response are values of the types decoded
+// from and sent back in the body, nil if none. Status is the status code of
+// the success, 200 if null.
+type routeSchema struct {
+	Summary  string
+	Query    []queryParam
+	Request  interface{}
+	Response interface{}
+	Status   int
+}
+
+// queryParam is a parameter of the query string. Type is an OpenAPI type.
+type queryParam struct {
+	Name string
+	Type string
+}
+
+// oneOf is used in a route schema when the body can have several types
+type oneOf []interface{}
+
+var (
+	yearParam   = queryParam{Name: "Year", Type: "integer"}
+	pageParam   = queryParam{Name: "Page", Type: "integer"}
+	searchParam = queryParam{Name: "Search", Type: "string"}
+	sizeParam   = queryParam{Name: "PageSize", Type: "integer"}
+	sortParam   = queryParam{Name: "Sort", Type: "string"}
+	cursorParam = queryParam{Name: "Cursor", Type: "string"}
+	formatParam = queryParam{Name: "Format", Type: "string"}
+	dryRunParam = queryParam{Name: "dryRun", Type: "boolean"}
+)
+
+// nullableSchemas gives the schemas of the types with a custom JSON encoding
+var nullableSchemas = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(models.NullTime{}): {"type": "string", "format": "date-time",
+		"nullable": true},
+	reflect.TypeOf(models.NullBool{}):    {"type": "boolean", "nullable": true},
+	reflect.TypeOf(models.NullInt64{}):   {"type": "integer", "format": "int64", "nullable": true},
+	reflect.TypeOf(models.NullString{}):  {"type": "string", "nullable": true},
+	reflect.TypeOf(models.NullFloat64{}): {"type": "number", "format": "double", "nullable": true},
+	reflect.TypeOf(time.Time{}):          {"type": "string", "format": "date-time"},
+}
+
+// pathParamRegexp matches the parameters of a route template with their
+// optional macro
+var pathParamRegexp = regexp.MustCompile(`\{(\w+)(?::(\w+))?\}`)
+
+// schemaBuilder converts the Go types to OpenAPI schemas, the named structs
+// being stored as components, and the routes to operations
+type schemaBuilder struct {
+	components   map[string]interface{}
+	names        map[reflect.Type]string
+	operationIDs map[string]int
+}
+
+// ref returns the schema of the value of a route schema
+func (b *schemaBuilder) ref(v interface{}) map[string]interface{} {
+	if o, ok := v.(oneOf); ok {
+		schemas := make([]interface{}, len(o))
+		for i, s := range o {
+			schemas[i] = b.schema(reflect.TypeOf(s))
+		}
+		return map[string]interface{}{"oneOf": schemas}
+	}
+	return b.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of the type
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	if s, ok := nullableSchemas[t]; ok {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object",
+			"additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + b.component(t)}
+	}
+	return map[string]interface{}{}
+}
+
+// component stores the schema of the named struct and returns its name,
+// qualified by its package if another type has the same name
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, used := b.components[name]; used {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	b.names[t] = name
+	b.components[name] = nil
+	b.components[name] = b.object(t)
+	return name
+}
+
+// object returns the schema of a struct, following the encoding/json rules
+// for the tags and the embedded structs
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	b.fields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// fields adds the properties of the struct fields, the fields of an embedded
+// struct being promoted unless a shallower field has the same name
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]interface{}) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct &&
+			nullableSchemas[ft] == nil {
+			embedded = append(embedded, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(tag, ",string") {
+			properties[name] = map[string]interface{}{"type": "string"}
+		} else {
+			properties[name] = b.schema(f.Type)
+		}
+	}
+	for _, e := range embedded {
+		promoted := make(map[string]interface{})
+		b.fields(e, promoted)
+		for k, v := range promoted {
+			if _, ok := properties[k]; !ok {
+				properties[k] = v
+			}
+		}
+	}
+}
+
+// jsonContent returns the JSON content of a request or response body
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{
+		"schema": schema}}
+}
+
+// operation returns the OpenAPI description of the route
+func (b *schemaBuilder) operation(r *RoutePermission,
+	s *routeSchema) map[string]interface{} {
+	operationID := r.Handler
+	if b.operationIDs[r.Handler]++; b.operationIDs[r.Handler] > 1 {
+		operationID += fmt.Sprint(b.operationIDs[r.Handler])
+	}
+	op := map[string]interface{}{
+		"summary":     s.Summary,
+		"operationId": operationID,
+		"tags":        []string{routeResource(r.Path)},
+	}
+	if r.Rights == "public" {
+		op["description"] = "Route publique"
+	} else {
+		op["description"] = "Droits : " + r.Rights + " (" + r.Message + ")"
+		op["security"] = []map[string][]string{{"bearer": {}}}
+	}
+	var params []interface{}
+	for _, m := range pathParamRegexp.FindAllStringSubmatch(r.Path, -1) {
+		t := "string"
+		if strings.HasPrefix(m[2], "int") || strings.HasSuffix(m[1], "ID") {
+			t = "integer"
+		}
+		params = append(params, map[string]interface{}{"name": m[1],
+			"in": "path", "required": true, "schema": map[string]string{"type": t}})
+	}
+	query := s.Query
+	if s.Request != nil {
+		content := jsonContent(b.ref(s.Request))
+		if batch, ok := reflect.New(reflect.TypeOf(s.Request)).Interface().(models.UploadableBatch); ok {
+			form := map[string]interface{}{"file": map[string]string{
+				"type": "string", "format": "binary"}}
+			for _, p := range batch.Mapping().Params {
+				form[p.Field] = map[string]string{"type": "string"}
+			}
+			content["multipart/form-data"] = map[string]interface{}{
+				"schema": map[string]interface{}{"type": "object",
+					"properties": form, "required": []string{"file"}}}
+			query = append(query, dryRunParam)
+		}
+		op["requestBody"] = map[string]interface{}{"required": true,
+			"content": content}
+	}
+	for _, q := range query {
+		params = append(params, map[string]interface{}{"name": q.Name,
+			"in": "query", "schema": map[string]string{"type": q.Type}})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	status := s.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	if s.Response != nil {
+		success["content"] = jsonContent(b.ref(s.Response))
+	}
+	op["responses"] = map[string]interface{}{
+		fmt.Sprint(status): success,
+		"default": map[string]interface{}{"description": "Erreur",
+			"content": jsonContent(b.ref(jsonError{}))}}
+	return op
+}
+
+// missingRouteSchemas returns the routes of the permission table without a
+// route schema
+func missingRouteSchemas() []string {
+	var missing []string
+	for _, r := range routePermissions {
+		if _, ok := routeSchemas[r.Method+" "+r.Path]; !ok {
+			missing = append(missing, r.Method+" "+r.Path)
+		}
+	}
+	return missing
+}
+
+// openAPIDocument builds the OpenAPI 3 description of the routes recorded in
+// the permission table
+func openAPIDocument() map[string]interface{} {
+	b := schemaBuilder{components: make(map[string]interface{}),
+		names: make(map[reflect.Type]string), operationIDs: make(map[string]int)}
+	paths := make(map[string]map[string]interface{})
+	for i := range routePermissions {
+		r := &routePermissions[i]
+		s, ok := routeSchemas[r.Method+" "+r.Path]
+		if !ok {
+			continue
+		}
+		p := pathParamRegexp.ReplaceAllString(r.Path, "{$1}")
+		if paths[p] == nil {
+			paths[p] = make(map[string]interface{})
+		}
+		paths[p][strings.ToLower(r.Method)] = b.operation(r, &s)
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]string{"title": "PreLoRuGo",
+			"description": "API de suivi des crédits logement et renouvellement urbain",
+			"version":     "1.0"},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"securitySchemes": map[string]interface{}{"bearer": map[string]string{
+				"type": "http", "scheme": "bearer",
+				"description": "Jeton de session ou clé d'API"}}}}
+}
+
+// GetOpenAPI handles the get request to fetch the OpenAPI description of the
+// API
+func GetOpenAPI(ctx iris.Context) {
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(openAPIDocument())
+}
+
+// swaggerUIPage displays the OpenAPI description with Swagger UI
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="fr">
+<head>
+  <meta charset="utf-8">
+  <title>PreLoRuGo API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// GetSwaggerUI handles the get request of the page displaying the OpenAPI
+// description
+func GetSwaggerUI(ctx iris.Context) {
+	ctx.StatusCode(http.StatusOK)
+	ctx.HTML(swaggerUIPage)
+}
@@ -0,0 +1,1106 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// routeSchemas gives the request and response types of each route, using the
+// method and the path template as key. Every route registered in SetRoutes
+// must have an entry to be described in the OpenAPI document.
+var routeSchemas = map[string]routeSchema{
+	"POST /api/user/sign_up": {
+		Summary:  "Inscription d'utilisateur",
+		Request:  signUpReq{},
+		Response: jsonMessage{},
+		Status:   http.StatusCreated},
+	"POST /api/user/login": {
+		Summary:  "Login",
+		Request:  credentials{},
+		Response: oneOf{signInResp{}, preAuthResp{}}},
+	"POST /api/user/login/totp": {
+		Summary:  "Login double authentification",
+		Request:  totpLoginReq{},
+		Response: totpSignInResp{}},
+	"POST /api/user/login/totp/setup": {
+		Summary:  "Configuration de la double authentification",
+		Request:  totpLoginReq{},
+		Response: totpSetupResp{}},
+	"GET /api/user/oidc/login": {
+		Summary:  "Login OIDC",
+		Response: oidcLoginResp{}},
+	"POST /api/user/oidc/callback": {
+		Summary:  "Login OIDC, retour du fournisseur",
+		Request:  oidcCallbackReq{},
+		Response: signInResp{}},
+	"POST /api/user/password/forgot": {
+		Summary:  "Mot de passe oublié",
+		Request:  forgotPwdReq{},
+		Response: jsonMessage{}},
+	"POST /api/user/password/reset": {
+		Summary:  "Réinitialisation de mot de passe",
+		Request:  resetPwdReq{},
+		Response: jsonMessage{}},
+	"GET /api/openapi.json": {
+		Summary:  "Description OpenAPI de l'API",
+		Response: map[string]interface{}{}},
+	"GET /api/docs": {
+		Summary: "Documentation Swagger UI de l'API"},
+
+	"POST /api/user": {
+		Summary:  "Création d'utilisateur",
+		Request:  sentUser{},
+		Response: userResp{},
+		Status:   http.StatusCreated},
+	"POST /api/user/invite": {
+		Summary:  "Invitation d'utilisateur",
+		Request:  inviteUserReq{},
+		Response: userResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/user/{userID}": {
+		Summary:  "Modification d'utilisateur",
+		Request:  sentUser{},
+		Response: userResp{}},
+	"DELETE /api/user/{userID}": {
+		Summary:  "Suppression d'utilisateur",
+		Response: jsonMessage{}},
+	"DELETE /api/user/{userID}/sessions": {
+		Summary:  "Suppression des sessions",
+		Response: jsonMessage{}},
+	"POST /api/user/{userID}/unlock": {
+		Summary:  "Déverrouillage d'utilisateur",
+		Response: jsonMessage{}},
+	"DELETE /api/user/{userID}/totp": {
+		Summary:  "Réinitialisation de la double authentification",
+		Response: jsonMessage{}},
+	"GET /api/api_keys": {
+		Summary:  "Liste des clés d'API",
+		Response: models.APIKeys{}},
+	"DELETE /api/api_key/{ID:int64}": {
+		Summary:  "Révocation de clé d'API",
+		Response: jsonMessage{}},
+	"GET /api/security_logs": {
+		Summary:  "Journal de sécurité",
+		Query:    []queryParam{pageParam, {Name: "UserID", Type: "integer"}, {Name: "Event", Type: "string"}},
+		Response: models.PaginatedSecurityEvents{}},
+	"GET /api/users": {
+		Summary:  "Liste des utilisateurs",
+		Response: models.Users{}},
+	"GET /api/audit": {
+		Summary:  "Journal d'audit",
+		Query:    []queryParam{pageParam, {Name: "Entity", Type: "string"}, {Name: "EntityID", Type: "string"}, {Name: "UserID", Type: "integer"}, {Name: "Begin", Type: "string"}, {Name: "End", Type: "string"}},
+		Response: models.PaginatedAuditLogs{}},
+	"GET /api/cache": {
+		Summary:  "Statistiques du cache",
+		Response: models.CacheStats{}},
+	"GET /api/routes": {
+		Summary:  "Droits des routes",
+		Response: routePermissionsResp{}},
+	"GET /api/roles": {
+		Summary:  "Liste des rôles",
+		Response: models.Roles{}},
+	"POST /api/role": {
+		Summary:  "Création de rôle",
+		Request:  roleReq{},
+		Response: roleReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/role": {
+		Summary:  "Modification de rôle",
+		Request:  roleReq{},
+		Response: roleReq{}},
+	"DELETE /api/role/{ID:int64}": {
+		Summary:  "Suppression de rôle",
+		Response: jsonMessage{}},
+	"GET /api/user/{userID}/roles": {
+		Summary:  "Rôles d'un utilisateur",
+		Response: models.UserRoles{}},
+	"POST /api/user/{userID}/roles": {
+		Summary:  "Affectation de rôles",
+		Request:  models.UserRoles{},
+		Response: models.UserRoles{}},
+	"GET /api/import_runs": {
+		Summary:  "Liste des imports",
+		Query:    []queryParam{pageParam, {Name: "Kind", Type: "string"}},
+		Response: models.PaginatedImportRuns{}},
+	"GET /api/import_run/{ID:int64}": {
+		Summary:  "Détail d'un import",
+		Query:    []queryParam{pageParam},
+		Response: models.PaginatedImportRunRows{}},
+	"POST /api/import_runs/{Kind:string}/revert": {
+		Summary:  "Annulation d'import",
+		Response: importRunResp{}},
+
+	"POST /api/copro": {
+		Summary:  "Création de copropriété",
+		Request:  coproReq{},
+		Response: coproReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/copro": {
+		Summary:  "Modification de copropriété",
+		Request:  coproReq{},
+		Response: coproReq{}},
+	"DELETE /api/copro/{CoproID:int64}": {
+		Summary:  "Suppression de copropriété",
+		Response: jsonMessage{}},
+	"POST /api/copros": {
+		Summary:  "Batch de copropriétés",
+		Request:  models.CoproBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/budget_action": {
+		Summary:  "Création d'action budgétaire",
+		Request:  budgetActionReq{},
+		Response: budgetActionReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/budget_action": {
+		Summary:  "Modification d'action budgétaire",
+		Request:  budgetActionReq{},
+		Response: budgetActionReq{}},
+	"DELETE /api/budget_action/{baID}": {
+		Summary:  "Suppression d'action budgétaire",
+		Response: jsonMessage{}},
+
+	"POST /api/renew_project": {
+		Summary:  "Création de projet de renouvellement",
+		Request:  renewProjectReq{},
+		Response: renewProjectReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/renew_project": {
+		Summary:  "Modification de projet de renouvellement",
+		Request:  renewProjectReq{},
+		Response: renewProjectReq{},
+		Status:   http.StatusCreated},
+	"DELETE /api/renew_project/{rpID}": {
+		Summary:  "Suppression de projet de renouvellement",
+		Response: jsonMessage{}},
+	"POST /api/renew_projects": {
+		Summary:  "Batch de projets de renouvellement",
+		Request:  models.RenewProjectBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/housing": {
+		Summary:  "Création de logement",
+		Request:  housingReq{},
+		Response: housingReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing": {
+		Summary:  "Modification de logement",
+		Request:  housingReq{},
+		Response: housingReq{}},
+	"DELETE /api/housing/{ID}": {
+		Summary:  "Suppression de logement",
+		Response: jsonMessage{}},
+	"POST /api/housings": {
+		Summary:  "Batch de Logements",
+		Request:  models.HousingBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/commitments": {
+		Summary:  "Batch de Engagements",
+		Request:  models.CommitmentBatch{},
+		Response: jsonMessage{}},
+	"POST /api/commitments/link": {
+		Summary: "Liens d'engagements",
+		Request: models.CommitmentLink{},
+		Response: oneOf{coproCmtPmResp{}, renewProjectCmtPmtResp{},
+			housingCmtPmtResp{}}},
+	"POST /api/commitments/unlink": {
+		Summary:  "Suppression de liens d'engagements",
+		Request:  models.CommitmentUnlink{},
+		Response: jsonMessage{}},
+
+	"POST /api/payments": {
+		Summary:  "Batch de Paiements",
+		Request:  models.PaymentBatch{},
+		Response: jsonMessage{}},
+	"GET /api/payments/forecasts": {
+		Summary:  "Prévisions de paiements",
+		Query:    []queryParam{yearParam},
+		Response: models.PmtForecasts{}},
+
+	"POST /api/budget_sector": {
+		Summary:  "Création de secteur budgétaire",
+		Request:  BudgetSectorReq{},
+		Response: BudgetSectorReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/budget_sector": {
+		Summary:  "Modification de secteur budgétaire",
+		Request:  BudgetSectorReq{},
+		Response: BudgetSectorReq{}},
+	"DELETE /api/budget_sector/{ID}": {
+		Summary:  "Suppression de secteur budgétaire",
+		Response: jsonMessage{}},
+
+	"POST /api/commission": {
+		Summary:  "Création de commission",
+		Request:  CommissionReq{},
+		Response: CommissionReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/commission": {
+		Summary:  "Modification de commission",
+		Request:  CommissionReq{},
+		Response: CommissionReq{}},
+	"DELETE /api/commission/{ID}": {
+		Summary:  "Suppression de commission",
+		Response: jsonMessage{}},
+
+	"POST /api/community": {
+		Summary:  "Création d'interco",
+		Request:  CommunityReq{},
+		Response: CommunityReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/community": {
+		Summary:  "Modification d'interco",
+		Request:  CommunityReq{},
+		Response: CommunityReq{}},
+	"DELETE /api/community/{ID}": {
+		Summary:  "Suppression d'interco",
+		Response: jsonMessage{}},
+	"POST /api/communities": {
+		Summary:  "Batch de Intercos",
+		Request:  models.CommunityBatch{},
+		Response: models.Communities{}},
+
+	"POST /api/department": {
+		Summary:  "Création de département",
+		Request:  DepartmentReq{},
+		Response: DepartmentReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/department": {
+		Summary:  "Modification de département",
+		Request:  DepartmentReq{},
+		Response: DepartmentReq{}},
+	"DELETE /api/department/{ID}": {
+		Summary:  "Suppression de département",
+		Response: jsonMessage{}},
+
+	"POST /api/city": {
+		Summary:  "Création de ville",
+		Request:  CityReq{},
+		Response: CityReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/city": {
+		Summary:  "Modification de ville",
+		Request:  CityReq{},
+		Response: CityReq{}},
+	"DELETE /api/city/{ID}": {
+		Summary:  "Suppression de ville",
+		Response: jsonMessage{}},
+	"POST /api/cities": {
+		Summary:  "Batch de Villes",
+		Request:  models.CityBatch{},
+		Response: models.Cities{}},
+
+	"POST /api/renew_project_forecasts": {
+		Summary:  "Batch de Prévision RUs",
+		Request:  models.RenewProjectForecastBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/housing_forecasts": {
+		Summary:  "Batch de Prévision logements",
+		Request:  models.HousingForecastBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/copro_forecasts": {
+		Summary:  "Batch de Prévision copros",
+		Request:  models.CoproForecastBatch{},
+		Response: jsonMessage{}},
+
+	"GET /api/settings": {
+		Summary:  "Administration",
+		Response: settingsResp{}},
+
+	"POST /api/ratios": {
+		Summary:  "Batch de ratios de paiement",
+		Request:  models.PmtRatioBatch{},
+		Response: jsonMessage{}},
+
+	"GET /api/pre_prog": {
+		Summary:  "Préprogrammation d'une année",
+		Query:    []queryParam{yearParam},
+		Response: models.PreProgs{}},
+
+	"POST /api/prog": {
+		Summary:  "Fixation de la programmation d'une année",
+		Query:    []queryParam{yearParam},
+		Request:  models.ProgBatch{},
+		Response: models.Progs{}},
+
+	"POST /api/rpls": {
+		Summary:  "Création de RPLS",
+		Request:  models.RPLS{},
+		Response: rplsResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/rpls": {
+		Summary:  "Modification de RPLS",
+		Request:  models.RPLS{},
+		Response: rplsResp{}},
+	"DELETE /api/rpls/{ID}": {
+		Summary:  "Suppression de RPLS",
+		Response: jsonMessage{}},
+	"POST /api/rpls/batch": {
+		Summary:  "Batch RPLS",
+		Request:  models.RPLSBatch{},
+		Response: jsonMessage{}},
+	"GET /api/rpls/datas": {
+		Summary:  "Données RPLS",
+		Response: rplsDatasResp{}},
+
+	"POST /api/payment_credits": {
+		Summary:  "Batch d'enveloppes de crédits",
+		Request:  models.PaymentCreditBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/payment_credit_journal": {
+		Summary:  "Batch mouvements de crédits",
+		Request:  models.PaymentCreditJournalBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/home_message": {
+		Summary:  "Fixation du message d'accueil",
+		Request:  models.HomeMessage{},
+		Response: jsonMessage{}},
+
+	"POST /api/placements": {
+		Summary:  "Batch de stages",
+		Request:  models.Placements{},
+		Response: jsonMessage{}},
+	"PUT /api/placement/{ID}": {
+		Summary:  "Engagement de stagiaires",
+		Request:  updatePlacementReq{},
+		Response: updatePlacementReq{}},
+
+	"POST /api/beneficiary_group": {
+		Summary:  "Création de groupe de bénéficiaires",
+		Request:  beneficiaryGroupReq{},
+		Response: beneficiaryGroupReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/beneficiary_group": {
+		Summary:  "Modification de groupe de bénéficiaires",
+		Request:  beneficiaryGroupReq{},
+		Response: beneficiaryGroupReq{}},
+	"DELETE /api/beneficiary_group/{ID}": {
+		Summary:  "Suppression de groupe de bénéficiaires",
+		Response: jsonMessage{}},
+	"POST /api/beneficiary_group/{ID}": {
+		Summary:  "Fixation de groupe de bénéficiaires",
+		Request:  beneficiaryBelongReq{},
+		Response: models.Beneficiaries{}},
+
+	"POST /api/housing_typology": {
+		Summary:  "Création de typologie de logement",
+		Request:  housingTypologyReq{},
+		Response: housingTypologyReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing_typology": {
+		Summary:  "Modification de typologie de logement",
+		Request:  housingTypologyReq{},
+		Response: housingTypologyReq{}},
+	"DELETE /api/housing_typology/{ID}": {
+		Summary:  "Suppression de typologie de logement",
+		Response: jsonMessage{}},
+
+	"POST /api/housing_convention": {
+		Summary:  "Création de convention de logement",
+		Request:  housingConventionReq{},
+		Response: housingConventionReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing_convention": {
+		Summary:  "Modification de convention de logement",
+		Request:  housingConventionReq{},
+		Response: housingConventionReq{}},
+	"DELETE /api/housing_convention/{ID}": {
+		Summary:  "Suppression de convention de logement",
+		Response: jsonMessage{}},
+
+	"POST /api/housing_comment": {
+		Summary:  "Création de commentaire de logement",
+		Request:  housingCommentReq{},
+		Response: housingCommentReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing_comment": {
+		Summary:  "Modification de commentaire de logement",
+		Request:  housingCommentReq{},
+		Response: housingCommentReq{}},
+	"DELETE /api/housing_comment/{ID}": {
+		Summary:  "Suppression de commentaire de logement",
+		Response: jsonMessage{}},
+
+	"POST /api/housing_transfer": {
+		Summary:  "Création de transfert de logement",
+		Request:  housingTransferReq{},
+		Response: housingTransferReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing_transfer": {
+		Summary:  "Modification de transfert de logement",
+		Request:  housingTransferReq{},
+		Response: housingTransferReq{}},
+	"DELETE /api/housing_transfer/{ID}": {
+		Summary:  "Suppression de transfert de logement",
+		Response: jsonMessage{}},
+
+	"POST /api/convention_type": {
+		Summary:  "Création de type de convention",
+		Request:  conventionTypeReq{},
+		Response: conventionTypeReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/convention_type": {
+		Summary:  "Modification de type de convention",
+		Request:  conventionTypeReq{},
+		Response: conventionTypeReq{}},
+	"DELETE /api/convention_type/{ID}": {
+		Summary:  "Suppression de type de convention",
+		Response: jsonMessage{}},
+
+	"POST /api/beneficiary": {
+		Summary:  "Création de bénéficiaire",
+		Request:  beneficiaryReq{},
+		Response: beneficiaryReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/beneficiary": {
+		Summary:  "Modification de bénéficiaire",
+		Request:  beneficiaryReq{},
+		Response: beneficiaryReq{}},
+	"DELETE /api/beneficiary/{ID}": {
+		Summary:  "Suppression de bénéficiaire",
+		Response: jsonMessage{}},
+
+	"POST /api/housing_type": {
+		Summary:  "Création de type de logement",
+		Request:  housingTypeReq{},
+		Response: housingTypeReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing_type": {
+		Summary:  "Modification de type de logement",
+		Request:  housingTypeReq{},
+		Response: housingTypeReq{}},
+	"DELETE /api/housing_type/{ID}": {
+		Summary:  "Suppression de type de logement",
+		Response: jsonMessage{}},
+
+	"POST /api/iris_housing_type": {
+		Summary:  "Batch de lien IRIS / type de logement",
+		Request:  models.IRISHousingTypes{},
+		Response: jsonMessage{}},
+
+	"GET /api/commitments/eldest": {
+		Summary:  "Liste des engagements anciens",
+		Response: models.SoldCommitments{}},
+	"GET /api/commitments/unpaid": {
+		Summary:  "Liste des engagements non payés",
+		Response: models.SoldCommitments{}},
+
+	"PUT /api/payment_demands": {
+		Summary:  "Mise à jour de demande de paiement",
+		Request:  paymentDemandReq{},
+		Response: paymentDemandReq{}},
+	"POST /api/payment_demands": {
+		Summary:  "Batch de demandes de paiement",
+		Request:  models.PaymentDemandBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/copro_forecast": {
+		Summary:  "Création de prévision copro",
+		Request:  CoproForecastReq{},
+		Response: CoproForecastReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/copro_forecast": {
+		Summary:  "Modification de prévision copro",
+		Request:  CoproForecastReq{},
+		Response: CoproForecastReq{}},
+	"DELETE /api/copro_forecast/{ID}": {
+		Summary:  "Suppression de prévision copro",
+		Response: jsonMessage{}},
+	"POST /api/copro/commitments": {
+		Summary:  "Liens engagements copros",
+		Request:  models.CoproCommitmentBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/copro_event_type": {
+		Summary:  "Création de type d'événement Copro",
+		Request:  models.CoproEventType{},
+		Response: coproEventTypeResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/copro_event_type": {
+		Summary:  "Modification de type d'événement Copro",
+		Request:  models.CoproEventType{},
+		Response: coproEventTypeResp{}},
+	"DELETE /api/copro_event_type/{ID}": {
+		Summary:  "Suppression de type d'événement Copro",
+		Response: jsonMessage{}},
+
+	"POST /api/copro_event": {
+		Summary:  "Création d'événement Copro",
+		Request:  models.CoproEvent{},
+		Response: coproEventResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/copro_event": {
+		Summary:  "Modification d'événement Copro",
+		Request:  models.CoproEvent{},
+		Response: coproEventResp{}},
+	"DELETE /api/copro_event/{ID}": {
+		Summary:  "Suppression d'événement Copro",
+		Response: jsonMessage{}},
+
+	"GET /api/pre_prog/copro": {
+		Summary:  "Préprogrammation copro d'une année",
+		Query:    []queryParam{yearParam},
+		Response: models.FcPreProgs{}},
+
+	"POST /api/copro/{CoproID}/copro_doc": {
+		Summary:  "Création d'un document copro",
+		Request:  coproDocResp{},
+		Response: coproDocResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/copro/{CoproID}/copro_doc": {
+		Summary:  "Modification d'un document copro",
+		Request:  coproDocResp{},
+		Response: coproDocResp{}},
+	"DELETE /api/copro/{CoproID}/copro_doc/{ID}": {
+		Summary:  "Suppression d'un document copro",
+		Response: jsonMessage{}},
+
+	"POST /api/pre_prog/copro": {
+		Summary:  "Fixation de la préprogrammation copro d'une année",
+		Query:    []queryParam{yearParam},
+		Request:  models.PreProgBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/renew_project_forecast": {
+		Summary:  "Création de prévision RU",
+		Request:  RenewProjectForecastReq{},
+		Response: RenewProjectForecastReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/renew_project_forecast": {
+		Summary:  "Modification de prévision RU",
+		Request:  RenewProjectForecastReq{},
+		Response: RenewProjectForecastReq{}},
+	"DELETE /api/renew_project_forecast/{ID}": {
+		Summary:  "Suppression de prévision RU",
+		Response: jsonMessage{}},
+
+	"POST /api/rp_event_type": {
+		Summary:  "Création de type d'événement RP",
+		Request:  models.RPEventType{},
+		Response: rpEventTypeResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/rp_event_type": {
+		Summary:  "Modification de type d'événement RP",
+		Request:  models.RPEventType{},
+		Response: rpEventTypeResp{}},
+	"DELETE /api/rp_event_type/{ID}": {
+		Summary:  "Suppression de type d'événement RP",
+		Response: jsonMessage{}},
+
+	"POST /api/rp_event": {
+		Summary:  "Création d'événement RP",
+		Request:  models.RPEvent{},
+		Response: rpEventResp{},
+		Status:   http.StatusCreated},
+	"PUT /api/rp_event": {
+		Summary:  "Modification d'événement RP",
+		Request:  models.RPEvent{},
+		Response: rpEventResp{}},
+	"DELETE /api/rp_event/{ID}": {
+		Summary:  "Suppression d'événement RP",
+		Response: jsonMessage{}},
+
+	"POST /api/rp_cmt_city_join": {
+		Summary:  "Création de lien engagement ville",
+		Request:  models.RPCmtCityJoin{},
+		Response: RPCmtCityJoinReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/rp_cmt_city_join": {
+		Summary:  "Modification de lien engagement ville",
+		Request:  models.RPCmtCityJoin{},
+		Response: RPCmtCityJoinReq{}},
+	"DELETE /api/rp_cmt_city_join/{ID}": {
+		Summary:  "Suppression de lien engagement ville",
+		Response: jsonMessage{}},
+
+	"GET /api/pre_prog/renew_project": {
+		Summary:  "Préprogrammation RU d'une année",
+		Query:    []queryParam{yearParam},
+		Response: models.FcPreProgs{}},
+
+	"POST /api/pre_prog/renew_project": {
+		Summary:  "Fixation de la préprogrammation RU d'une année",
+		Query:    []queryParam{yearParam},
+		Request:  models.PreProgBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/housing_forecast": {
+		Summary:  "Création de prévision logement",
+		Request:  HousingForecastReq{},
+		Response: HousingForecastReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/housing_forecast": {
+		Summary:  "Modification de prévision logement",
+		Request:  HousingForecastReq{},
+		Response: HousingForecastReq{}},
+	"DELETE /api/housing_forecast/{ID}": {
+		Summary:  "Suppression de prévision logement",
+		Response: jsonMessage{}},
+	"POST /api/housing/commitments": {
+		Summary:  "Liens engagements logements",
+		Request:  models.HousingCommitmentBach{},
+		Response: jsonMessage{}},
+
+	"GET /api/pre_prog/housing": {
+		Summary:  "Préprogrammation logement d'une année",
+		Query:    []queryParam{yearParam},
+		Response: models.FcPreProgs{}},
+
+	"POST /api/housing_summary": {
+		Summary:  "Batch de bilan logements",
+		Request:  models.HousingSummary{},
+		Response: jsonMessage{}},
+
+	"POST /api/pre_prog/housing": {
+		Summary:  "Fixation de la préprogrammation logement d'une année",
+		Query:    []queryParam{yearParam},
+		Request:  models.PreProgBatch{},
+		Response: jsonMessage{}},
+
+	"POST /api/reservation_fee": {
+		Summary:  "Création de réservation de logement",
+		Request:  reservationFeeReq{},
+		Response: reservationFeeReq{},
+		Status:   http.StatusCreated},
+	"GET /api/reservation_fees": {
+		Summary:  "Page de réservation de logements",
+		Query:    []queryParam{pageParam, searchParam, sizeParam, sortParam},
+		Response: models.PaginatedReservationFees{}},
+	"GET /api/reservation_fees/initial": {
+		Summary:  "Page initiale de réservation de logements",
+		Query:    []queryParam{pageParam, searchParam, sizeParam, sortParam},
+		Response: initialPaginatedReservationFeesResp{}},
+	"GET /api/reservation_fees/export": {
+		Summary:  "Export de réservation de logements",
+		Query:    []queryParam{searchParam, formatParam},
+		Response: models.ExportedReservationFees{}},
+	"POST /api/reservation_fee/batch": {
+		Summary:  "Batch de réservation de logement",
+		Request:  models.ReservationFeeBatch{},
+		Response: models.ReservationFeeBatchResults{}},
+	"POST /api/reservation_fee/batch/test": {
+		Summary:  "Test batch de réservation de logement",
+		Request:  models.ReservationFeeBatch{},
+		Response: models.ReservationFeeBatchResults{}},
+	"PUT /api/reservation_fee": {
+		Summary:  "Modification de réservation de logement",
+		Request:  reservationFeeReq{},
+		Response: reservationFeeReq{}},
+	"DELETE /api/reservation_fee/{ID}": {
+		Summary:  "Suppression de réservation de logement",
+		Response: jsonMessage{}},
+
+	"POST /api/reservation_report": {
+		Summary:  "Création de report de réservation",
+		Request:  reservationReportReq{},
+		Response: reservationReportReq{},
+		Status:   http.StatusCreated},
+	"PUT /api/reservation_report": {
+		Summary:  "Modification de report de réservation",
+		Request:  reservationReportReq{},
+		Response: reservationReportReq{}},
+	"DELETE /api/reservation_report/{ID}": {
+		Summary:  "Suppression de report de réservation",
+		Response: jsonMessage{}},
+	"GET /api/reservation_reports": {
+		Summary:  "Liste des reports de réservation",
+		Response: models.ReservationReports{}},
+
+	"POST /api/user/password": {
+		Summary:  "Changement de mot de passe",
+		Request:  chgPwdReq{},
+		Response: jsonMessage{}},
+	"POST /api/user/logout": {
+		Summary:  "Logout",
+		Response: jsonMessage{}},
+	"GET /api/user/sessions": {
+		Summary:  "Liste des sessions",
+		Response: userSessionsResp{}},
+	"DELETE /api/user/sessions/{ID}": {
+		Summary:  "Suppression de session",
+		Response: jsonMessage{}},
+	"GET /api/user/totp": {
+		Summary:  "Double authentification",
+		Response: twoFactorResp{}},
+	"POST /api/user/totp/setup": {
+		Summary:  "Configuration de la double authentification",
+		Response: totpSetupResp{}},
+	"POST /api/user/totp/enable": {
+		Summary:  "Activation de la double authentification",
+		Request:  totpCodeReq{},
+		Response: recoveryCodesResp{}},
+	"POST /api/user/totp/disable": {
+		Summary:  "Désactivation de la double authentification",
+		Request:  totpCodeReq{},
+		Response: jsonMessage{}},
+	"POST /api/user/totp/recovery_codes": {
+		Summary:  "Codes de secours",
+		Request:  totpCodeReq{},
+		Response: recoveryCodesResp{}},
+	"GET /api/user/api_keys": {
+		Summary:  "Liste des clés d'API",
+		Response: models.APIKeys{}},
+	"POST /api/user/api_key": {
+		Summary:  "Création de clé d'API",
+		Request:  apiKeyReq{},
+		Response: apiKeyResp{},
+		Status:   http.StatusCreated},
+	"DELETE /api/user/api_key/{ID:int64}": {
+		Summary:  "Suppression de clé d'API",
+		Response: jsonMessage{}},
+
+	"GET /api/budget_actions": {
+		Summary:  "Liste des actions budgétaires",
+		Response: budgetActionsResp{}},
+
+	"GET /api/copro": {
+		Summary:  "Liste des copropriétés",
+		Response: getCoprosResp{}},
+	"GET /api/copro/{ID}/datas": {
+		Summary:  "Données d'une copropriété",
+		Response: coproDatasResp{}},
+
+	"GET /api/renew_projects": {
+		Summary:  "Liste des projets de renouvellement",
+		Response: renewProjectsResp{}},
+	"GET /api/renew_project/{ID}/datas": {
+		Summary:  "Datas de projet de renouvellement",
+		Response: renewProjectDataResp{}},
+
+	"GET /api/housing/{ID}": {
+		Summary:  "Récupération d'un logement",
+		Response: housingReq{}},
+	"GET /api/housing/{ID}/datas": {
+		Summary:  "Données d'un logement",
+		Response: housingDatasResp{}},
+	"GET /api/housings": {
+		Summary:  "Liste des logements",
+		Response: models.Housings{}},
+	"GET /api/housings/datas": {
+		Summary:  "Données logement",
+		Response: HousingsDatasResp{}},
+	"GET /api/housings/paginated": {
+		Summary:  "Page de logements",
+		Query:    []queryParam{pageParam, searchParam, sizeParam, sortParam},
+		Response: models.PaginatedHousings{}},
+
+	"GET /api/commitments": {
+		Summary:  "Liste des engagements",
+		Response: models.Commitments{}},
+	"GET /api/commitments/paginated": {
+		Summary:  "Page d'engagements",
+		Query:    []queryParam{yearParam, pageParam, searchParam, sizeParam, sortParam},
+		Response: models.PaginatedCommitments{}},
+	"GET /api/commitments/unlinked": {
+		Summary:  "Page d'engagements non liés",
+		Query:    []queryParam{yearParam, pageParam, searchParam, sizeParam, sortParam},
+		Response: models.PaginatedCommitments{}},
+	"GET /api/commitments/cursor": {
+		Summary:  "Engagements par curseur",
+		Query:    []queryParam{yearParam, searchParam, cursorParam, sizeParam},
+		Response: models.CursorCommitments{}},
+	"GET /api/commitments/export": {
+		Summary:  "Export d'engagements",
+		Query:    []queryParam{yearParam, searchParam, formatParam},
+		Response: models.ExportedCommitments{}},
+
+	"GET /api/commitments/forecasts": {
+		Summary:  "Prévisions d'engagement",
+		Response: models.CmtForecasts{}},
+
+	"GET /api/beneficiaries": {
+		Summary:  "Liste des bénéficiaires",
+		Response: models.Beneficiaries{}},
+	"GET /api/beneficiaries/paginated": {
+		Summary:  "Page de bénéficiaires",
+		Query:    []queryParam{pageParam, searchParam, sizeParam, sortParam},
+		Response: models.PaginatedBeneficiaries{}},
+	"GET /api/beneficiary/{ID}/datas": {
+		Summary:  "Page de données bénéficiaire",
+		Query:    []queryParam{yearParam, pageParam, searchParam},
+		Response: models.PaginatedBeneficiaryDatas{}},
+	"GET /api/beneficiary/{ID}/export": {
+		Summary:  "Export données bénéficiaire",
+		Query:    []queryParam{yearParam, searchParam},
+		Response: models.BeneficiaryDatas{}},
+	"GET /api/beneficiary/{ID}/payments": {
+		Summary:  "Paiement d'un bénéficiaire",
+		Response: models.BeneficiaryPayments{}},
+	"GET /api/beneficiary/{ID}/placements": {
+		Summary:  "Stages d'un bénéficiaire",
+		Response: models.Placements{}},
+
+	"GET /api/payments": {
+		Summary:  "Liste des paiements",
+		Response: models.Payments{}},
+	"GET /api/payments/paginated": {
+		Summary:  "Page de paiements",
+		Query:    []queryParam{yearParam, pageParam, searchParam, sizeParam, sortParam},
+		Response: models.PaginatedPayments{}},
+	"GET /api/payments/cursor": {
+		Summary:  "Paiements par curseur",
+		Query:    []queryParam{yearParam, searchParam, cursorParam, sizeParam},
+		Response: models.CursorPayments{}},
+	"GET /api/payments/export": {
+		Summary:  "Export de paiements",
+		Query:    []queryParam{yearParam, searchParam, formatParam},
+		Response: models.ExportedPayments{}},
+
+	"GET /api/budget_sectors": {
+		Summary:  "Liste des secteurs budgétaires",
+		Response: models.BudgetSectors{}},
+	"GET /api/budget_sector/{ID}": {
+		Summary:  "Récupération de secteur budgétaire",
+		Response: BudgetSectorReq{}},
+
+	"GET /api/community/{ID}": {
+		Summary:  "Récupération d'interco",
+		Response: CommunityReq{}},
+	"GET /api/communities": {
+		Summary:  "Liste des intercos",
+		Response: models.Communities{}},
+
+	"GET /api/department/{ID}": {
+		Summary:  "Récupération de département",
+		Response: DepartmentReq{}},
+	"GET /api/departments": {
+		Summary:  "Liste des départements",
+		Response: models.Departments{}},
+
+	"GET /api/commission/{ID}": {
+		Summary:  "Récupération de commission",
+		Response: CommissionReq{}},
+	"GET /api/commissions": {
+		Summary:  "Liste des commissions",
+		Response: models.Commissions{}},
+
+	"GET /api/city/{ID}": {
+		Summary:  "Récupération de ville",
+		Response: CityReq{}},
+	"GET /api/cities": {
+		Summary:  "Liste des villes",
+		Response: models.Cities{}},
+	"GET /api/cities/paginated": {
+		Summary:  "Page de villes",
+		Query:    []queryParam{pageParam, searchParam},
+		Response: models.PaginatedCities{}},
+
+	"GET /api/renew_project_forecast/{ID}": {
+		Summary:  "Récupération de prévision RU",
+		Response: RenewProjectForecastReq{}},
+	"GET /api/renew_project_forecasts": {
+		Summary:  "Liste des prévision RUs",
+		Response: models.RenewProjectForecasts{}},
+
+	"GET /api/housing_forecast/{ID}": {
+		Summary:  "Récupération de prévision logement",
+		Response: HousingForecastReq{}},
+	"GET /api/housing_forecasts": {
+		Summary:  "Liste des prévision logements",
+		Response: models.HousingForecasts{}},
+
+	"GET /api/copro_forecast/{ID}": {
+		Summary:  "Récupération de prévision copro",
+		Response: CoproForecastReq{}},
+	"GET /api/copro_forecasts": {
+		Summary:  "Liste des prévision copros",
+		Response: models.CoproForecasts{}},
+
+	"GET /api/copro_event_types": {
+		Summary:  "Récupération des types d'événement Copro",
+		Response: models.CoproEventTypes{}},
+	"GET /api/copro_event_type/{ID}": {
+		Summary:  "Récupération de type d'événement Copro",
+		Response: coproEventTypeResp{}},
+
+	"GET /api/copro_events": {
+		Summary:  "Récupération d'événements Copro",
+		Response: models.CoproEvents{}},
+	"GET /api/copro_event/{ID}": {
+		Summary:  "Récupération d'événement Copro",
+		Response: coproEventResp{}},
+
+	"GET /api/home": {
+		Summary:  "Page d'accueil",
+		Response: homeResp{}},
+
+	"GET /api/ratios": {
+		Summary:  "Ratios de paiements",
+		Query:    []queryParam{yearParam},
+		Response: models.PmtRatios{}},
+	"GET /api/ratios/years": {
+		Summary:  "Années des ratios des paiements",
+		Response: models.PmtRatiosYears{}},
+
+	"GET /api/rp_event_types": {
+		Summary:  "Récupération des types d'événement RP",
+		Response: models.RPEventTypes{}},
+	"GET /api/rp_event_type/{ID}": {
+		Summary:  "Récupération de type d'événement RP",
+		Response: rpEventTypeResp{}},
+
+	"GET /api/rp_events": {
+		Summary:  "Récupération d'événements RP",
+		Response: models.RPEvents{}},
+	"GET /api/rp_event/{ID}": {
+		Summary:  "Récupération d'événement RP",
+		Response: rpEventResp{}},
+
+	"GET /api/renew_project/report": {
+		Summary:  "Rapport RU",
+		Response: models.RenewProjectReport{}},
+	"GET /api/renew_project/report_per_community": {
+		Summary:  "Rapport RU par interco",
+		Response: models.RPPerCommunityReport{}},
+
+	"GET /api/rp_cmt_city_joins": {
+		Summary:  "Liste des liens engagement ville",
+		Response: models.RPCmtCityJoins{}},
+	"GET /api/rp_cmt_city_join/{ID}": {
+		Summary:  "Récupération de lien engagement ville",
+		Response: RPCmtCityJoinReq{}},
+
+	"GET /api/department_report": {
+		Summary:  "Rapport par département",
+		Query:    []queryParam{{Name: "firstYear", Type: "integer"}, {Name: "lastYear", Type: "integer"}},
+		Response: models.DptReport{}},
+
+	"GET /api/city_report": {
+		Summary:  "Rapport par commune",
+		Query:    []queryParam{{Name: "firstYear", Type: "integer"}, {Name: "lastYear", Type: "integer"}, {Name: "inseeCode", Type: "integer"}},
+		Response: models.CityReport{}},
+
+	"GET /api/prog": {
+		Summary:  "Programmation d'une année",
+		Query:    []queryParam{yearParam},
+		Response: models.Progs{}},
+	"GET /api/prog/datas": {
+		Summary:  "Données de programmation d'une année",
+		Query:    []queryParam{yearParam},
+		Response: progDatasResp{}},
+	"GET /api/prog/years": {
+		Summary:  "Années de programmation",
+		Response: models.ProgYears{}},
+
+	"GET /api/rpls": {
+		Summary:  "Liste RPLS",
+		Response: models.RPLSArray{}},
+	"GET /api/rpls/report": {
+		Summary:  "Rapport RPLS",
+		Response: models.RPLSReport{}},
+	"GET /api/rpls/detailed_report": {
+		Summary:  "Rapport détaillé RPLS",
+		Response: models.RPLSDetailedReport{}},
+
+	"GET /api/summaries/datas": {
+		Summary:  "Données de synthèse",
+		Response: summariesResp{}},
+
+	"GET /api/copro/{CoproID}/copro_docs": {
+		Summary:  "Documents d'une copro",
+		Response: models.CoproDocs{}},
+
+	"GET /api/copro/report": {
+		Summary:  "Rapport sur les copropriétés",
+		Response: models.CoproReports{}},
+
+	"GET /api/renew_project/multi_annual_report": {
+		Summary:  "Rapport pluriannuel RU",
+		Response: models.RPMultiAnnualReports{}},
+
+	"GET /api/payment_credits": {
+		Summary:  "Liste des enveloppes de crédits",
+		Query:    []queryParam{yearParam},
+		Response: models.PaymentCredits{}},
+
+	"GET /api/payment_credit_journal": {
+		Summary:  "Mouvements de crédits",
+		Query:    []queryParam{yearParam},
+		Response: models.PaymentCreditJournals{}},
+
+	"GET /api/payment_credits_and_journal": {
+		Summary:  "Situation et mouvements de crédits",
+		Query:    []queryParam{yearParam},
+		Response: creditsAndJournalResp{}},
+
+	"GET /api/placements": {
+		Summary:  "Liste des stages",
+		Response: models.Placements{}},
+
+	"GET /api/beneficiary_groups": {
+		Summary:  "Liste des groupes de bénéficiaires",
+		Response: models.BeneficiaryGroups{}},
+	"GET /api/beneficiary_group/{ID}": {
+		Summary:  "Liste des bénéficiaires d'un groupe",
+		Response: models.Beneficiaries{}},
+	"GET /api/beneficiary_group/{ID}/datas": {
+		Summary:  "Page de données groupe de bénéficiaires",
+		Query:    []queryParam{yearParam, pageParam, searchParam},
+		Response: models.PaginatedBeneficiaryGroupDatas{}},
+	"GET /api/beneficiary_group/{ID}/export": {
+		Summary:  "Export données groupe de bénéficiaires",
+		Query:    []queryParam{yearParam, searchParam},
+		Response: models.BeneficiaryGroupDatas{}},
+	"GET /api/beneficiary_group/{ID}/placements": {
+		Summary:  "Stagiaires d'un groupe de bénéficiaires",
+		Response: models.Placements{}},
+
+	"GET /api/housing_typologies": {
+		Summary:  "Liste des typologies de logement",
+		Response: models.HousingTypologies{}},
+
+	"GET /api/housing_conventions": {
+		Summary:  "Liste des conventions de logement",
+		Response: models.HousingConventions{}},
+
+	"GET /api/housing_comments": {
+		Summary:  "Liste des commentaires de logement",
+		Response: models.HousingComments{}},
+
+	"GET /api/housing_transfers": {
+		Summary:  "Liste des transferts de logement",
+		Response: models.HousingTransfers{}},
+
+	"GET /api/convention_types": {
+		Summary:  "Liste des types de convention",
+		Response: models.ConventionTypes{}},
+
+	"GET /api/reservation_fees/settings": {
+		Summary:  "Administration des réservations",
+		Response: reservationFeeSettingsResp{}},
+
+	"GET /api/housing_types": {
+		Summary:  "Liste des types de logement",
+		Response: models.HousingTypes{}},
+
+	"GET /api/dif_action_pmt_prev": {
+		Summary:  "Prévisions de paiement par action",
+		Response: models.DifActionPmtPrevisions{}},
+
+	"GET /api/avg_pmt_times": {
+		Summary:  "Durée moyenne de paiement",
+		Response: models.AvgPmtTimes{}},
+	"GET /api/payment_demands": {
+		Summary:  "Demandes de paiement",
+		Response: models.PaymentDemands{}},
+	"GET /api/payment_demand_counts": {
+		Summary:  "Nombre de demandes de paiement",
+		Response: models.PaymentDemandCounts{}},
+	"GET /api/payment_demand_stocks": {
+		Summary:  "Stocks de demandes de paiement",
+		Response: models.PaymentDemandsStocks{}},
+
+	"GET /api/payment_delays": {
+		Summary:  "Délais de paiement",
+		Query:    []queryParam{{Name: "after", Type: "integer"}},
+		Response: models.PaymentDelays{}},
+	"GET /api/average_payment_time": {
+		Summary:  "Durée moyenne de paiement",
+		Response: models.AvgPmtTimes{}},
+}
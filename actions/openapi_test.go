@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testOpenAPI is the entry point for testing the OpenAPI description
+func testOpenAPI(t *testing.T, c *TestContext) {
+	t.Run("OpenAPI", func(t *testing.T) {
+		testRouteSchemas(t)
+		testGetOpenAPI(t, c)
+		testGetSwaggerUI(t, c)
+	})
+}
+
+// testRouteSchemas checks every route has a schema and every schema matches
+// a route
+func testRouteSchemas(t *testing.T) {
+	for _, r := range missingRouteSchemas() {
+		t.Errorf("RouteSchemas : schéma manquant pour %s", r)
+	}
+	routes := make(map[string]bool, len(routePermissions))
+	for _, r := range routePermissions {
+		routes[r.Method+" "+r.Path] = true
+	}
+	for r := range routeSchemas {
+		if !routes[r] {
+			t.Errorf("RouteSchemas : schéma %s sans route", r)
+		}
+	}
+}
+
+// testGetOpenAPI checks the document describes the paths, the parameters and
+// the bodies of the routes
+func testGetOpenAPI(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			RespContains: []string{`"openapi":"3.0.3"`,
+				`"/api/copro/{CoproID}":{"delete":{`,
+				`"/api/commitments/paginated":{"get":{`,
+				`{"in":"query","name":"Page","schema":{"type":"integer"}}`,
+				`"CommitmentBatch":{"properties":{"Commitment":{"items":`,
+				`"multipart/form-data":{"schema":{"properties":{"file":`,
+				`"securitySchemes":{"bearer":`,
+				`"operationId":"GetPaginatedCommitments"`},
+			StatusCode: http.StatusOK}, // 0 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/openapi.json").Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetOpenAPI") {
+		t.Error(r)
+	}
+}
+
+// testGetSwaggerUI checks the page loads the OpenAPI description
+func testGetSwaggerUI(t *testing.T, c *TestContext) {
+	c.E.GET("/api/docs").Expect().Status(http.StatusOK).Body().
+		Contains("swagger-ui").Contains("/api/openapi.json")
+}
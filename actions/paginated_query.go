@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// The suffixes of the URL parameters of the filters of a paginated query
+const (
+	filterMinSuffix = "Min"
+	filterMaxSuffix = "Max"
+	filterInSuffix  = "In"
+)
+
+// readQueryOptions decodes the page size, the sort and the filters of a
+// paginated query from the URL parameters. Sort lists the fields separated by
+// commas, a leading minus giving a descending order. A filter uses <Field>Min
+// and <Field>Max for a range and <Field>In for IDs separated by commas. The
+// fields are checked by the model.
+func readQueryOptions(ctx iris.Context, q *models.PaginatedQuery) error {
+	if ctx.URLParamExists("PageSize") {
+		size, err := ctx.URLParamInt64("PageSize")
+		if err != nil || size < 1 {
+			return &models.ValidationError{Field: "PageSize",
+				Message: fmt.Sprintf("taille de page %q incorrecte",
+					ctx.URLParam("PageSize"))}
+		}
+		q.PageSize = size
+	}
+	if s := ctx.URLParamTrim("Sort"); s != "" {
+		for _, f := range strings.Split(s, ",") {
+			f = strings.TrimSpace(f)
+			q.Sort = append(q.Sort, models.SortField{
+				Field: strings.TrimPrefix(f, "-"), Desc: strings.HasPrefix(f, "-")})
+		}
+	}
+	filters := make(map[string]*models.Filter)
+	filter := func(name string) *models.Filter {
+		f, ok := filters[name]
+		if !ok {
+			f = &models.Filter{Field: name}
+			filters[name] = f
+		}
+		return f
+	}
+	for k, v := range ctx.URLParams() {
+		switch {
+		case strings.HasSuffix(k, filterMinSuffix) && k != filterMinSuffix:
+			filter(strings.TrimSuffix(k, filterMinSuffix)).Min = v
+		case strings.HasSuffix(k, filterMaxSuffix) && k != filterMaxSuffix:
+			filter(strings.TrimSuffix(k, filterMaxSuffix)).Max = v
+		case strings.HasSuffix(k, filterInSuffix) && k != filterInSuffix:
+			f := filter(strings.TrimSuffix(k, filterInSuffix))
+			f.In = []int64{}
+			for _, s := range strings.Split(v, ",") {
+				ID, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+				if err != nil {
+					return &models.ValidationError{Field: k,
+						Message: fmt.Sprintf("identifiant %q incorrect", s)}
+				}
+				f.In = append(f.In, ID)
+			}
+		}
+	}
+	for _, f := range filters {
+		q.Filters = append(q.Filters, *f)
+	}
+	sort.Slice(q.Filters, func(i, j int) bool {
+		return q.Filters[i].Field < q.Filters[j].Field
+	})
+	return nil
+}
@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testPaginatedQuery is the entry point for testing the page size, the sort
+// and the filters of the paginated queries
+func testPaginatedQuery(t *testing.T, c *TestContext) {
+	t.Run("PaginatedQuery", func(t *testing.T) {
+		testPaginatedPaymentsQuery(t, c)
+		testPaginatedBeneficiariesQuery(t, c)
+	})
+}
+
+// testPaginatedPaymentsQuery checks the decoding and the whitelist of the
+// options and that the filters and the sort are applied to the payments
+func testPaginatedPaymentsQuery(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Page=1&Year=2010&PageSize=a`),
+			RespContains: []string{`Page de paiements, décodage : taille de page`, `"Field":"PageSize"`},
+			Count:        1,
+			StatusCode:   http.StatusBadRequest}, // 0 : bad page size
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Page=1&Year=2010&ActionIDIn=1,a`),
+			RespContains: []string{`Page de paiements, décodage : identifiant`, `"Field":"ActionIDIn"`},
+			Count:        1,
+			StatusCode:   http.StatusBadRequest}, // 1 : bad ID list
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Page=1&Year=2010&Sort=-IrisCode`),
+			RespContains: []string{`tri sur le champ IrisCode non autorisé`, `"Code":"validation"`, `"Field":"Sort"`},
+			Count:        1,
+			StatusCode:   http.StatusBadRequest}, // 2 : sort field not allowed
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Page=1&Year=2010&SectorMin=a`),
+			RespContains: []string{`filtre Sector : intervalle non autorisé`, `"Field":"Filters"`},
+			Count:        1,
+			StatusCode:   http.StatusBadRequest}, // 3 : range on a text field
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Page=1&Year=2010&ValueIn=1`),
+			RespContains: []string{`filtre Value : liste non autorisée`},
+			Count:        1,
+			StatusCode:   http.StatusBadRequest}, // 4 : list on a number field
+		{
+			Token:        c.Config.Users.User.Token,
+			Sent:         []byte(`Page=1&Year=2010&CreationDateMax=2016-13-01`),
+			RespContains: []string{`filtre CreationDate : date`},
+			Count:        1,
+			StatusCode:   http.StatusBadRequest}, // 5 : bad date
+		{
+			Token: c.Config.Users.User.Token,
+			Sent: []byte(`Page=1&Year=2010&Search=cld&PageSize=1&Sort=-Value,CreationDate` +
+				`&ValueMin=239200&ValueMax=239200&CreationDateMin=2016-09-12&CreationDateMax=2016-09-12`),
+			//cSpell: disable
+			RespContains: []string{`"Payments":[`, `"Year":2016,"CreationDate":"2016-09-12T00:00:00Z","Value":239200,"Number":141103`, `"Page":1`},
+			//cSpell: enable
+			Count:         1,
+			CountItemName: `"ID"`,
+			StatusCode:    http.StatusOK}, // 6 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/payments/paginated").WithQueryString(string(tc.Sent)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "PaginatedPaymentsQuery") {
+		t.Error(r)
+	}
+}
+
+// testPaginatedBeneficiariesQuery checks the page size and the descending
+// sort of the beneficiaries
+func testPaginatedBeneficiariesQuery(t *testing.T, c *TestContext) {
+	resp := c.E.GET("/api/beneficiaries/paginated").
+		WithQuery("Page", 1).WithQuery("PageSize", 2).WithQuery("Sort", "-Code").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK).JSON().Object()
+	list := resp.Value("Beneficiary").Array()
+	list.Length().Equal(2)
+	first := list.Element(0).Object().Value("Code").Number().Raw()
+	second := list.Element(1).Object().Value("Code").Number().Raw()
+	if first < second {
+		t.Errorf("PaginatedBeneficiariesQuery : tri décroissant attendu, reçu %v puis %v",
+			first, second)
+	}
+}
@@ -0,0 +1,217 @@
+package actions
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// mailer is used to send the password reset and invitation e-mails, these
+// e-mails being disabled if nil
+var mailer *models.Mailer
+
+// SetMailer sets the mailer used to send the password e-mails
+func SetMailer(m *models.Mailer) {
+	mailer = m
+}
+
+// pendingMails tracks the e-mails sent in background so that the shutdown of
+// the server waits for them
+var pendingMails sync.WaitGroup
+
+// WaitMails waits for the e-mails sent in background until the context is
+// done
+func WaitMails(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pendingMails.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// forgotMessage is sent back whether the e-mail is known or not so that the
+// route can't be used to find the accounts
+const forgotMessage = "Si l'adresse est connue, un courriel de réinitialisation a été envoyé"
+
+// passwordLink returns the link of the frontend page used to set a password
+// with the token
+func passwordLink(token string) string {
+	return mailer.BaseURL + "/password/reset?token=" + url.QueryEscape(token)
+}
+
+type forgotPwdReq struct {
+	Email string `json:"Email"`
+}
+
+// ForgotPassword handles the request of a user who forgot their password and
+// sends them an e-mail with a link to reset it. The same response is sent back
+// whether the address is known or not and whether the e-mail is sent or not.
+func ForgotPassword(ctx iris.Context) {
+	var req forgotPwdReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Mot de passe oublié, décodage : ", err)
+		return
+	}
+	if req.Email == "" {
+		sendError(ctx, http.StatusBadRequest, "Mot de passe oublié : Email vide", nil)
+		return
+	}
+	if mailer == nil {
+		sendError(ctx, http.StatusInternalServerError, "Mot de passe oublié : envoi de courriels non configuré", nil)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	var user models.User
+	if err := user.GetByEmail(req.Email, db); err != nil {
+		if err != sql.ErrNoRows {
+			sendError(ctx, http.StatusInternalServerError, "Mot de passe oublié, requête : ", err)
+			return
+		}
+		ctx.StatusCode(http.StatusOK)
+		ctx.JSON(jsonMessage{forgotMessage})
+		return
+	}
+	token, err := models.CreatePasswordToken(db, user.ID,
+		models.ResetPasswordToken, models.ResetTokenTTL)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Mot de passe oublié, jeton : ", err)
+		return
+	}
+	// Sent in background so that neither the delay nor a failure reveal that
+	// the account exists
+	body := "Bonjour " + user.Name + ",\n\n" +
+		"Pour choisir un nouveau mot de passe, utilisez le lien suivant " +
+		"valable une heure :\n" + passwordLink(token) + "\n\n" +
+		"Si vous n'êtes pas à l'origine de cette demande, ignorez ce courriel.\n"
+	m, logger := mailer, ctx.Application().Logger()
+	pendingMails.Add(1)
+	go func() {
+		defer pendingMails.Done()
+		if err := m.Send(user.Email, "PreLoRU : réinitialisation du mot de passe",
+			body); err != nil {
+			logger.Errorf("Mot de passe oublié, courriel : %v", err)
+		}
+	}()
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{forgotMessage})
+}
+
+type resetPwdReq struct {
+	Token    string `json:"Token"`
+	Password string `json:"Password"`
+}
+
+// ResetPassword handles the request to set a password using the token sent by
+// e-mail. The sessions of the user are closed.
+func ResetPassword(ctx iris.Context) {
+	var req resetPwdReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Réinitialisation de mot de passe, décodage : ", err)
+		return
+	}
+	if req.Token == "" || req.Password == "" {
+		sendError(ctx, http.StatusBadRequest, "Réinitialisation de mot de passe : jeton et mot de passe requis", nil)
+		return
+	}
+	user := models.User{Password: req.Password}
+	if err := user.CryptPwd(); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Réinitialisation de mot de passe, password : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	userID, err := models.ResetPassword(db, req.Token, user.Password)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Réinitialisation de mot de passe : ", err)
+		return
+	}
+	if err = tokens.DelByUser(userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Réinitialisation de mot de passe, sessions : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Mot de passe modifié"})
+}
+
+type inviteUserReq struct {
+	Name   string `json:"Name"`
+	Email  string `json:"Email"`
+	Rights int64  `json:"Rights"`
+}
+
+// InviteUser handles the creation by admin of a user without password and
+// sends them an e-mail with a link to set their first password. The user is
+// removed if the e-mail can't be sent so that the invitation can be retried.
+func InviteUser(ctx iris.Context) {
+	var req inviteUserReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Invitation d'utilisateur, décodage : ", err)
+		return
+	}
+	if mailer == nil {
+		sendError(ctx, http.StatusInternalServerError, "Invitation d'utilisateur : envoi de courriels non configuré", nil)
+		return
+	}
+	// The user can't log in until the first password is set with the token
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Invitation d'utilisateur, password : ", err)
+		return
+	}
+	user := models.User{Name: req.Name, Email: req.Email,
+		Password: hex.EncodeToString(b), Rights: req.Rights}
+	if err := user.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Invitation d'utilisateur : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err := user.Exists(db); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Invitation d'utilisateur : ", err)
+		return
+	}
+	if err := user.CryptPwd(); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Invitation d'utilisateur, cryptage : ", err)
+		return
+	}
+	if err := user.Create(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Invitation d'utilisateur, requête : ", err)
+		return
+	}
+	token, err := models.CreatePasswordToken(db, user.ID,
+		models.InvitePasswordToken, models.InviteTokenTTL)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Invitation d'utilisateur, jeton : ", err)
+		return
+	}
+	if err = mailer.Send(user.Email, "PreLoRU : invitation",
+		"Bonjour "+user.Name+",\n\n"+
+			"Un compte PreLoRU a été créé pour vous. Pour choisir votre mot de "+
+			"passe, utilisez le lien suivant valable sept jours :\n"+
+			passwordLink(token)+"\n"); err != nil {
+		user.Delete(db)
+		sendError(ctx, http.StatusInternalServerError,
+			"Invitation d'utilisateur, courriel : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusCreated)
+	ctx.JSON(userResp{user})
+}
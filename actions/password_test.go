@@ -0,0 +1,275 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/iris-contrib/httpexpect"
+)
+
+// fakeSMTP is a local SMTP server storing the received e-mails in place of
+// the relay used in production
+type fakeSMTP struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	mails    []string
+}
+
+// startFakeSMTP launches a fake SMTP server on a free local port
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("Serveur SMTP de test : %v", err)
+		t.FailNow()
+		return nil
+	}
+	s := &fakeSMTP{listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// mailer returns a mailer using the fake server
+func (s *fakeSMTP) mailer() *models.Mailer {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return &models.Mailer{Host: addr.IP.String(), Port: addr.Port,
+		From: "preloru@iledefrance.fr", BaseURL: "http://localhost:8080"}
+}
+
+// serve handles a SMTP session accepting every command
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"):
+			fmt.Fprint(conn, "250-localhost\r\n250 8BITMIME\r\n")
+		case cmd == "DATA":
+			fmt.Fprint(conn, "354 fin par <CRLF>.<CRLF>\r\n")
+			var mail strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				mail.WriteString(l)
+			}
+			s.mutex.Lock()
+			s.mails = append(s.mails, mail.String())
+			s.mutex.Unlock()
+			fmt.Fprint(conn, "250 OK\r\n")
+		case cmd == "QUIT":
+			fmt.Fprint(conn, "221 Bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "250 OK\r\n")
+		}
+	}
+}
+
+// count returns the number of received e-mails
+func (s *fakeSMTP) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.mails)
+}
+
+// wait waits for the number of received e-mails to exceed count for a few
+// seconds and returns false if no e-mail is received
+func (s *fakeSMTP) wait(count int) bool {
+	for i := 0; i < 50; i++ {
+		if s.count() > count {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+// last returns the last received e-mail
+func (s *fakeSMTP) last() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.mails) == 0 {
+		return ""
+	}
+	return s.mails[len(s.mails)-1]
+}
+
+// mailTokenRegexp extracts the token of the link sent by e-mail
+var mailTokenRegexp = regexp.MustCompile(`token=([0-9a-f]{64})`)
+
+// lastMailToken returns the token of the last e-mail sent to the address
+func lastMailToken(t *testing.T, c *TestContext, email string) string {
+	mail := c.Mails.last()
+	if !strings.Contains(mail, "To: "+email) {
+		t.Errorf("Courriel à %s non reçu : %s", email, mail)
+		return ""
+	}
+	m := mailTokenRegexp.FindStringSubmatch(mail)
+	if m == nil {
+		t.Errorf("Lien absent du courriel : %s", mail)
+		return ""
+	}
+	return m[1]
+}
+
+// testPassword is the entry point for testing the password reset and the
+// invitation of users
+func testPassword(t *testing.T, c *TestContext) {
+	t.Run("Password", func(t *testing.T) {
+		token := testForgotPassword(t, c)
+		testResetPassword(t, c, token)
+		testInviteUser(t, c)
+	})
+}
+
+// testForgotPassword checks an e-mail is only sent to a known address and
+// returns the token of the link
+func testForgotPassword(t *testing.T, c *TestContext) string {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			RespContains: []string{`Mot de passe oublié, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Email":""}`),
+			RespContains: []string{`Mot de passe oublié : Email vide`},
+			StatusCode:   http.StatusBadRequest}, // 1 : empty email
+		{
+			Sent:         []byte(`{"Email":"inconnu@iledefrance.fr"}`),
+			RespContains: []string{forgotMessage},
+			StatusCode:   http.StatusOK}, // 2 : unknown email
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/password/forgot").WithBytes(tc.Sent).Expect()
+	}
+	count := c.Mails.count()
+	for _, r := range chkFactory(tcc, f, "ForgotPassword") {
+		t.Error(r)
+	}
+	if c.Mails.count() != count {
+		t.Error("ForgotPassword : courriel envoyé à une adresse inconnue")
+	}
+	c.E.POST("/api/user/password/forgot").
+		WithBytes([]byte(`{"Email":"` + c.Config.Users.User.Email + `"}`)).
+		Expect().Status(http.StatusOK).Body().Contains(forgotMessage)
+	if !c.Mails.wait(count) {
+		t.Error("ForgotPassword : courriel non reçu")
+	}
+	return lastMailToken(t, c, c.Config.Users.User.Email)
+}
+
+// testResetPassword checks the token can only be used once and that the user
+// can log in with the new password. The password is set to the former one and
+// the token of the user, whose sessions are closed, is fetched again.
+func testResetPassword(t *testing.T, c *TestContext, token string) {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			RespContains: []string{`Réinitialisation de mot de passe, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Token":"` + token + `","Password":""}`),
+			RespContains: []string{`Réinitialisation de mot de passe : jeton et mot de passe requis`},
+			StatusCode:   http.StatusBadRequest}, // 1 : empty password
+		{
+			Sent:         []byte(`{"Token":"faux","Password":"nouveau"}`),
+			RespContains: []string{`Réinitialisation de mot de passe : jeton invalide ou expiré`},
+			StatusCode:   http.StatusBadRequest}, // 2 : bad token
+		{
+			Sent: []byte(`{"Token":"` + token + `","Password":"` +
+				c.Config.Users.User.Password + `"}`),
+			RespContains: []string{`Mot de passe modifié`},
+			StatusCode:   http.StatusOK}, // 3 : ok
+		{
+			Sent: []byte(`{"Token":"` + token + `","Password":"` +
+				c.Config.Users.User.Password + `"}`),
+			RespContains: []string{`Réinitialisation de mot de passe : jeton invalide ou expiré`},
+			StatusCode:   http.StatusBadRequest}, // 4 : token already used
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/password/reset").WithBytes(tc.Sent).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "ResetPassword") {
+		t.Error(r)
+	}
+	var lr struct{ Token string }
+	response := c.E.POST("/api/user/login").WithBytes([]byte(`{"Email":"` +
+		c.Config.Users.User.Email + `","Password":"` +
+		c.Config.Users.User.Password + `"}`)).Expect()
+	if err := json.Unmarshal(response.Content, &lr); err != nil || lr.Token == "" {
+		t.Errorf("ResetPassword : connexion impossible %s", string(response.Content))
+		return
+	}
+	c.Config.Users.User.Token = lr.Token
+	createTestCases(c)
+}
+
+// testInviteUser checks if route is admin protected and the invited user can
+// set their password with the link sent by e-mail
+func testInviteUser(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Sent:         []byte(`fake`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Invitation d'utilisateur, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
+		{
+			Sent:         []byte(`{"Name":"","Email":"invite@iledefrance.fr"}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Invitation d'utilisateur : Champ name vide`},
+			StatusCode:   http.StatusBadRequest}, // 2 : empty name
+		{
+			Sent:         []byte(`{"Name":"Utilisateur","Email":"invite@iledefrance.fr"}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Invitation d'utilisateur : Utilisateur existant`},
+			StatusCode:   http.StatusConflict}, // 3 : existing user
+		{
+			Sent: []byte(`{"Name":"Utilisateur invité","Email":"invite@iledefrance.fr",` +
+				`"Rights":1}`),
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"User":{"ID":`, `"Name":"Utilisateur invité"`,
+				`"Email":"invite@iledefrance.fr"`, `"Rights":1`},
+			StatusCode: http.StatusCreated}, // 4 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/invite").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "InviteUser") {
+		t.Error(r)
+	}
+	token := lastMailToken(t, c, "invite@iledefrance.fr")
+	c.E.POST("/api/user/password/reset").
+		WithBytes([]byte(`{"Token":"` + token + `","Password":"invite"}`)).
+		Expect().Status(http.StatusOK)
+	c.E.POST("/api/user/login").
+		WithBytes([]byte(`{"Email":"invite@iledefrance.fr","Password":"invite"}`)).
+		Expect().Status(http.StatusOK).Body().Contains(`"Token"`)
+}
@@ -17,8 +17,7 @@ func GetPayments(ctx iris.Context) {
 	var resp models.Payments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des paiements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des paiements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -28,15 +27,17 @@ func GetPayments(ctx iris.Context) {
 // BatchPayments handle the post request to update and insert a batch of payments into the database
 func BatchPayments(ctx iris.Context) {
 	var b models.PaymentBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Paiements, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de Paiements, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Paiements, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Paiements, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -48,47 +49,76 @@ func BatchPayments(ctx iris.Context) {
 func GetPaginatedPayments(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de paiements, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Page de paiements, décodage Year : ", err)
 		return
 	}
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de paiements, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Page de paiements, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
-	req := models.PaginatedQuery{Year: year, Page: page, Search: search}
+	req := models.PaginatedQuery{Year: year, Page: page, Search: search,
+		Scope: userScope(ctx)}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Page de paiements, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedPayments
 	if err := resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de paiements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Page de paiements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(resp)
 }
 
-// GetExportedPayments handle the get request for commitments that match a given
-// search pattern returning a payments with full linked names.
-func GetExportedPayments(ctx iris.Context) {
+// GetCursorPayments handles the get request to fetch the page of payments
+// following the cursor that match the given pattern and returns them with
+// the cursor of the next page
+func GetCursorPayments(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export de paiements, décodage Year : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Paiements par curseur, décodage Year : ", err)
+		return
+	}
+	req := models.PaginatedQuery{Year: year, Search: ctx.URLParam("Search"),
+		Cursor: ctx.URLParam("Cursor"), Scope: userScope(ctx)}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Paiements par curseur, décodage : ",
+			err)
 		return
 	}
-	search := ctx.URLParam("Search")
-	req := models.ExportQuery{Year: year, Search: search}
 	db := ctx.Values().Get("db").(*sql.DB)
-	var resp models.ExportedPayments
+	var resp models.CursorPayments
 	if err := resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export de paiements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Paiements par curseur, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(resp)
 }
+
+// GetExportedPayments handle the get request for commitments that match a given
+// search pattern returning a payments with full linked names.
+func GetExportedPayments(ctx iris.Context) {
+	year, err := ctx.URLParamInt64("Year")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Export de paiements, décodage Year : ", err)
+		return
+	}
+	search := ctx.URLParam("Search")
+	req := models.ExportQuery{Year: year, Search: search, Scope: userScope(ctx)}
+	db := ctx.Values().Get("db").(*sql.DB)
+	var resp models.ExportedPayments
+	sendExport(ctx, "Export de paiements, ", "ExportedPayment", "paiements",
+		models.ExportedPayment{}, func(w *models.ExportWriter) error {
+			return resp.Stream(db, &req, func(row *models.ExportedPayment) error {
+				return w.WriteRow(row)
+			})
+		})
+}
@@ -12,16 +12,18 @@ import (
 // BatchPaymentCredits handle the post request for a batch of payment credits
 func BatchPaymentCredits(ctx iris.Context) {
 	var req models.PaymentCreditBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch d'enveloppes de crédits, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch d'enveloppes de crédits, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	year := (int64)(time.Now().Year())
-	if err := req.Save(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch d'enveloppes de crédits, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), year, db)
+	if err != nil {
+		sendBatchError(ctx, "Batch d'enveloppes de crédits, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -33,15 +35,14 @@ func BatchPaymentCredits(ctx iris.Context) {
 func GetAllPaymentCredits(ctx iris.Context) {
 	year, err := ctx.URLParamInt("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Liste des enveloppes de crédits, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Liste des enveloppes de crédits, décodage : ", err)
 		return
 	}
 	var resp models.PaymentCredits
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des enveloppes de crédits, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des enveloppes de crédits, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
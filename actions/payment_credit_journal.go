@@ -11,15 +11,17 @@ import (
 // BatchPaymentCreditJournals handle the post request for a batch of payment credits
 func BatchPaymentCreditJournals(ctx iris.Context) {
 	var req models.PaymentCreditJournalBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch mouvements de crédits, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch mouvements de crédits, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch mouvements de crédits, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch mouvements de crédits, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -31,15 +33,13 @@ func BatchPaymentCreditJournals(ctx iris.Context) {
 func GetAllPaymentCreditJournals(ctx iris.Context) {
 	year, err := ctx.URLParamInt("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Mouvements de crédits, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Mouvements de crédits, décodage : ", err)
 		return
 	}
 	var resp models.PaymentCreditJournals
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Mouvements de crédits, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Mouvements de crédits, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -56,20 +56,20 @@ type creditsAndJournalResp struct {
 func GetPaymentCreditsAndJournal(ctx iris.Context) {
 	year, err := ctx.URLParamInt("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Situation et mouvements de crédits, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Situation et mouvements de crédits, décodage : ", err)
 		return
 	}
 	var resp creditsAndJournalResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.PaymentCreditJournals.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Situation et mouvements de crédits, requête journal : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Situation et mouvements de crédits, requête journal : ", err)
 		return
 	}
 	if err = resp.PaymentCredits.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Situation et mouvements de crédits, requête situation : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Situation et mouvements de crédits, requête situation : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -14,16 +14,14 @@ import (
 func GetPaymentDelays(ctx iris.Context) {
 	after, err := ctx.URLParamInt64("after")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Délais de paiement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Délais de paiement, décodage : ", err)
 		return
 	}
 	afterTime := time.Unix(after/1000, 0)
 	var resp models.PaymentDelays
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = resp.GetSome(afterTime, db); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Délais de paiement, requête : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Délais de paiement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
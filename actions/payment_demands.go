@@ -14,8 +14,7 @@ func GetAllPaymentDemands(ctx iris.Context) {
 	var resp models.PaymentDemands
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Demandes de paiement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Demandes de paiement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -30,14 +29,14 @@ type paymentDemandReq struct {
 func UpdatePaymentDemand(ctx iris.Context) {
 	var req paymentDemandReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Mise à jour de demande de paiement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Mise à jour de demande de paiement, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.PaymentDemand.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Mise à jour de demande de paiement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Mise à jour de demande de paiement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -48,20 +47,17 @@ func UpdatePaymentDemand(ctx iris.Context) {
 // batch of payment demands
 func BatchPaymentDemands(ctx iris.Context) {
 	var req models.PaymentDemandBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de demandes de paiement, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de demandes de paiement, décodage : ", err)
 		return
 	}
-	if err := req.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de demandes de paiement : " + err.Error()})
+	db := ctx.Values().Get("db").(*sql.DB)
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de demandes de paiement, requête : ", report, err)
 		return
 	}
-	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de demandes de paiement, requête : " + err.Error()})
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -74,8 +70,8 @@ func GetPaymentDemandCounts(ctx iris.Context) {
 	var resp models.PaymentDemandCounts
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Nombre de demandes de paiement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Nombre de demandes de paiement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -88,8 +84,8 @@ func GetPaymentDemandStocks(ctx iris.Context) {
 	var resp models.PaymentDemandsStocks
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Stocks de demandes de paiement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Stocks de demandes de paiement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -26,37 +26,45 @@ func batchPaymentDemandsTest(t *testing.T, c *TestContext) {
 			Sent:         []byte(`{"PaymentDemand":{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}]}`),
 			RespContains: []string{"Batch de demandes de paiement, décodage"}}, // 1 bad json
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 IrisCode vide"}}, // 2 IrisCode empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"IrisCode"`}}, // 2 IrisCode empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 IrisName vide"}}, //3 IrisName empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"IrisName"`}}, //3 IrisName empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 CommitmentDate vide"}}, // 4 commiment_date empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"CommitmentDate"`}}, // 4 commiment_date empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 BeneficiaryCode vide"}}, // 5 BeneficiaryCode empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"BeneficiaryCode"`}}, // 5 BeneficiaryCode empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 DemandNumber vide"}}, // 6 demande_number empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"DemandNumber"`}}, // 6 demande_number empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 DemandDate vide"}}, // 7 DemandDate empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"DemandDate"`}}, // 7 DemandDate empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
-			RespContains: []string{"Batch de demandes de paiement : ligne 1 ReceiptDate vide"}}, // 8 ReceiptDate empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":1,"Field":"ReceiptDate"`}}, // 8 ReceiptDate empty
 		{Token: c.Config.Users.Admin.Token,
-			StatusCode:   http.StatusBadRequest,
-			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}]}`),
-			RespContains: []string{"Batch de demandes de paiement : date d'import non définie"}}, // 9 import_date empty
+			StatusCode: http.StatusBadRequest,
+			Sent:       []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}]}`),
+			RespContains: []string{"Batch de demandes de paiement, requête : lignes incorrectes",
+				`{"Line":0,"Field":"ImportDate","Message":"date d'import non définie"}`}}, // 9 import_date empty
 		{Token: c.Config.Users.Admin.Token,
 			StatusCode:   http.StatusOK,
 			Sent:         []byte(`{"PaymentDemand":[{"IrisCode":"12000139","IrisName":"Construction logements PLAI","CommitmentDate":43168,"BeneficiaryCode":7010,"DemandNumber":1,"DemandDate":43268,"ReceiptDate":43278,"DemandValue":10000000,"CsfDate":null,"CsfComment":null,"DemandStatus":null,"StatusComment":null}],"ImportDate":"2018-06-28T01:00:00Z"}`),
@@ -28,10 +28,11 @@ func testBatchPayments(t *testing.T, c *TestContext) {
 	tcc := []TestCase{
 		*c.AdminCheckTestCase, // 0 : user unauthorized
 		{
-			Token:        c.Config.Users.Admin.Token,
-			Sent:         []byte(`{"Payment":[{"CommitmentYear":2012,"CommitmentCode":"IRIS "}]}`),
-			RespContains: []string{"Batch de Paiements, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : validation error
+			Token: c.Config.Users.Admin.Token,
+			Sent:  []byte(`{"Payment":[{"CommitmentYear":2012,"CommitmentCode":"IRIS "}]}`),
+			RespContains: []string{"Batch de Paiements, requête : lignes incorrectes",
+				`{"Line":1,"Field":"CommitmentNumber"`},
+			StatusCode: http.StatusBadRequest}, // 1 : validation error
 		{
 			Token:        c.Config.Users.Admin.Token,
 			Sent:         batchContent,
@@ -78,7 +79,7 @@ func testGetPaginatedPayments(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Page=2&Year=a&Search=cld`),
 			RespContains: []string{`Page de paiements, décodage Year :`},
 			Count:        1,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad param query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad param query
 		{
 			Token: c.Config.Users.User.Token,
 			Sent:  []byte(`Page=2&Year=2010&Search=cld`),
@@ -107,7 +108,7 @@ func testExportedPayments(t *testing.T, c *TestContext) {
 			Sent:         []byte(`Year=a&Search=cld`),
 			RespContains: []string{`Export de paiements, décodage Year :`},
 			Count:        1,
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad param query
+			StatusCode:   http.StatusBadRequest}, // 1 : bad param query
 		{
 			Token: c.Config.Users.User.Token,
 			Sent:  []byte(`Year=2010&Search=cld`),
@@ -0,0 +1,164 @@
+package actions
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+)
+
+// RoutePermission gives the rights required by a route. Rights is the name of
+// the right handler of the route, "public" if the route isn't protected.
+// Observer is true if an observer can use the route. Permission is the role
+// permission granting the route, empty if roles can't grant it. Handler is the
+// name of the function handling the route. Scoped is true if the route can
+// be granted by a role limited to a scope.
+type RoutePermission struct {
+	Method     string `json:"Method"`
+	Path       string `json:"Path"`
+	Rights     string `json:"Rights"`
+	Message    string `json:"Message"`
+	Observer   bool   `json:"Observer"`
+	Permission string `json:"Permission"`
+	Scoped     bool   `json:"Scoped"`
+	Handler    string `json:"-"`
+}
+
+// routePermissionsResp embeddes the route permission table for json export
+type routePermissionsResp struct {
+	Lines []RoutePermission `json:"RoutePermission"`
+}
+
+// routePermissions is the table filled by SetRoutes
+var routePermissions []RoutePermission
+
+// routeRolePermissions gives the role permission of the protected routes using
+// the route names
+var routeRolePermissions = make(map[string]string)
+
+// scopedRoutes gives the routes, using their names, whose handler limits the
+// rows to the scope of the user
+var scopedRoutes = make(map[string]bool)
+
+// scopedHandlers are the handlers limiting the fetched rows to the scope of
+// the user or checking the changed rows are in this scope. The other routes
+// are refused to a user whose roles are limited to a scope.
+var scopedHandlers = map[string]bool{"GetCopros": true, "GetCoproDatas": true,
+	"GetRenewProjects": true, "GetPaginatedHousings": true,
+	"GetPaginatedCommitments": true, "GetUnlinkedCommitments": true,
+	"GetCursorCommitments": true, "ExportCommitments": true,
+	"GetPaginatedPayments": true, "GetCursorPayments": true,
+	"GetExportedPayments": true, "GetCoproForecast": true,
+	"GetCoproForecasts": true, "CreateCoproForecast": true,
+	"UpdateCoproForecast": true, "DeleteCoproForecast": true}
+
+// adminResources can't be granted by a role to avoid a user being able to
+// extend their own rights, the routes of an AdminOnly right handler being
+// never granted
+var adminResources = map[string]bool{"api_key": true, "audit": true,
+	"cache": true, "role": true, "route": true, "security_log": true,
+	"user": true}
+
+// pluralExceptions are the resources whose name ends with a s in singular
+var pluralExceptions = map[string]bool{"rpls": true}
+
+// routeResource returns the resource of a route, the first segment of the
+// path in singular
+func routeResource(path string) string {
+	resource := strings.TrimPrefix(path, "/api/")
+	if i := strings.Index(resource, "/"); i >= 0 {
+		resource = resource[:i]
+	}
+	switch {
+	case pluralExceptions[resource]:
+	case strings.HasSuffix(resource, "ies"):
+		resource = strings.TrimSuffix(resource, "ies") + "y"
+	case strings.HasSuffix(resource, "s"):
+		resource = strings.TrimSuffix(resource, "s")
+	}
+	return resource
+}
+
+// rolePermission returns the role permission of a route, made of its resource
+// and of the action, read for the GET requests and write otherwise
+func rolePermission(method string, path string) string {
+	resource := routeResource(path)
+	if adminResources[resource] {
+		return ""
+	}
+	if method == http.MethodGet {
+		return resource + ":read"
+	}
+	return resource + ":write"
+}
+
+// rightsParty is a party whose routes are protected by a right handler and
+// recorded in the route permission table
+type rightsParty struct {
+	iris.Party
+	rights *RightHandler
+}
+
+// newRightsParty returns a party protected by the right handler
+func newRightsParty(p *rightsParty, r *RightHandler) *rightsParty {
+	return &rightsParty{Party: p.Party.Party("", RightsMiddleWare(r)), rights: r}
+}
+
+// record adds the route handled by the last handler to the permission table
+func (p *rightsParty) record(route *router.Route, h []iris.Handler) *router.Route {
+	perm := RoutePermission{Method: route.Method, Path: route.Tmpl().Src,
+		Rights: "public", Handler: strings.TrimPrefix(path.Ext(context.HandlerName(h[len(h)-1])), ".")}
+	if p.rights != nil {
+		perm.Rights, perm.Message = p.rights.Name, p.rights.Message
+		perm.Observer = (route.Method == http.MethodGet && !p.rights.AdminOnly) ||
+			p.rights.ObserverWrite
+		if !p.rights.AdminOnly {
+			perm.Permission = rolePermission(route.Method, perm.Path)
+			perm.Scoped = perm.Permission != "" && scopedHandlers[perm.Handler]
+		}
+		routeRolePermissions[route.Name] = perm.Permission
+		scopedRoutes[route.Name] = perm.Scoped
+	}
+	routePermissions = append(routePermissions, perm)
+	return route
+}
+
+// Get registers and records a GET route
+func (p *rightsParty) Get(path string, h ...iris.Handler) *router.Route {
+	return p.record(p.Party.Get(path, h...), h)
+}
+
+// Post registers and records a POST route
+func (p *rightsParty) Post(path string, h ...iris.Handler) *router.Route {
+	return p.record(p.Party.Post(path, h...), h)
+}
+
+// Put registers and records a PUT route
+func (p *rightsParty) Put(path string, h ...iris.Handler) *router.Route {
+	return p.record(p.Party.Put(path, h...), h)
+}
+
+// Delete registers and records a DELETE route
+func (p *rightsParty) Delete(path string, h ...iris.Handler) *router.Route {
+	return p.record(p.Party.Delete(path, h...), h)
+}
+
+// GetRoutePermissions handles the get request to fetch the rights required by
+// each route of the API sorted by path and method
+func GetRoutePermissions(ctx iris.Context) {
+	resp := routePermissionsResp{Lines: make([]RoutePermission,
+		len(routePermissions))}
+	copy(resp.Lines, routePermissions)
+	sort.Slice(resp.Lines, func(i, j int) bool {
+		if resp.Lines[i].Path != resp.Lines[j].Path {
+			return resp.Lines[i].Path < resp.Lines[j].Path
+		}
+		return resp.Lines[i].Method < resp.Lines[j].Method
+	})
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
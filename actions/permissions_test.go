@@ -0,0 +1,81 @@
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testRoutePermissions is the entry point for testing the observer role and
+// the route permission table
+func testRoutePermissions(t *testing.T, c *TestContext) {
+	t.Run("RoutePermissions", func(t *testing.T) {
+		testObserverRights(t, c)
+		testGetRoutePermissions(t, c)
+	})
+}
+
+// testObserverRights checks an observer can use the GET routes not reserved to
+// the admins but none of the mutating routes, even with other rights
+func testObserverRights(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Params:       `GET /api/users`,
+			Token:        c.Config.Users.ObserverUser.Token,
+			RespContains: []string{`Droits administrateur requis`},
+			StatusCode:   http.StatusUnauthorized}, // 0 : admin GET route
+		{
+			Params:       `GET /api/pre_prog/copro?Year=2019`,
+			Token:        c.Config.Users.ObserverUser.Token,
+			RespContains: []string{`"FcPreProg":`},
+			StatusCode:   http.StatusOK}, // 1 : copro bit not required
+		{
+			Params:       `GET /api/user/sessions`,
+			Token:        c.Config.Users.ObserverUser.Token,
+			RespContains: []string{`"UserSession":[`},
+			StatusCode:   http.StatusOK}, // 2 : account GET route
+		{
+			Params:       `POST /api/copro_forecast`,
+			Token:        c.Config.Users.ObserverUser.Token,
+			RespContains: []string{observerMessage},
+			StatusCode:   http.StatusUnauthorized}, // 3 : copro bit ignored
+		{
+			Params:       `DELETE /api/copro/1`,
+			Token:        c.Config.Users.ObserverUser.Token,
+			RespContains: []string{observerMessage},
+			StatusCode:   http.StatusUnauthorized}, // 4 : admin mutating route
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		req := strings.SplitN(tc.Params, " ", 2)
+		return c.E.Request(req[0], req[1]).WithBytes([]byte(`{}`)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "ObserverRights") {
+		t.Error(r)
+	}
+}
+
+// testGetRoutePermissions checks route is admin protected and the table gives
+// the rights of the routes
+func testGetRoutePermissions(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"RoutePermission":[`,
+				`{"Method":"GET","Path":"/api/audit","Rights":"admin","Message":"Droits administrateur requis","Observer":false,"Permission":"","Scoped":false}`,
+				`{"Method":"POST","Path":"/api/copro_forecast","Rights":"copro","Message":"Droits sur les copropriétés requis","Observer":false,"Permission":"copro_forecast:write","Scoped":true}`,
+				`{"Method":"POST","Path":"/api/user/login","Rights":"public","Message":"","Observer":false,"Permission":"","Scoped":false}`,
+				`{"Method":"POST","Path":"/api/user/logout","Rights":"account","Message":"Connexion requise","Observer":true,"Permission":"","Scoped":false}`},
+			StatusCode: http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/routes").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetRoutePermissions") {
+		t.Error(r)
+	}
+}
@@ -12,15 +12,17 @@ import (
 // with a set of datas
 func BatchPlacements(ctx iris.Context) {
 	var req models.Placements
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de stages, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de stages, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de stages, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de stages, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -32,8 +34,7 @@ func GetPlacements(ctx iris.Context) {
 	var resp models.Placements
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des stages, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des stages, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -45,14 +46,12 @@ func GetBeneficiaryPlacements(ctx iris.Context) {
 	var resp models.Placements
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Stages d'un bénéficiaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Stages d'un bénéficiaire, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetByBeneficiary(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Stages d'un bénéficiaire, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Stages d'un bénéficiaire, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -65,14 +64,14 @@ func GetBeneficiaryGroupPlacements(ctx iris.Context) {
 	var resp models.Placements
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Stagiaires d'un groupe de bénéficiaires, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Stagiaires d'un groupe de bénéficiaires, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetByBeneficiaryGroup(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Stagiaires d'un groupe de bénéficiaires, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Stagiaires d'un groupe de bénéficiaires, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -88,20 +87,17 @@ func UpdatePlacement(ctx iris.Context) {
 	var req updatePlacementReq
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Engagement de stagiaires, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Engagement de stagiaires, paramètre : ", err)
 		return
 	}
 	if err = ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Engagement de stagiaire, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Engagement de stagiaire, décodage : ", err)
 		return
 	}
 	req.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des stages, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des stages, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
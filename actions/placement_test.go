@@ -26,13 +26,14 @@ func testBatchPlacements(t *testing.T, c *TestContext) {
 			Sent: []byte(`"Placement":[{"IrisCode":"","Count":1,"ContractYear":null},
 			{"IrisCode":"14004240","Count":0,"ContractYear":2019}]}`),
 			RespContains: []string{"Batch de stages, décodage : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad payload
+			StatusCode:   http.StatusBadRequest}, // 1 : bad payload
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"Placement":[{"IrisCode":"","Count":1,"ContractYear":null},
 			{"IrisCode":"14004240","Count":0,"ContractYear":2019}]}`),
-			RespContains: []string{"Batch de stages, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 2 : IrisCode empty
+			RespContains: []string{"Batch de stages, requête : lignes incorrectes",
+				`{"Line":1,"Field":"IrisCode","Message":"code IRIS vide"}`},
+			StatusCode: http.StatusBadRequest}, // 2 : IrisCode empty
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"Placement":[{"IrisCode":"13021233","Count":1,"ContractYear":null},
@@ -12,15 +12,13 @@ import (
 func GetPmtForecasts(ctx iris.Context) {
 	year, err := ctx.URLParamInt("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Prévisions de paiements, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Prévisions de paiements, décodage : ", err)
 		return
 	}
 	var resp models.PmtForecasts
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db, year); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Prévisions de paiements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Prévisions de paiements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
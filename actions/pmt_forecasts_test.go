@@ -30,7 +30,7 @@ func testGetPmtForecasts(t *testing.T, c *TestContext) {
 			RespContains: []string{`Prévisions de paiements, décodage : `},
 			Count:        1,
 			Sent:         []byte(`Year=a`),
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad year parameter format
+			StatusCode:   http.StatusBadRequest}, // 2 : bad year parameter format
 		{
 			Token: c.Config.Users.Admin.Token,
 			RespContains: []string{`{"PmtForecast":[{"ActionID":3,"ActionCode":15400202,` +
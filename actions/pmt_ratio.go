@@ -12,15 +12,13 @@ import (
 func GetPmtRatios(ctx iris.Context) {
 	year, err := ctx.URLParamInt("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Ratios de paiements, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Ratios de paiements, décodage : ", err)
 		return
 	}
 	var resp models.PmtRatios
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db, year); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Ratios de paiements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Ratios de paiements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -31,15 +29,17 @@ func GetPmtRatios(ctx iris.Context) {
 // year
 func BatchPmtRatios(ctx iris.Context) {
 	var req models.PmtRatioBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de ratios de paiement, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de ratios de paiement, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de ratios de paiement, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de ratios de paiement, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -51,8 +51,8 @@ func GetPmtRatiosYears(ctx iris.Context) {
 	var resp models.PmtRatiosYears
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Années des ratios des paiements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Années des ratios des paiements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
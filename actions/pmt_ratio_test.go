@@ -25,7 +25,7 @@ func testGetPmtRatios(t *testing.T, c *TestContext) {
 			RespContains: []string{`Ratios de paiements, décodage : `},
 			Count:        1,
 			Sent:         []byte(`Year=a`),
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad year parameter format
+			StatusCode:   http.StatusBadRequest}, // 1 : bad year parameter format
 		{
 			Token:         c.Config.Users.User.Token,
 			RespContains:  []string{`"PmtRatio":[{"Index":0,"SectorID":1,"SectorName":"LO","Ratio":0.8},{"Index":1,"SectorID":1,"SectorName":"LO","Ratio":0.2}]`},
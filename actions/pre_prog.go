@@ -13,15 +13,14 @@ import (
 func GetPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Préprogrammation d'une année, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Préprogrammation d'une année, décodage : ", err)
 		return
 	}
 	var resp models.PreProgs
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Préprogrammation d'une année, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Préprogrammation d'une année, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -33,15 +32,15 @@ func GetPreProgs(ctx iris.Context) {
 func GetCoproPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Préprogrammation copro d'une année, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Préprogrammation copro d'une année, décodage : ", err)
 		return
 	}
 	var resp models.FcPreProgs
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAllOfKind(year, models.KindCopro, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Préprogrammation copro d'une année, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Préprogrammation copro d'une année, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -53,15 +52,14 @@ func GetCoproPreProgs(ctx iris.Context) {
 func GetRPPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Préprogrammation RU d'une année, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Préprogrammation RU d'une année, décodage : ", err)
 		return
 	}
 	var resp models.FcPreProgs
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAllOfKind(year, models.KindRenewProject, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Préprogrammation RU d'une année, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Préprogrammation RU d'une année, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -73,15 +71,15 @@ func GetRPPreProgs(ctx iris.Context) {
 func GetHousingPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Préprogrammation logement d'une année, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Préprogrammation logement d'une année, décodage : ", err)
 		return
 	}
 	var resp models.FcPreProgs
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAllOfKind(year, models.KindHousing, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Préprogrammation logement d'une année, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Préprogrammation logement d'une année, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -93,20 +91,23 @@ func GetHousingPreProgs(ctx iris.Context) {
 func SetCoproPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation copro d'une année, décodage année : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la préprogrammation copro d'une année, décodage année : ", err)
 		return
 	}
 	var req models.PreProgBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation copro d'une année, décodage batch : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la préprogrammation copro d'une année, décodage batch : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(models.KindCopro, year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation copro d'une année, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), models.KindCopro, year, db)
+	if err != nil {
+		sendBatchError(ctx, "Fixation de la préprogrammation copro d'une année, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -118,20 +119,23 @@ func SetCoproPreProgs(ctx iris.Context) {
 func SetRPPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation RU d'une année, décodage année : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la préprogrammation RU d'une année, décodage année : ", err)
 		return
 	}
 	var req models.PreProgBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation RU d'une année, décodage batch : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la préprogrammation RU d'une année, décodage batch : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(models.KindRenewProject, year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation RU d'une année, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), models.KindRenewProject, year, db)
+	if err != nil {
+		sendBatchError(ctx, "Fixation de la préprogrammation RU d'une année, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -143,20 +147,23 @@ func SetRPPreProgs(ctx iris.Context) {
 func SetHousingPreProgs(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation logement d'une année, décodage année : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la préprogrammation logement d'une année, décodage année : ", err)
 		return
 	}
 	var req models.PreProgBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation logement d'une année, décodage batch : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la préprogrammation logement d'une année, décodage batch : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(models.KindHousing, year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de la préprogrammation logement d'une année, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), models.KindHousing, year, db)
+	if err != nil {
+		sendBatchError(ctx, "Fixation de la préprogrammation logement d'une année, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -13,15 +13,14 @@ import (
 func GetProg(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Programmation d'une année, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Programmation d'une année, décodage : ", err)
 		return
 	}
 	var resp models.Progs
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Programmation d'une année, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Programmation d'une année, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -41,35 +40,35 @@ type progDatasResp struct {
 func GetProgDatas(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Données de programmation d'une année, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Données de programmation d'une année, décodage : ", err)
 		return
 	}
 	var resp progDatasResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Progs.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données de programmation d'une année, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de programmation d'une année, requête : ", err)
 		return
 	}
 	if err := resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données de programmation d'une année, requête commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de programmation d'une année, requête commissions : ", err)
 		return
 	}
 	if err := resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données de programmation d'une année, requête actions budgétaires : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de programmation d'une année, requête actions budgétaires : ", err)
 		return
 	}
 	if err := resp.Copros.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données de programmation d'une année, requête copros : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de programmation d'une année, requête copros : ", err)
 		return
 	}
 	if err := resp.RenewProjects.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Données de programmation d'une année, requête projets RU : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de programmation d'une année, requête projets RU : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -80,26 +79,29 @@ func GetProgDatas(ctx iris.Context) {
 func SetProg(ctx iris.Context) {
 	year, err := ctx.URLParamInt64("Year")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la programmation d'une année, décodage année : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la programmation d'une année, décodage année : ", err)
 		return
 	}
 	var req models.ProgBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Fixation de la programmation d'une année, décodage batch : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Fixation de la programmation d'une année, décodage batch : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de la programmation d'une année, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), year, db)
+	if err != nil {
+		sendBatchError(ctx, "Fixation de la programmation d'une année, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	var resp models.Progs
 	if err := resp.GetAll(year, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Fixation de la programmation d'une année, requête programmation : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Fixation de la programmation d'une année, requête programmation : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -112,8 +114,7 @@ func GetProgYears(ctx iris.Context) {
 	var resp models.ProgYears
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Années de programmation, requête : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Années de programmation, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
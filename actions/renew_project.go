@@ -33,56 +33,56 @@ type renewProjectDataResp struct {
 func GetRenewProjectDatas(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, paramètre : ", err)
 		return
 	}
 	var resp renewProjectDataResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	resp.RenewProject.ID = ID
 	if err = resp.RenewProject.GetByID(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête renewProject : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête renewProject : ", err)
 		return
 	}
 	if err = resp.RPLinkedCommitments.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête commitments : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête commitments : ", err)
 		return
 	}
 	if err = resp.Payments.GetLinkedToRenewProject(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête commitments : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête commitments : ", err)
 		return
 	}
 	if err = resp.RenewProjectForecasts.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête forecasts : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête forecasts : ", err)
 		return
 	}
 	if err = resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête commissions : ", err)
 		return
 	}
 	if err = resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête actions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête actions : ", err)
 		return
 	}
 	if err = resp.RPEventTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête RPEventType : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête RPEventType : ", err)
 		return
 	}
 	if err = resp.FullRPEvents.GetLinked(db, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête FullRPEvents : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête FullRPEvents : ", err)
 		return
 	}
 	if err = resp.RPCmtCityJoins.GetLinked(db, ID); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Datas de projet de renouvellement, requête lien engagements ville : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Datas de projet de renouvellement, requête lien engagements ville : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -101,13 +101,13 @@ func GetRenewProjectCmtAndPmt(ID int64, ctx iris.Context) {
 	var resp renewProjectCmtPmtResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.RPLinkedCommitments.Get(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Engagements payments de projet de renouvellement, requête commitments : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements payments de projet de renouvellement, requête commitments : ", err)
 		return
 	}
 	if err := resp.Payments.GetLinkedToRenewProject(ID, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Engagements payments de renouvellement, requête commitments : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Engagements payments de renouvellement, requête commitments : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -118,19 +118,18 @@ func GetRenewProjectCmtAndPmt(ID int64, ctx iris.Context) {
 func CreateRenewProject(ctx iris.Context) {
 	var req renewProjectReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de projet de renouvellement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de projet de renouvellement, décodage : ", err)
 		return
 	}
 	if err := req.RenewProject.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de projet de renouvellement : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de projet de renouvellement : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.RenewProject.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de projet de renouvellement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de projet de renouvellement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -141,19 +140,18 @@ func CreateRenewProject(ctx iris.Context) {
 func UpdateRenewProject(ctx iris.Context) {
 	var req renewProjectReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de projet de renouvellement, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de projet de renouvellement, décodage : ", err)
 		return
 	}
 	if err := req.RenewProject.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de projet de renouvellement : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de projet de renouvellement : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.RenewProject.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de projet de renouvellement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de projet de renouvellement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -178,40 +176,40 @@ type renewProjectsResp struct {
 func GetRenewProjects(ctx iris.Context) {
 	var resp renewProjectsResp
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := resp.RenewProjects.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête RU : " + err.Error()})
+	if err := resp.RenewProjects.GetInScope(db, userScope(ctx)); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête RU : ", err)
 		return
 	}
 	if err := resp.Cities.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête villes : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête villes : ", err)
 		return
 	}
 	if err := resp.RPEventTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête événements types : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête événements types : ", err)
 		return
 	}
 	if err := resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête commissions : ", err)
 		return
 	}
 	if err := resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête actions budgétaires : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête actions budgétaires : ", err)
 		return
 	}
 	year := (int64)(time.Now().Year())
 	if err := resp.FcPreProgs.GetAllOfKind(year, models.KindRenewProject, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête préprogrammation : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête préprogrammation : ", err)
 		return
 	}
 	if err := resp.RPMultiAnnualReports.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des projets de renouvellement, requête rapport pluriannuel : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des projets de renouvellement, requête rapport pluriannuel : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -222,15 +220,15 @@ func GetRenewProjects(ctx iris.Context) {
 func DeleteRenewProject(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("rpID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de projet de renouvellement, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de projet de renouvellement, paramètre : ", err)
 		return
 	}
 	rp := models.RenewProject{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = rp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de projet de renouvellement, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de projet de renouvellement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -241,15 +239,18 @@ func DeleteRenewProject(ctx iris.Context) {
 // renew projects into the database
 func BatchRenewProjects(ctx iris.Context) {
 	var rp models.RenewProjectBatch
-	if err := ctx.ReadJSON(&rp); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de projets de renouvellement, décodage : " + err.Error()})
+	if err := readBatch(ctx, &rp); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Batch de projets de renouvellement, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := rp.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de projets de renouvellement, requête : " + err.Error()})
+	report, err := rp.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de projets de renouvellement, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
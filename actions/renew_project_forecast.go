@@ -17,19 +17,17 @@ type RenewProjectForecastReq struct {
 func CreateRenewProjectForecast(ctx iris.Context) {
 	var req RenewProjectForecastReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de prévision RU, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de prévision RU, décodage : ", err)
 		return
 	}
 	if err := req.RenewProjectForecast.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de prévision RU : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de prévision RU : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.RenewProjectForecast.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de prévision RU, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de prévision RU, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +38,18 @@ func CreateRenewProjectForecast(ctx iris.Context) {
 func UpdateRenewProjectForecast(ctx iris.Context) {
 	var req RenewProjectForecastReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de prévision RU, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de prévision RU, décodage : ", err)
 		return
 	}
 	if err := req.RenewProjectForecast.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de prévision RU : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de prévision RU : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.RenewProjectForecast.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de prévision RU, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de prévision RU, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +60,16 @@ func UpdateRenewProjectForecast(ctx iris.Context) {
 func GetRenewProjectForecast(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de prévision RU, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision RU, paramètre : ", err)
 		return
 	}
 	var resp RenewProjectForecastReq
 	resp.RenewProjectForecast.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.RenewProjectForecast.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de prévision RU, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de prévision RU, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +81,7 @@ func GetRenewProjectForecasts(ctx iris.Context) {
 	var resp models.RenewProjectForecasts
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des prévision RUs, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Liste des prévision RUs, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +92,15 @@ func GetRenewProjectForecasts(ctx iris.Context) {
 func DeleteRenewProjectForecast(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de prévision RU, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision RU, paramètre : ", err)
 		return
 	}
 	resp := models.RenewProjectForecast{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de prévision RU, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de prévision RU, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -115,15 +111,17 @@ func DeleteRenewProjectForecast(ctx iris.Context) {
 // of RenewProjectForecasts into the database
 func BatchRenewProjectForecasts(ctx iris.Context) {
 	var b models.RenewProjectForecastBatch
-	if err := ctx.ReadJSON(&b); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Prévision RUs, décodage : " + err.Error()})
+	if err := readBatch(ctx, &b); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch de Prévision RUs, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := b.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de Prévision RUs, requête : " + err.Error()})
+	report, err := b.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch de Prévision RUs, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -35,7 +35,7 @@ func testCreateRenewProjectForecast(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Création de prévision RU, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"RenewProjectForecast":{"CommissionID":0,"Value":1000000,"Comment":"Essai","RenewProjectID":` +
 				strconv.Itoa(int(c.RenewProjectID)) + "}}"),
@@ -93,7 +93,7 @@ func testUpdateRenewProjectForecast(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification de prévision RU, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"RenewProjectForecast":{"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":0,"Comment":"Essai2","RenewProjectID":` +
@@ -123,7 +123,7 @@ func testUpdateRenewProjectForecast(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"RenewProjectForecast":{"ID":0,"CommissionID":2000000,"Value":2000000,"Comment":null,"RenewProjectID":2000000,"ActionID":3}}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification de prévision RU, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 5 : bad ID
+			StatusCode:   http.StatusNotFound}, // 5 : bad ID
 		{
 			Sent: []byte(`{"RenewProjectForecast":{"ID":` + strconv.Itoa(ID) + `,"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":2000000,"Project":"projet","Comment":"Essai2","RenewProjectID":` +
@@ -232,8 +232,9 @@ func testBatchRenewProjectForecasts(t *testing.T, c *TestContext) {
 				strconv.Itoa(int(c.RenewProjectID)) + `},{"ID":0,"CommissionID":` +
 				strconv.Itoa(int(c.CommissionID)) + `,"Value":200,"Comment":"Batch2","RenewProjectID":` +
 				strconv.Itoa(int(c.RenewProjectID)) + `}]}`),
-			RespContains: []string{"Batch de Prévision RUs, requête : "},
-			StatusCode:   http.StatusInternalServerError}, // 1 : value nul
+			RespContains: []string{"Batch de Prévision RUs, requête : lignes incorrectes",
+				`{"Line":1,"Field":"Value","Message":"montant nul"}`},
+			StatusCode: http.StatusBadRequest}, // 1 : value nul
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"RenewProjectForecast":[{"ID":0,"CommissionID":` +
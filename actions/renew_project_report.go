@@ -14,8 +14,7 @@ func GetRenewProjectReport(ctx iris.Context) {
 	var resp models.RenewProjectReport
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Report RU : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Report RU : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
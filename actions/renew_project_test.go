@@ -34,7 +34,7 @@ func testCreateRenewProject(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de projet de renouvellement, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"RenewProject":{"Reference":"","Name":"PRU"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -86,7 +86,7 @@ func testUpdateRenewProject(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de projet de renouvellement, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"RenewProject":{"Reference":"","Name":"PRU"}}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -106,7 +106,7 @@ func testUpdateRenewProject(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"RenewProject":{"ID":0,"Reference":"PRU001","Name":"PRU","Budget":250000000,"PRIN":false,"CityCode1":75101,"CityCode2":null,"CityCode3":null,"Population":null,"CompositeIndex":null}}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de projet de renouvellement, requête : Projet de renouvellement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 5 : bad ID
+			StatusCode:   http.StatusNotFound}, // 5 : bad ID
 		{
 			Sent: []byte(`{"RenewProject":{"ID":` + strconv.Itoa(ID) +
 				`,"Reference":"PRU002","Name":"PRU2","Budget":150000000,"PRIN":false,` +
@@ -170,7 +170,7 @@ func testDeleteRenewProject(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de projet de renouvellement, requête : Projet de renouvellement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
@@ -205,8 +205,9 @@ func testBatchRenewProject(t *testing.T, c *TestContext) {
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"RenewProject":[{"Reference":"PRU002","Name":"Site RU 1","Budget":250000000},
 			{"Reference":"PRU002","Name":"Site RU 2","Budget":150000000}]}`),
-			RespContains: []string{`Batch de projets de renouvellement, requête`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : duplicated reference
+			RespContains: []string{`Batch de projets de renouvellement, requête : lignes incorrectes`,
+				`{"Line":1,"Field":"CityCode1","Message":"code ville nul"}`},
+			StatusCode: http.StatusBadRequest}, // 3 : duplicated reference
 		{
 			Token: c.Config.Users.Admin.Token,
 			Sent: []byte(`{"RenewProject":[{"Reference":"PRU002","Name":"Site RU 1","Budget":250000000,"PRIN":true,"CityCode1":75101,"CityCode2":null,"CityCode3":null,"Population":null,"CompositeIndex":null},
@@ -254,7 +255,7 @@ func testGetRenewProjectDatas(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.User.Token,
 			ID:           0,
 			RespContains: []string{`Datas de projet de renouvellement, requête renewProject :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token: c.Config.Users.User.Token,
 			ID:    ID,
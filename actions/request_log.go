@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kataras/iris"
+)
+
+// requestLogger writes the log of the requests, nil if they aren't logged
+var requestLogger *log.Logger
+
+// SetRequestLogger sets the logger of the requests, nil to disable the log
+func SetRequestLogger(l *log.Logger) {
+	requestLogger = l
+}
+
+// requestLogEntry is the JSON log entry of a request. Error is the message
+// sent back if the request failed.
+type requestLogEntry struct {
+	Time      string  `json:"time"`
+	Level     string  `json:"level"`
+	Message   string  `json:"msg"`
+	RequestID string  `json:"requestID"`
+	Method    string  `json:"method"`
+	Route     string  `json:"route"`
+	UserID    int     `json:"userID,omitempty"`
+	Status    int     `json:"status"`
+	Latency   float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// requestLogMiddleware logs the request once handled, the level depending on
+// the status of the response
+func requestLogMiddleware(ctx iris.Context) {
+	start := time.Now()
+	ctx.Next()
+	l := requestLogger
+	if l == nil {
+		return
+	}
+	entry := requestLogEntry{
+		Time:      start.Format(time.RFC3339),
+		Level:     "info",
+		Message:   "requête",
+		RequestID: requestID(ctx),
+		Method:    ctx.Method(),
+		Route:     ctx.Path(),
+		Status:    ctx.GetStatusCode(),
+		Latency:   float64(time.Since(start).Microseconds()) / 1000,
+		Error:     ctx.Values().GetString("error")}
+	if r := ctx.GetCurrentRoute(); r != nil {
+		entry.Route = r.Path()
+	}
+	entry.UserID, _ = ctx.Values().GetInt("userID")
+	switch {
+	case entry.Status >= http.StatusInternalServerError:
+		entry.Level = "error"
+	case entry.Status >= http.StatusBadRequest:
+		entry.Level = "warn"
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.Println(string(b))
+}
@@ -0,0 +1,75 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// testRequestLog is the entry point for testing the log of the requests and
+// of the slow queries
+func testRequestLog(t *testing.T, c *TestContext) {
+	t.Run("RequestLog", func(t *testing.T) {
+		testRequestLogEntries(t, c)
+		testSlowQueryLog(t, c)
+	})
+}
+
+// testRequestLogEntries checks the JSON entries of a successful and of a
+// failed request
+func testRequestLogEntries(t *testing.T, c *TestContext) {
+	var buf bytes.Buffer
+	SetRequestLogger(log.New(&buf, "", 0))
+	defer SetRequestLogger(nil)
+	c.E.GET("/api/user/api_keys").WithHeader(requestIDHeader, "log-ok").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK)
+	c.E.DELETE("/api/user/api_key/0").WithHeader(requestIDHeader, "log-ko").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusNotFound)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Errorf("RequestLog : 2 lignes attendues, reçu %q", buf.String())
+		return
+	}
+	var entries [2]requestLogEntry
+	for i, l := range lines {
+		if err := json.Unmarshal([]byte(l), &entries[i]); err != nil {
+			t.Errorf("RequestLog : décodage %v %s", err, l)
+			return
+		}
+	}
+	ok, ko := entries[0], entries[1]
+	if ok.RequestID != "log-ok" || ok.Method != http.MethodGet ||
+		ok.Route != "/api/user/api_keys" || ok.Status != http.StatusOK ||
+		ok.Level != "info" || ok.UserID == 0 || ok.Error != "" {
+		t.Errorf("RequestLog : entrée incorrecte %s", lines[0])
+	}
+	if ko.RequestID != "log-ko" || ko.Route != "/api/user/api_key/{ID:int64}" ||
+		ko.Status != http.StatusNotFound || ko.Level != "warn" ||
+		!strings.Contains(ko.Error, "Clé d'API introuvable") {
+		t.Errorf("RequestLog : entrée incorrecte %s", lines[1])
+	}
+}
+
+// testSlowQueryLog checks the queries above the threshold are logged with
+// their caller
+func testSlowQueryLog(t *testing.T, c *TestContext) {
+	var buf bytes.Buffer
+	models.SetQueryTracer(&models.QueryTracer{Logger: log.New(&buf, "", 0)})
+	defer models.SetQueryTracer(nil)
+	c.E.GET("/api/user/api_keys").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).
+		Expect().Status(http.StatusOK)
+	for _, s := range []string{`"msg":"requête lente"`, `"durationMs":`,
+		`"caller":"models.(*APIKeys).Get (api_key.go:`, `"query":"SELECT `} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("SlowQueryLog : %s attendu, reçu %s", s, buf.String())
+		}
+	}
+}
@@ -16,22 +16,19 @@ type reservationFeeReq struct {
 func CreateReservationFee(ctx iris.Context) {
 	var req reservationFeeReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de réservation de logement, décodage : " +
-			err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de réservation de logement, décodage : ", err)
 		return
 	}
 	if err := req.ReservationFee.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de réservation de logement, paramètre : " +
-			err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de réservation de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.ReservationFee.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de réservation de logement, requête : " +
-			err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de réservation de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -42,22 +39,19 @@ func CreateReservationFee(ctx iris.Context) {
 func UpdateReservationFee(ctx iris.Context) {
 	var req reservationFeeReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de réservation de logement, décodage : " +
-			err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de réservation de logement, décodage : ", err)
 		return
 	}
 	if err := req.ReservationFee.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de réservation de logement, paramètre : " +
-			err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de réservation de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.ReservationFee.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de réservation de logement, requête : " +
-			err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de réservation de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -68,17 +62,15 @@ func UpdateReservationFee(ctx iris.Context) {
 func DeleteReservationFee(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de réservation de logement, paramètre : " +
-			err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de réservation de logement, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.ReservationFee{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de réservation de logement, requête : " +
-			err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de réservation de logement, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -88,18 +80,16 @@ func DeleteReservationFee(ctx iris.Context) {
 // BatchReservationFee handle the post request of a batch of reservation fees
 func BatchReservationFee(ctx iris.Context) {
 	var req models.ReservationFeeBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Batch de réservation de logement, décodage : " +
-			err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Batch de réservation de logement, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	resp, err := req.Save(false, db)
+	resp, err := req.Save(batchOptions(ctx), db)
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch de réservation de logement, requête : " +
-			err.Error()})
+		sendBatchError(ctx, "Batch de réservation de logement, requête : ",
+			resp.Report, err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -107,20 +97,21 @@ func BatchReservationFee(ctx iris.Context) {
 }
 
 // TestBatchReservationFee handle the post request of a batch of reservation fees
+// in dry run mode, kept for the clients not using the dryRun parameter
 func TestBatchReservationFee(ctx iris.Context) {
 	var req models.ReservationFeeBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Test batch de réservation de logement, décodage : " +
-			err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Test batch de réservation de logement, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	resp, err := req.Save(true, db)
+	opt := batchOptions(ctx)
+	opt.DryRun = true
+	resp, err := req.Save(opt, db)
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Test batch de réservation de logement, requête : " +
-			err.Error()})
+		sendBatchError(ctx, "Test batch de réservation de logement, requête : ",
+			resp.Report, err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -133,17 +124,22 @@ func TestBatchReservationFee(ctx iris.Context) {
 func GetPaginatedReservationFees(ctx iris.Context) {
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Page de réservation de logements, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page de réservation de logements, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
 	req := models.PaginatedQuery{Page: page, Search: search}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Page de réservation de logements, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.PaginatedReservationFees
 	if err := resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page de réservation de logements, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page de réservation de logements, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -167,52 +163,57 @@ type initialPaginatedReservationFeesResp struct {
 func GetInitialPaginatedReservationFees(ctx iris.Context) {
 	page, err := ctx.URLParamInt64("Page")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, décodage Page : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Page initiale de réservation de logements, décodage Page : ", err)
 		return
 	}
 	search := ctx.URLParam("Search")
 	req := models.PaginatedQuery{Page: page, Search: search}
+	if err := readQueryOptions(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Page initiale de réservation de logements, décodage : ", err)
+		return
+	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp initialPaginatedReservationFeesResp
 	if err := resp.PaginatedReservationFees.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête fees : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête fees : ", err)
 		return
 	}
 	if err := resp.Cities.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête cities : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête cities : ", err)
 		return
 	}
 	if err := resp.Beneficiaries.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête beneficiaries : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête beneficiaries : ", err)
 		return
 	}
 	if err := resp.HousingTypologies.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête typologies : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête typologies : ", err)
 		return
 	}
 	if err := resp.HousingComments.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête comments : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête comments : ", err)
 		return
 	}
 	if err := resp.HousingTransfers.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête transfers : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête transfers : ", err)
 		return
 	}
 	if err := resp.ConventionTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête conventions types : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête conventions types : ", err)
 		return
 	}
 	if err := resp.ReservationReports.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Page initiale de réservation de logements, requête report de réservations : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Page initiale de réservation de logements, requête report de réservations : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -226,13 +227,13 @@ func ExportReservationFees(ctx iris.Context) {
 	req := models.PaginatedQuery{Search: search}
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp models.ExportedReservationFees
-	if err := resp.Get(db, &req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Export de réservation de logements, requête : " + err.Error()})
-		return
-	}
-	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(resp)
+	sendExport(ctx, "Export de réservation de logements, ",
+		"ExportedReservationFee", "reservations", models.ExportedReservationFee{},
+		func(w *models.ExportWriter) error {
+			return resp.Stream(db, &req, func(row *models.ExportedReservationFee) error {
+				return w.WriteRow(row)
+			})
+		})
 }
 
 type reservationFeeSettingsResp struct {
@@ -248,23 +249,23 @@ func GetReservationFeeSettings(ctx iris.Context) {
 	db := ctx.Values().Get("db").(*sql.DB)
 	var resp reservationFeeSettingsResp
 	if err := resp.HousingComments.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration des réservations, requête commentaires : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Administration des réservations, requête commentaires : ", err)
 		return
 	}
 	if err := resp.HousingTransfers.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration des réservations, requête cessions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Administration des réservations, requête cessions : ", err)
 		return
 	}
 	if err := resp.HousingTypologies.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration des réservations, requête typologies : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Administration des réservations, requête typologies : ", err)
 		return
 	}
 	if err := resp.ConventionTypes.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration des réservations, requête types de conventions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Administration des réservations, requête types de conventions : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
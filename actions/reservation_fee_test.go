@@ -120,7 +120,7 @@ func testUpdateReservationFee(t *testing.T, c *TestContext, ID int) {
 			"Charges":99.99}}`),
 			Token:        c.Config.Users.ReservationFeeUser.Token,
 			RespContains: []string{`Modification de réservation de logement, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 5 : bad ID
+			StatusCode:   http.StatusNotFound}, // 5 : bad ID
 		{
 			Sent: []byte(`{"ReservationFee":{"ID":` + strconv.Itoa(ID) + `,"CurrentBeneficiaryID":2,
 			"FirstBeneficiaryID":3,"CityCode":75101,"AddressNumber":"25",
@@ -158,7 +158,7 @@ func testDeleteReservationFee(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.ReservationFeeUser.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de réservation de logement, requête : réservation non trouvée`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.ReservationFeeUser.Token,
 			ID:           ID,
@@ -224,7 +224,8 @@ func testTestBatchReservationFees(t *testing.T, c *TestContext) {
 		{
 			Token: c.Config.Users.ReservationFeeUser.Token,
 			Sent:  batchContent,
-			RespContains: []string{`"AddedItems":0`, `"MissingCities":[]`,
+			RespContains: []string{`"AddedItems":3`, `"MissingCities":[]`,
+				`"BatchReport":{"DryRun":true`,
 				// cSpell: disable
 				`"MissingBeneficiaries":["OSICA"]`},
 			//cSpell: enable
@@ -16,19 +16,19 @@ type reservationReportReq struct {
 func CreateReservationReport(ctx iris.Context) {
 	var req reservationReportReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de report de réservation, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de report de réservation, décodage : ", err)
 		return
 	}
 	if err := req.ReservationReport.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de report de réservation, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de report de réservation, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.ReservationReport.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de report de réservation, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de report de réservation, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -39,19 +39,19 @@ func CreateReservationReport(ctx iris.Context) {
 func UpdateReservationReport(ctx iris.Context) {
 	var req reservationReportReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de report de réservation, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de report de réservation, décodage : ", err)
 		return
 	}
 	if err := req.ReservationReport.Valid(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de report de réservation, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de report de réservation, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.ReservationReport.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de report de réservation, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de report de réservation, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -62,15 +62,15 @@ func UpdateReservationReport(ctx iris.Context) {
 func DeleteReservationReport(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de report de réservation, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Suppression de report de réservation, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	b := models.ReservationReport{ID: ID}
 	if err := b.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de report de réservation, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de report de réservation, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -82,8 +82,8 @@ func GetReservationReports(ctx iris.Context) {
 	var resp models.ReservationReports
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des reports de réservation, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des reports de réservation, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
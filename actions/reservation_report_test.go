@@ -74,7 +74,7 @@ func testUpdateReservationReport(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"ReservationReport":{"ID":0,"BeneficiaryID":3,"Area":15.5,"SourceIRISCode":"EX0002","DestIRISCode":"EX111","DestDate":"2019-03-01T00:00:00Z"}}`),
 			Token:        c.Config.Users.ReservationFeeUser.Token,
 			RespContains: []string{`Modification de report de réservation, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Sent:         []byte(`{"ReservationReport":{"ID":` + strconv.Itoa(ID) + `,"BeneficiaryID":0,"Area":15.5,"SourceIRISCode":"EX0002","DestIRISCode":"EX111","DestDate":"2019-03-01T00:00:00Z"}}`),
 			Token:        c.Config.Users.ReservationFeeUser.Token,
@@ -130,7 +130,7 @@ func testDeleteReservationReport(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.ReservationFeeUser.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de report de réservation, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.ReservationFeeUser.Token,
 			ID:           ID,
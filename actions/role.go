@@ -0,0 +1,129 @@
+package actions
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// roleReq is used to embed a role for requests
+type roleReq struct {
+	Role models.Role `json:"Role"`
+}
+
+// GetRoles handles the get request to fetch all roles with their permissions
+func GetRoles(ctx iris.Context) {
+	var resp models.Roles
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err := resp.GetAll(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des rôles, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// CreateRole handles the post request to create a role with its permissions
+func CreateRole(ctx iris.Context) {
+	var req roleReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Création de rôle, décodage : ", err)
+		return
+	}
+	if err := req.Role.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Création de rôle : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err := req.Role.Create(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Création de rôle, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusCreated)
+	ctx.JSON(req)
+}
+
+// UpdateRole handles the put request to modify a role and its permissions
+func UpdateRole(ctx iris.Context) {
+	var req roleReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Modification de rôle, décodage : ", err)
+		return
+	}
+	if err := req.Role.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Modification de rôle : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err := req.Role.Update(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Modification de rôle, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(req)
+}
+
+// DeleteRole handles the delete request to remove a role and its assignments
+func DeleteRole(ctx iris.Context) {
+	ID, err := ctx.Params().GetInt64("ID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Suppression de rôle, paramètre : ", err)
+		return
+	}
+	r := models.Role{ID: ID}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = r.Delete(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression de rôle, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Rôle supprimé"})
+}
+
+// GetUserRoles handles the get request to fetch the role assignments of a
+// user
+func GetUserRoles(ctx iris.Context) {
+	userID, err := ctx.Params().GetInt64("userID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Rôles d'un utilisateur, paramètre : ", err)
+		return
+	}
+	var resp models.UserRoles
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = resp.Get(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Rôles d'un utilisateur, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// SetUserRoles handles the post request to replace the role assignments of a
+// user and sends back the new assignments
+func SetUserRoles(ctx iris.Context) {
+	userID, err := ctx.Params().GetInt64("userID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Affectation de rôles, paramètre : ", err)
+		return
+	}
+	var req models.UserRoles
+	if err = ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Affectation de rôles, décodage : ", err)
+		return
+	}
+	for _, r := range req.Lines {
+		if err = r.Validate(); err != nil {
+			sendError(ctx, http.StatusBadRequest, "Affectation de rôles : ", err)
+			return
+		}
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = req.Set(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Affectation de rôles, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(req)
+}
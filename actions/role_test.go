@@ -0,0 +1,257 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testRole is the entry point for testing roles, their assignments to users
+// and their effect on the rights and the fetched rows
+func testRole(t *testing.T, c *TestContext) {
+	t.Run("Role", func(t *testing.T) {
+		ID := testCreateRole(t, c)
+		if ID == 0 {
+			t.Error("Impossible de créer le rôle")
+			t.FailNow()
+			return
+		}
+		testUpdateRole(t, c, ID)
+		testGetRoles(t, c)
+		testUserRoles(t, c, ID)
+		testDeleteRole(t, c, ID)
+	})
+}
+
+// testCreateRole checks if route is admin protected and created role is
+// properly filled
+func testCreateRole(t *testing.T, c *TestContext) (ID int) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Sent:         []byte(`fake`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Création de rôle, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
+		{
+			Sent:         []byte(`{"Role":{"Name":""}}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Création de rôle : Name vide`},
+			StatusCode:   http.StatusBadRequest}, // 2 : empty name
+		{
+			Sent:         []byte(`{"Role":{"Name":"Copro","Permissions":["copro:lire"]}}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Création de rôle : permission copro:lire incorrecte`},
+			StatusCode:   http.StatusBadRequest}, // 3 : bad permission
+		{
+			Sent: []byte(`{"Role":{"Name":"Copro","Description":"Suivi des copropriétés",` +
+				`"Permissions":["copro_forecast:write","copro:read"]}}`),
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"Role":{"ID":`, `"Name":"Copro"`,
+				`"Description":"Suivi des copropriétés"`,
+				`"Permissions":["copro_forecast:write","copro:read"]`},
+			IDName:     `{"ID"`,
+			StatusCode: http.StatusCreated}, // 4 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/role").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "CreateRole", &ID) {
+		t.Error(r)
+	}
+	return ID
+}
+
+// testUpdateRole checks if route is admin protected and the permissions are
+// replaced
+func testUpdateRole(t *testing.T, c *TestContext, ID int) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Sent:         []byte(`fake`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Modification de rôle, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
+		{
+			Sent:         []byte(`{"Role":{"ID":0,"Name":"Copro"}}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Modification de rôle, requête : ID introuvable`},
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
+		{
+			Sent: []byte(`{"Role":{"ID":` + strconv.Itoa(ID) + `,"Name":"Copro",` +
+				`"Permissions":["copro_forecast:write","copro:read","prog:*"]}}`),
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"Name":"Copro"`,
+				`"Permissions":["copro_forecast:write","copro:read","prog:*"]`},
+			StatusCode: http.StatusOK}, // 3 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.PUT("/api/role").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UpdateRole") {
+		t.Error(r)
+	}
+}
+
+// testGetRoles checks if route is admin protected and roles are sent back
+// with their sorted permissions
+func testGetRoles(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"Role":[`, `"Name":"Copro"`,
+				`"Permissions":["copro:read","copro_forecast:write","prog:*"]`},
+			Count:         1,
+			CountItemName: `"Permissions"`,
+			StatusCode:    http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/roles").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetRoles") {
+		t.Error(r)
+	}
+}
+
+// testUserRoles checks the assignment of a role to a user grants the
+// permissions of the role and limits the fetched rows to its scope
+func testUserRoles(t *testing.T, c *TestContext, ID int) {
+	var userID int64
+	if err := c.DB.QueryRow(`SELECT id FROM users WHERE email=$1`,
+		c.Config.Users.User.Email).Scan(&userID); err != nil {
+		t.Errorf("Impossible de récupérer l'utilisateur : %v", err)
+		return
+	}
+	rolesPath := "/api/user/" + strconv.FormatInt(userID, 10) + "/roles"
+	roleID := strconv.Itoa(ID)
+	tcc := []TestCase{
+		{
+			Params:       `POST /api/copro_forecast`,
+			Sent:         []byte(`{"CoproForecast":{}}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Droits sur les copropriétés requis`},
+			StatusCode:   http.StatusUnauthorized}, // 0 : no role
+		{
+			Params:       `POST ` + rolesPath,
+			Sent:         []byte(`{"UserRole":[]}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Droits administrateur requis`},
+			StatusCode:   http.StatusUnauthorized}, // 1 : user unauthorized
+		{
+			Params: `POST ` + rolesPath,
+			Sent: []byte(`{"UserRole":[{"RoleID":` + roleID +
+				`,"DepartmentID":1,"CommunityIDs":[1]}]}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`Affectation de rôles : un seul périmètre par affectation`},
+			StatusCode:   http.StatusBadRequest}, // 2 : two scopes
+		{
+			Params: `POST ` + rolesPath,
+			Sent: []byte(`{"UserRole":[{"RoleID":` + roleID +
+				`,"CommunityIDs":[999999]}]}`),
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"UserRole":[{"ID":`, `"RoleID":` + roleID,
+				`"RoleName":"Copro"`, `"CommunityIDs":[999999]`},
+			StatusCode: http.StatusOK}, // 3 : ok
+		{
+			Params:        `GET ` + rolesPath,
+			Token:         c.Config.Users.Admin.Token,
+			RespContains:  []string{`"RoleName":"Copro"`, `"DepartmentID":null`},
+			Count:         1,
+			CountItemName: `"RoleName"`,
+			StatusCode:    http.StatusOK}, // 4 : get assignments
+		{
+			Params:       `POST /api/copro_forecast`,
+			Sent:         []byte(`{"CoproForecast":{}}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Création de prévision copro : `},
+			StatusCode:   http.StatusBadRequest}, // 5 : granted by the role
+		{
+			Params:       `GET /api/copro`,
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`"Copro":[`},
+			StatusCode:   http.StatusOK}, // 6 : rows not limited if rights match
+		{
+			Params: `POST /api/copro_forecast`,
+			Sent: []byte(`{"CoproForecast":{"CommissionID":1,"Value":100,` +
+				`"CoproID":1,"ActionID":1}}`),
+			Token: c.Config.Users.User.Token,
+			RespContains: []string{
+				`Création de prévision copro, périmètre : hors du périmètre de l'utilisateur`},
+			StatusCode: http.StatusForbidden}, // 7 : copro out of the scope
+		{
+			Params: `PUT /api/role`,
+			Sent: []byte(`{"Role":{"ID":` + roleID + `,"Name":"Copro",` +
+				`"Permissions":["copro_forecast:write","copro:read","prog:*",` +
+				`"copro_event_type:write"]}}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`"copro_event_type:write"`},
+			StatusCode:   http.StatusOK}, // 8 : grant a route without scope
+		{
+			Params: `POST /api/copro_event_type`,
+			Sent:   []byte(`{"CoproEventType":{"Name":"Périmètre"}}`),
+			Token:  c.Config.Users.User.Token,
+			RespContains: []string{
+				`Rôles utilisateur : route non accessible à un rôle limité à un périmètre`},
+			StatusCode: http.StatusForbidden}, // 9 : scoped role refused
+		{
+			Params:       `POST /api/prog`,
+			Sent:         []byte(`{}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Droits administrateur requis`},
+			StatusCode:   http.StatusUnauthorized}, // 10 : admin route not granted
+		{
+			Params:       `POST ` + rolesPath,
+			Sent:         []byte(`{"UserRole":[]}`),
+			Token:        c.Config.Users.Admin.Token,
+			RespContains: []string{`"UserRole":[]`},
+			StatusCode:   http.StatusOK}, // 11 : remove assignments
+		{
+			Params:       `POST /api/copro_forecast`,
+			Sent:         []byte(`{"CoproForecast":{}}`),
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`Droits sur les copropriétés requis`},
+			StatusCode:   http.StatusUnauthorized}, // 12 : no more role
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		req := strings.SplitN(tc.Params, " ", 2)
+		r := c.E.Request(req[0], req[1])
+		if tc.Sent != nil {
+			r = r.WithBytes(tc.Sent)
+		}
+		return r.WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UserRoles") {
+		t.Error(r)
+	}
+}
+
+// testDeleteRole checks if route is admin protected and role is removed
+func testDeleteRole(t *testing.T, c *TestContext, ID int) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           0,
+			RespContains: []string{`Suppression de rôle, requête : ID introuvable`},
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           ID,
+			RespContains: []string{`Rôle supprimé`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.DELETE("/api/role/"+strconv.Itoa(tc.ID)).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "DeleteRole") {
+		t.Error(r)
+	}
+}
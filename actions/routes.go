@@ -9,16 +9,52 @@ import (
 // SetRoutes initialize all routes for the application
 func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 
-	api := app.Party("/api", setDBMiddleware(db, superAdminEmail))
+	routePermissions, routeRolePermissions = nil, make(map[string]string)
+	scopedRoutes = make(map[string]bool)
+	api := &rightsParty{
+		Party: app.Party("/api", requestIDMiddleware, requestLogMiddleware,
+			httpMetricsMiddleware, setDBMiddleware(db, superAdminEmail))}
+	app.Get("/metrics", requestIDMiddleware, setDBMiddleware(db, superAdminEmail),
+		GetMetrics)
+	app.Get("/healthz", requestIDMiddleware, GetHealth)
+	app.Get("/readyz", requestIDMiddleware, setDBMiddleware(db, superAdminEmail),
+		GetReadiness)
 
 	api.Post("/user/sign_up", setDBMiddleware(db, superAdminEmail), SignUp)
 	api.Post("/user/login", setDBMiddleware(db, superAdminEmail), Login)
-
-	adminParty := api.Party("", RightsMiddleWare(&admHandler))
+	api.Post("/user/login/totp", setDBMiddleware(db, superAdminEmail), LoginTOTP)
+	api.Post("/user/login/totp/setup", setDBMiddleware(db, superAdminEmail), SetupLoginTOTP)
+	api.Get("/user/oidc/login", setDBMiddleware(db, superAdminEmail), OIDCLogin)
+	api.Post("/user/oidc/callback", setDBMiddleware(db, superAdminEmail), OIDCCallback)
+	api.Post("/user/password/forgot", setDBMiddleware(db, superAdminEmail), ForgotPassword)
+	api.Post("/user/password/reset", setDBMiddleware(db, superAdminEmail), ResetPassword)
+	api.Get("/openapi.json", GetOpenAPI)
+	api.Get("/docs", GetSwaggerUI)
+
+	adminParty := newRightsParty(api, &admHandler)
 	adminParty.Post("/user", CreateUser)
+	adminParty.Post("/user/invite", InviteUser)
 	adminParty.Put("/user/{userID}", UpdateUser)
 	adminParty.Delete("/user/{userID}", DeleteUser)
+	adminParty.Delete("/user/{userID}/sessions", DeleteUserSessions)
+	adminParty.Post("/user/{userID}/unlock", UnlockUser)
+	adminParty.Delete("/user/{userID}/totp", ResetUserTOTP)
+	adminParty.Get("/api_keys", GetAllAPIKeys)
+	adminParty.Delete("/api_key/{ID:int64}", RevokeAPIKey)
+	adminParty.Get("/security_logs", GetSecurityLogs)
 	adminParty.Get("/users", GetUsers)
+	adminParty.Get("/audit", GetAuditLogs)
+	adminParty.Get("/cache", GetCacheStats)
+	adminParty.Get("/routes", GetRoutePermissions)
+	adminParty.Get("/roles", GetRoles)
+	adminParty.Post("/role", CreateRole)
+	adminParty.Put("/role", UpdateRole)
+	adminParty.Delete("/role/{ID:int64}", DeleteRole)
+	adminParty.Get("/user/{userID}/roles", GetUserRoles)
+	adminParty.Post("/user/{userID}/roles", SetUserRoles)
+	adminParty.Get("/import_runs", GetImportRuns)
+	adminParty.Get("/import_run/{ID:int64}", GetImportRunRows)
+	adminParty.Post("/import_runs/{Kind:string}/revert", RevertLastImport)
 
 	adminParty.Post("/copro", CreateCopro)
 	adminParty.Put("/copro", ModifyCopro)
@@ -138,7 +174,7 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 	adminParty.Put("/payment_demands", UpdatePaymentDemand)
 	adminParty.Post("/payment_demands", BatchPaymentDemands)
 
-	coproUserParty := api.Party("", RightsMiddleWare(&coproHandler))
+	coproUserParty := newRightsParty(api, &coproHandler)
 	coproUserParty.Post("/copro_forecast", CreateCoproForecast)
 	coproUserParty.Put("/copro_forecast", UpdateCoproForecast)
 	coproUserParty.Delete("/copro_forecast/{ID}", DeleteCoproForecast)
@@ -158,10 +194,10 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 	coproUserParty.Put("/copro/{CoproID}/copro_doc", UpdateCoproDoc)
 	coproUserParty.Delete("/copro/{CoproID}/copro_doc/{ID}", DeleteCoproDoc)
 
-	coproPreProgParty := api.Party("", RightsMiddleWare(&coproPreProgHandler))
+	coproPreProgParty := newRightsParty(api, &coproPreProgHandler)
 	coproPreProgParty.Post("/pre_prog/copro", SetCoproPreProgs)
 
-	renewProjectUserParty := api.Party("", RightsMiddleWare(&rpHandler))
+	renewProjectUserParty := newRightsParty(api, &rpHandler)
 	renewProjectUserParty.Post("/renew_project_forecast", CreateRenewProjectForecast)
 	renewProjectUserParty.Put("/renew_project_forecast", UpdateRenewProjectForecast)
 	renewProjectUserParty.Delete("/renew_project_forecast/{ID}", DeleteRenewProjectForecast)
@@ -180,10 +216,10 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 
 	renewProjectUserParty.Get("/pre_prog/renew_project", GetRPPreProgs)
 
-	renewProjectPreProgUserParty := api.Party("", RightsMiddleWare(&rpPreProgHandler))
+	renewProjectPreProgUserParty := newRightsParty(api, &rpPreProgHandler)
 	renewProjectPreProgUserParty.Post("/pre_prog/renew_project", SetRPPreProgs)
 
-	housingUserParty := api.Party("", RightsMiddleWare(&housingHandler))
+	housingUserParty := newRightsParty(api, &housingHandler)
 	housingUserParty.Post("/housing_forecast", CreateHousingForecast)
 	housingUserParty.Put("/housing_forecast", UpdateHousingForecast)
 	housingUserParty.Delete("/housing_forecast/{ID}", DeleteHousingForecast)
@@ -193,10 +229,10 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 
 	housingUserParty.Post("/housing_summary", BatchHousingSummary)
 
-	housingPreProgUserParty := api.Party("", RightsMiddleWare(&housingPreProgHandler))
+	housingPreProgUserParty := newRightsParty(api, &housingPreProgHandler)
 	housingPreProgUserParty.Post("/pre_prog/housing", SetHousingPreProgs)
 
-	reservationUserParty := api.Party("", RightsMiddleWare(&reservationHandler))
+	reservationUserParty := newRightsParty(api, &reservationHandler)
 	reservationUserParty.Post("/reservation_fee", CreateReservationFee)
 	reservationUserParty.Get("/reservation_fees", GetPaginatedReservationFees)
 	reservationUserParty.Get("/reservation_fees/initial", GetInitialPaginatedReservationFees)
@@ -211,9 +247,21 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 	reservationUserParty.Delete("/reservation_report/{ID}", DeleteReservationReport)
 	reservationUserParty.Get("/reservation_reports", GetReservationReports)
 
-	userParty := api.Party("", RightsMiddleWare(&userHandler))
-	userParty.Post("/user/password", ChangeUserPwd)
-	userParty.Post("/user/logout", Logout)
+	accountParty := newRightsParty(api, &accountHandler)
+	accountParty.Post("/user/password", ChangeUserPwd)
+	accountParty.Post("/user/logout", Logout)
+	accountParty.Get("/user/sessions", GetUserSessions)
+	accountParty.Delete("/user/sessions/{ID}", DeleteUserSession)
+	accountParty.Get("/user/totp", GetTwoFactor)
+	accountParty.Post("/user/totp/setup", SetupTOTP)
+	accountParty.Post("/user/totp/enable", EnableTOTP)
+	accountParty.Post("/user/totp/disable", DisableTOTP)
+	accountParty.Post("/user/totp/recovery_codes", RenewRecoveryCodes)
+	accountParty.Get("/user/api_keys", GetAPIKeys)
+	accountParty.Post("/user/api_key", CreateAPIKey)
+	accountParty.Delete("/user/api_key/{ID:int64}", DeleteAPIKey)
+
+	userParty := newRightsParty(api, &userHandler)
 	userParty.Get("/budget_actions", GetBudgetActions)
 
 	userParty.Get("/copro", GetCopros)
@@ -231,6 +279,7 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 	userParty.Get("/commitments", GetCommitments)
 	userParty.Get("/commitments/paginated", GetPaginatedCommitments)
 	userParty.Get("/commitments/unlinked", GetUnlinkedCommitments)
+	userParty.Get("/commitments/cursor", GetCursorCommitments)
 	userParty.Get("/commitments/export", ExportCommitments)
 
 	userParty.Get("/commitments/forecasts", GetCmtForecasts)
@@ -244,6 +293,7 @@ func SetRoutes(app *iris.Application, superAdminEmail string, db *sql.DB) {
 
 	userParty.Get("/payments", GetPayments)
 	userParty.Get("/payments/paginated", GetPaginatedPayments)
+	userParty.Get("/payments/cursor", GetCursorPayments)
 	userParty.Get("/payments/export", GetExportedPayments)
 
 	userParty.Get("/budget_sectors", GetBudgetSectors)
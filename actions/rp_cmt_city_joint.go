@@ -17,19 +17,18 @@ type RPCmtCityJoinReq struct {
 func CreateRPCmtCityJoin(ctx iris.Context) {
 	var req RPCmtCityJoinReq
 	if err := ctx.ReadJSON(&req.RPCmtCityJoin); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de lien engagement ville, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de lien engagement ville, décodage : ", err)
 		return
 	}
 	if err := req.RPCmtCityJoin.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de lien engagement ville : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de lien engagement ville : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.RPCmtCityJoin.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de lien engagement ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de lien engagement ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -40,19 +39,18 @@ func CreateRPCmtCityJoin(ctx iris.Context) {
 func UpdateRPCmtCityJoin(ctx iris.Context) {
 	var req RPCmtCityJoinReq
 	if err := ctx.ReadJSON(&req.RPCmtCityJoin); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de lien engagement ville, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de lien engagement ville, décodage : ", err)
 		return
 	}
 	if err := req.RPCmtCityJoin.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de lien engagement ville : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de lien engagement ville : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.RPCmtCityJoin.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de lien engagement ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de lien engagement ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -63,16 +61,16 @@ func UpdateRPCmtCityJoin(ctx iris.Context) {
 func GetRPCmtCityJoin(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de lien engagement ville, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de lien engagement ville, paramètre : ", err)
 		return
 	}
 	var resp RPCmtCityJoinReq
 	resp.RPCmtCityJoin.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.RPCmtCityJoin.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de lien engagement ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de lien engagement ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,8 +82,8 @@ func GetRPCmtCityJoins(ctx iris.Context) {
 	var resp models.RPCmtCityJoins
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des liens engagement ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des liens engagement ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -96,15 +94,15 @@ func GetRPCmtCityJoins(ctx iris.Context) {
 func DeleteRPCmtCityJoin(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de lien engagement ville, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de lien engagement ville, paramètre : ", err)
 		return
 	}
 	resp := models.RPCmtCityJoin{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de lien engagement ville, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de lien engagement ville, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
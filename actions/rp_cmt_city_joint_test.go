@@ -34,7 +34,7 @@ func testCreateRPCmtCityJoin(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Création de lien engagement ville, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"CommitmentID":0,"CityCode":75101}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
@@ -76,7 +76,7 @@ func testUpdateRPCmtCityJoin(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification de lien engagement ville, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"ID":` + strconv.Itoa(ID) + `,"CommitmentID":0,"CityCode":77001}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
@@ -91,7 +91,7 @@ func testUpdateRPCmtCityJoin(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"ID":0,"CommitmentID":4,"CityCode":77001}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification de lien engagement ville, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent:         []byte(`{"ID":` + strconv.Itoa(ID) + `,"CommitmentID":4,"CityCode":77000}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
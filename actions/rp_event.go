@@ -18,19 +18,16 @@ type rpEventResp struct {
 func CreateRPEvent(ctx iris.Context) {
 	var req rpEventResp
 	if err := ctx.ReadJSON(&req.R); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'événement RP, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'événement RP, décodage : ", err)
 		return
 	}
 	if err := req.R.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'événement RP : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'événement RP : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.R.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -41,19 +38,18 @@ func CreateRPEvent(ctx iris.Context) {
 func UpdateRPEvent(ctx iris.Context) {
 	var req rpEventResp
 	if err := ctx.ReadJSON(&req.R); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'événement RP, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification d'événement RP, décodage : ", err)
 		return
 	}
 	if err := req.R.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification d'événement RP : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'événement RP : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.R.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -64,15 +60,15 @@ func UpdateRPEvent(ctx iris.Context) {
 func DeleteRPEvent(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'événement RP, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'événement RP, paramètre : ", err)
 		return
 	}
 	r := models.RPEvent{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := r.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,16 +80,16 @@ func DeleteRPEvent(ctx iris.Context) {
 func GetRPEvent(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'événement RP, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'événement RP, paramètre : ", err)
 		return
 	}
 	var resp rpEventResp
 	resp.R.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.R.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -105,8 +101,8 @@ func GetRPEvents(ctx iris.Context) {
 	var resp models.RPEvents
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération d'événements RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération d'événements RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -33,7 +33,7 @@ func testCreateRPEvent(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Création d'événement RP, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"RenewProjectID":0,"RPEventTypeID":` + strconv.FormatInt(c.RPEventTypeID, 10) +
 				`,"Date":"2015-04-13T00:00:00Z","Comment":"Commentaire"}`),
@@ -77,7 +77,7 @@ func testUpdateRPEvent(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification d'événement RP, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"ID":` + strconv.Itoa(ID) + `,"RenewProjectID":0` +
 				`,"RPEventTypeID":` + strconv.FormatInt(c.RPEventTypeID, 10) +
@@ -98,7 +98,7 @@ func testUpdateRPEvent(t *testing.T, c *TestContext, ID int) {
 				`,"Date":"2016-04-13T00:00:00Z","Comment":null}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification d'événement RP, requête : Événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 4 : bad ID
+			StatusCode:   http.StatusNotFound}, // 4 : bad ID
 		{
 			Sent: []byte(`{"ID":` + strconv.Itoa(ID) + `,"RenewProjectID":` +
 				strconv.FormatInt(c.RPEventTypeID, 10) +
@@ -129,7 +129,7 @@ func testGetRPEvent(t *testing.T, c *TestContext, ID int) {
 			ID:           0,
 			Token:        c.Config.Users.User.Token,
 			RespContains: []string{`Récupération d'événement RP, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			ID:    ID,
 			Token: c.Config.Users.User.Token,
@@ -187,7 +187,7 @@ func testDeleteRPEvent(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			ID:           0,
 			RespContains: []string{`Suppression d'événement RP, requête : Événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			ID:           ID,
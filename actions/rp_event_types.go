@@ -18,19 +18,18 @@ type rpEventTypeResp struct {
 func CreateRPEventType(ctx iris.Context) {
 	var req rpEventTypeResp
 	if err := ctx.ReadJSON(&req.R); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de type d'événement RP, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Création de type d'événement RP, décodage : ", err)
 		return
 	}
 	if err := req.R.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création de type d'événement RP : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de type d'événement RP : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.R.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de type d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Création de type d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -41,19 +40,18 @@ func CreateRPEventType(ctx iris.Context) {
 func UpdateRPEventType(ctx iris.Context) {
 	var req rpEventTypeResp
 	if err := ctx.ReadJSON(&req.R); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de type d'événement RP, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification de type d'événement RP, décodage : ", err)
 		return
 	}
 	if err := req.R.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification de type d'événement RP : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de type d'événement RP : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := req.R.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de type d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification de type d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -64,15 +62,15 @@ func UpdateRPEventType(ctx iris.Context) {
 func DeleteRPEventType(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de type d'événement RP, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de type d'événement RP, paramètre : ", err)
 		return
 	}
 	r := models.RPEventType{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := r.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de type d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression de type d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -84,16 +82,16 @@ func DeleteRPEventType(ctx iris.Context) {
 func GetRPEventType(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de type d'événement RP, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de type d'événement RP, paramètre : ", err)
 		return
 	}
 	var resp rpEventTypeResp
 	resp.R.ID = ID
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.R.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération de type d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération de type d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -105,8 +103,8 @@ func GetRPEventTypes(ctx iris.Context) {
 	var resp models.RPEventTypes
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Récupération des types d'événement RP, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Récupération des types d'événement RP, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -40,7 +40,7 @@ func testCreateRPEventType(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Création de type d'événement RP, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Name":""}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
@@ -76,7 +76,7 @@ func testUpdateRPEventType(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification de type d'événement RP, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Name":""}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
@@ -86,7 +86,7 @@ func testUpdateRPEventType(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`{"ID":0,"Name":"Comité d'engagement"}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			RespContains: []string{`Modification de type d'événement RP, requête : Type d'événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : bad ID
+			StatusCode:   http.StatusNotFound}, // 3 : bad ID
 		{
 			Sent:         []byte(`{"ID":` + strconv.Itoa(ID) + `,"Name":"Comité d'engagement"}`),
 			Token:        c.Config.Users.RenewProjectUser.Token,
@@ -110,7 +110,7 @@ func testGetRPEventType(t *testing.T, c *TestContext, ID int) {
 		{ID: 0,
 			Token:        c.Config.Users.User.Token,
 			RespContains: []string{`Récupération de type d'événement RP, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{ID: ID,
 			Token:        c.Config.Users.User.Token,
 			RespContains: []string{`"RPEventType":{"ID":` + strconv.Itoa(ID) + `,"Name":"Comité d'engagement"}`},
@@ -161,7 +161,7 @@ func testDeleteRPEventType(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			ID:           0,
 			RespContains: []string{`Suppression de type d'événement RP, requête : Type d'événement introuvable`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : bad ID
+			StatusCode:   http.StatusNotFound}, // 2 : bad ID
 		{
 			Token:        c.Config.Users.RenewProjectUser.Token,
 			ID:           ID,
@@ -14,8 +14,8 @@ func GetRPMultiAnnualReport(ctx iris.Context) {
 	var resp models.RPMultiAnnualReports
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"rapport sur les copropriétés, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"rapport sur les copropriétés, requête : ", err)
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(resp)
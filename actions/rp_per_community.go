@@ -14,8 +14,7 @@ func GetRPPerCommunityReport(ctx iris.Context) {
 	var resp models.RPPerCommunityReport
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Get(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Report RU par interco : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Report RU par interco : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
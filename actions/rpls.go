@@ -21,13 +21,11 @@ func GetRPLSDatas(ctx iris.Context) {
 	var resp rplsDatasResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.RPLSArray.GetAll(db); err != nil {
-		ctx.JSON(jsonError{"Datas RPLS, requête RPLS : " + err.Error()})
-		ctx.StatusCode(http.StatusInternalServerError)
+		sendError(ctx, http.StatusInternalServerError, "Datas RPLS, requête RPLS : ", err)
 		return
 	}
 	if err := resp.Cities.GetAll(db); err != nil {
-		ctx.JSON(jsonError{"Datas RPLS, requête Cities : " + err.Error()})
-		ctx.StatusCode(http.StatusInternalServerError)
+		sendError(ctx, http.StatusInternalServerError, "Datas RPLS, requête Cities : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -39,8 +37,7 @@ func GetAllRPLS(ctx iris.Context) {
 	var resp models.RPLSArray
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(db); err != nil {
-		ctx.JSON(jsonError{"Liste RPLS, requête : " + err.Error()})
-		ctx.StatusCode(http.StatusInternalServerError)
+		sendError(ctx, http.StatusInternalServerError, "Liste RPLS, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -56,14 +53,12 @@ type rplsResp struct {
 func CreateRPLS(ctx iris.Context) {
 	var resp rplsResp
 	if err := ctx.ReadJSON(&resp.RPLS); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de RPLS, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création de RPLS, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.RPLS.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création de RPLS, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création de RPLS, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -74,14 +69,12 @@ func CreateRPLS(ctx iris.Context) {
 func UpdateRPLS(ctx iris.Context) {
 	var resp rplsResp
 	if err := ctx.ReadJSON(&resp.RPLS); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de RPLS, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification de RPLS, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.RPLS.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification de RPLS, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Modification de RPLS, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -92,15 +85,13 @@ func UpdateRPLS(ctx iris.Context) {
 func DeleteRPLS(ctx iris.Context) {
 	ID, err := ctx.Params().GetInt64("ID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression de RPLS, ID introuvable : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Suppression de RPLS, ID introuvable : ", err)
 		return
 	}
 	req := models.RPLS{ID: ID}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err = req.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression de RPLS, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Suppression de RPLS, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -110,15 +101,17 @@ func DeleteRPLS(ctx iris.Context) {
 // BatchRPLS handle the post request to insert a batch of RPLS into database
 func BatchRPLS(ctx iris.Context) {
 	var req models.RPLSBatch
-	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch RPLS, décodage : " + err.Error()})
+	if err := readBatch(ctx, &req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Batch RPLS, décodage : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
-	if err := req.Save(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Batch RPLS, requête : " + err.Error()})
+	report, err := req.Save(batchOptions(ctx), db)
+	if err != nil {
+		sendBatchError(ctx, "Batch RPLS, requête : ", report, err)
+		return
+	}
+	if sendDryRunReport(ctx, report) {
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -158,15 +151,13 @@ func decodeParams(p *models.RPLSReportParams, ctx iris.Context) (err error) {
 func RPLSReport(ctx iris.Context) {
 	var req models.RPLSReportParams
 	if err := decodeParams(&req, ctx); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport RPLS, décodage " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Rapport RPLS, décodage ", err)
 		return
 	}
 	var resp models.RPLSReport
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(&req, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Rapport RPLS, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Rapport RPLS, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -177,15 +168,13 @@ func RPLSReport(ctx iris.Context) {
 func RPLSDetailedReport(ctx iris.Context) {
 	var req models.RPLSReportParams
 	if err := decodeParams(&req, ctx); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Rapport détaillé RPLS, décodage " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Rapport détaillé RPLS, décodage ", err)
 		return
 	}
 	var resp models.RPLSDetailedReport
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetAll(&req, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Rapport détaillé RPLS, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Rapport détaillé RPLS, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -36,17 +36,17 @@ func testCreateRPLS(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création de RPLS, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"Year":2016,"Ratio":0.167}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`"Création de RPLS, requête : InseeCode nul"`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : InseeCode nul
+			StatusCode:   http.StatusBadRequest}, // 2 : InseeCode nul
 		{
 			Sent:         []byte(`{"InseeCode":75101,"Ratio":0.167}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`"Création de RPLS, requête : Year nul"`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : Year nul
+			StatusCode:   http.StatusBadRequest}, // 3 : Year nul
 		{
 			Sent:         []byte(`{"InseeCode":75000,"Year":2016,"Ratio":0.167}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -79,22 +79,22 @@ func testUpdateRPLS(t *testing.T, c *TestContext, ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de RPLS, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent:         []byte(`{"InseeCode":77001,"Year":2017,"Ratio":0.3}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de RPLS, requête : ID nul`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : ID nul
+			StatusCode:   http.StatusBadRequest}, // 2 : ID nul
 		{
 			Sent:         []byte(`{"ID":` + strconv.Itoa(ID) + `,"Year":2017,"Ratio":0.3}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de RPLS, requête : InseeCode nul`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : InseeCode nul
+			StatusCode:   http.StatusBadRequest}, // 3 : InseeCode nul
 		{
 			Sent:         []byte(`{"ID":` + strconv.Itoa(ID) + `,"InseeCode":77001,"Ratio":0.3}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Modification de RPLS, requête : Year nul`},
-			StatusCode:   http.StatusInternalServerError}, // 4 : Year nul
+			StatusCode:   http.StatusBadRequest}, // 4 : Year nul
 		{
 			Sent: []byte(`{"ID":` + strconv.Itoa(ID) + `,"InseeCode":77000,` +
 				`"Year":2017,"Ratio":0.3}`),
@@ -127,7 +127,7 @@ func testDeleteRPLS(t *testing.T, c *TestContext, ID int) {
 			ID:           0,
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Suppression de RPLS, requête :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			ID:           ID,
 			Token:        c.Config.Users.Admin.Token,
@@ -152,21 +152,23 @@ func testBatchRPLS(t *testing.T, c *TestContext) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Batch RPLS, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad request
+			StatusCode:   http.StatusBadRequest}, // 1 : bad request
 		{
 			Sent: []byte(`{"RPLS":[{"Year":2016,"Ratio":0.167},` +
 				`{"InseeCode":77101,"Year":2016,"Ratio":0.3},` +
 				`{"InseeCode":78146,"Year":2016,"Ratio":0.1955}]}`),
-			Token:        c.Config.Users.Admin.Token,
-			RespContains: []string{`"Batch RPLS, requête : ligne 1 InseeCode null"`},
-			StatusCode:   http.StatusInternalServerError}, // 2 : InseeCode nul
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"Batch RPLS, requête : lignes incorrectes"`,
+				`{"Line":1,"Field":"InseeCode","Message":"code INSEE nul"}`},
+			StatusCode: http.StatusBadRequest}, // 2 : InseeCode nul
 		{
 			Sent: []byte(`{"RPLS":[{"InseeCode":75101,"Year":2016,"Ratio":0.167},` +
 				`{"InseeCode":77101,"Ratio":0.3},` +
 				`{"InseeCode":78146,"Year":2016,"Ratio":0.1955}]}`),
-			Token:        c.Config.Users.Admin.Token,
-			RespContains: []string{`"Batch RPLS, requête : ligne 2 Year null"`},
-			StatusCode:   http.StatusInternalServerError}, // 3 : Year nul
+			Token: c.Config.Users.Admin.Token,
+			RespContains: []string{`"Batch RPLS, requête : lignes incorrectes"`,
+				`{"Line":2,"Field":"Year","Message":"année nulle"}`},
+			StatusCode: http.StatusBadRequest}, // 3 : Year nul
 		{
 			Sent: []byte(`{"RPLS":[{"InseeCode":75101,"Year":2016,"Ratio":0.167},` +
 				`{"InseeCode":77001,"Year":2016,"Ratio":0.3},` +
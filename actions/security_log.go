@@ -0,0 +1,160 @@
+package actions
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// loginPolicy is the protection of the login route against brute force
+// attacks
+var loginPolicy = models.DefaultLoginPolicy
+
+// maxUserAgentLength is the size of the user_agent column of the security log
+const maxUserAgentLength = 255
+
+// maxLogEmailLength is the size of the email column of the security log
+const maxLogEmailLength = 120
+
+// SetLoginPolicy sets the protection of the login route
+func SetLoginPolicy(p models.LoginPolicy) {
+	loginPolicy = p
+}
+
+// logSecurityEvent writes the event into the security log with the address
+// and the device of the request. A null userID means the account is unknown.
+func logSecurityEvent(ctx iris.Context, db *sql.DB, event string,
+	userID int64, email string) error {
+	userAgent := truncate(ctx.GetHeader("User-Agent"), maxUserAgentLength)
+	email = truncate(email, maxLogEmailLength)
+	e := models.SecurityEvent{Event: event,
+		UserID:    models.NullInt64{Int64: userID, Valid: userID != 0},
+		Email:     models.NullString{String: email, Valid: email != ""},
+		IP:        models.NullString{String: ctx.RemoteAddr(), Valid: true},
+		UserAgent: models.NullString{String: userAgent, Valid: userAgent != ""}}
+	return e.Log(db)
+}
+
+// lockLogin takes the lock serializing the login attempts of the key, IP
+// address or account, and sends back an error if it fails, returning false in
+// that case
+func lockLogin(ctx iris.Context, lock *models.LoginLock, key string) bool {
+	if err := lock.Lock(key); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, verrou : ", err)
+		return false
+	}
+	return true
+}
+
+// accountLoginKey returns the key of the lock of the login attempts of a user
+func accountLoginKey(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10)
+}
+
+// throttleLogin sends back a too many requests error if the login attempt
+// must be rejected because of the former failures and returns true in that
+// case
+func throttleLogin(ctx iris.Context, db *sql.DB, userID int64, email string,
+	message string, retry time.Duration) bool {
+	if retry <= 0 {
+		return false
+	}
+	if err := logSecurityEvent(ctx, db, models.LoginThrottledEvent, userID,
+		email); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, journal : ", err)
+		return true
+	}
+	secs := strconv.FormatInt(int64(math.Ceil(retry.Seconds())), 10)
+	ctx.Header("Retry-After", secs)
+	sendError(ctx, http.StatusTooManyRequests, message+", réessayer dans "+secs+" s", nil)
+	return true
+}
+
+// checkAccountState fetches the failed attempts of the account and sends back
+// an error if the attempt must be rejected, returning false in that case
+func checkAccountState(ctx iris.Context, db *sql.DB, user *models.User,
+	state *models.LoginState) bool {
+	if err := state.GetByUser(db, user.ID, loginPolicy.Window); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, tentatives : ", err)
+		return false
+	}
+	if throttleLogin(ctx, db, user.ID, user.Email,
+		"Compte temporairement verrouillé", state.Locked) {
+		return false
+	}
+	return !throttleLogin(ctx, db, user.ID, user.Email,
+		"Trop de tentatives de connexion",
+		loginPolicy.Delay(state.Failures)-state.SinceLastFailure)
+}
+
+// recordLoginFailure logs a failed attempt of the account and locks it if
+// there are too many failures. It returns false if an error has been sent back.
+func recordLoginFailure(ctx iris.Context, db *sql.DB, user *models.User,
+	state *models.LoginState) bool {
+	err := logSecurityEvent(ctx, db, models.LoginFailureEvent, user.ID, user.Email)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, journal : ", err)
+		return false
+	}
+	if state.Failures+1 < loginPolicy.MaxAccountFailures {
+		return true
+	}
+	if err = models.LockUser(db, user.ID, loginPolicy.LockoutDuration); err == nil {
+		err = logSecurityEvent(ctx, db, models.LockoutEvent, user.ID, user.Email)
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, verrouillage : ", err)
+		return false
+	}
+	return true
+}
+
+// GetSecurityLogs handles the get request to fetch the security log,
+// optionally filtered by event and user, using paginated format
+func GetSecurityLogs(ctx iris.Context) {
+	page, err := ctx.URLParamInt64("Page")
+	if err != nil {
+		page = 1
+	}
+	userID, err := ctx.URLParamInt64("UserID")
+	if err != nil {
+		userID = 0
+	}
+	var resp models.PaginatedSecurityEvents
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = resp.Get(db, page, ctx.URLParam("Event"), userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Journal de sécurité, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// UnlockUser handles the post request of an admin to unlock an account and
+// reset its failed login attempts
+func UnlockUser(ctx iris.Context) {
+	userID, err := ctx.Params().GetInt64("userID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Déverrouillage d'utilisateur, paramètre : ", err)
+		return
+	}
+	actorID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Déverrouillage d'utilisateur, user ID : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = models.UnlockUser(db, userID, actorID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Déverrouillage d'utilisateur, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Utilisateur déverrouillé"})
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testLoginPolicy uses short delays to keep the tests fast
+var testLoginPolicy = models.LoginPolicy{
+	MaxAccountFailures: 3,
+	MaxIPFailures:      1000,
+	FreeFailures:       1,
+	DelayStep:          100 * time.Millisecond,
+	MaxDelay:           500 * time.Millisecond,
+	LockoutDuration:    time.Minute,
+	Window:             15 * time.Minute,
+}
+
+// testSecurityLog is the entry point for testing the protection of the login
+// and the security log. It uses the account created by testInviteUser.
+func testSecurityLog(t *testing.T, c *TestContext) {
+	t.Run("SecurityLog", func(t *testing.T) {
+		SetLoginPolicy(testLoginPolicy)
+		defer SetLoginPolicy(models.DefaultLoginPolicy)
+		ID := testLoginThrottling(t, c)
+		testUnlockUser(t, c, ID)
+		testGetSecurityLogs(t, c)
+		clearLoginFailures(t, c)
+	})
+}
+
+// clearLoginFailures removes the failed login attempts from the security log
+// so that they don't delay the logins of the next tests
+func clearLoginFailures(t *testing.T, c *TestContext) {
+	if _, err := c.DB.Exec(`DELETE FROM security_log WHERE event=$1`,
+		models.LoginFailureEvent); err != nil {
+		t.Errorf("Nettoyage du journal de sécurité : %v", err)
+	}
+}
+
+// testLoginThrottling checks that attempts following a failure are delayed and
+// that the account is locked after too many failures. It returns the ID of the
+// locked user.
+func testLoginThrottling(t *testing.T, c *TestContext) int {
+	wait := testLoginPolicy.MaxDelay + 100*time.Millisecond
+	bad := []byte(`{"Email":"invite@iledefrance.fr","Password":"faux"}`)
+	good := []byte(`{"Email":"invite@iledefrance.fr","Password":"invite"}`)
+	steps := []struct {
+		Sent         []byte
+		Wait         time.Duration
+		RespContains string
+		StatusCode   int
+	}{
+		{bad, 0, loginError, http.StatusNotFound},                               // 0 : first failure
+		{bad, 0, `Trop de tentatives de connexion`, http.StatusTooManyRequests}, // 1 : too soon
+		{bad, wait, loginError, http.StatusNotFound},                            // 2 : second failure
+		{bad, wait, loginError, http.StatusNotFound},                            // 3 : third failure locks
+		{good, wait, `Compte temporairement verrouillé, réessayer dans`,
+			http.StatusTooManyRequests}, // 4 : locked account
+		{[]byte(`{"Email":"inconnu@iledefrance.fr","Password":"faux"}`), wait,
+			loginError, http.StatusNotFound}, // 5 : unknown account
+	}
+	for i, s := range steps {
+		time.Sleep(s.Wait)
+		response := c.E.POST("/api/user/login").WithBytes(s.Sent).Expect()
+		response.Status(s.StatusCode)
+		response.Body().Contains(s.RespContains)
+		if s.StatusCode == http.StatusTooManyRequests &&
+			response.Header("Retry-After").Raw() == "" {
+			t.Errorf("LoginThrottling[%d] : en-tête Retry-After absent", i)
+		}
+	}
+	var ID int
+	if err := c.DB.QueryRow(`SELECT id FROM users WHERE email=$1`,
+		"invite@iledefrance.fr").Scan(&ID); err != nil {
+		t.Errorf("LoginThrottling, ID : %v", err)
+	}
+	return ID
+}
+
+// testUnlockUser checks if route is admin protected and the unlocked user can
+// log in
+func testUnlockUser(t *testing.T, c *TestContext, ID int) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           0,
+			RespContains: []string{`Déverrouillage d'utilisateur, requête : Utilisateur introuvable`},
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           ID,
+			RespContains: []string{`Utilisateur déverrouillé`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/"+strconv.Itoa(tc.ID)+"/unlock").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UnlockUser") {
+		t.Error(r)
+	}
+	time.Sleep(testLoginPolicy.MaxDelay + 100*time.Millisecond)
+	c.E.POST("/api/user/login").
+		WithBytes([]byte(`{"Email":"invite@iledefrance.fr","Password":"invite"}`)).
+		Expect().Status(http.StatusOK).Body().Contains(`"Token"`)
+}
+
+// testGetSecurityLogs checks if route is admin protected and the events are
+// correctly sent back
+func testGetSecurityLogs(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "Event=lockout",
+			RespContains: []string{`"SecurityEvent":[{"ID":`, `"Event":"lockout"`,
+				`"UserName":"Utilisateur invité"`, `"Email":"invite@iledefrance.fr"`,
+				`"Page":1`, `"ItemsCount":1`},
+			StatusCode: http.StatusOK}, // 1 : lockout
+		{
+			Token:  c.Config.Users.Admin.Token,
+			Params: "Event=unlock",
+			RespContains: []string{`"Event":"unlock"`, `"UserName":"Utilisateur invité"`,
+				`"ItemsCount":1`},
+			StatusCode: http.StatusOK}, // 2 : unlock
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "Event=login_failure",
+			RespContains: []string{`"Email":"inconnu@iledefrance.fr"`, `"UserID":null`},
+			StatusCode:   http.StatusOK}, // 3 : failure of an unknown account
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/security_logs").WithQueryString(tc.Params).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetSecurityLogs") {
+		t.Error(r)
+	}
+}
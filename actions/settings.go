@@ -24,40 +24,35 @@ func GetSettings(ctx iris.Context) {
 	var qry models.PaginatedQuery
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.BudgetSectors.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, secteurs budgétaires : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Administration, secteurs budgétaires : ", err)
 		return
 	}
 	if err := resp.BudgetActions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, actions budgétaires : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Administration, actions budgétaires : ", err)
 		return
 	}
 	if err := resp.Commissions.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, commissions : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Administration, commissions : ", err)
 		return
 	}
 	if err := resp.PaginatedCities.Get(db, &qry); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, villes : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Administration, villes : ", err)
 		return
 	}
 	if err := resp.PaginatedPayments.Get(db, &qry); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, paiements : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Administration, paiements : ", err)
 		return
 	}
 	if err := resp.PaginatedCommitments.Get(db, &qry); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, engagements : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Administration, engagements : ", err)
 		return
 	}
 	if err := resp.Communities.GetAll(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Administration, interco : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Administration, interco : ", err)
 		return
 	}
-	ctx.JSON(resp)
 	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
 }
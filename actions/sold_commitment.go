@@ -14,8 +14,8 @@ func GetEldestCommitments(ctx iris.Context) {
 	var resp models.SoldCommitments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetOld(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des engagements anciens, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des engagements anciens, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -28,8 +28,8 @@ func GetUnpaidCommitments(ctx iris.Context) {
 	var resp models.SoldCommitments
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.GetUnpaid(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Liste des engagements non payés, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Liste des engagements non payés, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
@@ -20,15 +20,15 @@ func GetSummariesDatas(ctx iris.Context) {
 	var resp summariesResp
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := resp.Cities.GetAll(db); err != nil {
-		ctx.JSON(jsonError{"Données de synthèse, requête Cities : " + err.Error()})
-		ctx.StatusCode(http.StatusInternalServerError)
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de synthèse, requête Cities : ", err)
 		return
 	}
 	if err := resp.RPLSYears.GetAll(db); err != nil {
-		ctx.JSON(jsonError{"Données de synthèse, requête Cities : " + err.Error()})
-		ctx.StatusCode(http.StatusInternalServerError)
+		sendError(ctx, http.StatusInternalServerError,
+			"Données de synthèse, requête RPLSYears : ", err)
 		return
 	}
-	ctx.JSON(resp)
 	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
 }
@@ -1,13 +1,11 @@
 package actions
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"io/ioutil"
-	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Iledant/PreLoRUGo/models"
@@ -22,65 +20,109 @@ type UserClaims struct {
 
 // customClaims add role and active to token to avoid fetching database
 type customClaims struct {
-	Rights int64 `json:"rig"`
+	Rights        int64 `json:"rig"`
+	RightsVersion int64 `json:"rv"`
 	jwt.StandardClaims
 }
 
 var (
-	signingKey   = []byte(os.Getenv("JWT_SIGNING_KEY"))
-	expireDelay  = time.Second * 30
-	refreshDelay = int64((time.Hour * 15 * 24).Seconds())
-	iss          = "https://www.propera.net"
-	tokens       = map[int]bool{}
 	// ErrNoToken happens when header have no or bad authorization bearer
-	ErrNoToken = errors.New("Token absent")
+	ErrNoToken = models.NewKindError(models.ErrUnauthorized, "Token absent")
 	// ErrBadToken happends when bearer token can't be verified
 	// or isn't already stored after login or refresh
-	ErrBadToken = errors.New("Token invalide")
+	ErrBadToken = models.NewKindError(models.ErrUnauthorized, "Token invalide")
 )
 
-// getTokenString store claims and return JWT token string
+// tokenPolicy defines the signature and the lifetime of the tokens
+var tokenPolicy = models.DefaultTokenPolicy
+
+// SetTokenPolicy sets the signature and the lifetime of the tokens
+func SetTokenPolicy(p models.TokenPolicy) {
+	tokenPolicy = p
+}
+
+// lastSeenDelay is the minimum delay between two updates of the last seen
+// time of a session to avoid writing the store on every request
+var lastSeenDelay = time.Minute
+
+// getTokenString signs claims and return JWT token string
 func getTokenString(claims *customClaims) (tokenString string, err error) {
-	mutex := &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	if tokenString, err = token.SignedString(signingKey); err != nil {
+	if tokenString, err = token.SignedString(tokenPolicy.SigningKey); err != nil {
 		return "", err
 	}
-	userID, err := strconv.Atoi(claims.Subject)
-	if err != nil {
+	return tokenString, nil
+}
+
+// newSessionID returns a random identifier used as jti claim
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-	tokens[userID] = true
-	return tokenString, nil
+	return hex.EncodeToString(b), nil
 }
 
-// setToken creates or update a token for a given user
-func setToken(u *models.User) (string, error) {
+// setToken creates a new session and its token for a given user, storing the
+// device and IP the login came from
+func setToken(ctx iris.Context, u *models.User) (string, error) {
 	t := time.Now()
+	sessionID, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
 	claims := customClaims{
-		Rights: u.Rights,
+		Rights:        u.Rights,
+		RightsVersion: u.RightsVersion,
 		StandardClaims: jwt.StandardClaims{
+			Id:        sessionID,
 			Subject:   strconv.FormatInt(u.ID, 10),
-			ExpiresAt: t.Add(expireDelay).Unix(),
+			ExpiresAt: t.Add(tokenPolicy.Lifetime).Unix(),
 			IssuedAt:  t.Unix(),
-			Issuer:    iss}}
-	return getTokenString(&claims)
+			Issuer:    tokenPolicy.Issuer}}
+	tokenString, err := getTokenString(&claims)
+	if err != nil {
+		return "", err
+	}
+	session := models.UserSession{
+		ID:            sessionID,
+		UserID:        u.ID,
+		Created:       t,
+		LastSeen:      t,
+		Device:        truncate(ctx.GetHeader("User-Agent"), models.MaxDeviceLength),
+		IP:            ctx.RemoteAddr(),
+		Rights:        u.Rights,
+		RightsVersion: u.RightsVersion,
+	}
+	if err = tokens.Create(&session); err != nil {
+		return "", err
+	}
+	// Purge the sessions that can no longer be refreshed. The last seen time
+	// lags behind the last refresh by at most lastSeenDelay.
+	if err = tokens.DelExpired(t.Add(-tokenPolicy.RefreshDelay -
+		lastSeenDelay)); err != nil {
+		return "", err
+	}
+	return tokenString, nil
 }
 
-// delToken remove user ID from list of stored tokens
-func delToken(userID int) {
-	mutex := &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
-	delete(tokens, userID)
+// truncate returns the first characters of s up to max
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
+
+// delToken removes a session from the stored ones
+func delToken(sessionID string) error {
+	return tokens.Del(sessionID)
 }
 
 // refreshToken replace an existing expired token and add it to the response header
 func refreshToken(ctx iris.Context, u *customClaims) error {
 	t := time.Now()
-	u.ExpiresAt = t.Add(expireDelay).Unix()
+	u.ExpiresAt = t.Add(tokenPolicy.Lifetime).Unix()
 	u.IssuedAt = t.Unix()
 	tokenString, err := getTokenString(u)
 	if err != nil {
@@ -92,7 +134,8 @@ func refreshToken(ctx iris.Context, u *customClaims) error {
 }
 
 // bearerToUser gets user claims (ID, role, active) from token in request header
-// and send refreshed token if first time expired
+// and send refreshed token if first time expired. An API key can be used in
+// place of the token.
 func bearerToUser(ctx iris.Context) (claims *customClaims, err error) {
 	bearer := ctx.GetHeader("Authorization")
 	if len(bearer) < 8 {
@@ -102,54 +145,67 @@ func bearerToUser(ctx iris.Context) (claims *customClaims, err error) {
 	if tokenString == "" {
 		return nil, ErrNoToken
 	}
+	if strings.HasPrefix(tokenString, models.APIKeyPrefix) {
+		return apiKeyToUser(ctx, tokenString)
+	}
 	parser := jwt.Parser{ValidMethods: nil, UseJSONNumber: true,
 		SkipClaimsValidation: true}
 	token, err := parser.ParseWithClaims(tokenString, &customClaims{},
-		func(token *jwt.Token) (interface{}, error) { return []byte(signingKey), nil })
+		func(token *jwt.Token) (interface{}, error) { return tokenPolicy.SigningKey, nil })
 	if err != nil || !token.Valid {
 		return nil, ErrBadToken
 	}
 	claims = token.Claims.(*customClaims)
-	// Check if previously connected
+	// Check if the session is still opened
 	userID, _ := strconv.Atoi(claims.Subject)
-	mutex := &sync.Mutex{}
-	mutex.Lock()
-	_, ok := tokens[userID]
-	mutex.Unlock()
-	if !ok {
+	session, err := tokens.Get(claims.Id)
+	if err != nil || session == nil || session.UserID != int64(userID) {
 		return nil, ErrBadToken
 	}
 	// Refresh if expired
-	t := time.Now().Unix()
-	if t > claims.IssuedAt+refreshDelay {
+	now := time.Now()
+	t := now.Unix()
+	if t > claims.IssuedAt+int64(tokenPolicy.RefreshDelay.Seconds()) {
+		tokens.Del(session.ID)
 		return claims, errors.New("Token expiré")
 	}
-	if t > claims.ExpiresAt {
+	if now.Sub(session.LastSeen) > lastSeenDelay {
+		if err = tokens.Touch(session.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	// Reissue the token with the current rights if they have been changed or
+	// close the session if the user has been deactivated
+	rightsChanged := claims.RightsVersion != session.RightsVersion
+	if rightsChanged {
+		if session.Rights&models.ActiveBit == 0 {
+			tokens.Del(session.ID)
+			return nil, ErrBadToken
+		}
+		claims.Rights, claims.RightsVersion = session.Rights, session.RightsVersion
+	}
+	if rightsChanged || t > claims.ExpiresAt {
 		err = refreshToken(ctx, claims)
 	}
 	ctx.Values().Set("userID", userID)
+	ctx.Values().Set("sessionID", session.ID)
 	ctx.Values().Set("rights", claims.Rights)
 	return claims, err
 }
 
-// TokenRecover tries to load a previously saved file with tokens history.
-// Used to allow users keep beeing logged in even after a relaunch of server.
+// TokenRecover tries to load a previously saved file with sessions history.
+// Used to allow users keep beeing logged in even after a relaunch of server
+// when the in memory token store is used.
 func TokenRecover(fileName string) {
-	fileContent, err := ioutil.ReadFile(fileName)
-	if err != nil {
-		return
-	}
-	if err = json.Unmarshal(fileContent, &tokens); err != nil {
-		return
+	if m, ok := tokens.(*MemTokenStore); ok {
+		m.Recover(fileName)
 	}
 }
 
-// TokenSave saves the current map of tokens to a file in order to persist them
-// for the next call of TokenRecover
+// TokenSave saves the current map of sessions to a file in order to persist them
+// for the next call of TokenRecover when the in memory token store is used
 func TokenSave(fileName string) {
-	jsonTokens, err := json.Marshal(tokens)
-	if err != nil {
-		return
+	if m, ok := tokens.(*MemTokenStore); ok {
+		m.Save(fileName)
 	}
-	err = ioutil.WriteFile(fileName, jsonTokens, os.ModePerm)
 }
@@ -0,0 +1,252 @@
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// TokenStore is used to keep track of the sessions opened by logins. A token
+// is only accepted if the store knows the session whose ID is the jti claim
+// of the token.
+type TokenStore interface {
+	Create(s *models.UserSession) error
+	Get(ID string) (*models.UserSession, error)
+	Touch(ID string, t time.Time) error
+	Del(ID string) error
+	GetByUser(userID int64) ([]models.UserSession, error)
+	DelByUser(userID int64) error
+	DelExpired(before time.Time) error
+	SetRights(userID int64, rights int64, version int64) error
+}
+
+// MemTokenStore is a TokenStore that keeps sessions in memory. It can be
+// persisted to a file on server stop.
+type MemTokenStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]models.UserSession
+}
+
+// DBTokenStore is a TokenStore that keeps sessions in the user_session table
+// so that they survive crashes and can be shared between several server
+// instances.
+type DBTokenStore struct {
+	db *sql.DB
+}
+
+var tokens TokenStore = NewMemTokenStore()
+
+// SetTokenStore replaces the store used to check tokens
+func SetTokenStore(s TokenStore) {
+	tokens = s
+}
+
+// NewMemTokenStore returns an empty in memory store
+func NewMemTokenStore() *MemTokenStore {
+	return &MemTokenStore{sessions: map[string]models.UserSession{}}
+}
+
+// Create implements TokenStore
+func (m *MemTokenStore) Create(s *models.UserSession) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sessions[s.ID] = *s
+	return nil
+}
+
+// Get implements TokenStore and returns nil if the session doesn't exist
+func (m *MemTokenStore) Get(ID string) (*models.UserSession, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	s, ok := m.sessions[ID]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+// Touch implements TokenStore
+func (m *MemTokenStore) Touch(ID string, t time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if s, ok := m.sessions[ID]; ok {
+		s.LastSeen = t
+		m.sessions[ID] = s
+	}
+	return nil
+}
+
+// Del implements TokenStore
+func (m *MemTokenStore) Del(ID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.sessions, ID)
+	return nil
+}
+
+// GetByUser implements TokenStore
+func (m *MemTokenStore) GetByUser(userID int64) ([]models.UserSession, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	sessions := []models.UserSession{}
+	for _, s := range m.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+	return sessions, nil
+}
+
+// DelByUser implements TokenStore
+func (m *MemTokenStore) DelByUser(userID int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for ID, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, ID)
+		}
+	}
+	return nil
+}
+
+// DelExpired implements TokenStore
+func (m *MemTokenStore) DelExpired(before time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for ID, s := range m.sessions {
+		if s.LastSeen.Before(before) {
+			delete(m.sessions, ID)
+		}
+	}
+	return nil
+}
+
+// SetRights implements TokenStore and stores the new rights of the user in
+// all his sessions
+func (m *MemTokenStore) SetRights(userID int64, rights int64, version int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for ID, s := range m.sessions {
+		if s.UserID == userID {
+			s.Rights, s.RightsVersion = rights, version
+			m.sessions[ID] = s
+		}
+	}
+	return nil
+}
+
+// savedSession is used to persist the rights of the user with the session
+// since they are not exported in JSON by the model
+type savedSession struct {
+	models.UserSession
+	Rights        int64 `json:"Rights"`
+	RightsVersion int64 `json:"RightsVersion"`
+}
+
+// Recover tries to load a previously saved file with sessions history.
+func (m *MemTokenStore) Recover(fileName string) error {
+	fileContent, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	saved := map[string]savedSession{}
+	if err = json.Unmarshal(fileContent, &saved); err != nil {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for ID, s := range saved {
+		s.UserSession.Rights = s.Rights
+		s.UserSession.RightsVersion = s.RightsVersion
+		m.sessions[ID] = s.UserSession
+	}
+	return nil
+}
+
+// Save writes the current map of sessions to a file in order to persist them
+// for the next call of Recover
+func (m *MemTokenStore) Save(fileName string) error {
+	m.mutex.RLock()
+	saved := make(map[string]savedSession, len(m.sessions))
+	for ID, s := range m.sessions {
+		saved[ID] = savedSession{UserSession: s, Rights: s.Rights,
+			RightsVersion: s.RightsVersion}
+	}
+	m.mutex.RUnlock()
+	jsonTokens, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, jsonTokens, os.ModePerm)
+}
+
+// NewDBTokenStore returns a store using the user_session table of the database
+func NewDBTokenStore(db *sql.DB) *DBTokenStore {
+	return &DBTokenStore{db: db}
+}
+
+// Create implements TokenStore
+func (d *DBTokenStore) Create(s *models.UserSession) error {
+	return s.Create(d.db)
+}
+
+// Get implements TokenStore and returns nil if the session doesn't exist
+func (d *DBTokenStore) Get(ID string) (*models.UserSession, error) {
+	s := models.UserSession{ID: ID}
+	err := s.Get(d.db)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Touch implements TokenStore
+func (d *DBTokenStore) Touch(ID string, t time.Time) error {
+	s := models.UserSession{ID: ID, LastSeen: t}
+	return s.Touch(d.db)
+}
+
+// Del implements TokenStore
+func (d *DBTokenStore) Del(ID string) error {
+	s := models.UserSession{ID: ID}
+	return s.Delete(d.db)
+}
+
+// GetByUser implements TokenStore
+func (d *DBTokenStore) GetByUser(userID int64) ([]models.UserSession, error) {
+	var s models.UserSessions
+	if err := s.GetByUser(userID, d.db); err != nil {
+		return nil, err
+	}
+	return s.Lines, nil
+}
+
+// DelByUser implements TokenStore
+func (d *DBTokenStore) DelByUser(userID int64) error {
+	var s models.UserSessions
+	return s.DeleteByUser(userID, d.db)
+}
+
+// DelExpired implements TokenStore
+func (d *DBTokenStore) DelExpired(before time.Time) error {
+	var s models.UserSessions
+	return s.DeleteExpired(before, d.db)
+}
+
+// SetRights implements TokenStore. Nothing is stored because the rights are
+// fetched from the users table with the session.
+func (d *DBTokenStore) SetRights(userID int64, rights int64, version int64) error {
+	return nil
+}
@@ -0,0 +1,457 @@
+package actions
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// twoFactorPolicy defines the users who must use the double authentication
+var twoFactorPolicy = models.DefaultTwoFactorPolicy
+
+// SetTwoFactorPolicy sets the double authentication policy
+func SetTwoFactorPolicy(p models.TwoFactorPolicy) {
+	twoFactorPolicy = p
+}
+
+// Values of the TwoFactor field of the login response giving the next step
+const (
+	twoFactorCode  = "code"
+	twoFactorSetup = "setup"
+)
+
+// preAuthResp is sent back by the login when a TOTP code is required. If
+// TwoFactor is setup, the user must first configure the double authentication.
+type preAuthResp struct {
+	PreAuthToken string `json:"PreAuthToken"`
+	TwoFactor    string `json:"TwoFactor"`
+}
+
+// totpLoginReq is used to decode the second login step
+type totpLoginReq struct {
+	PreAuthToken string `json:"PreAuthToken"`
+	Code         string `json:"Code"`
+	RecoveryCode string `json:"RecoveryCode"`
+}
+
+// totpSignInResp is sent back after the second login step with the recovery
+// codes if the double authentication has just been enabled
+type totpSignInResp struct {
+	Token         string      `json:"Token"`
+	User          models.User `json:"User"`
+	RecoveryCodes []string    `json:"RecoveryCodes,omitempty"`
+}
+
+// totpSetupResp gives the secret to register in an authenticator app
+type totpSetupResp struct {
+	Secret string `json:"Secret"`
+	URI    string `json:"URI"`
+}
+
+// totpCodeReq is used to decode the requests of a connected user checked
+// with a TOTP or a recovery code
+type totpCodeReq struct {
+	Password     string `json:"Password"`
+	Code         string `json:"Code"`
+	RecoveryCode string `json:"RecoveryCode"`
+}
+
+type recoveryCodesResp struct {
+	RecoveryCodes []string `json:"RecoveryCodes"`
+}
+
+// twoFactorStatus adds to the status of the user whether the double
+// authentication is mandatory
+type twoFactorStatus struct {
+	models.TwoFactor
+	Required bool `json:"Required"`
+}
+
+type twoFactorResp struct {
+	TwoFactor twoFactorStatus `json:"TwoFactor"`
+}
+
+// sendPreAuthToken sends back the token used for the second login step
+func sendPreAuthToken(ctx iris.Context, db *sql.DB, user *models.User,
+	enabled bool) {
+	token, err := models.CreatePreAuthToken(db, user.ID)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Login, jeton de double authentification : ", err)
+		return
+	}
+	resp := preAuthResp{PreAuthToken: token, TwoFactor: twoFactorCode}
+	if !enabled {
+		resp.TwoFactor = twoFactorSetup
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// checkSecondFactor returns true if the recovery code, if sent, or the TOTP
+// code is valid
+func checkSecondFactor(db *sql.DB, tf *models.TwoFactor, code string,
+	recoveryCode string) (bool, error) {
+	if recoveryCode != "" && tf.Enabled {
+		return tf.UseRecoveryCode(db, recoveryCode)
+	}
+	return tf.CheckCode(db, code, time.Now())
+}
+
+// preAuthUser fetches the user of the pre-authentication token and sends back
+// an error if the token isn't valid, returning false in that case
+func preAuthUser(ctx iris.Context, db *sql.DB, token string, user *models.User,
+	errPrefix string) bool {
+	userID, err := models.CheckPreAuthToken(db, token)
+	if err == models.ErrBadPreAuthToken {
+		sendError(ctx, http.StatusUnauthorized, errPrefix+" : ", err)
+		return false
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, errPrefix+", jeton : ", err)
+		return false
+	}
+	user.ID = userID
+	if err = user.GetByID(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, errPrefix+", get : ", err)
+		return false
+	}
+	return true
+}
+
+// LoginTOTP handles the second login step checking the TOTP or a recovery
+// code with the pre-authentication token and sends back the token of the
+// session. If the user has configured the double authentication at login, it
+// is enabled and the recovery codes are sent back. A wrong code counts as a
+// failed login attempt.
+func LoginTOTP(ctx iris.Context) {
+	var req totpLoginReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Login double authentification, décodage : ", err)
+		return
+	}
+	if req.PreAuthToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		sendError(ctx, http.StatusBadRequest, "Login double authentification : jeton et code requis", nil)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	var user models.User
+	if !preAuthUser(ctx, db, req.PreAuthToken, &user,
+		"Login double authentification") {
+		return
+	}
+	lock, err := models.NewLoginLock(db)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Login double authentification, verrou : ", err)
+		return
+	}
+	defer lock.Release()
+	if !lockLogin(ctx, lock, accountLoginKey(user.ID)) {
+		return
+	}
+	var state models.LoginState
+	if !checkAccountState(ctx, db, &user, &state) {
+		return
+	}
+	var tf models.TwoFactor
+	if err := tf.Get(db, user.ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Login double authentification, get : ", err)
+		return
+	}
+	ok, err := checkSecondFactor(db, &tf, req.Code, req.RecoveryCode)
+	if err == models.ErrTOTPNotSetup {
+		sendError(ctx, http.StatusBadRequest, "Login double authentification : ", err)
+		return
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Login double authentification, code : ", err)
+		return
+	}
+	if !ok {
+		if recordLoginFailure(ctx, db, &user, &state) {
+			sendError(ctx, http.StatusUnauthorized, "Login double authentification : code invalide", nil)
+		}
+		return
+	}
+	var codes []string
+	if !tf.Enabled {
+		if codes, err = tf.Enable(db); err != nil {
+			sendError(ctx, http.StatusInternalServerError,
+				"Login double authentification, activation : ", err)
+			return
+		}
+	}
+	if err = models.DeletePreAuthToken(db, req.PreAuthToken); err == nil {
+		err = logSecurityEvent(ctx, db, models.LoginSuccessEvent, user.ID,
+			user.Email)
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Login double authentification, journal : ", err)
+		return
+	}
+	token, err := setToken(ctx, &user)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(totpSignInResp{token, user, codes})
+}
+
+// sendTOTPSecret stores a new pending secret for the user and sends it back
+func sendTOTPSecret(ctx iris.Context, db *sql.DB, user *models.User) {
+	tf := models.TwoFactor{UserID: user.ID}
+	secret, err := tf.Setup(db)
+	if err == models.ErrTOTPEnabled {
+		sendError(ctx, http.StatusBadRequest,
+			"Configuration de la double authentification : ", err)
+		return
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Configuration de la double authentification, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(totpSetupResp{Secret: secret,
+		URI: models.TOTPURI(twoFactorPolicy.Issuer, user.Email, secret)})
+}
+
+// SetupLoginTOTP handles the request of a user who must configure the double
+// authentication at login and sends back a new secret
+func SetupLoginTOTP(ctx iris.Context) {
+	var req totpLoginReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Configuration de la double authentification, décodage : ", err)
+		return
+	}
+	db := ctx.Values().Get("db").(*sql.DB)
+	var user models.User
+	if !preAuthUser(ctx, db, req.PreAuthToken, &user,
+		"Configuration de la double authentification") {
+		return
+	}
+	sendTOTPSecret(ctx, db, &user)
+}
+
+// GetTwoFactor handles the get request of a connected user to fetch their
+// double authentication status
+func GetTwoFactor(ctx iris.Context) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Double authentification, user ID : ", err)
+		return
+	}
+	db, user := ctx.Values().Get("db").(*sql.DB), models.User{ID: userID}
+	if err = user.GetByID(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Double authentification, get : ", err)
+		return
+	}
+	var resp twoFactorResp
+	if err = resp.TwoFactor.Get(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Double authentification, requête : ", err)
+		return
+	}
+	resp.TwoFactor.Required = twoFactorPolicy.RequiredFor(user.Rights)
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(resp)
+}
+
+// SetupTOTP handles the request of a connected user to configure the double
+// authentication and sends back a new secret, pending until EnableTOTP
+func SetupTOTP(ctx iris.Context) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Configuration de la double authentification, user ID : ", err)
+		return
+	}
+	db, user := ctx.Values().Get("db").(*sql.DB), models.User{ID: userID}
+	if err = user.GetByID(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Configuration de la double authentification, get : ", err)
+		return
+	}
+	sendTOTPSecret(ctx, db, &user)
+}
+
+// EnableTOTP handles the request of a connected user to enable the double
+// authentication with a first code of the pending secret and sends back the
+// recovery codes
+func EnableTOTP(ctx iris.Context) {
+	var req totpCodeReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Activation de la double authentification, décodage : ", err)
+		return
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Activation de la double authentification, user ID : ", err)
+		return
+	}
+	var tf models.TwoFactor
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = tf.Get(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Activation de la double authentification, get : ", err)
+		return
+	}
+	if tf.Enabled {
+		sendError(ctx, http.StatusBadRequest,
+			"Activation de la double authentification : ", models.ErrTOTPEnabled)
+		return
+	}
+	ok, err := tf.CheckCode(db, req.Code, time.Now())
+	if err == models.ErrTOTPNotSetup {
+		sendError(ctx, http.StatusBadRequest, "Activation de la double authentification : ", err)
+		return
+	}
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Activation de la double authentification, code : ", err)
+		return
+	}
+	if !ok {
+		sendError(ctx, http.StatusBadRequest, "Activation de la double authentification : code invalide", nil)
+		return
+	}
+	codes, err := tf.Enable(db)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Activation de la double authentification, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(recoveryCodesResp{codes})
+}
+
+// DisableTOTP handles the request of a connected user to disable the double
+// authentication, checked with the password and a TOTP or recovery code. It
+// is rejected if the double authentication is mandatory for the user.
+func DisableTOTP(ctx iris.Context) {
+	var req totpCodeReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Désactivation de la double authentification, décodage : ", err)
+		return
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Désactivation de la double authentification, user ID : ", err)
+		return
+	}
+	db, user := ctx.Values().Get("db").(*sql.DB), models.User{ID: userID}
+	if err = user.GetByID(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Désactivation de la double authentification, get : ", err)
+		return
+	}
+	if twoFactorPolicy.RequiredFor(user.Rights) {
+		sendError(ctx, http.StatusBadRequest, "Désactivation de la double authentification : obligatoire pour cet utilisateur", nil)
+		return
+	}
+	if err = user.ValidatePwd(req.Password); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Désactivation de la double authentification : erreur de mot de passe", nil)
+		return
+	}
+	var tf models.TwoFactor
+	if err = tf.Get(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Désactivation de la double authentification, get : ", err)
+		return
+	}
+	if !tf.Enabled {
+		sendError(ctx, http.StatusBadRequest, "Désactivation de la double authentification : non activée", nil)
+		return
+	}
+	ok, err := checkSecondFactor(db, &tf, req.Code, req.RecoveryCode)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Désactivation de la double authentification, code : ", err)
+		return
+	}
+	if !ok {
+		sendError(ctx, http.StatusBadRequest, "Désactivation de la double authentification : code invalide", nil)
+		return
+	}
+	if err = tf.Disable(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Désactivation de la double authentification, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Double authentification désactivée"})
+}
+
+// RenewRecoveryCodes handles the request of a connected user to replace their
+// recovery codes, checked with a TOTP code
+func RenewRecoveryCodes(ctx iris.Context) {
+	var req totpCodeReq
+	if err := ctx.ReadJSON(&req); err != nil {
+		sendError(ctx, http.StatusBadRequest, "Codes de secours, décodage : ", err)
+		return
+	}
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Codes de secours, user ID : ", err)
+		return
+	}
+	var tf models.TwoFactor
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = tf.Get(db, userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Codes de secours, get : ", err)
+		return
+	}
+	if !tf.Enabled {
+		sendError(ctx, http.StatusBadRequest, "Codes de secours : double authentification non activée", nil)
+		return
+	}
+	ok, err := tf.CheckCode(db, req.Code, time.Now())
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Codes de secours, code : ", err)
+		return
+	}
+	if !ok {
+		sendError(ctx, http.StatusBadRequest, "Codes de secours : code invalide", nil)
+		return
+	}
+	codes, err := tf.RenewRecoveryCodes(db)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Codes de secours, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(recoveryCodesResp{codes})
+}
+
+// ResetUserTOTP handles the request of an admin to remove the double
+// authentication of a user who has lost their device and recovery codes
+func ResetUserTOTP(ctx iris.Context) {
+	userID, err := ctx.Params().GetInt64("userID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest,
+			"Réinitialisation de la double authentification, paramètre : ", err)
+		return
+	}
+	tf := models.TwoFactor{UserID: userID}
+	db := ctx.Values().Get("db").(*sql.DB)
+	if err = tf.Disable(db); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Réinitialisation de la double authentification, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Double authentification réinitialisée"})
+}
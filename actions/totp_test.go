@@ -0,0 +1,331 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testTOTP is the entry point for testing the double authentication. It uses
+// the account created by testInviteUser and creates a reservation user for
+// whom the double authentication is mandatory.
+func testTOTP(t *testing.T, c *TestContext) {
+	t.Run("TOTP", func(t *testing.T) {
+		token := totpSignIn(t, c, "invite@iledefrance.fr", "invite").Token
+		testGetTwoFactor(t, c, token, `"Enabled":false`, `"Required":false`)
+		secret := testSetupTOTP(t, c, token)
+		codes := testEnableTOTP(t, c, token, secret)
+		if len(codes) != models.RecoveryCodesCount {
+			t.Errorf("TOTP : %d codes de secours reçus", len(codes))
+			return
+		}
+		testGetTwoFactor(t, c, token, `"Enabled":true`, `"RecoveryCodes":10`)
+		testLoginTOTP(t, c, secret, codes[0])
+		testRenewRecoveryCodes(t, c, token)
+		testDisableTOTP(t, c, token, codes[1])
+		testRequiredTOTP(t, c)
+	})
+}
+
+// totpSignIn logs in and returns the decoded response
+func totpSignIn(t *testing.T, c *TestContext, email string,
+	password string) (resp struct {
+	Token        string
+	PreAuthToken string
+	TwoFactor    string
+}) {
+	response := c.E.POST("/api/user/login").WithBytes([]byte(`{"Email":"` +
+		email + `","Password":"` + password + `"}`)).Expect()
+	if err := json.Unmarshal(response.Content, &resp); err != nil {
+		t.Errorf("TOTP, connexion de %s : %v", email, err)
+	}
+	return resp
+}
+
+// totpCode returns the code of the secret shifted by a number of time steps
+func totpCode(t *testing.T, secret string, steps int) string {
+	code, err := models.TOTPCode(secret,
+		time.Now().Add(time.Duration(steps)*models.TOTPPeriod))
+	if err != nil {
+		t.Errorf("TOTP, code : %v", err)
+	}
+	return code
+}
+
+// testGetTwoFactor checks the status of the double authentication
+func testGetTwoFactor(t *testing.T, c *TestContext, token string,
+	contains ...string) {
+	tcc := []TestCase{
+		{
+			Token:        token,
+			RespContains: contains,
+			StatusCode:   http.StatusOK}, // 0 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/user/totp").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetTwoFactor") {
+		t.Error(r)
+	}
+}
+
+// testSetupTOTP checks a secret is sent back and returns it
+func testSetupTOTP(t *testing.T, c *TestContext, token string) string {
+	response := c.E.POST("/api/user/totp/setup").
+		WithHeader("Authorization", "Bearer "+token).Expect()
+	response.Status(http.StatusOK)
+	response.Body().Contains(`"URI":"otpauth://totp/PreLoRuGo:invite@iledefrance.fr?`)
+	var resp totpSetupResp
+	if err := json.Unmarshal(response.Content, &resp); err != nil || resp.Secret == "" {
+		t.Errorf("SetupTOTP : secret absent %s", string(response.Content))
+	}
+	return resp.Secret
+}
+
+// testEnableTOTP checks the double authentication is only enabled with a
+// valid code and returns the recovery codes
+func testEnableTOTP(t *testing.T, c *TestContext, token string,
+	secret string) []string {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			Token:        token,
+			RespContains: []string{`Activation de la double authentification, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Code":"abcdef"}`),
+			Token:        token,
+			RespContains: []string{`Activation de la double authentification : code invalide`},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad code
+		{
+			Sent:         []byte(`{"Code":"` + totpCode(t, secret, 0) + `"}`),
+			Token:        token,
+			RespContains: []string{`"RecoveryCodes":["`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+		{
+			Sent:         []byte(`{"Code":"` + totpCode(t, secret, 0) + `"}`),
+			Token:        token,
+			RespContains: []string{`Activation de la double authentification : double authentification déjà activée`},
+			StatusCode:   http.StatusConflict}, // 3 : already enabled
+	}
+	var codes []string
+	f := func(tc TestCase) *httpexpect.Response {
+		response := c.E.POST("/api/user/totp/enable").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+		if response.Raw().StatusCode == http.StatusOK {
+			var resp recoveryCodesResp
+			json.Unmarshal(response.Content, &resp)
+			codes = resp.RecoveryCodes
+		}
+		return response
+	}
+	for _, r := range chkFactory(tcc, f, "EnableTOTP") {
+		t.Error(r)
+	}
+	c.E.POST("/api/user/totp/setup").
+		WithHeader("Authorization", "Bearer "+token).Expect().
+		Status(http.StatusConflict).Body().Contains(`déjà activée`)
+	return codes
+}
+
+// testLoginTOTP checks the second login step with a code and with a recovery
+// code, both of which can't be used twice. The failures are then removed so
+// that the next logins aren't delayed.
+func testLoginTOTP(t *testing.T, c *TestContext, secret string, recovery string) {
+	preAuth := totpSignIn(t, c, "invite@iledefrance.fr", "invite")
+	if preAuth.TwoFactor != twoFactorCode || preAuth.PreAuthToken == "" ||
+		preAuth.Token != "" {
+		t.Errorf("LoginTOTP : jeton de pré-authentification attendu %+v", preAuth)
+		return
+	}
+	// The code of the current step has been used to enable the double
+	// authentication so that the next one must be used
+	code := totpCode(t, secret, 1)
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			RespContains: []string{`Login double authentification, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"PreAuthToken":"` + preAuth.PreAuthToken + `"}`),
+			RespContains: []string{`Login double authentification : jeton et code requis`},
+			StatusCode:   http.StatusBadRequest}, // 1 : no code
+		{
+			Sent:         []byte(`{"PreAuthToken":"faux","Code":"` + code + `"}`),
+			RespContains: []string{`Login double authentification : jeton invalide ou expiré`},
+			StatusCode:   http.StatusUnauthorized}, // 2 : bad token
+		{
+			Sent:         []byte(`{"PreAuthToken":"` + preAuth.PreAuthToken + `","Code":"abcdef"}`),
+			RespContains: []string{`Login double authentification : code invalide`},
+			StatusCode:   http.StatusUnauthorized}, // 3 : bad code
+		{
+			Sent: []byte(`{"PreAuthToken":"` + preAuth.PreAuthToken +
+				`","Code":"` + code + `"}`),
+			RespContains: []string{`"Token":"`, `"Email":"invite@iledefrance.fr"`},
+			StatusCode:   http.StatusOK}, // 4 : ok
+		{
+			Sent: []byte(`{"PreAuthToken":"` + preAuth.PreAuthToken +
+				`","Code":"` + code + `"}`),
+			RespContains: []string{`Login double authentification : jeton invalide ou expiré`},
+			StatusCode:   http.StatusUnauthorized}, // 5 : token already used
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/login/totp").WithBytes(tc.Sent).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "LoginTOTP") {
+		t.Error(r)
+	}
+	for _, status := range []int{http.StatusOK, http.StatusUnauthorized} {
+		preAuth = totpSignIn(t, c, "invite@iledefrance.fr", "invite")
+		c.E.POST("/api/user/login/totp").
+			WithBytes([]byte(`{"PreAuthToken":"` + preAuth.PreAuthToken +
+				`","RecoveryCode":"` + recovery + `"}`)).
+			Expect().Status(status)
+	}
+	clearLoginFailures(t, c)
+}
+
+// testRenewRecoveryCodes checks the recovery codes are only replaced with a
+// valid code
+func testRenewRecoveryCodes(t *testing.T, c *TestContext, token string) {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			Token:        token,
+			RespContains: []string{`Codes de secours, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Code":"abcdef"}`),
+			Token:        token,
+			RespContains: []string{`Codes de secours : code invalide`},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad code
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/totp/recovery_codes").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "RenewRecoveryCodes") {
+		t.Error(r)
+	}
+}
+
+// testDisableTOTP checks the password and a code are required to disable the
+// double authentication
+func testDisableTOTP(t *testing.T, c *TestContext, token string, recovery string) {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			Token:        token,
+			RespContains: []string{`Désactivation de la double authentification, décodage :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Password":"faux","RecoveryCode":"` + recovery + `"}`),
+			Token:        token,
+			RespContains: []string{`Désactivation de la double authentification : erreur de mot de passe`},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad password
+		{
+			Sent:         []byte(`{"Password":"invite","Code":"abcdef"}`),
+			Token:        token,
+			RespContains: []string{`Désactivation de la double authentification : code invalide`},
+			StatusCode:   http.StatusBadRequest}, // 2 : bad code
+		{
+			Sent:         []byte(`{"Password":"invite","RecoveryCode":"` + recovery + `"}`),
+			Token:        token,
+			RespContains: []string{`Double authentification désactivée`},
+			StatusCode:   http.StatusOK}, // 3 : ok
+		{
+			Sent:         []byte(`{"Password":"invite","RecoveryCode":"` + recovery + `"}`),
+			Token:        token,
+			RespContains: []string{`Désactivation de la double authentification : non activée`},
+			StatusCode:   http.StatusBadRequest}, // 4 : already disabled
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/totp/disable").WithBytes(tc.Sent).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "DisableTOTP") {
+		t.Error(r)
+	}
+	testGetTwoFactor(t, c, token, `"Enabled":false`, `"RecoveryCodes":0`)
+	if resp := totpSignIn(t, c, "invite@iledefrance.fr", "invite"); resp.Token == "" {
+		t.Error("DisableTOTP : connexion sans code impossible")
+	}
+}
+
+// testRequiredTOTP checks that a reservation user must configure the double
+// authentication at login when it is mandatory, can't disable it and that an
+// admin can reset it
+func testRequiredTOTP(t *testing.T, c *TestContext) {
+	SetTwoFactorPolicy(models.TwoFactorPolicy{Required: true, Issuer: "PreLoRuGo"})
+	defer SetTwoFactorPolicy(models.DefaultTwoFactorPolicy)
+	response := c.E.POST("/api/user").WithHeader("Authorization",
+		"Bearer "+c.Config.Users.Admin.Token).WithBytes([]byte(`{"Name":"Réservation TOTP",` +
+		`"Email":"totp@iledefrance.fr","Password":"totp","Rights":` +
+		strconv.Itoa(models.ActiveReservationMask) + `}`)).Expect()
+	var created struct{ User struct{ ID int } }
+	if err := json.Unmarshal(response.Content, &created); err != nil ||
+		created.User.ID == 0 {
+		t.Errorf("RequiredTOTP, création : %s", string(response.Content))
+		return
+	}
+	ID := created.User.ID
+	preAuth := totpSignIn(t, c, "totp@iledefrance.fr", "totp")
+	if preAuth.TwoFactor != twoFactorSetup || preAuth.PreAuthToken == "" {
+		t.Errorf("RequiredTOTP : configuration attendue %+v", preAuth)
+		return
+	}
+	c.E.POST("/api/user/login/totp").
+		WithBytes([]byte(`{"PreAuthToken":"` + preAuth.PreAuthToken +
+			`","Code":"123456"}`)).Expect().Status(http.StatusBadRequest).
+		Body().Contains(`Login double authentification : double authentification non configurée`)
+	response = c.E.POST("/api/user/login/totp/setup").
+		WithBytes([]byte(`{"PreAuthToken":"` + preAuth.PreAuthToken + `"}`)).Expect()
+	response.Status(http.StatusOK)
+	response.Body().Contains(`otpauth://totp/PreLoRuGo:totp@iledefrance.fr?`)
+	var setup totpSetupResp
+	json.Unmarshal(response.Content, &setup)
+	response = c.E.POST("/api/user/login/totp").
+		WithBytes([]byte(`{"PreAuthToken":"` + preAuth.PreAuthToken +
+			`","Code":"` + totpCode(t, setup.Secret, 0) + `"}`)).Expect()
+	response.Status(http.StatusOK)
+	response.Body().Contains(`"RecoveryCodes":["`)
+	var signIn totpSignInResp
+	if err := json.Unmarshal(response.Content, &signIn); err != nil ||
+		len(signIn.RecoveryCodes) == 0 {
+		t.Errorf("RequiredTOTP, connexion : %s", string(response.Content))
+		return
+	}
+	c.E.POST("/api/user/totp/disable").
+		WithHeader("Authorization", "Bearer "+signIn.Token).
+		WithBytes([]byte(`{"Password":"totp","RecoveryCode":"` +
+			signIn.RecoveryCodes[0] + `"}`)).Expect().
+		Status(http.StatusBadRequest).
+		Body().Contains(`Désactivation de la double authentification : obligatoire pour cet utilisateur`)
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           0,
+			RespContains: []string{`Réinitialisation de la double authentification, requête : Utilisateur introuvable`},
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
+		{
+			Token:        c.Config.Users.Admin.Token,
+			ID:           ID,
+			RespContains: []string{`Double authentification réinitialisée`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.DELETE("/api/user/"+strconv.Itoa(tc.ID)+"/totp").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "ResetUserTOTP") {
+		t.Error(r)
+	}
+	testGetTwoFactor(t, c, signIn.Token, `"Enabled":false`, `"Required":true`)
+}
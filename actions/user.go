@@ -34,51 +34,113 @@ type credentials struct {
 	Password string `json:"Password"`
 }
 
+// loginError is sent back for an unknown e-mail or a wrong password
+const loginError = "Erreur de login ou mot de passe"
+
 // Login handles user login using credentials and return token if success.
+// The attempts are written into the security log and rejected if there were
+// too many failures from the IP address or for the account, which is locked
+// after a given number of failures. If the user must give a TOTP code, a
+// pre-authentication token is sent back instead.
 func Login(ctx iris.Context) {
 	var c credentials
 	if err := ctx.ReadJSON(&c); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Décodage login : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Décodage login : ", err)
 		return
 	}
 	if c.Email == "" || c.Password == "" {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Champ manquant ou incorrect"})
+		sendError(ctx, http.StatusBadRequest, "Champ manquant ou incorrect", nil)
 		return
 	}
-	var user models.User
 	db := ctx.Values().Get("db").(*sql.DB)
+	// The attempts are serialized by IP address and by account so that the
+	// failures are counted before a new attempt is checked
+	lock, err := models.NewLoginLock(db)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, verrou : ", err)
+		return
+	}
+	defer lock.Release()
+	if !lockLogin(ctx, lock, "ip:"+ctx.RemoteAddr()) {
+		return
+	}
+	var ipState models.LoginState
+	if err := ipState.GetByIP(db, ctx.RemoteAddr(), loginPolicy.Window); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, tentatives : ", err)
+		return
+	}
+	if ipState.Failures >= loginPolicy.MaxIPFailures {
+		throttleLogin(ctx, db, 0, c.Email,
+			"Trop de tentatives de connexion depuis cette adresse", loginPolicy.Window)
+		return
+	}
+	if throttleLogin(ctx, db, 0, c.Email, "Trop de tentatives de connexion",
+		loginPolicy.Delay(ipState.Failures)-ipState.SinceLastFailure) {
+		return
+	}
+	var user models.User
 	if err := user.GetByEmail(c.Email, db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{err.Error()})
+		if err != sql.ErrNoRows {
+			sendError(ctx, http.StatusInternalServerError, "", err)
+			return
+		}
+		if err = logSecurityEvent(ctx, db, models.LoginFailureEvent, 0,
+			c.Email); err != nil {
+			sendError(ctx, http.StatusInternalServerError, "Login, journal : ", err)
+			return
+		}
+		sendError(ctx, http.StatusNotFound, loginError, nil)
+		return
+	}
+	if !lockLogin(ctx, lock, accountLoginKey(user.ID)) {
+		return
+	}
+	var state models.LoginState
+	if !checkAccountState(ctx, db, &user, &state) {
 		return
 	}
 	if err := user.ValidatePwd(c.Password); err != nil {
-		ctx.StatusCode(http.StatusNotFound)
-		ctx.JSON(jsonError{"Erreur de login ou mot de passe"})
+		if recordLoginFailure(ctx, db, &user, &state) {
+			sendError(ctx, http.StatusNotFound, loginError, nil)
+		}
+		return
+	}
+	var tf models.TwoFactor
+	if err := tf.Get(db, user.ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, double authentification : ", err)
+		return
+	}
+	if tf.Enabled || twoFactorPolicy.RequiredFor(user.Rights) {
+		sendPreAuthToken(ctx, db, &user, tf.Enabled)
+		return
+	}
+	if err := logSecurityEvent(ctx, db, models.LoginSuccessEvent, user.ID,
+		c.Email); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Login, journal : ", err)
 		return
 	}
-	token, err := setToken(&user)
+	token, err := setToken(ctx, &user)
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(signInResp{token, user})
 }
 
-// Logout handles users logout and destroy his token.
+// Logout handles users logout and destroy the token of the current session.
 func Logout(ctx iris.Context) {
-	userID, err := getUserID(ctx)
-	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Logout, impossible de récupérer l'ID"})
+	sessionID, ok := ctx.Values().Get("sessionID").(string)
+	if !ok {
+		sendError(ctx, http.StatusInternalServerError, "Logout, impossible de récupérer la session", nil)
+		return
+	}
+	if err := delToken(sessionID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Logout, suppression de session : ", err)
+		return
 	}
-	delToken(int(userID))
 	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(jsonError{"Utilisateur déconnecté"})
+	ctx.JSON(jsonMessage{"Utilisateur déconnecté"})
 }
 
 // GetUsers handles the GET request for all users and send back only secure fields.
@@ -86,43 +148,37 @@ func GetUsers(ctx iris.Context) {
 	var users models.Users
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := users.GetAll(db); err != nil {
-		ctx.JSON(jsonError{"Liste des utilisateurs : " + err.Error()})
-		ctx.StatusCode(http.StatusInternalServerError)
+		sendError(ctx, http.StatusInternalServerError, "Liste des utilisateurs : ", err)
 		return
 	}
-	ctx.JSON(users)
 	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(users)
 }
 
 // CreateUser handles the creation by admin of a new user and returns the created user.
 func CreateUser(ctx iris.Context) {
 	var req sentUser
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'utilisateur, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'utilisateur, décodage : ", err)
 		return
 	}
 	user := models.User{Name: req.Name, Email: req.Email, Password: req.Password,
 		Rights: req.Rights}
 	if err := user.Validate(); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'utilisateur : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'utilisateur : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	if err := user.Exists(db); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Création d'utilisateur : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Création d'utilisateur : ", err)
 		return
 	}
 	if err := user.CryptPwd(); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'utilisateur, cryptage : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création d'utilisateur, cryptage : ", err)
 		return
 	}
 	if err := user.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Création d'utilisateur, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Création d'utilisateur, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
@@ -133,20 +189,18 @@ func CreateUser(ctx iris.Context) {
 func UpdateUser(ctx iris.Context) {
 	userID, err := ctx.Params().GetInt64("userID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Modification d'utilisateur, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Modification d'utilisateur, paramètre : ", err)
 		return
 	}
 	db, user := ctx.Values().Get("db").(*sql.DB), models.User{ID: userID}
 	if err = user.GetByID(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'utilisateur, get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Modification d'utilisateur, get : ", err)
 		return
 	}
 	var req sentUser
 	if err = ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'utilisateur, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Modification d'utilisateur, décodage : ", err)
 		return
 	}
 	if req.Email != "" {
@@ -158,17 +212,25 @@ func UpdateUser(ctx iris.Context) {
 	if req.Password != "" {
 		user.Password = req.Password
 		if err = user.CryptPwd(); err != nil {
-			ctx.StatusCode(http.StatusInternalServerError)
-			ctx.JSON(jsonError{"Modification d'utilisateur, mot de passe : " + err.Error()})
+			sendError(ctx, http.StatusInternalServerError,
+				"Modification d'utilisateur, mot de passe : ", err)
 			return
 		}
 	}
 	user.Rights = req.Rights
+	formerVersion := user.RightsVersion
 	if err = user.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Modification d'utilisateur, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Modification d'utilisateur, requête : ", err)
 		return
 	}
+	if user.RightsVersion != formerVersion {
+		if err = tokens.SetRights(user.ID, user.Rights, user.RightsVersion); err != nil {
+			sendError(ctx, http.StatusInternalServerError,
+				"Modification d'utilisateur, sessions : ", err)
+			return
+		}
+	}
 	ctx.StatusCode(http.StatusOK)
 	ctx.JSON(userResp{user})
 }
@@ -177,19 +239,23 @@ func UpdateUser(ctx iris.Context) {
 func DeleteUser(ctx iris.Context) {
 	userID, err := ctx.Params().GetInt64("userID")
 	if err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Suppression d'utilisateur, paramètre : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Suppression d'utilisateur, paramètre : ", err)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
 	user := models.User{ID: userID}
 	if err = user.Delete(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Suppression d'utilisateur, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'utilisateur, requête : ", err)
+		return
+	}
+	if err = tokens.DelByUser(userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError,
+			"Suppression d'utilisateur, sessions : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(jsonError{"Utilisateur supprimé"})
+	ctx.JSON(jsonMessage{"Utilisateur supprimé"})
 }
 
 type signUpReq struct {
@@ -202,13 +268,12 @@ type signUpReq struct {
 func SignUp(ctx iris.Context) {
 	var req signUpReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Inscription d'utilisateur, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Inscription d'utilisateur, décodage : ", err)
 		return
 	}
 	if req.Name == "" || req.Email == "" || req.Password == "" {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Inscription d'utilisateur : Champ manquant ou incorrect"})
+		sendError(ctx, http.StatusBadRequest, "Inscription d'utilisateur : Champ manquant ou incorrect", nil)
 		return
 	}
 	db := ctx.Values().Get("db").(*sql.DB)
@@ -216,22 +281,21 @@ func SignUp(ctx iris.Context) {
 		Email:    req.Email,
 		Password: req.Password}
 	if err := user.Exists(db); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Inscription d'utilisateur, exists : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest, "Inscription d'utilisateur, exists : ", err)
 		return
 	}
 	if err := user.CryptPwd(); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Inscription d'utilisateur, password : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Inscription d'utilisateur, password : ", err)
 		return
 	}
 	if err := user.Create(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Inscription d'utilisateur, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Inscription d'utilisateur, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusCreated)
-	ctx.JSON(jsonError{"Utilisateur créé, en attente d'activation"})
+	ctx.JSON(jsonMessage{"Utilisateur créé, en attente d'activation"})
 }
 
 type chgPwdReq struct {
@@ -243,46 +307,43 @@ type chgPwdReq struct {
 func ChangeUserPwd(ctx iris.Context) {
 	var req chgPwdReq
 	if err := ctx.ReadJSON(&req); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Changement de mot de passe, décodage : " + err.Error()})
+		sendError(ctx, http.StatusBadRequest,
+			"Changement de mot de passe, décodage : ", err)
 		return
 
 	}
 	if req.CurrentPassword == "" || req.NewPassword == "" {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Changement de mot de passe : ancien et nouveau mots de passe requis"})
+		sendError(ctx, http.StatusBadRequest, "Changement de mot de passe : ancien et nouveau mots de passe requis", nil)
 		return
 	}
 	userID, err := getUserID(ctx)
 	if err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Changement de mot de passe, user ID : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Changement de mot de passe, user ID : ", err)
 		return
 	}
 	db, user := ctx.Values().Get("db").(*sql.DB), models.User{ID: userID}
 	if err = user.GetByID(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Changement de mot de passe, get : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError, "Changement de mot de passe, get : ", err)
 		return
 	}
 	if err = user.ValidatePwd(req.CurrentPassword); err != nil {
-		ctx.StatusCode(http.StatusBadRequest)
-		ctx.JSON(jsonError{"Changement de mot de passe : erreur de mot de passe"})
+		sendError(ctx, http.StatusBadRequest, "Changement de mot de passe : erreur de mot de passe", nil)
 		return
 	}
 	user.Password = req.NewPassword
 	if err = user.CryptPwd(); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Changement de mot de passe, password : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Changement de mot de passe, password : ", err)
 		return
 	}
 	if err = user.Update(db); err != nil {
-		ctx.StatusCode(http.StatusInternalServerError)
-		ctx.JSON(jsonError{"Changement de mot de passe, requête : " + err.Error()})
+		sendError(ctx, http.StatusInternalServerError,
+			"Changement de mot de passe, requête : ", err)
 		return
 	}
 	ctx.StatusCode(http.StatusOK)
-	ctx.JSON(jsonError{"Mot de passe changé"})
+	ctx.JSON(jsonMessage{"Mot de passe changé"})
 }
 
 // getUserID fetch user role and ID with the token
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// userSessionsResp is used to send the active sessions of a user, flagging
+// the one used by the request
+type userSessionsResp struct {
+	Sessions []models.UserSession `json:"UserSession"`
+	Current  string               `json:"Current"`
+}
+
+// GetUserSessions handles the get request of the connected user to fetch all
+// his active sessions
+func GetUserSessions(ctx iris.Context) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des sessions, user ID : ", err)
+		return
+	}
+	sessions, err := tokens.GetByUser(userID)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Liste des sessions, requête : ", err)
+		return
+	}
+	current, _ := ctx.Values().Get("sessionID").(string)
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(userSessionsResp{Sessions: sessions, Current: current})
+}
+
+// DeleteUserSession handles the delete request of the connected user to end
+// one of his sessions
+func DeleteUserSession(ctx iris.Context) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression de session, user ID : ", err)
+		return
+	}
+	session, err := tokens.Get(ctx.Params().Get("ID"))
+	if err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression de session, get : ", err)
+		return
+	}
+	if session == nil || session.UserID != userID {
+		sendError(ctx, http.StatusNotFound, "Suppression de session : session introuvable", nil)
+		return
+	}
+	if err = delToken(session.ID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression de session, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Session supprimée"})
+}
+
+// DeleteUserSessions handles the delete request of an admin to end all the
+// sessions of a user, for example after a change of his rights
+func DeleteUserSessions(ctx iris.Context) {
+	userID, err := ctx.Params().GetInt64("userID")
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, "Suppression des sessions, paramètre : ", err)
+		return
+	}
+	if err = tokens.DelByUser(userID); err != nil {
+		sendError(ctx, http.StatusInternalServerError, "Suppression des sessions, requête : ", err)
+		return
+	}
+	ctx.StatusCode(http.StatusOK)
+	ctx.JSON(jsonMessage{"Sessions supprimées"})
+}
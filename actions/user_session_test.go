@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/config"
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/iris-contrib/httpexpect"
+)
+
+// testUserSession is the entry point for testing all user session requests
+func testUserSession(t *testing.T, c *TestContext) {
+	t.Run("UserSession", func(t *testing.T) {
+		testGetUserSessions(t, c)
+		testDeleteUserSession(t, c)
+		testDeleteUserSessions(t, c)
+		testLongUserAgent(t, c)
+		testPurgeExpiredSessions(t, c)
+	})
+}
+
+// loginSession logs the user in and returns the token of the new session
+func loginSession(t *testing.T, c *TestContext, u *config.Credentials) string {
+	response := c.E.POST("/api/user/login").WithBytes([]byte(`{"Email":"` +
+		u.Email + `","Password":"` + u.Password + `"}`)).Expect()
+	lr := struct{ Token string }{}
+	if err := json.Unmarshal(response.Content, &lr); err != nil {
+		t.Errorf("Login : " + err.Error())
+		t.FailNow()
+	}
+	return lr.Token
+}
+
+// currentSession fetches the sessions using the token and returns the current
+// session ID and the user ID
+func currentSession(t *testing.T, c *TestContext, token string) (string, int64) {
+	response := c.E.GET("/api/user/sessions").
+		WithHeader("Authorization", "Bearer "+token).Expect()
+	var resp userSessionsResp
+	if err := json.Unmarshal(response.Content, &resp); err != nil {
+		t.Errorf("Sessions : " + err.Error())
+		t.FailNow()
+	}
+	for _, s := range resp.Sessions {
+		if s.ID == resp.Current {
+			return s.ID, s.UserID
+		}
+	}
+	t.Error("Sessions : session courante introuvable")
+	t.FailNow()
+	return "", 0
+}
+
+// testGetUserSessions checks route is protected and sessions correctly sent back
+func testGetUserSessions(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		*c.UserCheckTestCase, // 0 : token empty
+		{
+			Token:        c.Config.Users.User.Token,
+			RespContains: []string{`"UserSession":[{"ID":"`, `"Current":"`},
+			StatusCode:   http.StatusOK}, // 1 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.GET("/api/user/sessions").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "GetUserSessions") {
+		t.Error(r)
+	}
+}
+
+// testDeleteUserSession checks route is protected and only the given session
+// is closed
+func testDeleteUserSession(t *testing.T, c *TestContext) {
+	token := loginSession(t, c, &c.Config.Users.User)
+	sessionID, _ := currentSession(t, c, token)
+	tcc := []TestCase{
+		*c.UserCheckTestCase, // 0 : token empty
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       sessionID,
+			RespContains: []string{`Suppression de session : session introuvable`},
+			StatusCode:   http.StatusNotFound}, // 1 : session of another user
+		{
+			Token:        c.Config.Users.User.Token,
+			Params:       sessionID,
+			RespContains: []string{`Session supprimée`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+		{
+			Token:        token,
+			Params:       sessionID,
+			RespContains: []string{`Token invalide`},
+			StatusCode:   http.StatusUnauthorized}, // 3 : session closed
+		{
+			Token:        c.Config.Users.User.Token,
+			Params:       sessionID,
+			RespContains: []string{`Suppression de session : session introuvable`},
+			StatusCode:   http.StatusNotFound}, // 4 : already deleted
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.DELETE("/api/user/sessions/"+tc.Params).
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "DeleteUserSession") {
+		t.Error(r)
+	}
+}
+
+// testDeleteUserSessions checks route is admin protected and all sessions of
+// the user are closed
+func testDeleteUserSessions(t *testing.T, c *TestContext) {
+	_, userID := currentSession(t, c, c.Config.Users.User.Token)
+	ID := strconv.FormatInt(userID, 10)
+	tcc := []TestCase{
+		*c.AdminCheckTestCase, // 0 : user unauthorized
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       "fake",
+			RespContains: []string{`Suppression des sessions, paramètre : `},
+			StatusCode:   http.StatusBadRequest}, // 1 : bad ID
+		{
+			Token:        c.Config.Users.Admin.Token,
+			Params:       ID,
+			RespContains: []string{`Sessions supprimées`},
+			StatusCode:   http.StatusOK}, // 2 : ok
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.DELETE("/api/user/"+tc.Params+"/sessions").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "DeleteUserSessions") {
+		t.Error(r)
+	}
+	response := c.E.GET("/api/user/sessions").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.User.Token).Expect()
+	if status := response.Raw().StatusCode; status != http.StatusInternalServerError {
+		t.Errorf("DeleteUserSessions  ->status attendu %d  ->reçu: %d",
+			http.StatusInternalServerError, status)
+	}
+	c.Config.Users.User.Token = loginSession(t, c, &c.Config.Users.User)
+}
+
+// testLongUserAgent checks a login with a User-Agent longer than the device
+// column succeeds, the device being truncated
+func testLongUserAgent(t *testing.T, c *TestContext) {
+	u := c.Config.Users.User
+	response := c.E.POST("/api/user/login").
+		WithHeader("User-Agent", strings.Repeat("é", 300)).
+		WithBytes([]byte(`{"Email":"` + u.Email + `","Password":"` + u.Password +
+			`"}`)).Expect()
+	response.Status(http.StatusOK)
+	lr := struct{ Token string }{}
+	if err := json.Unmarshal(response.Content, &lr); err != nil {
+		t.Errorf("LongUserAgent : " + err.Error())
+		return
+	}
+	var resp userSessionsResp
+	if err := json.Unmarshal(c.E.GET("/api/user/sessions").
+		WithHeader("Authorization", "Bearer "+lr.Token).Expect().Content,
+		&resp); err != nil {
+		t.Errorf("LongUserAgent : " + err.Error())
+		return
+	}
+	for _, s := range resp.Sessions {
+		if s.ID == resp.Current && s.Device != strings.Repeat("é", models.MaxDeviceLength) {
+			t.Errorf("LongUserAgent : appareil tronqué attendu, reçu %q", s.Device)
+		}
+	}
+}
+
+// testPurgeExpiredSessions checks a login removes the sessions that can no
+// longer be refreshed
+func testPurgeExpiredSessions(t *testing.T, c *TestContext) {
+	_, userID := currentSession(t, c, c.Config.Users.User.Token)
+	old := time.Now().Add(-tokenPolicy.RefreshDelay - 2*lastSeenDelay)
+	if _, err := c.DB.Exec(`INSERT INTO user_session (id,user_id,created,
+	last_seen) VALUES('expiredsession',$1,$2,$2)`, userID, old); err != nil {
+		t.Errorf("PurgeExpiredSessions, insert : %v", err)
+		return
+	}
+	c.Config.Users.User.Token = loginSession(t, c, &c.Config.Users.User)
+	var count int
+	if err := c.DB.QueryRow(`SELECT count(1) FROM user_session
+	WHERE id='expiredsession'`).Scan(&count); err != nil {
+		t.Errorf("PurgeExpiredSessions, select : %v", err)
+		return
+	}
+	if count != 0 {
+		t.Error("PurgeExpiredSessions : session expirée non supprimée")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Iledant/PreLoRUGo/models"
 	"github.com/iris-contrib/httpexpect"
 )
 
@@ -20,11 +21,13 @@ func testUser(t *testing.T, c *TestContext) {
 			return
 		}
 		testUpdateUser(t, c, ID)
+		testUpdateUserRights(t, c)
 		testLogout(t, c, ID)
 		testChangeUserPwd(t, c)
 		testGetUsers(t, c)
 		testDeleteUser(t, c, ID)
 		testSignUp(t, c)
+		testLoginBadRequest(t, c)
 	})
 }
 
@@ -36,7 +39,7 @@ func testCreateUser(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`fake`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création d'utilisateur, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad JSON
+			StatusCode:   http.StatusBadRequest}, // 1 : bad JSON
 		{
 			Sent:         []byte(`{"Name":"","Email":"toto@iledefrance.fr","Password":"toto","Rights":0}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -56,12 +59,12 @@ func testCreateUser(t *testing.T, c *TestContext) (ID int) {
 			Sent:         []byte(`{"Name":"Utilisateur","Email":"toto@iledefrance.fr","Password":"toto","Rights":0}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création d'utilisateur : Utilisateur existant`},
-			StatusCode:   http.StatusBadRequest}, // 5 : name already exists
+			StatusCode:   http.StatusConflict}, // 5 : name already exists
 		{
 			Sent:         []byte(`{"Name":"essai","Email":"` + c.Config.Users.User.Email + `","Password":"toto","Rights":0}`),
 			Token:        c.Config.Users.Admin.Token,
 			RespContains: []string{`Création d'utilisateur : Utilisateur existant`},
-			StatusCode:   http.StatusBadRequest}, // 6 : email already exists
+			StatusCode:   http.StatusConflict}, // 6 : email already exists
 		{
 			Sent:         []byte(`{"Name":"essai","Email":"toto@iledefrance.fr","Password":"toto","Rights":0}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -114,7 +117,7 @@ func testUpdateUser(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Modification d'utilisateur, get`},
-			StatusCode:   http.StatusInternalServerError}, // 1 : ID doesn't exist
+			StatusCode:   http.StatusNotFound}, // 1 : ID doesn't exist
 		{
 			Sent:         []byte(`{"Name":"","Email":"toto@iledefrance.fr","Password":"toto","Rights":1}`),
 			Token:        c.Config.Users.Admin.Token,
@@ -137,6 +140,59 @@ func testUpdateUser(t *testing.T, c *TestContext, ID int) {
 	}
 }
 
+// testUpdateUserRights checks that a change of rights by an admin is taken into
+// account by the tokens already issued to the user
+func testUpdateUserRights(t *testing.T, c *TestContext) {
+	var users models.Users
+	response := c.E.GET("/api/users").
+		WithHeader("Authorization", "Bearer "+c.Config.Users.Admin.Token).Expect()
+	if err := json.Unmarshal(response.Content, &users); err != nil {
+		t.Errorf("UpdateUserRights, liste des utilisateurs : " + err.Error())
+		return
+	}
+	var ID int64
+	for _, u := range users.Users {
+		if u.Email == c.Config.Users.ReservationFeeUser.Email {
+			ID = u.ID
+		}
+	}
+	tcc := []TestCase{
+		{
+			Token:      c.Config.Users.ReservationFeeUser.Token,
+			StatusCode: http.StatusOK}, // 0 : rights granted
+		{
+			Sent:         []byte(`{"Rights":1}`),
+			Token:        c.Config.Users.ReservationFeeUser.Token,
+			RespContains: []string{`Droits sur les réservations requis`},
+			StatusCode:   http.StatusUnauthorized}, // 1 : rights removed
+		{
+			Sent:       []byte(`{"Rights":` + strconv.Itoa(models.ActiveReservationMask) + `}`),
+			Token:      c.Config.Users.ReservationFeeUser.Token,
+			StatusCode: http.StatusOK}, // 2 : rights restored
+		{
+			Sent:         []byte(`{"Rights":256}`),
+			Token:        c.Config.Users.ReservationFeeUser.Token,
+			RespContains: []string{`Token invalide`},
+			StatusCode:   http.StatusUnauthorized}, // 3 : user deactivated
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		if tc.Sent != nil {
+			c.E.PUT("/api/user/"+strconv.FormatInt(ID, 10)).WithBytes(tc.Sent).
+				WithHeader("Authorization", "Bearer "+c.Config.Users.Admin.Token).Expect()
+		}
+		return c.E.GET("/api/reservation_reports").
+			WithHeader("Authorization", "Bearer "+tc.Token).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "UpdateUserRights") {
+		t.Error(r)
+	}
+	c.E.PUT("/api/user/"+strconv.FormatInt(ID, 10)).
+		WithBytes([]byte(`{"Rights":`+strconv.Itoa(models.ActiveReservationMask)+`}`)).
+		WithHeader("Authorization", "Bearer "+c.Config.Users.Admin.Token).Expect()
+	c.Config.Users.ReservationFeeUser.Token =
+		loginSession(t, c, &c.Config.Users.ReservationFeeUser)
+}
+
 // testChangeUserPwd checks route is protected and user correctly modified
 func testChangeUserPwd(t *testing.T, c *TestContext) {
 	tcc := []TestCase{
@@ -183,7 +239,7 @@ func testGetUsers(t *testing.T, c *TestContext) {
 		{
 			Token:         c.Config.Users.Admin.Token,
 			RespContains:  []string{`"Christophe Saintillan"`, `"essai2"`, `"Utilisateur"`},
-			Count:         10,
+			Count:         11,
 			CountItemName: `"ID"`,
 			StatusCode:    http.StatusOK}, // 0 : user unauthorized
 	}
@@ -204,7 +260,7 @@ func testDeleteUser(t *testing.T, c *TestContext, ID int) {
 			Token:        c.Config.Users.Admin.Token,
 			ID:           0,
 			RespContains: []string{`Suppression d'utilisateur, requête : `},
-			StatusCode:   http.StatusInternalServerError}, // 1 : bad ID
+			StatusCode:   http.StatusNotFound}, // 1 : bad ID
 		{
 			Token:        c.Config.Users.Admin.Token,
 			ID:           ID,
@@ -226,7 +282,7 @@ func testSignUp(t *testing.T, c *TestContext) {
 		{
 			Sent:         []byte(`fake}`),
 			RespContains: []string{`Inscription d'utilisateur, décodage :`},
-			StatusCode:   http.StatusInternalServerError}, // 0 : user unauthorized
+			StatusCode:   http.StatusBadRequest}, // 0 : user unauthorized
 		{
 			Sent:         []byte(`{"Name":""}`),
 			RespContains: []string{`Inscription d'utilisateur : Champ manquant ou incorrect`},
@@ -255,3 +311,24 @@ func testSignUp(t *testing.T, c *TestContext) {
 		t.Error(r)
 	}
 }
+
+// testLoginBadRequest checks a malformed or incomplete login request is
+// rejected as a bad request
+func testLoginBadRequest(t *testing.T, c *TestContext) {
+	tcc := []TestCase{
+		{
+			Sent:         []byte(`fake`),
+			RespContains: []string{`Décodage login :`},
+			StatusCode:   http.StatusBadRequest}, // 0 : bad request
+		{
+			Sent:         []byte(`{"Email":"","Password":"faux"}`),
+			RespContains: []string{`Champ manquant ou incorrect`},
+			StatusCode:   http.StatusBadRequest}, // 1 : empty field
+	}
+	f := func(tc TestCase) *httpexpect.Response {
+		return c.E.POST("/api/user/login").WithBytes(tc.Sent).Expect()
+	}
+	for _, r := range chkFactory(tcc, f, "LoginBadRequest") {
+		t.Error(r)
+	}
+}
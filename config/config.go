@@ -1,16 +1,12 @@
 package config
 
 import (
-	"fmt"
-	"io/ioutil"
 	"os"
 
 	"github.com/kataras/iris"
 
 	// Imported in config to avoid double import
 	_ "github.com/lib/pq"
-
-	yaml "gopkg.in/yaml.v2"
 )
 
 // AppStage defines the if the application is used for test, development or
@@ -24,7 +20,8 @@ const (
 // PreLoRuGoConf embeddes the configuration options of the application.
 // It's structure is designed to match to the yaml config file used by tests
 // and development stages.
-// When deployed on production, PreLoRuGoConf uses environnement variables.
+// It's built from layers : the default values, the yaml file, the environment
+// variables used in production and the command line flags.
 // Databases field embeddes configurations fields for tests, development and
 // production.
 // Users are only for tests purpose to check routes protections.
@@ -34,6 +31,9 @@ type PreLoRuGoConf struct {
 	Databases Databases
 	Users     Users
 	App       App
+	// fileName is the yaml file read, sources gives the layer of each setting
+	fileName string
+	sources  map[string]string
 }
 
 // Credentials embeddes email and password for a user.
@@ -53,6 +53,7 @@ type Users struct {
 	HousingUser             Credentials `yaml:"housinguser"`
 	HousingPreProgUser      Credentials `yaml:"housing_pre_prog_user"`
 	ReservationFeeUser      Credentials `yaml:"reservation_fee_user"`
+	ObserverUser            Credentials `yaml:"observer_user"`
 }
 
 // DBConf includes all informations for connecting to a database.
@@ -71,13 +72,121 @@ type Databases struct {
 	Test        DBConf
 }
 
-// App defines global configuration fields for the application (stage, log and
-// token file name).
+// App defines global configuration fields for the application (stage, log,
+// token file name, HTTP server, automatic ingestion, e-mail relay, login
+// protection, double authentication and single sign-on). SlowQueryThreshold is the
+// duration in milliseconds above which a query is logged, the default one
+// being used if null and the queries not being traced if negative.
+// MetricsToken is the bearer token required by the metrics route, which is
+// disabled if empty. PageSize is the number of rows of the paginated queries.
 type App struct {
-	Stage         int    `yaml:"stage"`
-	LogFileName   string `yaml:"logfilename"`
-	LoggerLevel   string `yaml:"loggerlevel"`
-	TokenFileName string `yaml:"tokenfilename"`
+	Stage              int       `yaml:"stage"`
+	LogFileName        string    `yaml:"logfilename"`
+	LoggerLevel        string    `yaml:"loggerlevel"`
+	SlowQueryThreshold int       `yaml:"slowquerythreshold"`
+	MetricsToken       string    `yaml:"metricstoken"`
+	TokenFileName      string    `yaml:"tokenfilename"`
+	PageSize           int       `yaml:"pagesize"`
+	Token              Token     `yaml:"token"`
+	Server             Server    `yaml:"server"`
+	Ingestion          Ingestion `yaml:"ingestion"`
+	SMTP               SMTP      `yaml:"smtp"`
+	Login              Login     `yaml:"login"`
+	TwoFactor          TwoFactor `yaml:"twofactor"`
+	OIDC               OIDC      `yaml:"oidc"`
+}
+
+// Token defines the JWT tokens of the sessions. SigningKey is required in
+// production and must be strong. Lifetime is in seconds and RefreshDelay, the
+// time since its token was issued during which a session can be refreshed,
+// in hours.
+type Token struct {
+	SigningKey   string `yaml:"signingkey"`
+	Lifetime     int    `yaml:"lifetime"`
+	RefreshDelay int    `yaml:"refreshdelay"`
+	Issuer       string `yaml:"issuer"`
+}
+
+// Server defines the HTTP server. Addr is the listen address, ":5000" if
+// empty, and TLS is used if CertFile and KeyFile are set. The timeouts are in
+// seconds, the default values being used if null. ShutdownTimeout is the time
+// given to the requests in progress to complete when the server stops.
+type Server struct {
+	Addr            string `yaml:"addr"`
+	CertFile        string `yaml:"certfile"`
+	KeyFile         string `yaml:"keyfile"`
+	ReadTimeout     int    `yaml:"readtimeout"`
+	WriteTimeout    int    `yaml:"writetimeout"`
+	IdleTimeout     int    `yaml:"idletimeout"`
+	ShutdownTimeout int    `yaml:"shutdowntimeout"`
+}
+
+// OIDC defines the single sign-on through an OpenID Connect identity
+// provider, disabled if Issuer is empty. RedirectURL is the page of the
+// frontend receiving the authorization code. Scopes are separated by spaces
+// and "openid email profile" is used if empty. GroupRights maps the groups
+// found in the GroupsClaim of the ID token, "groups" by default, to the rights
+// bits of the user, which are then synchronized at each login. Unknown users
+// are created if Provision is set.
+type OIDC struct {
+	Issuer       string           `yaml:"issuer"`
+	ClientID     string           `yaml:"clientid"`
+	ClientSecret string           `yaml:"clientsecret"`
+	RedirectURL  string           `yaml:"redirecturl"`
+	Scopes       string           `yaml:"scopes"`
+	GroupsClaim  string           `yaml:"groupsclaim"`
+	GroupRights  map[string]int64 `yaml:"grouprights"`
+	Provision    bool             `yaml:"provision"`
+}
+
+// TwoFactor defines the double authentication using TOTP codes. If Required
+// is set, the users having the admin or reservation rights must enable it.
+// Issuer is the name displayed by authenticator apps.
+type TwoFactor struct {
+	Required bool   `yaml:"required"`
+	Issuer   string `yaml:"issuer"`
+}
+
+// Login defines the protection of the login route against brute force
+// attacks. The failed attempts are counted per account and per IP address
+// over Window seconds. Once FreeFailures failures are reached, an attempt is
+// rejected until a delay starting at DelayStep milliseconds and doubling with
+// each failure, up to MaxDelay seconds, has elapsed since the last failure. An
+// account is locked for LockoutDuration seconds after MaxAccountFailures
+// failures and an address is blocked after MaxIPFailures failures. The zero
+// fields use the default values.
+type Login struct {
+	MaxAccountFailures int `yaml:"maxaccountfailures"`
+	MaxIPFailures      int `yaml:"maxipfailures"`
+	FreeFailures       int `yaml:"freefailures"`
+	DelayStep          int `yaml:"delaystep"`
+	MaxDelay           int `yaml:"maxdelay"`
+	LockoutDuration    int `yaml:"lockoutduration"`
+	Window             int `yaml:"window"`
+}
+
+// SMTP defines the relay used to send the password reset and invitation
+// e-mails. BaseURL is the address of the frontend used in the links sent. The
+// e-mails are disabled if Host is empty.
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	UserName string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	BaseURL  string `yaml:"baseurl"`
+}
+
+// Ingestion defines the automatic import of the IRIS exports dropped in Dir.
+// The directory is polled every Interval seconds and files modified for less
+// than MinAge seconds are skipped, 0 importing them at once. Imported files are moved to ArchiveDir and
+// the others to ErrorDir. The ingestion is disabled if Dir is empty.
+type Ingestion struct {
+	Dir        string `yaml:"dir"`
+	ArchiveDir string `yaml:"archivedir"`
+	ErrorDir   string `yaml:"errordir"`
+	Interval   int    `yaml:"interval"`
+	MinAge     int    `yaml:"minage"`
 }
 
 var config *PreLoRuGoConf
@@ -92,62 +201,32 @@ func logFileOpen(name string, app *iris.Application) (*os.File, error) {
 	return logFile, err
 }
 
-// Get fetches all parameters according to tne context : if proper environment
-// variables are set, assumes beeing in prod, otherwise read the config.yml file
+// Get loads the configuration without command line flags, configures the
+// logger and opens the log file if any
 func (p *PreLoRuGoConf) Get(app *iris.Application) (logFile *os.File, err error) {
 	if config != nil {
 		p = config
 		return nil, nil
 	}
-	// Configure the log file as first step to catch all messages
-	p.App.LogFileName = os.Getenv("LOG_FILE_NAME")
-	if p.App.LogFileName != "" {
-		logFile, err = logFileOpen(p.App.LogFileName, app)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// Check if RDS environment variables are set
-	name := os.Getenv("RDS_DB_NAME")
-	host := os.Getenv("RDS_HOSTNAME")
-	port := os.Getenv("RDS_PORT")
-	username := os.Getenv("RDS_USERNAME")
-	password := os.Getenv("RDS_PASSWORD")
-
-	if name != "" && host != "" && port != "" && username != "" && password != "" {
-		app.Logger().Infof("Utilisation des variables d'environnement")
-		p.Databases.Prod.Name = name
-		p.Databases.Prod.Host = host
-		p.Databases.Prod.Port = port
-		p.Databases.Prod.UserName = username
-		p.Databases.Prod.Password = password
-		p.App.TokenFileName = os.Getenv("TOKEN_FILE_NAME")
-		p.Users.SuperAdmin.Email = os.Getenv("SUPERADMIN_EMAIL")
-		p.Users.SuperAdmin.Password = os.Getenv("SUPERADMIN_PWD")
-		p.App.Stage = ProductionStage
-		app.Logger().SetLevel("info")
-		return logFile, nil
+	if _, err = p.Load(nil); err != nil {
+		return nil, err
 	}
+	return p.Setup(app)
+}
 
-	// Otherwise try to fetch configuration through yml configuration file
-	cfgFile, err := ioutil.ReadFile("../config.yml")
-	if err != nil {
-		// Check an other location for test purposes
-		cfgFile, err = ioutil.ReadFile("config.yml")
-		if err != nil {
-			return logFile, fmt.Errorf("Erreur de lecture de config.yml : %v", err)
-		}
-	}
-	if err = yaml.Unmarshal(cfgFile, p); err != nil {
-		return logFile, fmt.Errorf("Erreur de  décodage de config.yml : %v", err)
-	}
+// Setup configures the logger of the application and opens the log file if
+// configured
+func (p *PreLoRuGoConf) Setup(app *iris.Application) (logFile *os.File, err error) {
 	if p.App.LoggerLevel != "" {
 		app.Logger().SetLevel(p.App.LoggerLevel)
 	}
-	if logFile == nil && p.App.LogFileName != "" {
-		logFile, err = logFileOpen(p.App.LogFileName, app)
+	if p.App.LogFileName != "" {
+		if logFile, err = logFileOpen(p.App.LogFileName, app); err != nil {
+			return nil, err
+		}
+	}
+	if p.fileName != "" {
+		app.Logger().Infof("Utilisation de %s", p.fileName)
 	}
-	app.Logger().Infof("Utilisation de config.yml")
-	return logFile, err
+	return logFile, nil
 }
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"database/sql"
+	"path/filepath"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	"github.com/kataras/iris"
+)
+
+// Default values of the ingestion configuration
+const (
+	defaultIngestionInterval = 300
+	defaultIngestionMinAge   = 60
+)
+
+// StartIngestion launches the automatic ingestion of the IRIS exports if a
+// drop directory is configured and returns the ingestor, nil otherwise
+func StartIngestion(cfg *PreLoRuGoConf, db *sql.DB, app *iris.Application) (*models.Ingestor, error) {
+	c := cfg.App.Ingestion
+	if c.Dir == "" {
+		return nil, nil
+	}
+	if c.ArchiveDir == "" {
+		c.ArchiveDir = filepath.Join(c.Dir, "archive")
+	}
+	if c.ErrorDir == "" {
+		c.ErrorDir = filepath.Join(c.Dir, "erreur")
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultIngestionInterval
+	}
+	i := &models.Ingestor{
+		Dir:        c.Dir,
+		ArchiveDir: c.ArchiveDir,
+		ErrorDir:   c.ErrorDir,
+		Interval:   time.Duration(c.Interval) * time.Second,
+		MinAge:     time.Duration(c.MinAge) * time.Second,
+		DB:         db,
+		Logger:     app.Logger(),
+	}
+	if err := i.Start(); err != nil {
+		return nil, err
+	}
+	app.Logger().Infof("Ingestion automatique de %s toutes les %d secondes",
+		c.Dir, c.Interval)
+	return i, nil
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Sources of the settings displayed by Print
+const (
+	defaultSource = "défaut"
+	fileSource    = "fichier"
+	envSource     = "environnement"
+	flagSource    = "option"
+)
+
+// defaultConfigFiles are the yaml files read if none is given, the first one
+// found being used
+var defaultConfigFiles = []string{"../config.yml", "config.yml"}
+
+// settingEnvs gives the environment variable of the settings that can be
+// defined by one, the RDS_ ones being set by the hosting
+var settingEnvs = map[string]string{
+	"databases.prod.name":          "RDS_DB_NAME",
+	"databases.prod.host":          "RDS_HOSTNAME",
+	"databases.prod.port":          "RDS_PORT",
+	"databases.prod.username":      "RDS_USERNAME",
+	"databases.prod.password":      "RDS_PASSWORD",
+	"users.superadmin.email":       "SUPERADMIN_EMAIL",
+	"users.superadmin.password":    "SUPERADMIN_PWD",
+	"app.stage":                    "APP_STAGE",
+	"app.logfilename":              "LOG_FILE_NAME",
+	"app.loggerlevel":              "LOGGER_LEVEL",
+	"app.slowquerythreshold":       "SLOW_QUERY_THRESHOLD",
+	"app.metricstoken":             "METRICS_TOKEN",
+	"app.tokenfilename":            "TOKEN_FILE_NAME",
+	"app.pagesize":                 "PAGE_SIZE",
+	"app.token.signingkey":         "JWT_SIGNING_KEY",
+	"app.token.lifetime":           "JWT_LIFETIME",
+	"app.token.refreshdelay":       "JWT_REFRESH_DELAY",
+	"app.token.issuer":             "JWT_ISSUER",
+	"app.server.addr":              "SERVER_ADDR",
+	"app.server.certfile":          "TLS_CERT_FILE",
+	"app.server.keyfile":           "TLS_KEY_FILE",
+	"app.server.readtimeout":       "SERVER_READ_TIMEOUT",
+	"app.server.writetimeout":      "SERVER_WRITE_TIMEOUT",
+	"app.server.idletimeout":       "SERVER_IDLE_TIMEOUT",
+	"app.server.shutdowntimeout":   "SERVER_SHUTDOWN_TIMEOUT",
+	"app.ingestion.dir":            "INGESTION_DIR",
+	"app.ingestion.archivedir":     "INGESTION_ARCHIVE_DIR",
+	"app.ingestion.errordir":       "INGESTION_ERROR_DIR",
+	"app.ingestion.interval":       "INGESTION_INTERVAL",
+	"app.ingestion.minage":         "INGESTION_MIN_AGE",
+	"app.smtp.host":                "SMTP_HOST",
+	"app.smtp.port":                "SMTP_PORT",
+	"app.smtp.username":            "SMTP_USERNAME",
+	"app.smtp.password":            "SMTP_PASSWORD",
+	"app.smtp.from":                "SMTP_FROM",
+	"app.smtp.baseurl":             "APP_BASE_URL",
+	"app.login.maxaccountfailures": "LOGIN_MAX_ACCOUNT_FAILURES",
+	"app.login.maxipfailures":      "LOGIN_MAX_IP_FAILURES",
+	"app.login.freefailures":       "LOGIN_FREE_FAILURES",
+	"app.login.delaystep":          "LOGIN_DELAY_STEP",
+	"app.login.maxdelay":           "LOGIN_MAX_DELAY",
+	"app.login.lockoutduration":    "LOGIN_LOCKOUT_DURATION",
+	"app.login.window":             "LOGIN_WINDOW",
+	"app.twofactor.required":       "TOTP_REQUIRED",
+	"app.twofactor.issuer":         "TOTP_ISSUER",
+	"app.oidc.issuer":              "OIDC_ISSUER",
+	"app.oidc.clientid":            "OIDC_CLIENT_ID",
+	"app.oidc.clientsecret":        "OIDC_CLIENT_SECRET",
+	"app.oidc.redirecturl":         "OIDC_REDIRECT_URL",
+	"app.oidc.scopes":              "OIDC_SCOPES",
+	"app.oidc.groupsclaim":         "OIDC_GROUPS_CLAIM",
+	"app.oidc.grouprights":         "OIDC_GROUP_RIGHTS",
+	"app.oidc.provision":           "OIDC_PROVISION",
+}
+
+// rdsEnvs are the variables of the production database. If they are all set
+// and the stage isn't given, the production stage is used.
+var rdsEnvs = []string{"RDS_DB_NAME", "RDS_HOSTNAME", "RDS_PORT", "RDS_USERNAME",
+	"RDS_PASSWORD"}
+
+// secretSettings are the last parts of the paths of the settings whose value
+// is redacted by Print
+var secretSettings = map[string]bool{"password": true, "token": true,
+	"signingkey": true, "metricstoken": true, "clientsecret": true}
+
+// defaultConf returns the configuration with the default values
+func defaultConf() PreLoRuGoConf {
+	login := models.DefaultLoginPolicy
+	return PreLoRuGoConf{App: App{
+		Stage:              DevelopmentStage,
+		LoggerLevel:        "info",
+		SlowQueryThreshold: int(models.DefaultSlowQueryThreshold / time.Millisecond),
+		PageSize:           models.DefaultPageSize,
+		Token: Token{
+			Lifetime:     int(models.DefaultTokenPolicy.Lifetime / time.Second),
+			RefreshDelay: int(models.DefaultTokenPolicy.RefreshDelay / time.Hour),
+			Issuer:       models.DefaultTokenPolicy.Issuer,
+		},
+		Server: Server{
+			Addr:            defaultServerAddr,
+			ReadTimeout:     defaultReadTimeout,
+			WriteTimeout:    defaultWriteTimeout,
+			IdleTimeout:     defaultIdleTimeout,
+			ShutdownTimeout: defaultShutdownTimeout,
+		},
+		Ingestion: Ingestion{
+			Interval: defaultIngestionInterval,
+			MinAge:   defaultIngestionMinAge,
+		},
+		SMTP: SMTP{Port: defaultSMTPPort},
+		Login: Login{
+			MaxAccountFailures: int(login.MaxAccountFailures),
+			MaxIPFailures:      int(login.MaxIPFailures),
+			FreeFailures:       int(login.FreeFailures),
+			DelayStep:          int(login.DelayStep / time.Millisecond),
+			MaxDelay:           int(login.MaxDelay / time.Second),
+			LockoutDuration:    int(login.LockoutDuration / time.Second),
+			Window:             int(login.Window / time.Second),
+		},
+		TwoFactor: TwoFactor{Issuer: models.DefaultTwoFactorPolicy.Issuer},
+		OIDC: OIDC{
+			Scopes:      defaultOIDCScopes,
+			GroupsClaim: defaultGroupsClaim,
+		},
+	}}
+}
+
+// setting is a leaf of the configuration identified by its yaml path
+type setting struct {
+	path  string
+	value reflect.Value
+}
+
+// settings returns the leaves of the configuration in the order of the
+// fields
+func (p *PreLoRuGoConf) settings() []setting {
+	var s []setting
+	walkSettings(reflect.ValueOf(p).Elem(), "", &s)
+	return s
+}
+
+// walkSettings appends the leaves of a struct, the path using the yaml names
+// of the fields
+func walkSettings(v reflect.Value, prefix string, s *[]setting) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if f.Type.Kind() == reflect.Struct {
+			walkSettings(v.Field(i), name, s)
+			continue
+		}
+		*s = append(*s, setting{path: name, value: v.Field(i)})
+	}
+}
+
+// set decodes the text of an environment variable or of a flag into the
+// setting
+func (s *setting) set(text string) error {
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(text)
+	case reflect.Int:
+		i, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("%s : entier attendu, reçu %q", s.path, text)
+		}
+		s.value.SetInt(int64(i))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("%s : booléen attendu, reçu %q", s.path, text)
+		}
+		s.value.SetBool(b)
+	case reflect.Map:
+		s.value.Set(reflect.ValueOf(parseGroupRights(text)))
+	}
+	return nil
+}
+
+// String returns the value of the setting, redacted if it's a secret
+func (s *setting) String() string {
+	parts := strings.Split(s.path, ".")
+	if secretSettings[parts[len(parts)-1]] {
+		if s.value.Len() == 0 {
+			return `""`
+		}
+		return "<masqué>"
+	}
+	switch s.value.Kind() {
+	case reflect.String:
+		return strconv.Quote(s.value.String())
+	case reflect.Map:
+		var items []string
+		for _, k := range s.value.MapKeys() {
+			items = append(items, fmt.Sprintf("%s=%v", k, s.value.MapIndex(k)))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(s.value.Interface())
+}
+
+// flagValue records a command line flag, the flags being applied after the
+// file and the environment
+type flagValue struct {
+	setting *setting
+	flags   *[]flagText
+}
+
+// flagText is a flag found on the command line
+type flagText struct {
+	setting *setting
+	text    string
+}
+
+// String implements the flag.Value interface
+func (f flagValue) String() string {
+	return ""
+}
+
+// Set implements the flag.Value interface
+func (f flagValue) Set(text string) error {
+	*f.flags = append(*f.flags, flagText{setting: f.setting, text: text})
+	return nil
+}
+
+// IsBoolFlag allows to use a boolean flag without value
+func (f flagValue) IsBoolFlag() bool {
+	return f.setting != nil && f.setting.value.Kind() == reflect.Bool
+}
+
+// Load builds the configuration from the default values, the yaml file, the
+// environment variables and the command line flags, each layer overriding
+// the previous ones. The flags use the yaml paths of the settings, for
+// example -app.server.addr=:8080, and -config gives the yaml file, otherwise
+// CONFIG_FILE or config.yml if found. It returns the arguments following the
+// flags.
+func (p *PreLoRuGoConf) Load(args []string) ([]string, error) {
+	*p = defaultConf()
+	p.sources = make(map[string]string)
+	settings := p.settings()
+	fs := flag.NewFlagSet("preloru", flag.ContinueOnError)
+	fileName := fs.String("config", os.Getenv("CONFIG_FILE"),
+		"fichier de configuration")
+	var flags []flagText
+	for i := range settings {
+		fs.Var(flagValue{setting: &settings[i], flags: &flags},
+			settings[i].path, "voir config.yml")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := p.loadFile(*fileName, settings); err != nil {
+		return nil, err
+	}
+	if err := p.loadEnv(settings); err != nil {
+		return nil, err
+	}
+	for _, f := range flags {
+		if err := f.setting.set(f.text); err != nil {
+			return nil, err
+		}
+		p.sources[f.setting.path] = flagSource
+	}
+	return fs.Args(), nil
+}
+
+// loadFile decodes the yaml file, a default one being optional
+func (p *PreLoRuGoConf) loadFile(fileName string, settings []setting) error {
+	names := defaultConfigFiles
+	if fileName != "" {
+		names = []string{fileName}
+	}
+	for _, name := range names {
+		content, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) && fileName == "" {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Erreur de lecture de %s : %v", name, err)
+		}
+		if err = yaml.Unmarshal(content, p); err != nil {
+			return fmt.Errorf("Erreur de décodage de %s : %v", name, err)
+		}
+		var keys map[string]interface{}
+		if err = yaml.Unmarshal(content, &keys); err != nil {
+			return fmt.Errorf("Erreur de décodage de %s : %v", name, err)
+		}
+		known := make(map[string]bool, len(settings))
+		for _, s := range settings {
+			known[s.path] = true
+		}
+		p.markFileKeys(keys, "", known)
+		p.fileName = name
+		return nil
+	}
+	return nil
+}
+
+// markFileKeys records the settings defined by the yaml file
+func (p *PreLoRuGoConf) markFileKeys(keys map[string]interface{}, prefix string,
+	known map[string]bool) {
+	for k, v := range keys {
+		path := strings.ToLower(k)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if known[path] {
+			p.sources[path] = fileSource
+			continue
+		}
+		if m, ok := v.(map[interface{}]interface{}); ok {
+			sub := make(map[string]interface{}, len(m))
+			for mk, mv := range m {
+				sub[fmt.Sprint(mk)] = mv
+			}
+			p.markFileKeys(sub, path, known)
+		}
+	}
+}
+
+// loadEnv applies the environment variables which are set
+func (p *PreLoRuGoConf) loadEnv(settings []setting) error {
+	for i := range settings {
+		s := &settings[i]
+		name, ok := settingEnvs[s.path]
+		if !ok {
+			continue
+		}
+		text, ok := os.LookupEnv(name)
+		if !ok || text == "" {
+			continue
+		}
+		if err := s.set(text); err != nil {
+			return fmt.Errorf("%s : %v", name, err)
+		}
+		p.sources[s.path] = envSource + " " + name
+	}
+	if _, ok := p.sources["app.stage"]; ok {
+		return nil
+	}
+	for _, name := range rdsEnvs {
+		if os.Getenv(name) == "" {
+			return nil
+		}
+	}
+	p.App.Stage = ProductionStage
+	p.sources["app.stage"] = envSource + " RDS_*"
+	return nil
+}
+
+// Print writes the effective value of every setting with its source, the
+// secrets being redacted
+func (p *PreLoRuGoConf) Print(w io.Writer) {
+	if p.fileName != "" {
+		fmt.Fprintf(w, "# fichier : %s\n", p.fileName)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, s := range p.settings() {
+		source, ok := p.sources[s.path]
+		if !ok {
+			source = defaultSource
+		}
+		fmt.Fprintf(tw, "%s\t= %s\t# %s\n", s.path, s.String(), source)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// setEnv sets the environment variables and returns the function restoring
+// their former values
+func setEnv(vars map[string]string) func() {
+	former := make(map[string]*string, len(vars))
+	for k, v := range vars {
+		if old, ok := os.LookupEnv(k); ok {
+			former[k] = &old
+		} else {
+			former[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range former {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
+
+// writeConfigFile writes a yaml file in a temporary directory and returns its
+// name and the function removing the directory
+func writeConfigFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("Répertoire temporaire : %v", err)
+	}
+	name := filepath.Join(dir, "config.yml")
+	if err = ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Écriture de %s : %v", name, err)
+	}
+	return name, func() { os.RemoveAll(dir) }
+}
+
+// printedSetting returns the value and the source of a setting in the output
+// of Print
+func printedSetting(t *testing.T, out string, path string) (string, string) {
+	m := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(path) +
+		` +=\s(.*?)\s+# (.*)$`).FindStringSubmatch(out)
+	if m == nil {
+		t.Errorf("%s absent de l'affichage :\n%s", path, out)
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+// TestLoadLayers checks the file overrides the default values, the
+// environment the file and the flags the environment
+func TestLoadLayers(t *testing.T) {
+	name, remove := writeConfigFile(t, `app:
+  pagesize: 20
+  server:
+    addr: ":9000"
+  ingestion:
+    interval: 100
+`)
+	defer remove()
+	defer setEnv(map[string]string{"PAGE_SIZE": "30", "SERVER_ADDR": ":9100"})()
+	var cfg PreLoRuGoConf
+	args, err := cfg.Load([]string{"-config", name, "-app.pagesize=40", "print"})
+	if err != nil {
+		t.Fatalf("Chargement : %v", err)
+	}
+	if len(args) != 1 || args[0] != "print" {
+		t.Errorf("Arguments : [print] attendu, reçu %v", args)
+	}
+	if cfg.App.PageSize != 40 || cfg.App.Server.Addr != ":9100" ||
+		cfg.App.Ingestion.Interval != 100 ||
+		cfg.App.Ingestion.MinAge != defaultIngestionMinAge {
+		t.Errorf("Valeurs incorrectes : %d %s %d %d", cfg.App.PageSize,
+			cfg.App.Server.Addr, cfg.App.Ingestion.Interval,
+			cfg.App.Ingestion.MinAge)
+	}
+	var out bytes.Buffer
+	cfg.Print(&out)
+	for _, tc := range []struct{ path, value, source string }{
+		{"app.pagesize", "40", flagSource},
+		{"app.server.addr", `":9100"`, envSource + " SERVER_ADDR"},
+		{"app.ingestion.interval", "100", fileSource},
+		{"app.ingestion.minage", "60", defaultSource},
+	} {
+		value, source := printedSetting(t, out.String(), tc.path)
+		if value != tc.value || source != tc.source {
+			t.Errorf("%s : %s (%s) attendu, reçu %s (%s)", tc.path, tc.value,
+				tc.source, value, source)
+		}
+	}
+}
+
+// TestPrintRedactsSecrets checks the passwords, tokens and keys are masked by
+// Print, an empty secret being shown as such
+func TestPrintRedactsSecrets(t *testing.T) {
+	name, remove := writeConfigFile(t, `databases:
+  prod:
+    password: "mot-de-passe-base"
+app:
+  metricstoken: "jeton-metriques"
+  token:
+    signingkey: "cle-de-signature-secrete"
+  oidc:
+    clientsecret: "secret-client"
+`)
+	defer remove()
+	defer setEnv(map[string]string{"SMTP_PASSWORD": "mot-de-passe-smtp"})()
+	var cfg PreLoRuGoConf
+	if _, err := cfg.Load([]string{"-config", name}); err != nil {
+		t.Fatalf("Chargement : %v", err)
+	}
+	var out bytes.Buffer
+	cfg.Print(&out)
+	for _, secret := range []string{"mot-de-passe-base", "jeton-metriques",
+		"cle-de-signature-secrete", "secret-client", "mot-de-passe-smtp"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Secret %s affiché", secret)
+		}
+	}
+	for path, value := range map[string]string{
+		"databases.prod.password": "<masqué>",
+		"app.token.signingkey":    "<masqué>",
+		"app.smtp.password":       "<masqué>",
+		"databases.test.password": `""`,
+	} {
+		if v, _ := printedSetting(t, out.String(), path); v != value {
+			t.Errorf("%s : %s attendu, reçu %s", path, value, v)
+		}
+	}
+}
+
+// TestValidateSigningKey checks a weak signing key is refused in any stage and
+// a missing one in production only
+func TestValidateSigningKey(t *testing.T) {
+	tcc := []struct {
+		stage int
+		key   string
+		err   string
+	}{
+		{ProductionStage, "", "clé de signature requise en production"},
+		{ProductionStage, "court", "clé de signature trop faible"},
+		{ProductionStage, strings.Repeat("ab", 20), "clé de signature trop faible"},
+		{ProductionStage, "Kq8#vT2!xLm9$Rz4@Wp7&Yn3*Hd6^Fb1", ""},
+		{DevelopmentStage, "", ""},
+		{DevelopmentStage, "court", "clé de signature trop faible"},
+	}
+	for i, tc := range tcc {
+		cfg := defaultConf()
+		cfg.App.Stage, cfg.App.Token.SigningKey = tc.stage, tc.key
+		db := DBConf{Name: "preloru", Host: "localhost", Port: "5432",
+			UserName: "preloru"}
+		cfg.Databases.Prod, cfg.Databases.Development = db, db
+		err := cfg.Validate()
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%d : aucune erreur attendue, reçu %v", i, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%d : %s attendu, reçu %v", i, tc.err, err)
+		}
+	}
+}
+
+// TestValidatePorts checks a null SMTP port is refused when a host is set and
+// a null ingestion interval when a drop directory is set
+func TestValidatePorts(t *testing.T) {
+	tcc := []struct {
+		set func(c *App)
+		err string
+	}{
+		{func(c *App) { c.SMTP.Host, c.SMTP.From = "smtp", "preloru@iledefrance.fr" }, ""},
+		{func(c *App) {
+			c.SMTP.Host, c.SMTP.From, c.SMTP.Port = "smtp", "preloru@iledefrance.fr", 0
+		}, "app.smtp : expéditeur ou port incorrects"},
+		{func(c *App) { c.SMTP.Port = 0 }, ""},
+		{func(c *App) { c.Ingestion.Dir = "depot" }, ""},
+		{func(c *App) { c.Ingestion.Dir, c.Ingestion.Interval = "depot", 0 },
+			"app.ingestion.interval : intervalle positif requis"},
+		{func(c *App) { c.Ingestion.Interval = 0 }, ""},
+	}
+	for i, tc := range tcc {
+		cfg := defaultConf()
+		cfg.App.Stage = DevelopmentStage
+		cfg.Databases.Development = DBConf{Name: "preloru", Host: "localhost",
+			Port: "5432", UserName: "preloru"}
+		tc.set(&cfg.App)
+		err := cfg.Validate()
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%d : aucune erreur attendue, reçu %v", i, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%d : %s attendu, reçu %v", i, tc.err, err)
+		}
+	}
+}
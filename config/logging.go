@@ -0,0 +1,35 @@
+package config
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// NewJSONLogger returns the logger of the JSON entries of the requests and of
+// the slow queries, writing to the log file if configured or to the standard
+// output
+func NewJSONLogger(logFile *os.File) *log.Logger {
+	var out io.Writer = os.Stdout
+	if logFile != nil {
+		out = logFile
+	}
+	return log.New(out, "", 0)
+}
+
+// NewQueryTracer returns the tracer logging the slow queries with the
+// configured threshold, nil if disabled
+func NewQueryTracer(cfg *PreLoRuGoConf, l *log.Logger) *models.QueryTracer {
+	threshold := cfg.App.SlowQueryThreshold
+	if threshold < 0 {
+		return nil
+	}
+	t := models.QueryTracer{Threshold: models.DefaultSlowQueryThreshold, Logger: l}
+	if threshold > 0 {
+		t.Threshold = time.Duration(threshold) * time.Millisecond
+	}
+	return &t
+}
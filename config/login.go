@@ -0,0 +1,35 @@
+package config
+
+import (
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// NewLoginPolicy returns the brute force protection of the login using the
+// default values for the fields that aren't configured
+func NewLoginPolicy(cfg *PreLoRuGoConf) models.LoginPolicy {
+	c, p := cfg.App.Login, models.DefaultLoginPolicy
+	if c.MaxAccountFailures > 0 {
+		p.MaxAccountFailures = int64(c.MaxAccountFailures)
+	}
+	if c.MaxIPFailures > 0 {
+		p.MaxIPFailures = int64(c.MaxIPFailures)
+	}
+	if c.FreeFailures > 0 {
+		p.FreeFailures = int64(c.FreeFailures)
+	}
+	if c.DelayStep > 0 {
+		p.DelayStep = time.Duration(c.DelayStep) * time.Millisecond
+	}
+	if c.MaxDelay > 0 {
+		p.MaxDelay = time.Duration(c.MaxDelay) * time.Second
+	}
+	if c.LockoutDuration > 0 {
+		p.LockoutDuration = time.Duration(c.LockoutDuration) * time.Second
+	}
+	if c.Window > 0 {
+		p.Window = time.Duration(c.Window) * time.Second
+	}
+	return p
+}
@@ -0,0 +1,26 @@
+package config
+
+import "github.com/Iledant/PreLoRUGo/models"
+
+// defaultSMTPPort is used if the port of the relay isn't configured
+const defaultSMTPPort = 25
+
+// NewMailer returns the mailer using the configured SMTP relay, nil if no
+// relay is configured
+func NewMailer(cfg *PreLoRuGoConf) *models.Mailer {
+	c := cfg.App.SMTP
+	if c.Host == "" {
+		return nil
+	}
+	if c.Port == 0 {
+		c.Port = defaultSMTPPort
+	}
+	return &models.Mailer{
+		Host:     c.Host,
+		Port:     c.Port,
+		UserName: c.UserName,
+		Password: c.Password,
+		From:     c.From,
+		BaseURL:  c.BaseURL,
+	}
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Migration is a numbered change of the database schema. Up statements apply
+// the change and Down statements revert it. Each migration is defined in its
+// own migration_NNNN_name.go file and registered in an init function.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// MigrationState gives the status of a migration compared to the database
+type MigrationState struct {
+	Version  int
+	Name     string
+	Applied  *time.Time
+	Checksum string
+	// Valid is false if the migration applied to the database doesn't match
+	// the one defined in the code or is unknown
+	Valid bool
+}
+
+// migrationLockKey is the key of the PostgreSQL advisory lock taken during
+// migrations to prevent two instances from migrating at the same time
+const migrationLockKey = 5240117
+
+var registeredMigrations []Migration
+
+const createMigrationTable = `CREATE TABLE IF NOT EXISTS migration (
+		version int PRIMARY KEY,
+		name varchar(100) NOT NULL,
+		checksum varchar(64) NOT NULL,
+		applied timestamp NOT NULL
+	)`
+
+// registerMigration adds a migration to the list, keeping it sorted by version
+func registerMigration(m Migration) {
+	registeredMigrations = append(registeredMigrations, m)
+	sort.Slice(registeredMigrations, func(i, j int) bool {
+		return registeredMigrations[i].Version < registeredMigrations[j].Version
+	})
+}
+
+// tableQueries returns the query for each table, the format using the table
+// name as its first argument
+func tableQueries(tables []string, format string) []string {
+	queries := make([]string, len(tables))
+	for i, t := range tables {
+		queries[i] = fmt.Sprintf(format, t)
+	}
+	return queries
+}
+
+// Checksum returns the hash of the up and down statements of the migration
+func (m *Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(strings.Join(m.Up, "\n;\n") + "\n;;\n" +
+		strings.Join(m.Down, "\n;\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// upChecksum returns the former checksum that only hashed the up statements
+func (m *Migration) upChecksum() string {
+	sum := sha256.Sum256([]byte(strings.Join(m.Up, "\n;\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// upgradeChecksums replaces the former checksums stored in the migration
+// table by the ones including the down statements
+func upgradeChecksums(tx *sql.Tx) error {
+	for _, m := range registeredMigrations {
+		if _, err := tx.Exec(`UPDATE migration SET checksum=$1
+			WHERE version=$2 AND checksum=$3`, m.Checksum(), m.Version,
+			m.upChecksum()); err != nil {
+			return fmt.Errorf("migration %d update checksum %v", m.Version, err)
+		}
+	}
+	return nil
+}
+
+// exec launches the queries of the migration inside the transaction
+func (m *Migration) exec(tx *sql.Tx, queries []string) error {
+	for i, q := range queries {
+		if _, err := tx.Exec(q); err != nil {
+			return fmt.Errorf("migration %d requête %d : %v", m.Version, i, err)
+		}
+	}
+	return nil
+}
+
+// lockedTx begins a transaction holding the migration advisory lock and
+// ensures the migration table exists
+func lockedTx(db *sql.DB) (*sql.Tx, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("tx begin %v", err)
+	}
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock($1)`,
+		migrationLockKey); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("advisory lock %v", err)
+	}
+	if err = upgradeLegacyMigrations(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if _, err = tx.Exec(createMigrationTable); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("create migration %v", err)
+	}
+	if err = upgradeChecksums(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// querier is implemented by a database and a transaction
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// appliedVersions fetches the applied migrations from the database
+func appliedVersions(q querier) (map[int]MigrationState, error) {
+	rows, err := q.Query(`SELECT version,name,checksum,applied FROM migration`)
+	if err != nil {
+		return nil, fmt.Errorf("select migration %v", err)
+	}
+	defer rows.Close()
+	applied := map[int]MigrationState{}
+	for rows.Next() {
+		var (
+			s MigrationState
+			t time.Time
+		)
+		if err = rows.Scan(&s.Version, &s.Name, &s.Checksum, &t); err != nil {
+			return nil, fmt.Errorf("scan migration %v", err)
+		}
+		s.Applied = &t
+		applied[s.Version] = s
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err migration %v", err)
+	}
+	return applied, nil
+}
+
+// MigrateUp applies all the migrations that are not yet in the database, each
+// one in its own transaction, and returns the count of applied migrations
+func MigrateUp(db *sql.DB) (int, error) {
+	count := 0
+	for _, m := range registeredMigrations {
+		tx, err := lockedTx(db)
+		if err != nil {
+			return count, err
+		}
+		applied, err := appliedVersions(tx)
+		if err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if _, ok := applied[m.Version]; ok {
+			tx.Rollback()
+			continue
+		}
+		if err = m.exec(tx, m.Up); err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if _, err = tx.Exec(`INSERT INTO migration (version,name,checksum,applied)
+			VALUES($1,$2,$3,$4)`, m.Version, m.Name, m.Checksum(),
+			time.Now()); err != nil {
+			tx.Rollback()
+			return count, fmt.Errorf("migration %d insert %v", m.Version, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return count, fmt.Errorf("migration %d commit %v", m.Version, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// MigrateDown reverts the given count of migrations starting from the last
+// applied one and returns the count of reverted migrations
+func MigrateDown(db *sql.DB, steps int) (int, error) {
+	count := 0
+	for count < steps {
+		tx, err := lockedTx(db)
+		if err != nil {
+			return count, err
+		}
+		applied, err := appliedVersions(tx)
+		if err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		var last *Migration
+		for i := len(registeredMigrations) - 1; i >= 0; i-- {
+			if _, ok := applied[registeredMigrations[i].Version]; ok {
+				last = &registeredMigrations[i]
+				break
+			}
+		}
+		if last == nil {
+			tx.Rollback()
+			return count, nil
+		}
+		if err = last.exec(tx, last.Down); err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if _, err = tx.Exec(`DELETE FROM migration WHERE version=$1`,
+			last.Version); err != nil {
+			tx.Rollback()
+			return count, fmt.Errorf("migration %d delete %v", last.Version, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return count, fmt.Errorf("migration %d commit %v", last.Version, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// MigrationStatus returns the state of every migration defined in the code
+// and of the unknown ones found in the database
+func MigrationStatus(db *sql.DB) ([]MigrationState, error) {
+	tx, err := lockedTx(db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	applied, err := appliedVersions(tx)
+	if err != nil {
+		return nil, err
+	}
+	var states []MigrationState
+	for _, m := range registeredMigrations {
+		s := MigrationState{Version: m.Version, Name: m.Name,
+			Checksum: m.Checksum(), Valid: true}
+		if a, ok := applied[m.Version]; ok {
+			s.Applied = a.Applied
+			s.Valid = a.Checksum == s.Checksum
+			delete(applied, m.Version)
+		}
+		states = append(states, s)
+	}
+	for _, a := range applied {
+		a.Valid = false
+		states = append(states, a)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Version < states[j].Version
+	})
+	return states, nil
+}
+
+// VerifyMigrations checks that every migration applied to the database
+// matches the one defined in the code and that none is pending
+func VerifyMigrations(db *sql.DB) error {
+	states, err := MigrationStatus(db)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, s := range states {
+		switch {
+		case !s.Valid && s.Applied != nil:
+			errs = append(errs, fmt.Sprintf("migration %d (%s) : somme de contrôle différente ou migration inconnue",
+				s.Version, s.Name))
+		case s.Applied == nil:
+			errs = append(errs, fmt.Sprintf("migration %d (%s) : non appliquée",
+				s.Version, s.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// CheckMigrations checks without locking the database that every migration
+// defined in the code is applied with the same checksum. It's used by the
+// readiness probe and doesn't create the migration table.
+func CheckMigrations(db *sql.DB) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range registeredMigrations {
+		a, ok := applied[m.Version]
+		switch {
+		case !ok:
+			return fmt.Errorf("migration %d (%s) : non appliquée", m.Version, m.Name)
+		case a.Checksum != m.Checksum():
+			return fmt.Errorf("migration %d (%s) : somme de contrôle différente",
+				m.Version, m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+// TestMigrationChecksum checks a change of the up or the down statements of a
+// migration changes its checksum
+func TestMigrationChecksum(t *testing.T) {
+	m := Migration{Version: 1, Name: "test", Up: []string{"CREATE TABLE t (id int)"},
+		Down: []string{"DROP TABLE t"}}
+	sum := m.Checksum()
+	if sum == m.upChecksum() {
+		t.Error("Somme de contrôle : instructions down ignorées")
+	}
+	for i, c := range []Migration{
+		{Up: []string{"CREATE TABLE t (id bigint)"}, Down: m.Down},
+		{Up: m.Up, Down: []string{"DROP TABLE IF EXISTS t"}},
+		{Up: m.Up},
+	} {
+		if c.Checksum() == sum {
+			t.Errorf("%d : somme de contrôle inchangée", i)
+		}
+	}
+}
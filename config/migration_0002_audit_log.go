@@ -0,0 +1,64 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 2,
+		Name:    "journal d'audit",
+		Up:      auditLogUp,
+		Down:    auditLogDown,
+	})
+}
+
+// auditedTables are the tables modified by users whose changes are logged
+var auditedTables = []string{"users", "department", "community", "city",
+	"copro", "budget_sector", "budget_action", "renew_project", "housing_type",
+	"housing", "commission", "renew_project_forecast", "copro_forecast", "ratio",
+	"housing_forecast", "rp_event_type", "rp_event", "rp_cmt_city_join",
+	"pre_prog", "copro_event_type", "copro_event", "copro_doc", "home_message",
+	"beneficiary_group", "housing_typology", "housing_convention",
+	"convention_type", "housing_transfer", "housing_comment", "reservation_fee"}
+
+// auditLogUp creates the audit_log table and a trigger on each audited table
+// that stores the row before and after the change. The user is fetched from
+// the preloru.user_id parameter of the connection and is null for changes
+// made outside of a user request.
+var auditLogUp = append([]string{`CREATE TABLE IF NOT EXISTS audit_log (
+	    id SERIAL PRIMARY KEY,
+	    user_id int,
+	    entity varchar(50) NOT NULL,
+	    entity_id varchar(50),
+	    operation varchar(6) NOT NULL,
+	    before jsonb,
+	    after jsonb,
+	    created timestamp NOT NULL
+	  )`, // 0 audit_log
+	`CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity, entity_id)`, // 1
+	`CREATE INDEX IF NOT EXISTS audit_log_created_idx ON audit_log (created)`,          // 2
+	`CREATE OR REPLACE FUNCTION log_audit() RETURNS TRIGGER AS $log_audit$
+	DECLARE
+		old_row jsonb;
+		new_row jsonb;
+	BEGIN
+		IF TG_OP <> 'INSERT' THEN
+			old_row := to_jsonb(OLD) - 'password';
+		END IF;
+		IF TG_OP <> 'DELETE' THEN
+			new_row := to_jsonb(NEW) - 'password';
+		END IF;
+		IF TG_OP = 'UPDATE' AND old_row = new_row THEN
+			RETURN NULL;
+		END IF;
+		INSERT INTO audit_log (user_id,entity,entity_id,operation,before,after,created)
+		VALUES (NULLIF(current_setting('preloru.user_id', true), '')::int,
+			TG_TABLE_NAME,
+			COALESCE(new_row->>'id', old_row->>'id', new_row->>'insee_code',
+				old_row->>'insee_code'),
+			TG_OP, old_row, new_row, now());
+		RETURN NULL;
+	END;
+	$log_audit$ LANGUAGE plpgsql;`, // 3
+}, tableQueries(auditedTables, "CREATE TRIGGER %[1]s_audit AFTER INSERT OR UPDATE OR DELETE ON %[1]s FOR EACH ROW EXECUTE PROCEDURE log_audit()")...)
+
+var auditLogDown = append(tableQueries(auditedTables, "DROP TRIGGER IF EXISTS %[1]s_audit ON %[1]s"),
+	`DROP FUNCTION IF EXISTS log_audit()`,
+	`DROP TABLE IF EXISTS audit_log`)
@@ -0,0 +1,23 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 3,
+		Name:    "suppression des mises à jour redondantes",
+		Up:      redundantUpdatesUp,
+		Down:    redundantUpdatesDown,
+	})
+}
+
+// batchUpdatedTables are the tables updated by batch imports. Updates that
+// don't change the row are skipped so that the batch reports only count the
+// rows really updated.
+var batchUpdatedTables = []string{"city", "community", "copro", "commitment",
+	"copro_forecast", "housing", "housing_forecast", "payment", "payment_demands",
+	"placement", "renew_project", "renew_project_forecast", "rpls"}
+
+var redundantUpdatesUp = tableQueries(batchUpdatedTables,
+	"CREATE TRIGGER z_%[1]s_redundant BEFORE UPDATE ON %[1]s FOR EACH ROW EXECUTE PROCEDURE suppress_redundant_updates_trigger()")
+
+var redundantUpdatesDown = tableQueries(batchUpdatedTables,
+	"DROP TRIGGER IF EXISTS z_%[1]s_redundant ON %[1]s")
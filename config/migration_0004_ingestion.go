@@ -0,0 +1,26 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 4,
+		Name:    "import automatique des exports IRIS",
+		Up:      ingestionUp,
+		Down:    ingestionDown,
+	})
+}
+
+// ingestionUp adds to import_logs the outcome of the last automatic import of
+// each kind. The date remains the one of the last successful import.
+var ingestionUp = []string{
+	`ALTER TABLE import_logs ADD COLUMN IF NOT EXISTS file_name varchar(255),
+		ADD COLUMN IF NOT EXISTS status varchar(10),
+		ADD COLUMN IF NOT EXISTS report jsonb,
+		ADD COLUMN IF NOT EXISTS error text,
+		ADD COLUMN IF NOT EXISTS ran_at timestamp`, // 0
+}
+
+var ingestionDown = []string{
+	`ALTER TABLE import_logs DROP COLUMN IF EXISTS file_name,
+		DROP COLUMN IF EXISTS status, DROP COLUMN IF EXISTS report,
+		DROP COLUMN IF EXISTS error, DROP COLUMN IF EXISTS ran_at`,
+}
@@ -0,0 +1,78 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 5,
+		Name:    "historique des imports",
+		Up:      importRunsUp,
+		Down:    importRunsDown,
+	})
+}
+
+// importedTables are the main tables of the batch imports whose changed rows
+// are stored with the import run
+var importedTables = []string{"city", "commitment", "community", "copro",
+	"copro_forecast", "housing", "housing_forecast", "housing_summary", "payment",
+	"payment_credit", "payment_credit_journal", "payment_demands", "placement",
+	"pre_prog", "prog", "ratio", "renew_project", "renew_project_forecast",
+	"reservation_fee", "rpls"}
+
+// importRunsUp creates the import_run table that stores each batch import and
+// the import_run_row table that stores the rows changed by an import before
+// and after the change. The rows are logged by a trigger when the
+// preloru.import_run_id parameter is set by the import transaction.
+var importRunsUp = append([]string{`CREATE TABLE IF NOT EXISTS import_run (
+	    id SERIAL PRIMARY KEY,
+	    kind varchar(50) NOT NULL,
+	    user_id int,
+	    file_name varchar(255),
+	    lines int NOT NULL DEFAULT 0,
+	    inserted int NOT NULL DEFAULT 0,
+	    updated int NOT NULL DEFAULT 0,
+	    deleted int NOT NULL DEFAULT 0,
+	    unchanged int NOT NULL DEFAULT 0,
+	    created timestamp NOT NULL,
+	    reverted timestamp,
+	    reverted_by int
+	  )`, // 0 import_run
+	`CREATE INDEX IF NOT EXISTS import_run_kind_idx ON import_run (kind, id)`, // 1
+	`CREATE TABLE IF NOT EXISTS import_run_row (
+	    id SERIAL PRIMARY KEY,
+	    run_id int NOT NULL REFERENCES import_run(id) ON DELETE CASCADE,
+	    entity varchar(50) NOT NULL,
+	    entity_id varchar(50),
+	    operation varchar(6) NOT NULL,
+	    before jsonb,
+	    after jsonb
+	  )`, // 2 import_run_row
+	`CREATE INDEX IF NOT EXISTS import_run_row_run_idx ON import_run_row (run_id, entity)`, // 3
+	`CREATE OR REPLACE FUNCTION log_import_row() RETURNS TRIGGER AS $log_import_row$
+	DECLARE
+		run_id int;
+		old_row jsonb;
+		new_row jsonb;
+	BEGIN
+		run_id := NULLIF(current_setting('preloru.import_run_id', true), '')::int;
+		IF run_id IS NULL THEN
+			RETURN NULL;
+		END IF;
+		IF TG_OP <> 'INSERT' THEN
+			old_row := to_jsonb(OLD);
+		END IF;
+		IF TG_OP <> 'DELETE' THEN
+			new_row := to_jsonb(NEW);
+		END IF;
+		INSERT INTO import_run_row (run_id,entity,entity_id,operation,before,after)
+		VALUES (run_id, TG_TABLE_NAME,
+			COALESCE(new_row->>'id', old_row->>'id', new_row->>'insee_code',
+				old_row->>'insee_code'),
+			TG_OP, old_row, new_row);
+		RETURN NULL;
+	END;
+	$log_import_row$ LANGUAGE plpgsql;`, // 4
+}, tableQueries(importedTables, "CREATE TRIGGER %[1]s_import AFTER INSERT OR UPDATE OR DELETE ON %[1]s FOR EACH ROW EXECUTE PROCEDURE log_import_row()")...)
+
+var importRunsDown = append(tableQueries(importedTables, "DROP TRIGGER IF EXISTS %[1]s_import ON %[1]s"),
+	`DROP FUNCTION IF EXISTS log_import_row()`,
+	`DROP TABLE IF EXISTS import_run_row`,
+	`DROP TABLE IF EXISTS import_run`)
@@ -0,0 +1,41 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 6,
+		Name:    "rôles et périmètres des utilisateurs",
+		Up:      rolesUp,
+		Down:    rolesDown,
+	})
+}
+
+// rolesUp creates the role table, the permissions of each role and the
+// assignments of the roles to the users. An assignment can be limited to a
+// department, a budget sector or a set of communities.
+var rolesUp = []string{
+	`CREATE TABLE IF NOT EXISTS role (
+	    id SERIAL PRIMARY KEY,
+	    name varchar(50) NOT NULL UNIQUE,
+	    description varchar(255)
+	  )`, // 0 role
+	`CREATE TABLE IF NOT EXISTS role_permission (
+	    role_id int NOT NULL REFERENCES role(id) ON DELETE CASCADE,
+	    permission varchar(100) NOT NULL,
+	    PRIMARY KEY (role_id, permission)
+	  )`, // 1 role_permission
+	`CREATE TABLE IF NOT EXISTS user_role (
+	    id SERIAL PRIMARY KEY,
+	    user_id int NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	    role_id int NOT NULL REFERENCES role(id) ON DELETE CASCADE,
+	    department_id int REFERENCES department(id) ON DELETE CASCADE,
+	    budget_sector_id int REFERENCES budget_sector(id) ON DELETE CASCADE,
+	    community_ids int[]
+	  )`, // 2 user_role
+	`CREATE INDEX IF NOT EXISTS user_role_user_idx ON user_role (user_id)`, // 3
+}
+
+var rolesDown = []string{
+	`DROP TABLE IF EXISTS user_role`,
+	`DROP TABLE IF EXISTS role_permission`,
+	`DROP TABLE IF EXISTS role`,
+}
@@ -0,0 +1,30 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 7,
+		Name:    "jetons de réinitialisation de mot de passe",
+		Up:      passwordTokensUp,
+		Down:    passwordTokensDown,
+	})
+}
+
+// passwordTokensUp creates the table of the single-use tokens sent by e-mail
+// to reset a password or to set the first one of an invited user. Only the
+// hash of the token is stored.
+var passwordTokensUp = []string{
+	`CREATE TABLE IF NOT EXISTS password_token (
+	    id SERIAL PRIMARY KEY,
+	    user_id int NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	    token_hash char(64) NOT NULL UNIQUE,
+	    kind varchar(10) NOT NULL,
+	    created timestamp NOT NULL DEFAULT now(),
+	    expires timestamp NOT NULL,
+	    used timestamp
+	  )`, // 0 password_token
+	`CREATE INDEX IF NOT EXISTS password_token_user_idx ON password_token (user_id)`, // 1
+}
+
+var passwordTokensDown = []string{
+	`DROP TABLE IF EXISTS password_token`,
+}
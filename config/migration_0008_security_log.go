@@ -0,0 +1,34 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 8,
+		Name:    "journal de sécurité et verrouillage des comptes",
+		Up:      securityLogUp,
+		Down:    securityLogDown,
+	})
+}
+
+// securityLogUp creates the security_log table storing the login attempts,
+// the lockouts and the unlocks, and adds to users the end of the lockout of
+// the account. The failed attempts are counted using the log.
+var securityLogUp = []string{
+	`CREATE TABLE IF NOT EXISTS security_log (
+	    id SERIAL PRIMARY KEY,
+	    created timestamp NOT NULL DEFAULT now(),
+	    event varchar(20) NOT NULL,
+	    user_id int REFERENCES users(id) ON DELETE SET NULL,
+	    actor_id int REFERENCES users(id) ON DELETE SET NULL,
+	    email varchar(120),
+	    ip varchar(50),
+	    user_agent varchar(255)
+	  )`, // 0 security_log
+	`CREATE INDEX IF NOT EXISTS security_log_user_idx ON security_log (user_id, created)`, // 1
+	`CREATE INDEX IF NOT EXISTS security_log_ip_idx ON security_log (ip, created)`,        // 2
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamp`,                   // 3
+}
+
+var securityLogDown = []string{
+	`ALTER TABLE users DROP COLUMN IF EXISTS locked_until`,
+	`DROP TABLE IF EXISTS security_log`,
+}
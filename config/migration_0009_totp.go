@@ -0,0 +1,43 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 9,
+		Name:    "double authentification TOTP",
+		Up:      totpUp,
+		Down:    totpDown,
+	})
+}
+
+// totpUp adds to users the TOTP secret, which is pending until the first code
+// is checked, and the last time step used to prevent replays. It creates the
+// tables of the single-use recovery codes and of the pre-authentication tokens
+// sent back by the login when a code is required. Only the hashes of the codes
+// and tokens are stored.
+var totpUp = []string{
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret varchar(64)`,                     // 0
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled boolean NOT NULL DEFAULT false`, // 1
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint`,                       // 2
+	`CREATE TABLE IF NOT EXISTS totp_recovery_code (
+	    id SERIAL PRIMARY KEY,
+	    user_id int NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	    code_hash char(64) NOT NULL,
+	    used timestamp
+	  )`, // 3 totp_recovery_code
+	`CREATE INDEX IF NOT EXISTS totp_recovery_code_user_idx ON totp_recovery_code (user_id)`, // 4
+	`CREATE TABLE IF NOT EXISTS preauth_token (
+	    id SERIAL PRIMARY KEY,
+	    user_id int NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	    token_hash char(64) NOT NULL UNIQUE,
+	    expires timestamp NOT NULL,
+	    attempts int NOT NULL DEFAULT 0
+	  )`, // 5 preauth_token
+}
+
+var totpDown = []string{
+	`DROP TABLE IF EXISTS preauth_token`,
+	`DROP TABLE IF EXISTS totp_recovery_code`,
+	`ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step`,
+	`ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled`,
+	`ALTER TABLE users DROP COLUMN IF EXISTS totp_secret`,
+}
@@ -0,0 +1,32 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 10,
+		Name:    "clés d'API personnelles",
+		Up:      apiKeysUp,
+		Down:    apiKeysDown,
+	})
+}
+
+// apiKeysUp creates the table of the personal API keys used by scripts in
+// place of a session token. Only the hash of the key is stored with its first
+// characters to help the user identify it.
+var apiKeysUp = []string{
+	`CREATE TABLE IF NOT EXISTS api_key (
+	    id SERIAL PRIMARY KEY,
+	    user_id int NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	    name varchar(50) NOT NULL,
+	    prefix varchar(12) NOT NULL,
+	    key_hash char(64) NOT NULL UNIQUE,
+	    read_only boolean NOT NULL DEFAULT false,
+	    created timestamp NOT NULL DEFAULT now(),
+	    expires timestamp NOT NULL,
+	    last_used timestamp,
+	    UNIQUE (user_id, name)
+	  )`, // 0 api_key
+}
+
+var apiKeysDown = []string{
+	`DROP TABLE IF EXISTS api_key`,
+}
@@ -0,0 +1,27 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 11,
+		Name:    "authentification unique OpenID Connect",
+		Up:      oidcUp,
+		Down:    oidcDown,
+	})
+}
+
+// oidcUp creates the table of the pending single sign-on logins storing the
+// PKCE code verifier and the nonce of each authorization request. Only the
+// hash of the state sent to the identity provider is stored.
+var oidcUp = []string{
+	`CREATE TABLE IF NOT EXISTS oidc_login (
+	    id SERIAL PRIMARY KEY,
+	    state_hash char(64) NOT NULL UNIQUE,
+	    nonce varchar(64) NOT NULL,
+	    code_verifier varchar(128) NOT NULL,
+	    expires timestamp NOT NULL
+	  )`, // 0 oidc_login
+}
+
+var oidcDown = []string{
+	`DROP TABLE IF EXISTS oidc_login`,
+}
@@ -0,0 +1,20 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 12,
+		Name:    "index de la pagination par curseur des paiements",
+		Up:      keysetIndexUp,
+		Down:    keysetIndexDown,
+	})
+}
+
+// keysetIndexUp creates the index used by the keyset pagination of the
+// payments, the commitments using their primary key
+var keysetIndexUp = []string{
+	`CREATE INDEX IF NOT EXISTS payment_keyset_idx ON payment (year,number,id)`,
+}
+
+var keysetIndexDown = []string{
+	`DROP INDEX IF EXISTS payment_keyset_idx`,
+}
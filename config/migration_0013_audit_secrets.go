@@ -0,0 +1,53 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 13,
+		Name:    "exclusion des secrets du journal d'audit",
+		Up:      auditSecretsUp,
+		Down:    auditSecretsDown,
+	})
+}
+
+// auditExcludedColumns are the columns of the users removed from the audit
+// log, either secret or changed by each login
+const auditExcludedColumns = `'password' - 'totp_secret' - 'totp_last_step' -
+	'locked_until' - 'rights_version'`
+
+// auditSecretsUp replaces the audit function to remove from the logged rows
+// the TOTP secret and the columns changed by the logins, so that a login
+// doesn't create an entry, and removes them from the existing entries
+var auditSecretsUp = []string{
+	`CREATE OR REPLACE FUNCTION log_audit() RETURNS TRIGGER AS $log_audit$
+	DECLARE
+		old_row jsonb;
+		new_row jsonb;
+	BEGIN
+		IF TG_OP <> 'INSERT' THEN
+			old_row := to_jsonb(OLD) - ` + auditExcludedColumns + `;
+		END IF;
+		IF TG_OP <> 'DELETE' THEN
+			new_row := to_jsonb(NEW) - ` + auditExcludedColumns + `;
+		END IF;
+		IF TG_OP = 'UPDATE' AND old_row = new_row THEN
+			RETURN NULL;
+		END IF;
+		INSERT INTO audit_log (user_id,entity,entity_id,operation,before,after,created)
+		VALUES (NULLIF(current_setting('preloru.user_id', true), '')::int,
+			TG_TABLE_NAME,
+			COALESCE(new_row->>'id', old_row->>'id', new_row->>'insee_code',
+				old_row->>'insee_code'),
+			TG_OP, old_row, new_row, now());
+		RETURN NULL;
+	END;
+	$log_audit$ LANGUAGE plpgsql;`, // 0
+	`UPDATE audit_log SET before=before - ` + auditExcludedColumns + `,
+		after=after - ` + auditExcludedColumns + `
+	WHERE entity='users'`, // 1
+	`DELETE FROM audit_log WHERE entity='users' AND operation='UPDATE'
+		AND before=after`, // 2
+}
+
+// auditSecretsDown restores the audit function, the removed columns of the
+// existing entries being lost
+var auditSecretsDown = []string{auditLogUp[3]}
@@ -0,0 +1,23 @@
+package config
+
+func init() {
+	registerMigration(Migration{
+		Version: 14,
+		Name:    "journal d'audit des tables importées",
+		Up:      auditImportsUp,
+		Down:    auditImportsDown,
+	})
+}
+
+// auditedImportTables are the tables only modified by the batch imports whose
+// changes are also logged in the audit log
+var auditedImportTables = []string{"commitment", "payment", "prog",
+	"payment_demands", "placement", "housing_summary", "rpls", "payment_credit",
+	"payment_credit_journal"}
+
+// auditImportsUp creates the audit trigger on each table of the batch imports
+var auditImportsUp = tableQueries(auditedImportTables,
+	"CREATE TRIGGER %[1]s_audit AFTER INSERT OR UPDATE OR DELETE ON %[1]s FOR EACH ROW EXECUTE PROCEDURE log_audit()")
+
+var auditImportsDown = tableQueries(auditedImportTables,
+	"DROP TRIGGER IF EXISTS %[1]s_audit ON %[1]s")
@@ -6,14 +6,10 @@ import (
 	"time"
 )
 
-type migrationEntry struct {
-	ID      int
-	Created time.Time
-	Index   int
-	Query   string
-}
-
-var migrations = []string{`ALTER TABLE copro ALTER COLUMN reference TYPE varchar(25)`, // 0
+// legacyMigrations are the migrations used before the versioned ones were
+// introduced. They are only launched to upgrade a database still using the
+// former migration table and must not be modified anymore.
+var legacyMigrations = []string{`ALTER TABLE copro ALTER COLUMN reference TYPE varchar(25)`, // 0
 	`ALTER TABLE renew_project_forecast ADD COLUMN action_id int NOT NULL,
 		ADD CONSTRAINT renew_project_action_id_fkey FOREIGN KEY (action_id) 
 		REFERENCES budget_action (id) MATCH SIMPLE
@@ -65,29 +61,73 @@ var migrations = []string{`ALTER TABLE copro ALTER COLUMN reference TYPE varchar
 	`ALTER TABLE payment ADD COLUMN receipt_date date`,                                          // 21
 	`ALTER TABLE temp_payment ADD COLUMN receipt_date date`,                                     // 22
 	`ALTER TABLE payment_demands ALTER excluded SET NOT NULL, ALTER excluded SET DEFAULT FALSE`, //23
+	`DROP TABLE IF EXISTS user_session`,                                                         // 24
+	`CREATE TABLE IF NOT EXISTS user_session (
+		id varchar(32) PRIMARY KEY,
+		user_id int NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		created timestamp NOT NULL,
+		last_seen timestamp NOT NULL,
+		device varchar(250) NOT NULL DEFAULT '',
+		ip varchar(45) NOT NULL DEFAULT ''
+	)`, // 25
+	`ALTER TABLE users ADD COLUMN rights_version int NOT NULL DEFAULT 0`, // 26
 }
 
-// handleMigrations check if new migrations have been created and launches them
-// against the database
-func handleMigrations(db *sql.DB) error {
+// upgradeLegacyMigrations converts a database using the former migration
+// table whose rows store an index in the legacyMigrations list. As the former
+// initialization did, the initial schema queries are launched, which is
+// possible since they are idempotent, and then the missing legacy migrations.
+// The former table is renamed legacy_migration and the initial schema is
+// marked as applied in the new migration table.
+func upgradeLegacyMigrations(tx *sql.Tx) error {
+	var count int64
+	if err := tx.QueryRow(`SELECT count(1) FROM information_schema.columns
+		WHERE table_schema='public' AND table_name='migration'
+			AND column_name='index'`).Scan(&count); err != nil {
+		return fmt.Errorf("legacy migration check %v", err)
+	}
+	if count == 0 {
+		return nil
+	}
+	for i, q := range initialSchemaUp {
+		if _, err := tx.Exec(q); err != nil {
+			return fmt.Errorf("legacy initial query %d : %v", i, err)
+		}
+	}
 	var maxIdx *int
-	err := db.QueryRow("SELECT max(index) FROM migration").Scan(&maxIdx)
-	if err != nil {
-		return fmt.Errorf("Migration select max index : %+v", err)
+	if err := tx.QueryRow("SELECT max(index) FROM migration").
+		Scan(&maxIdx); err != nil {
+		return fmt.Errorf("legacy migration select max index : %v", err)
 	}
 	var i int
 	if maxIdx != nil {
 		i = *maxIdx + 1
 	}
-	for i < len(migrations) {
-		if _, err = db.Exec(migrations[i]); err != nil {
-			return fmt.Errorf("Migration %d : %+v", i, err)
+	for i < len(legacyMigrations) {
+		if _, err := tx.Exec(legacyMigrations[i]); err != nil {
+			return fmt.Errorf("legacy migration %d : %v", i, err)
 		}
-		if _, err = db.Exec(`INSERT INTO migration (created,index,query) 
-		VALUES($1,$2,$3)`, time.Now(), i, migrations[i]); err != nil {
-			return fmt.Errorf("Migration %d sauvegarde bdd : %+v", i, err)
+		if _, err := tx.Exec(`INSERT INTO migration (created,index,query)
+		VALUES($1,$2,$3)`, time.Now(), i, legacyMigrations[i]); err != nil {
+			return fmt.Errorf("legacy migration %d sauvegarde bdd : %v", i, err)
 		}
 		i++
 	}
+	if _, err := tx.Exec(`ALTER TABLE migration RENAME TO legacy_migration`); err != nil {
+		return fmt.Errorf("legacy migration rename %v", err)
+	}
+	if _, err := tx.Exec(createMigrationTable); err != nil {
+		return fmt.Errorf("create migration %v", err)
+	}
+	for _, m := range registeredMigrations {
+		if m.Version != 1 {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO migration (version,name,checksum,applied)
+			VALUES($1,$2,$3,$4)`, m.Version, m.Name, m.Checksum(),
+			time.Now()); err != nil {
+			return fmt.Errorf("legacy migration baseline %v", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// defaultOIDCScopes and defaultGroupsClaim are used if not configured
+const (
+	defaultOIDCScopes  = "openid email profile"
+	defaultGroupsClaim = "groups"
+)
+
+// NewOIDCProvider returns the configured identity provider, nil if the single
+// sign-on isn't configured
+func NewOIDCProvider(cfg *PreLoRuGoConf) *models.OIDCProvider {
+	c := cfg.App.OIDC
+	if c.Issuer == "" {
+		return nil
+	}
+	if c.Scopes == "" {
+		c.Scopes = defaultOIDCScopes
+	}
+	if c.GroupsClaim == "" {
+		c.GroupsClaim = defaultGroupsClaim
+	}
+	return &models.OIDCProvider{
+		Issuer:       strings.TrimSuffix(c.Issuer, "/"),
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.Scopes,
+		GroupsClaim:  c.GroupsClaim,
+		GroupRights:  c.GroupRights,
+		Provision:    c.Provision,
+	}
+}
+
+// parseGroupRights decodes the group to rights mapping of an environment
+// variable using the "group=rights,group=rights" format. Malformed items are
+// skipped.
+func parseGroupRights(s string) map[string]int64 {
+	m := make(map[string]int64)
+	for _, item := range strings.Split(s, ",") {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		rights, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		m[strings.TrimSpace(kv[0])] = rights
+	}
+	return m
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/core/host"
+)
+
+// Default values of the server configuration, the timeouts being in seconds.
+// The write timeout leaves time to the batch imports and to the exports.
+const (
+	defaultServerAddr      = ":5000"
+	defaultReadTimeout     = 60
+	defaultWriteTimeout    = 300
+	defaultIdleTimeout     = 120
+	defaultShutdownTimeout = 30
+)
+
+// Listener gives the address, the TLS files and the timeouts of the HTTP
+// server with the default values applied
+type Listener struct {
+	Addr            string
+	CertFile        string
+	KeyFile         string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// seconds returns the duration of the configured seconds or of the default
+// ones if null
+func seconds(value, def int) time.Duration {
+	if value <= 0 {
+		value = def
+	}
+	return time.Duration(value) * time.Second
+}
+
+// NewListener returns the listener of the configured server, the TLS files
+// being checked by Validate
+func NewListener(cfg *PreLoRuGoConf) *Listener {
+	c := cfg.App.Server
+	l := Listener{
+		Addr:            c.Addr,
+		CertFile:        c.CertFile,
+		KeyFile:         c.KeyFile,
+		ReadTimeout:     seconds(c.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:    seconds(c.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:     seconds(c.IdleTimeout, defaultIdleTimeout),
+		ShutdownTimeout: seconds(c.ShutdownTimeout, defaultShutdownTimeout),
+	}
+	if l.Addr == "" {
+		l.Addr = defaultServerAddr
+	}
+	return &l
+}
+
+// Runner returns the iris runner of the server using TLS if configured
+func (l *Listener) Runner() iris.Runner {
+	timeouts := func(su *host.Supervisor) {
+		su.Server.ReadTimeout = l.ReadTimeout
+		su.Server.WriteTimeout = l.WriteTimeout
+		su.Server.IdleTimeout = l.IdleTimeout
+	}
+	if l.CertFile != "" {
+		return iris.TLS(l.Addr, l.CertFile, l.KeyFile, timeouts)
+	}
+	return iris.Addr(l.Addr, timeouts)
+}
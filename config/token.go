@@ -0,0 +1,24 @@
+package config
+
+import (
+	"time"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// NewTokenPolicy returns the signature and the lifetime of the tokens using
+// the default values for the fields that aren't configured
+func NewTokenPolicy(cfg *PreLoRuGoConf) models.TokenPolicy {
+	c, p := cfg.App.Token, models.DefaultTokenPolicy
+	p.SigningKey = []byte(c.SigningKey)
+	if c.Lifetime > 0 {
+		p.Lifetime = time.Duration(c.Lifetime) * time.Second
+	}
+	if c.RefreshDelay > 0 {
+		p.RefreshDelay = time.Duration(c.RefreshDelay) * time.Hour
+	}
+	if c.Issuer != "" {
+		p.Issuer = c.Issuer
+	}
+	return p
+}
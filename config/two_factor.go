@@ -0,0 +1,14 @@
+package config
+
+import "github.com/Iledant/PreLoRUGo/models"
+
+// NewTwoFactorPolicy returns the double authentication policy using the
+// default issuer if none is configured
+func NewTwoFactorPolicy(cfg *PreLoRuGoConf) models.TwoFactorPolicy {
+	c, p := cfg.App.TwoFactor, models.DefaultTwoFactorPolicy
+	p.Required = c.Required
+	if c.Issuer != "" {
+		p.Issuer = c.Issuer
+	}
+	return p
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Iledant/PreLoRUGo/models"
+)
+
+// minSigningKeyLength and minSigningKeyChars are the length and the number of
+// distinct characters below which a signing key is considered weak
+const (
+	minSigningKeyLength = 32
+	minSigningKeyChars  = 10
+)
+
+// loggerLevels are the levels accepted by the logger
+var loggerLevels = map[string]bool{"disable": true, "fatal": true,
+	"error": true, "warn": true, "info": true, "debug": true}
+
+// weakSigningKey returns true if the key is too short or made of too few
+// distinct characters
+func weakSigningKey(key string) bool {
+	chars := make(map[rune]bool)
+	for _, c := range key {
+		chars[c] = true
+	}
+	return len(key) < minSigningKeyLength || len(chars) < minSigningKeyChars
+}
+
+// Validate checks the effective configuration and returns all the errors
+// found, one per line. It refuses a weak signing key and an empty one in
+// production.
+func (p *PreLoRuGoConf) Validate() error {
+	var errs []string
+	add := func(format string, a ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, a...))
+	}
+	c := &p.App
+	var db *DBConf
+	switch c.Stage {
+	case ProductionStage:
+		db = &p.Databases.Prod
+	case DevelopmentStage:
+		db = &p.Databases.Development
+	case TestStage:
+		db = &p.Databases.Test
+	default:
+		add("app.stage : %d incorrect (1 production, 2 développement, 3 test)",
+			c.Stage)
+	}
+	if db != nil && (db.Name == "" || db.Host == "" || db.Port == "" ||
+		db.UserName == "") {
+		add("databases : base de données de l'étape %d incomplète", c.Stage)
+	}
+	if c.LoggerLevel != "" && !loggerLevels[c.LoggerLevel] {
+		add("app.loggerlevel : niveau %q inconnu", c.LoggerLevel)
+	}
+	if c.PageSize < 1 || c.PageSize > models.MaxPageSize {
+		add("app.pagesize : doit être compris entre 1 et %d", models.MaxPageSize)
+	}
+	switch {
+	case c.Stage == ProductionStage && c.Token.SigningKey == "":
+		add("app.token.signingkey : clé de signature requise en production")
+	case c.Token.SigningKey != "" && weakSigningKey(c.Token.SigningKey):
+		add("app.token.signingkey : clé de signature trop faible, %d caractères dont %d différents au minimum",
+			minSigningKeyLength, minSigningKeyChars)
+	}
+	if c.Token.Lifetime <= 0 || c.Token.RefreshDelay <= 0 {
+		add("app.token : durées de validité positives requises")
+	}
+	if c.Server.Addr == "" {
+		add("app.server.addr : adresse requise")
+	}
+	if (c.Server.CertFile == "") != (c.Server.KeyFile == "") {
+		add("app.server : certificat et clé TLS requis ensemble")
+	}
+	for _, f := range []string{c.Server.CertFile, c.Server.KeyFile} {
+		if f == "" {
+			continue
+		}
+		if _, err := os.Stat(f); err != nil {
+			add("app.server : fichier TLS %s inaccessible", f)
+		}
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 ||
+		c.Server.IdleTimeout < 0 || c.Server.ShutdownTimeout < 0 {
+		add("app.server : délais négatifs")
+	}
+	if c.Ingestion.Interval < 0 || c.Ingestion.Dir != "" && c.Ingestion.Interval == 0 {
+		add("app.ingestion.interval : intervalle positif requis")
+	}
+	if c.Ingestion.MinAge < 0 {
+		add("app.ingestion.minage : ancienneté négative")
+	}
+	if c.SMTP.Host != "" && (c.SMTP.From == "" || c.SMTP.Port < 1 ||
+		c.SMTP.Port > 65535) {
+		add("app.smtp : expéditeur ou port incorrects")
+	}
+	l := c.Login
+	if l.MaxAccountFailures < 0 || l.MaxIPFailures < 0 || l.FreeFailures < 0 ||
+		l.DelayStep < 0 || l.MaxDelay < 0 || l.LockoutDuration < 0 || l.Window < 0 {
+		add("app.login : valeurs négatives")
+	}
+	if c.OIDC.Issuer != "" && (c.OIDC.ClientID == "" || c.OIDC.RedirectURL == "") {
+		add("app.oidc : clientid et redirecturl requis avec issuer")
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -2,39 +2,111 @@ package main
 
 import (
 	stdContext "context"
-	"time"
+	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/Iledant/PreLoRUGo/actions"
 	"github.com/Iledant/PreLoRUGo/config"
+	"github.com/Iledant/PreLoRUGo/models"
 	"github.com/kataras/iris"
 )
 
-func run(app *iris.Application, cfg *config.PreLoRuGoConf) error {
-	// if cfg.App.Stage == config.ProductionStage {
-	// 	domain := os.Getenv("APP_DOMAIN")
-	// 	if domain == "" {
-	// 		app.Logger().Error("Variable d'environnement APP_DOMAIN vide")
-	// 		return fmt.Errorf("Mauvaise configuration des variables d'environnement")
-	// 	}
-	// 	addr := os.Getenv("APP_ADDR")
-	// 	if addr == "" {
-	// 		app.Logger().Error("Variable d'environnement APP_ADDR vide")
-	// 		return fmt.Errorf("Mauvaise configuration des variables d'environnement")
-	// 	}
-	// 	email := os.Getenv("DOMAIN_OWNER_EMAIL")
-	// 	if email == "" {
-	// 		app.Logger().Error("Variable d'environnement DOMAIN_OWNER_EMAIL vide")
-	// 		return fmt.Errorf("Mauvaise configuration des variables d'environnement")
-	// 	}
-	// 	crtDir := os.Getenv("CRT_DIR")
-	// 	if crtDir == "" {
-	// 		app.Logger().Error("Variable d'environnement CRT_DIR vide")
-	// 		return fmt.Errorf("Mauvaise configuration des variables d'environnement")
-	// 	}
-	// 	return app.NewHost(&http.Server{Addr: ":5443"}).
-	// 		ListenAndServeAutoTLS(domain, email, crtDir)
-	// }
-	return app.Run(iris.Addr(":5000"))
+// run serves the application until the server is shut down, the interrupt
+// being handled by shutdown
+func run(app *iris.Application, l *config.Listener) error {
+	return app.Run(l.Runner(), iris.WithoutInterruptHandler,
+		iris.WithoutServerError(iris.ErrServerClosed))
+}
+
+// shutdown stops the application gracefully : the readiness probe fails, the
+// requests in progress, the e-mails being sent and the current automatic
+// import are completed, the background tasks are stopped and the tokens are saved if the in memory
+// store is used
+func shutdown(app *iris.Application, cfg *config.PreLoRuGoConf,
+	l *config.Listener, ingestor *models.Ingestor) {
+	app.Logger().Infof("Arrêt du serveur")
+	actions.SetDraining(true)
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(),
+		l.ShutdownTimeout)
+	defer cancel()
+	if err := app.Shutdown(ctx); err != nil {
+		app.Logger().Errorf("Arrêt du serveur : %v", err)
+	}
+	if err := actions.WaitMails(ctx); err != nil {
+		app.Logger().Errorf("Arrêt du serveur, courriels en cours : %v", err)
+	}
+	if ingestor != nil {
+		ingestor.Stop()
+	}
+	models.StopCacheInvalidation()
+	if cfg.App.TokenFileName != "" {
+		actions.TokenSave(cfg.App.TokenFileName)
+	}
+}
+
+// migrate handles the migrate subcommand : migrate up|down [n]|status|verify
+func migrate(cfg *config.PreLoRuGoConf, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage : migrate up|down [n]|status|verify")
+	}
+	db, err := config.OpenDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	switch args[0] {
+	case "up":
+		count, err := config.MigrateUp(db)
+		fmt.Printf("%d migration(s) appliquée(s)\n", count)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("nombre de migrations incorrect : %s", args[1])
+			}
+		}
+		count, err := config.MigrateDown(db, steps)
+		fmt.Printf("%d migration(s) annulée(s)\n", count)
+		return err
+	case "status":
+		states, err := config.MigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			applied, valid := "en attente", ""
+			if s.Applied != nil {
+				applied = s.Applied.Format("2006-01-02 15:04:05")
+			}
+			if !s.Valid {
+				valid = " (somme de contrôle invalide)"
+			}
+			fmt.Printf("%04d %-40s %s%s\n", s.Version, s.Name, applied, valid)
+		}
+		return nil
+	case "verify":
+		if err = config.VerifyMigrations(db); err != nil {
+			return err
+		}
+		fmt.Println("Migrations vérifiées")
+		return nil
+	}
+	return fmt.Errorf("commande inconnue %s", args[0])
+}
+
+// configCommand handles the config subcommand : config print writes the
+// effective settings, the secrets being redacted, and checks them
+func configCommand(cfg *config.PreLoRuGoConf, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage : config print")
+	}
+	cfg.Print(os.Stdout)
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalide :\n%v", err)
+	}
+	return nil
 }
 
 func main() {
@@ -42,7 +114,20 @@ func main() {
 		iris.WithConfiguration(iris.Configuration{DisablePathCorrection: true}))
 
 	var cfg config.PreLoRuGoConf
-	logFile, err := cfg.Get(app)
+	args, err := cfg.Load(os.Args[1:])
+	if err != nil {
+		app.Logger().Fatalf("Configuration : %v", err)
+	}
+	if len(args) > 0 && args[0] == "config" {
+		if err = configCommand(&cfg, args[1:]); err != nil {
+			app.Logger().Fatalf("Configuration : %v", err)
+		}
+		return
+	}
+	if err = cfg.Validate(); err != nil {
+		app.Logger().Fatalf("Configuration invalide :\n%v", err)
+	}
+	logFile, err := cfg.Setup(app)
 	if logFile != nil {
 		defer logFile.Close()
 	}
@@ -50,30 +135,57 @@ func main() {
 		app.Logger().Fatalf("Configuration : %v", err)
 	}
 
+	if len(args) > 0 && args[0] == "migrate" {
+		if err = migrate(&cfg, args[1:]); err != nil {
+			app.Logger().Fatalf("Migrations : %v", err)
+		}
+		return
+	}
+
+	listener := config.NewListener(&cfg)
+	logger := config.NewJSONLogger(logFile)
+	models.SetQueryTracer(config.NewQueryTracer(&cfg, logger))
 	db, err := config.InitDatabase(&cfg, app, false, true)
 	if err != nil {
 		app.Logger().Fatalf("Initialisation de la base de données : %v", err)
 	}
 	app.Logger().Infof("Base de données connectée et initialisée")
 	defer db.Close()
+	actions.SetRequestLogger(logger)
+	actions.SetMetricsToken(cfg.App.MetricsToken)
+	actions.SetTokenPolicy(config.NewTokenPolicy(&cfg))
+	models.SetPageSize(cfg.App.PageSize)
+	actions.SetReadinessCheck(config.CheckMigrations)
+	actions.SetMailer(config.NewMailer(&cfg))
+	actions.SetLoginPolicy(config.NewLoginPolicy(&cfg))
+	actions.SetTwoFactorPolicy(config.NewTwoFactorPolicy(&cfg))
+	actions.SetOIDCProvider(config.NewOIDCProvider(&cfg))
+	ingestor, err := config.StartIngestion(&cfg, db, app)
+	if err != nil {
+		app.Logger().Fatalf("Ingestion automatique : %v", err)
+	}
 
 	actions.SetRoutes(app, cfg.Users.SuperAdmin.Email, db)
 	app.StaticWeb("/", "./dist")
 	app.Logger().Infof("Routes et serveur statique configurés")
 
-	// Configure tokens recover and autosave on stop
-	if cfg.App.TokenFileName != "" {
+	// Configure tokens recover and autosave on stop when the in memory token
+	// store is used, otherwise sessions are stored in the database
+	if cfg.App.TokenFileName == "" {
+		actions.SetTokenStore(actions.NewDBTokenStore(db))
+		app.Logger().Infof("Sessions stockées en base de données")
+	} else {
 		actions.TokenRecover(cfg.App.TokenFileName)
-		iris.RegisterOnInterrupt(func() {
-			timeout := 2 * time.Second
-			ctx, cancel := stdContext.WithTimeout(stdContext.Background(), timeout)
-			defer cancel()
-			actions.TokenSave(cfg.App.TokenFileName)
-			app.Shutdown(ctx)
-		})
 		app.Logger().Infof("Fichier de sauvegarde des tokens configuré")
 	}
-	// Run application according to application stage
-	err = run(app, &cfg)
-	app.Logger().Fatalf("Erreur de serveur run %v", err)
+	stopped := make(chan struct{})
+	iris.RegisterOnInterrupt(func() {
+		defer close(stopped)
+		shutdown(app, &cfg, listener, ingestor)
+	})
+	if err = run(app, listener); err != nil {
+		app.Logger().Fatalf("Erreur de serveur run %v", err)
+	}
+	<-stopped
+	app.Logger().Infof("Serveur arrêté")
 }
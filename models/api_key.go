@@ -0,0 +1,147 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every API key so that it can be told apart from a
+	// session token
+	APIKeyPrefix = "plg_"
+	// MaxAPIKeyLifetime is the longest validity of an API key
+	MaxAPIKeyLifetime = 366 * 24 * time.Hour
+	// apiKeyShownLength is the number of characters of the key stored to help
+	// the user identify it
+	apiKeyShownLength = 12
+)
+
+// ErrBadAPIKey is returned when an API key is unknown or expired
+var ErrBadAPIKey = NewKindError(ErrUnauthorized, "Clé d'API invalide ou expirée")
+
+// APIKey model is a personal key used by scripts in place of a session token.
+// The key itself is only known when it is created.
+type APIKey struct {
+	ID       int64     `json:"ID"`
+	UserID   int64     `json:"UserID"`
+	UserName string    `json:"UserName"`
+	Name     string    `json:"Name"`
+	Prefix   string    `json:"Prefix"`
+	ReadOnly bool      `json:"ReadOnly"`
+	Created  time.Time `json:"Created"`
+	Expires  time.Time `json:"Expires"`
+	LastUsed NullTime  `json:"LastUsed"`
+}
+
+// APIKeys embeddes an array of APIKey for json export
+type APIKeys struct {
+	Lines []APIKey `json:"APIKey"`
+}
+
+// Validate checks if fields are correctly formed
+func (k *APIKey) Validate() error {
+	if k.Name == "" || len(k.Name) > 50 {
+		return &ValidationError{Field: "Name", Message: "Champ Name vide ou trop long"}
+	}
+	now := time.Now()
+	if !k.Expires.After(now) || k.Expires.After(now.Add(MaxAPIKeyLifetime)) {
+		return &ValidationError{Field: "Expires", Message: "Champ Expires hors de l'année à venir"}
+	}
+	return nil
+}
+
+// Create inserts a new key for the user and returns it
+func (k *APIKey) Create(db *sql.DB) (string, error) {
+	var count int64
+	if err := db.QueryRow(`SELECT count(1) FROM api_key
+	WHERE user_id=$1 AND name=$2`, k.UserID, k.Name).Scan(&count); err != nil {
+		return "", fmt.Errorf("select count %v", err)
+	}
+	if count != 0 {
+		return "", NewKindError(ErrConflict, "Nom de clé déjà utilisé")
+	}
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("random %v", err)
+	}
+	key := APIKeyPrefix + hex.EncodeToString(b)
+	k.Prefix = key[:apiKeyShownLength]
+	if err := db.QueryRow(`INSERT INTO api_key (user_id,name,prefix,key_hash,
+	read_only,expires) VALUES($1,$2,$3,$4,$5,$6) RETURNING id,created`,
+		k.UserID, k.Name, k.Prefix, hashPasswordToken(key), k.ReadOnly,
+		k.Expires).Scan(&k.ID, &k.Created); err != nil {
+		return "", fmt.Errorf("insert %v", err)
+	}
+	return key, nil
+}
+
+// Delete removes the key. If userID isn't null, the key must belong to this
+// user.
+func (k *APIKey) Delete(db *sql.DB, userID int64) error {
+	res, err := db.Exec(`DELETE FROM api_key WHERE id=$1 AND ($2=0 OR user_id=$2)`,
+		k.ID, userID)
+	if err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected %v", err)
+	}
+	if count != 1 {
+		return notFound("Clé d'API")
+	}
+	return nil
+}
+
+// Authenticate fetches the valid key, records its use and returns the current
+// rights of its user
+func (k *APIKey) Authenticate(db *sql.DB, key string) (rights int64, err error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return 0, ErrBadAPIKey
+	}
+	err = db.QueryRow(`UPDATE api_key k SET last_used=now() FROM users u
+	WHERE k.key_hash=$1 AND k.expires>now() AND u.id=k.user_id
+	RETURNING k.id,k.user_id,u.name,k.name,k.prefix,k.read_only,k.created,
+		k.expires,k.last_used,u.rights`, hashPasswordToken(key)).Scan(&k.ID,
+		&k.UserID, &k.UserName, &k.Name, &k.Prefix, &k.ReadOnly, &k.Created,
+		&k.Expires, &k.LastUsed, &rights)
+	if err == sql.ErrNoRows {
+		return 0, ErrBadAPIKey
+	}
+	if err != nil {
+		return 0, fmt.Errorf("update %v", err)
+	}
+	return rights, nil
+}
+
+// Get fetches the keys of the user, all the keys if userID is null
+func (a *APIKeys) Get(db *sql.DB, userID int64) error {
+	rows, err := db.Query(`SELECT k.id,k.user_id,u.name,k.name,k.prefix,
+	k.read_only,k.created,k.expires,k.last_used
+	FROM api_key k JOIN users u ON k.user_id=u.id
+	WHERE $1=0 OR k.user_id=$1 ORDER BY 3,4`, userID)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row APIKey
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&row.ID, &row.UserID, &row.UserName, &row.Name,
+			&row.Prefix, &row.ReadOnly, &row.Created, &row.Expires,
+			&row.LastUsed); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		a.Lines = append(a.Lines, row)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(a.Lines) == 0 {
+		a.Lines = []APIKey{}
+	}
+	return nil
+}
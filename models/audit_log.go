@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AuditLog model stores a change made to a row of an audited table. Before and
+// After are the JSON of the row and are null respectively for an insert and a
+// delete.
+type AuditLog struct {
+	ID        int64           `json:"ID"`
+	UserID    NullInt64       `json:"UserID"`
+	UserName  NullString      `json:"UserName"`
+	Entity    string          `json:"Entity"`
+	EntityID  NullString      `json:"EntityID"`
+	Operation string          `json:"Operation"`
+	Before    json.RawMessage `json:"Before"`
+	After     json.RawMessage `json:"After"`
+	Created   time.Time       `json:"Created"`
+}
+
+// AuditQuery embeddes the filters of a request to the audit log
+type AuditQuery struct {
+	Page     int64
+	Entity   string
+	EntityID string
+	UserID   NullInt64
+	Begin    NullTime
+	End      NullTime
+}
+
+// PaginatedAuditLogs embeddes the query results of an AuditQuery
+type PaginatedAuditLogs struct {
+	Lines      []AuditLog `json:"AuditLog"`
+	Page       int64      `json:"Page"`
+	ItemsCount int64      `json:"ItemsCount"`
+}
+
+// Get fetches the audit logs matching the query using paginated format
+func (p *PaginatedAuditLogs) Get(db *sql.DB, q *AuditQuery) error {
+	var count int64
+	commonQryPart := ` FROM audit_log a LEFT JOIN users u ON a.user_id=u.id
+	WHERE ($1='' OR a.entity=$1) AND ($2='' OR a.entity_id=$2)
+		AND ($3::int IS NULL OR a.user_id=$3)
+		AND ($4::timestamp IS NULL OR a.created>=$4)
+		AND ($5::timestamp IS NULL OR a.created<$5)`
+	if err := db.QueryRow("SELECT count(1)"+commonQryPart, q.Entity, q.EntityID,
+		q.UserID, q.Begin, q.End).Scan(&count); err != nil {
+		return fmt.Errorf("count %v", err)
+	}
+	offset, newPage := GetPaginateParams(q.Page, count)
+	rows, err := db.Query(`SELECT a.id,a.user_id,u.name,a.entity,a.entity_id,
+	a.operation,a.before,a.after,a.created`+commonQryPart+
+		` ORDER BY a.id DESC LIMIT `+strconv.Itoa(PageSize)+` OFFSET $6`,
+		q.Entity, q.EntityID, q.UserID, q.Begin, q.End, offset)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	defer rows.Close()
+	var before, after []byte
+	for rows.Next() {
+		var row AuditLog
+		if err = rows.Scan(&row.ID, &row.UserID, &row.UserName, &row.Entity,
+			&row.EntityID, &row.Operation, &before, &after,
+			&row.Created); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		row.Before, row.After = before, after
+		p.Lines = append(p.Lines, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(p.Lines) == 0 {
+		p.Lines = []AuditLog{}
+	}
+	p.Page = newPage
+	p.ItemsCount = count
+	return nil
+}
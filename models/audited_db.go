@@ -0,0 +1,291 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// AuditedDB returns a handle on db whose changes are logged with the given
+// user by the audit triggers. The statements use the connections of db, each
+// one being run in a transaction setting the preloru.user_id parameter for
+// this transaction only. The handle must be closed once the request is done.
+func AuditedDB(db *sql.DB, userID int64) *sql.DB {
+	return sql.OpenDB(auditConnector{db: db,
+		userID: strconv.FormatInt(userID, 10)})
+}
+
+// errAuditPrepare is returned when a statement is prepared outside a
+// transaction because it couldn't set the user when it is executed
+var errAuditPrepare = errors.New("préparation de requête hors transaction")
+
+// auditConnector creates the connections of an audited handle
+type auditConnector struct {
+	db     *sql.DB
+	userID string
+}
+
+// Connect implements the driver.Connector interface
+func (a auditConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &auditConn{db: a.db, userID: a.userID}, nil
+}
+
+// Driver implements the driver.Connector interface
+func (a auditConnector) Driver() driver.Driver {
+	return a.db.Driver()
+}
+
+// auditConn forwards the statements to the underlying database, inside the
+// transaction begun on the connection or otherwise in their own one
+type auditConn struct {
+	db     *sql.DB
+	userID string
+	tx     *sql.Tx
+}
+
+// begin starts a transaction on the underlying database setting the user
+func (c *auditConn) begin(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := c.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `SELECT set_config('preloru.user_id',$1,true)`,
+		c.userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Prepare implements the driver.Conn interface
+func (c *auditConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements the driver.ConnPrepareContext interface
+func (c *auditConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.tx == nil {
+		return nil, errAuditPrepare
+	}
+	s, err := c.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return auditStmt{s}, nil
+}
+
+// Close implements the driver.Conn interface
+func (c *auditConn) Close() error {
+	if c.tx != nil {
+		c.tx.Rollback()
+		c.tx = nil
+	}
+	return nil
+}
+
+// Begin implements the driver.Conn interface
+func (c *auditConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements the driver.ConnBeginTx interface
+func (c *auditConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.begin(ctx, &sql.TxOptions{
+		Isolation: sql.IsolationLevel(opts.Isolation), ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	c.tx = tx
+	return auditTx{c}, nil
+}
+
+// CheckNamedValue implements the driver.NamedValueChecker interface and keeps
+// the arguments as is for the underlying database to convert them
+func (c *auditConn) CheckNamedValue(v *driver.NamedValue) error {
+	return nil
+}
+
+// ExecContext implements the driver.ExecerContext interface
+func (c *auditConn) ExecContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Result, error) {
+	if c.tx != nil {
+		return c.tx.ExecContext(ctx, query, namedArgs(args)...)
+	}
+	tx, err := c.begin(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.ExecContext(ctx, query, namedArgs(args)...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// QueryContext implements the driver.QueryerContext interface. Outside a
+// transaction, the one of the query is committed when the rows are closed.
+func (c *auditConn) QueryContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Rows, error) {
+	if c.tx != nil {
+		rows, err := c.tx.QueryContext(ctx, query, namedArgs(args)...)
+		if err != nil {
+			return nil, err
+		}
+		return &auditRows{rows: rows}, nil
+	}
+	tx, err := c.begin(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, query, namedArgs(args)...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &auditRows{rows: rows, tx: tx}, nil
+}
+
+// namedArgs converts the driver arguments to the ones of the database
+func namedArgs(args []driver.NamedValue) []interface{} {
+	a := make([]interface{}, len(args))
+	for i, v := range args {
+		if v.Name != "" {
+			a[i] = sql.Named(v.Name, v.Value)
+		} else {
+			a[i] = v.Value
+		}
+	}
+	return a
+}
+
+// auditTx ends the transaction of the connection
+type auditTx struct {
+	c *auditConn
+}
+
+// Commit implements the driver.Tx interface
+func (t auditTx) Commit() error {
+	tx := t.c.tx
+	t.c.tx = nil
+	return tx.Commit()
+}
+
+// Rollback implements the driver.Tx interface
+func (t auditTx) Rollback() error {
+	tx := t.c.tx
+	t.c.tx = nil
+	return tx.Rollback()
+}
+
+// auditStmt forwards a statement prepared in a transaction
+type auditStmt struct {
+	s *sql.Stmt
+}
+
+// Close implements the driver.Stmt interface
+func (s auditStmt) Close() error {
+	return s.s.Close()
+}
+
+// NumInput implements the driver.Stmt interface
+func (s auditStmt) NumInput() int {
+	return -1
+}
+
+// Exec implements the driver.Stmt interface
+func (s auditStmt) Exec(args []driver.Value) (driver.Result, error) {
+	a := make([]interface{}, len(args))
+	for i, v := range args {
+		a[i] = v
+	}
+	return s.s.Exec(a...)
+}
+
+// Query implements the driver.Stmt interface
+func (s auditStmt) Query(args []driver.Value) (driver.Rows, error) {
+	a := make([]interface{}, len(args))
+	for i, v := range args {
+		a[i] = v
+	}
+	rows, err := s.s.Query(a...)
+	if err != nil {
+		return nil, err
+	}
+	return &auditRows{rows: rows}, nil
+}
+
+// ExecContext implements the driver.StmtExecContext interface
+func (s auditStmt) ExecContext(ctx context.Context,
+	args []driver.NamedValue) (driver.Result, error) {
+	return s.s.ExecContext(ctx, namedArgs(args)...)
+}
+
+// QueryContext implements the driver.StmtQueryContext interface
+func (s auditStmt) QueryContext(ctx context.Context,
+	args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.s.QueryContext(ctx, namedArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return &auditRows{rows: rows}, nil
+}
+
+// CheckNamedValue implements the driver.NamedValueChecker interface
+func (s auditStmt) CheckNamedValue(v *driver.NamedValue) error {
+	return nil
+}
+
+// auditRows forwards the rows of the underlying database and commits the
+// transaction of the query if it has its own one
+type auditRows struct {
+	rows *sql.Rows
+	tx   *sql.Tx
+}
+
+// Columns implements the driver.Rows interface
+func (r *auditRows) Columns() []string {
+	cols, _ := r.rows.Columns()
+	return cols
+}
+
+// Close implements the driver.Rows interface
+func (r *auditRows) Close() error {
+	err := r.rows.Close()
+	if r.tx == nil {
+		return err
+	}
+	if err != nil {
+		r.tx.Rollback()
+		return err
+	}
+	return r.tx.Commit()
+}
+
+// Next implements the driver.Rows interface
+func (r *auditRows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	values := make([]interface{}, len(dest))
+	pointers := make([]interface{}, len(dest))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return err
+	}
+	for i, v := range values {
+		dest[i] = v
+	}
+	return nil
+}
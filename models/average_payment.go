@@ -19,7 +19,22 @@ type AveragePayments struct {
 }
 
 // GetAll fetches the cumulated payment rates of each month of the past years
-func (a *AveragePayments) GetAll(db *sql.DB) (err error) {
+func (a *AveragePayments) GetAll(db *sql.DB) error {
+	v, err := aggregates.get(&averagePaymentsCache, "",
+		func() (interface{}, error) {
+			var r AveragePayments
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*a = v.(AveragePayments)
+	return nil
+}
+
+// fetch computes the cumulated payment rates of each month of the past years
+func (a *AveragePayments) fetch(db *sql.DB) (err error) {
 	rows, err := db.Query(`WITH 
 		q as (SELECT m,SUM(v) OVER (ORDER BY m) FROM
     	(SELECT EXTRACT(month FROM creation_date)::int m,SUM(value)::bigint v
@@ -20,6 +20,20 @@ type AvgPmtTimes struct {
 
 // GetAll fetches the average payments times of the past 12 monthes
 func (a *AvgPmtTimes) GetAll(db *sql.DB) error {
+	v, err := aggregates.get(&avgPmtTimesCache, "", func() (interface{}, error) {
+		var r AvgPmtTimes
+		err := r.fetch(db)
+		return r, err
+	})
+	if err != nil {
+		return err
+	}
+	*a = v.(AvgPmtTimes)
+	return nil
+}
+
+// fetch computes the average payments times of the past 12 monthes
+func (a *AvgPmtTimes) fetch(db *sql.DB) error {
 	rows, err := db.Query(`SELECT m.d,AVG(p.creation_date-p.receipt_date),
 	stddev_samp(p.creation_date-p.receipt_date) 
 	FROM payment p,
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// BatchRowError is a validation error of a line of a batch. Line starts at 1.
+type BatchRowError struct {
+	Line    int    `json:"Line"`
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
+
+// BatchReport gives the result of a batch import. The counts are those of the
+// main table of the batch. Unchanged is the count of lines that neither
+// inserted nor updated a row.
+type BatchReport struct {
+	DryRun    bool            `json:"DryRun"`
+	Lines     int64           `json:"Lines"`
+	Inserted  int64           `json:"Inserted"`
+	Updated   int64           `json:"Updated"`
+	Deleted   int64           `json:"Deleted"`
+	Unchanged int64           `json:"Unchanged"`
+	Errors    []BatchRowError `json:"Errors"`
+	fileName  string
+}
+
+// BatchOptions defines how a batch is saved. If DryRun is set, the batch is
+// only checked and rolled back. FileName is the name of the uploaded file,
+// empty if the batch was sent as JSON.
+type BatchOptions struct {
+	DryRun   bool
+	FileName string
+}
+
+// ErrBatchInvalid is returned when some lines of the batch are incorrect, the
+// details being given by the errors of the report
+var ErrBatchInvalid = errors.New("lignes incorrectes")
+
+// newBatchReport creates an empty report for a batch of the given lines count
+func newBatchReport(opt BatchOptions, lines int) *BatchReport {
+	return &BatchReport{DryRun: opt.DryRun, Lines: int64(lines),
+		Errors: []BatchRowError{}, fileName: opt.FileName}
+}
+
+// addError appends the validation error of a line. index is the one of the
+// line in the batch, starting at 0.
+func (r *BatchReport) addError(index int, field string, message string) {
+	r.Errors = append(r.Errors,
+		BatchRowError{Line: index + 1, Field: field, Message: message})
+}
+
+// tableStats fetches the count of rows inserted, updated and deleted in the
+// table since the beginning of the transaction
+func tableStats(tx *sql.Tx, table string) (ins, upd, del int64, err error) {
+	err = tx.QueryRow(`SELECT n_tup_ins,n_tup_upd,n_tup_del
+	FROM pg_stat_xact_user_tables WHERE schemaname='public' AND relname=$1`,
+		table).Scan(&ins, &upd, &del)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return ins, upd, del, err
+}
+
+// run launches the import function in a transaction if the lines are valid,
+// computes the counts of the report using the table statistics and commits
+// the transaction or rolls it back if it's a dry run. The import is recorded
+// as an import run with the rows it changed and the cached aggregates
+// computed from the changed tables are invalidated.
+func (r *BatchReport) run(db *sql.DB, table string, f func(tx *sql.Tx) error) error {
+	if len(r.Errors) > 0 {
+		return ErrBatchInvalid
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	ins, upd, del, err := tableStats(tx, table)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("stats %v", err)
+	}
+	runID, err := beginImportRun(tx, table, r.fileName)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if r.Inserted, r.Updated, r.Deleted, err = tableStats(tx, table); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("stats %v", err)
+	}
+	r.Inserted -= ins
+	r.Updated -= upd
+	r.Deleted -= del
+	if r.Unchanged = r.Lines - r.Inserted - r.Updated; r.Unchanged < 0 {
+		r.Unchanged = 0
+	}
+	tables, err := endImportRun(tx, runID, r)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if r.DryRun {
+		return tx.Rollback()
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit %v", err)
+	}
+	invalidateCache(append(tables, table)...)
+	return nil
+}
+
+// execQueries launches the queries of a batch import
+func execQueries(tx *sql.Tx, queries []string) error {
+	for i, q := range queries {
+		if _, err := tx.Exec(q); err != nil {
+			return fmt.Errorf("requête %d : %v", i, err)
+		}
+	}
+	return nil
+}
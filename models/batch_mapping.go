@@ -0,0 +1,294 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnKind defines how the cells of a column of an uploaded file are
+// converted into the JSON value of the batch line field
+type ColumnKind int
+
+const (
+	// ColText is a text. An empty cell gives null.
+	ColText ColumnKind = iota
+	// ColInt is an integer that may use the French number format
+	ColInt
+	// ColFloat is a decimal number that may use the French number format
+	ColFloat
+	// ColBool accepts oui/non, vrai/faux, o/n, x, 1/0 and true/false
+	ColBool
+	// ColDateYMD is a date converted to an integer using the YYYYMMDD format
+	ColDateYMD
+	// ColDateExcel is a date converted to an Excel serial number
+	ColDateExcel
+	// ColTime is a date converted to a time
+	ColTime
+)
+
+// BatchColumn defines a field of a batch line and the headers that can be
+// used in a file for that field, the field name being always accepted. The
+// comparison of headers ignores case, accents, spaces and punctuation.
+type BatchColumn struct {
+	Field   string
+	Kind    ColumnKind
+	Aliases []string
+}
+
+// BatchMapping defines how a XLSX or CSV file is converted into a batch. Key
+// is the JSON name of the lines of the batch and Params are the other fields
+// of the batch that are given by form values.
+type BatchMapping struct {
+	Key     string
+	Columns []BatchColumn
+	Params  []BatchColumn
+}
+
+// UploadableBatch is implemented by the batches that can be imported using a
+// XLSX or CSV file
+type UploadableBatch interface {
+	Mapping() *BatchMapping
+}
+
+var accentReplacer = strings.NewReplacer("à", "a", "â", "a", "ä", "a", "ç", "c",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "î", "i", "ï", "i", "ô", "o", "ö", "o",
+	"ù", "u", "û", "u", "ü", "u", "œ", "oe")
+
+// normalizeHeader returns the header in lower case without accents and
+// keeping only letters and digits
+func normalizeHeader(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(s))
+	var n strings.Builder
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			n.WriteRune(r)
+		}
+	}
+	return n.String()
+}
+
+// parseNumber parses a number using either the French or the English format.
+// Spaces are ignored and a comma is a decimal separator unless both commas
+// and dots are used.
+func parseNumber(s string) (float64, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '€', '%':
+			return -1
+		}
+		return r
+	}, s)
+	if strings.Contains(s, ",") {
+		if strings.Contains(s, ".") {
+			if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+				s = strings.Replace(s, ".", "", -1)
+				s = strings.Replace(s, ",", ".", 1)
+			} else {
+				s = strings.Replace(s, ",", "", -1)
+			}
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// dateLayouts are the accepted formats for a textual date
+var dateLayouts = []string{"02/01/2006", "2/1/2006", "02/01/2006 15:04:05",
+	"02/01/2006 15:04", "2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+// parseDate parses a date given as an Excel serial number, an integer using
+// the YYYYMMDD format or a text using one of the accepted layouts
+func parseDate(s string) (time.Time, error) {
+	if len(s) == 8 && strings.Trim(s, "0123456789") == "" {
+		return time.Parse("20060102", s)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if f < 0 || f >= 1e6 {
+			return time.Time{}, fmt.Errorf("numéro de série hors limites")
+		}
+		return b.Add(time.Duration(math.Round(f*86400)) * time.Second), nil
+	}
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("format de date inconnu")
+}
+
+// parseBool parses the usual French and English booleans
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "o", "oui", "x", "vrai", "true", "y", "yes":
+		return true, nil
+	case "0", "n", "non", "faux", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("booléen inconnu")
+}
+
+// value converts the text of a cell according to the kind of the column. An
+// empty cell gives nil.
+func (c *BatchColumn) value(s string) (interface{}, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	if c.Kind != ColText {
+		s = strings.TrimSpace(s)
+	}
+	switch c.Kind {
+	case ColInt:
+		f, err := parseNumber(s)
+		if err != nil {
+			return nil, err
+		}
+		return int64(math.Round(f)), nil
+	case ColFloat:
+		return parseNumber(s)
+	case ColBool:
+		return parseBool(s)
+	case ColDateYMD, ColDateExcel, ColTime:
+		t, err := parseDate(s)
+		if err != nil {
+			return nil, err
+		}
+		switch c.Kind {
+		case ColDateYMD:
+			return t.Year()*10000 + int(t.Month())*100 + t.Day(), nil
+		case ColDateExcel:
+			return int64(t.Sub(b).Hours() / 24), nil
+		}
+		return t, nil
+	}
+	return s, nil
+}
+
+// matches returns true if the header designates the column
+func (c *BatchColumn) matches(header string) bool {
+	h := normalizeHeader(header)
+	if h == "" {
+		return false
+	}
+	if h == normalizeHeader(c.Field) {
+		return true
+	}
+	for _, a := range c.Aliases {
+		if h == normalizeHeader(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyRow returns true if all the cells of the row are blank
+func isEmptyRow(row []string) bool {
+	return strings.TrimSpace(strings.Join(row, "")) == ""
+}
+
+// headerIndex returns the index of the first non empty row that gives the
+// headers of the file
+func headerIndex(rows [][]string) (int, error) {
+	for i, row := range rows {
+		if !isEmptyRow(row) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("fichier vide")
+}
+
+// matchCount returns the count of headers that designate a column of the
+// mapping and whether the required fields are all found
+func (m *BatchMapping) matchCount(headers []string, required []string) (int, bool) {
+	count, found := 0, map[string]bool{}
+	for _, h := range headers {
+		for j := range m.Columns {
+			if m.Columns[j].matches(h) {
+				count++
+				found[m.Columns[j].Field] = true
+				break
+			}
+		}
+	}
+	for _, f := range required {
+		if !found[f] {
+			return count, false
+		}
+	}
+	return count, true
+}
+
+// DecodeBatchFile reads the XLSX or CSV file whose name is used to find the
+// format, converts its lines using the mapping of the batch and unmarshals
+// them into the batch. The first non empty line of the file gives the headers
+// and the columns that don't match any field are ignored. params gives the
+// values of the parameters of the mapping.
+func DecodeBatchFile(name string, r io.ReaderAt, size int64,
+	params map[string]string, batch UploadableBatch) error {
+	m := batch.Mapping()
+	rows, err := readSpreadsheet(name, r, size)
+	if err != nil {
+		return err
+	}
+	headerIdx, err := headerIndex(rows)
+	if err != nil {
+		return err
+	}
+	columns := make([]*BatchColumn, len(rows[headerIdx]))
+	found := false
+	for i, h := range rows[headerIdx] {
+		for j := range m.Columns {
+			if m.Columns[j].matches(h) {
+				columns[i], found = &m.Columns[j], true
+				break
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("aucune colonne reconnue dans l'entête")
+	}
+	lines := []map[string]interface{}{}
+	for i, row := range rows[headerIdx+1:] {
+		if isEmptyRow(row) {
+			continue
+		}
+		line := make(map[string]interface{}, len(m.Columns))
+		for j, cell := range row {
+			if j >= len(columns) || columns[j] == nil {
+				continue
+			}
+			v, err := columns[j].value(cell)
+			if err != nil {
+				return fmt.Errorf("ligne %d, colonne %s, valeur %q : %v",
+					headerIdx+i+2, rows[headerIdx][j], cell, err)
+			}
+			line[columns[j].Field] = v
+		}
+		lines = append(lines, line)
+	}
+	content := map[string]interface{}{m.Key: lines}
+	for i := range m.Params {
+		p := &m.Params[i]
+		v, err := p.value(params[p.Field])
+		if err != nil {
+			return fmt.Errorf("paramètre %s, valeur %q : %v", p.Field,
+				params[p.Field], err)
+		}
+		if v != nil {
+			content[p.Field] = v
+		}
+	}
+	j, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("conversion json %v", err)
+	}
+	if err = json.Unmarshal(j, batch); err != nil {
+		return fmt.Errorf("décodage json %v", err)
+	}
+	return nil
+}
@@ -48,21 +48,35 @@ func (b *Beneficiaries) GetAll(db *sql.DB) (err error) {
 	return err
 }
 
+// beneficiaryQueryFields are the fields of the paginated beneficiaries that
+// can be used to sort or filter them
+var beneficiaryQueryFields = queryFields{
+	"ID":   {"b.id", idField},
+	"Code": {"b.code", numberField},
+	"Name": {"b.name", textField},
+}
+
 // Get fetches beneficiaries from database according to PaginatedQuery where
-// only Page and Search fields are used
+// the Year field is not used
 func (p *PaginatedBeneficiaries) Get(db *sql.DB, q *PaginatedQuery) error {
 	var count int64
-	if err := db.QueryRow(`SELECT count(1) FROM beneficiary 
-		WHERE name ILIKE $1 OR code::varchar ILIKE $1`, "%"+q.Search+"%").
+	filters, order, args, err := q.clauses(beneficiaryQueryFields, "2,1",
+		[]interface{}{"%" + q.Search + "%"})
+	if err != nil {
+		return err
+	}
+	if err := db.QueryRow(`SELECT count(1) FROM beneficiary b
+		WHERE (name ILIKE $1 OR code::varchar ILIKE $1)`+filters, args...).
 		Scan(&count); err != nil {
 		return errors.New("count query failed " + err.Error())
 	}
-	offset, newPage := GetPaginateParams(q.Page, count)
+	size := q.size()
+	offset, newPage := getPageParams(q.Page, count, size)
 
 	rows, err := db.Query(`SELECT id,code,name FROM beneficiary b
-	WHERE name ILIKE $1 OR code::varchar ILIKE $1
-	ORDER BY 2,1 LIMIT `+strconv.Itoa(PageSize)+` OFFSET $2`,
-		"%"+q.Search+"%", offset)
+	WHERE (name ILIKE $1 OR code::varchar ILIKE $1)`+filters+`
+	ORDER BY `+order+` LIMIT `+strconv.FormatInt(size, 10)+` OFFSET $`+
+		strconv.Itoa(len(args)+1), append(args, offset)...)
 	if err != nil {
 		return err
 	}
@@ -86,7 +100,7 @@ func (p *PaginatedBeneficiaries) Get(db *sql.DB, q *PaginatedQuery) error {
 // Validate checks if the fields matches the database constraints
 func (b *Beneficiary) Validate() error {
 	if b.Name == "" {
-		return errors.New("name vide")
+		return &ValidationError{Field: "Name", Message: "name vide"}
 	}
 	return nil
 }
@@ -109,7 +123,7 @@ func (b *Beneficiary) Update(db *sql.DB) error {
 		return fmt.Errorf("rowsaffected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("bénéficiaire introuvable")
+		return notFound("bénéficiaire")
 	}
 	return nil
 }
@@ -125,7 +139,7 @@ func (b *Beneficiary) Delete(db *sql.DB) error {
 		return fmt.Errorf("rowsaffected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("bénéficiaire introuvable")
+		return notFound("bénéficiaire")
 	}
 	return nil
 }
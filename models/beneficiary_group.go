@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -23,7 +22,7 @@ type BeneficiaryGroups struct {
 // Valid checks if fields are correctly filled
 func (b *BeneficiaryGroup) Valid() error {
 	if b.Name == "" {
-		return errors.New("name vide")
+		return &ValidationError{Field: "Name", Message: "name vide"}
 	}
 	return nil
 }
@@ -72,7 +71,7 @@ func (b *BeneficiaryGroup) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", res)
 	}
 	if count != 1 {
-		return fmt.Errorf("groupe non trouvé")
+		return NewKindError(ErrNotFound, "groupe non trouvé")
 	}
 	return nil
 }
@@ -90,7 +89,7 @@ func (b *BeneficiaryGroup) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", res)
 	}
 	if count != 1 {
-		return fmt.Errorf("groupe non trouvé")
+		return NewKindError(ErrNotFound, "groupe non trouvé")
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 )
 
 //BudgetAction model
@@ -21,10 +20,10 @@ type BudgetActions struct {
 // Validate checks if the fields of a budget action are correctly filled
 func (b *BudgetAction) Validate() error {
 	if b.Code == 0 {
-		return errors.New("Champ code incorrect")
+		return &ValidationError{Field: "Code", Message: "Champ code incorrect"}
 	}
 	if b.Name == "" {
-		return errors.New("Champ name incorrect")
+		return &ValidationError{Field: "Name", Message: "Champ name incorrect"}
 	}
 	return nil
 }
@@ -48,7 +47,7 @@ func (b *BudgetAction) Update(db *sql.DB) (err error) {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Action budgétaire introuvable")
+		return notFound("Action budgétaire")
 	}
 	return err
 }
@@ -85,7 +84,7 @@ func (b *BudgetAction) Delete(db *sql.DB) (err error) {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Action budgétaire introuvable")
+		return notFound("Action budgétaire")
 	}
 	return nil
 }
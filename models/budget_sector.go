@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 )
 
 // BudgetSector model
@@ -20,7 +19,7 @@ type BudgetSectors struct {
 // Validate checks if BudgetSector's fields are correctly filled
 func (b *BudgetSector) Validate() error {
 	if b.Name == "" {
-		return errors.New("Champ incorrect")
+		return &ValidationError{Field: "Name", Message: "Champ incorrect"}
 	}
 	return nil
 }
@@ -54,7 +53,7 @@ func (b *BudgetSector) Update(db *sql.DB) (err error) {
 		return err
 	}
 	if count != 1 {
-		return errors.New("ID introuvable")
+		return notFound("ID")
 	}
 	return err
 }
@@ -91,7 +90,7 @@ func (b *BudgetSector) Delete(db *sql.DB) (err error) {
 		return err
 	}
 	if count != 1 {
-		return errors.New("ID introuvable")
+		return notFound("ID")
 	}
 	return nil
 }
@@ -0,0 +1,305 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheSource is a table, or the change of day, whose modification makes the
+// cached aggregates computed from it obsolete
+type cacheSource string
+
+// daySource is used by the aggregates depending on the current date
+const daySource cacheSource = "day"
+
+// Times to live of the cached aggregates, limiting the effect of the changes
+// that are not reported by an invalidation
+const (
+	homeCacheTTL   = time.Hour
+	reportCacheTTL = 15 * time.Minute
+)
+
+// maxCacheEntries limits the number of entries of the cache, the reports being
+// cached for each combination of their parameters
+const maxCacheEntries = 1000
+
+// cacheDef defines a cached aggregate. The name is used for the keys of the
+// entries and for the metrics.
+type cacheDef struct {
+	name    string
+	ttl     time.Duration
+	sources []cacheSource
+}
+
+// Cached aggregates with their dependencies
+var (
+	twoYearsCommitmentsCache = cacheDef{"two_years_commitments", homeCacheTTL,
+		[]cacheSource{"commitment", daySource}}
+	twoYearsPaymentsCache = cacheDef{"two_years_payments", homeCacheTTL,
+		[]cacheSource{"payment", "commitment", daySource}}
+	cumulatedProgrammationCache = cacheDef{"cumulated_programmation",
+		homeCacheTTL, []cacheSource{"prog", "commission", daySource}}
+	averagePaymentsCache = cacheDef{"average_payments", homeCacheTTL,
+		[]cacheSource{"payment", daySource}}
+	paymentCreditSumCache = cacheDef{"payment_credit_sum", homeCacheTTL,
+		[]cacheSource{"payment_credit", daySource}}
+	csfWeekTrendCache = cacheDef{"csf_week_trend", homeCacheTTL,
+		[]cacheSource{"payment_demands", daySource}}
+	flowStockDelaysCache = cacheDef{"flow_stock_delays", homeCacheTTL,
+		[]cacheSource{"payment_demands", "payment", daySource}}
+	paymentRateCache = cacheDef{"payment_rate", homeCacheTTL,
+		[]cacheSource{"payment", "payment_credit", daySource}}
+	avgPmtTimesCache = cacheDef{"average_payment_times", homeCacheTTL,
+		[]cacheSource{"payment", daySource}}
+	dptReportCache = cacheDef{"department_report", reportCacheTTL,
+		[]cacheSource{"commitment", "payment", "copro", "housing", "renew_project",
+			"rp_cmt_city_join", "city", "community", "department"}}
+	cityReportCache = cacheDef{"city_report", reportCacheTTL,
+		[]cacheSource{"commitment", "payment", "copro", "housing", "renew_project",
+			"rp_cmt_city_join", "city"}}
+	coproReportCache = cacheDef{"copro_report", reportCacheTTL,
+		[]cacheSource{"commitment", "copro", "copro_forecast", "prog", "commission",
+			"city", daySource}}
+	rpMultiAnnualReportCache = cacheDef{"renew_project_multi_annual_report",
+		reportCacheTTL, []cacheSource{"commitment", "renew_project",
+			"renew_project_forecast", "prog", "commission", "city", daySource}}
+	renewProjectReportCache = cacheDef{"renew_project_report", reportCacheTTL,
+		[]cacheSource{"commitment", "payment", "renew_project", "rp_cmt_city_join",
+			"rp_event", "city", "community"}}
+)
+
+// cacheEntry is a computed aggregate with the versions of its sources at the
+// time of the computation
+type cacheEntry struct {
+	def      *cacheDef
+	value    interface{}
+	expires  time.Time
+	versions []uint64
+}
+
+// cacheCounters are the metrics of a cached aggregate
+type cacheCounters struct {
+	hits          int64
+	misses        int64
+	invalidations int64
+	expirations   int64
+}
+
+// aggregateCache stores the computed aggregates. An entry is valid until its
+// time to live is reached or one of its sources is invalidated, the version
+// of each source being incremented by the invalidation.
+type aggregateCache struct {
+	mutex    sync.RWMutex
+	versions map[cacheSource]uint64
+	entries  map[string]*cacheEntry
+	counters map[string]*cacheCounters
+}
+
+// aggregates is the cache used by the models
+var aggregates = newAggregateCache()
+
+// stopDayInvalidation ends the invalidation of the aggregates depending on
+// the current date, stopDayOnce ensuring it's closed once
+var (
+	stopDayInvalidation = make(chan struct{})
+	stopDayOnce         sync.Once
+)
+
+func init() {
+	go invalidateEveryDay()
+}
+
+// StopCacheInvalidation ends the background invalidation of the aggregates
+// depending on the current date, used when the server stops
+func StopCacheInvalidation() {
+	stopDayOnce.Do(func() { close(stopDayInvalidation) })
+}
+
+// newAggregateCache returns an empty cache
+func newAggregateCache() *aggregateCache {
+	return &aggregateCache{versions: make(map[cacheSource]uint64),
+		entries:  make(map[string]*cacheEntry),
+		counters: make(map[string]*cacheCounters)}
+}
+
+// invalidateEveryDay invalidates the aggregates depending on the current date
+// just after midnight until stopped
+func invalidateEveryDay() {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 10, 0,
+			time.Local)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stopDayInvalidation:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		aggregates.invalidate(daySource)
+	}
+}
+
+// invalidateCache marks the tables as modified so that the aggregates
+// computed from them are computed again
+func invalidateCache(tables ...string) {
+	sources := make([]cacheSource, len(tables))
+	for i, t := range tables {
+		sources[i] = cacheSource(t)
+	}
+	aggregates.invalidate(sources...)
+}
+
+// invalidate increments the versions of the sources
+func (c *aggregateCache) invalidate(sources ...cacheSource) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, s := range sources {
+		c.versions[s]++
+	}
+}
+
+// sourceVersions returns the current versions of the sources of a definition.
+// The read lock must be held.
+func (c *aggregateCache) sourceVersions(def *cacheDef) []uint64 {
+	versions := make([]uint64, len(def.sources))
+	for i, s := range def.sources {
+		versions[i] = c.versions[s]
+	}
+	return versions
+}
+
+// counter returns the metrics of a definition. The write lock must be held.
+func (c *aggregateCache) counter(def *cacheDef) *cacheCounters {
+	cnt, ok := c.counters[def.name]
+	if !ok {
+		cnt = &cacheCounters{}
+		c.counters[def.name] = cnt
+	}
+	return cnt
+}
+
+// get returns the cached value of the aggregate for the parameters or
+// computes and stores it. The returned value is shared between the requests
+// and must not be modified.
+func (c *aggregateCache) get(def *cacheDef, params string,
+	compute func() (interface{}, error)) (interface{}, error) {
+	key := def.name + "|" + params
+	c.mutex.RLock()
+	entry, ok := c.entries[key]
+	versions := c.sourceVersions(def)
+	c.mutex.RUnlock()
+	valid := ok && time.Now().Before(entry.expires) &&
+		equalVersions(entry.versions, versions)
+	c.mutex.Lock()
+	cnt := c.counter(def)
+	switch {
+	case valid:
+		cnt.hits++
+	case ok && !equalVersions(entry.versions, versions):
+		cnt.invalidations++
+		cnt.misses++
+	case ok:
+		cnt.expirations++
+		cnt.misses++
+	default:
+		cnt.misses++
+	}
+	c.mutex.Unlock()
+	if valid {
+		return entry.value, nil
+	}
+	value, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		c.purge()
+	}
+	c.entries[key] = &cacheEntry{def: def, value: value,
+		expires: time.Now().Add(def.ttl), versions: versions}
+	return value, nil
+}
+
+// purge removes the expired or invalidated entries and, if the cache is still
+// full, the entries expiring first. The write lock must be held.
+func (c *aggregateCache) purge() {
+	now := time.Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) ||
+			!equalVersions(e.versions, c.sourceVersions(e.def)) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) < maxCacheEntries {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].expires.Before(c.entries[keys[j]].expires)
+	})
+	for _, k := range keys[:len(keys)-maxCacheEntries/2] {
+		delete(c.entries, k)
+	}
+}
+
+// equalVersions returns true if the source versions are the same
+func equalVersions(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CacheStat gives the metrics of a cached aggregate. Invalidations and
+// Expirations are the misses due to a modification of a source or to the time
+// to live.
+type CacheStat struct {
+	Name          string  `json:"Name"`
+	Entries       int64   `json:"Entries"`
+	Hits          int64   `json:"Hits"`
+	Misses        int64   `json:"Misses"`
+	Invalidations int64   `json:"Invalidations"`
+	Expirations   int64   `json:"Expirations"`
+	HitRate       float64 `json:"HitRate"`
+}
+
+// CacheStats embeddes an array of CacheStat for json export
+type CacheStats struct {
+	Lines []CacheStat `json:"CacheStat"`
+}
+
+// Get fetches the metrics of the cached aggregates sorted by name
+func (c *CacheStats) Get() {
+	aggregates.mutex.RLock()
+	defer aggregates.mutex.RUnlock()
+	entries := make(map[string]int64)
+	for k := range aggregates.entries {
+		entries[k[:strings.Index(k, "|")]]++
+	}
+	c.Lines = []CacheStat{}
+	for name, cnt := range aggregates.counters {
+		s := CacheStat{Name: name, Entries: entries[name], Hits: cnt.hits,
+			Misses: cnt.misses, Invalidations: cnt.invalidations,
+			Expirations: cnt.expirations}
+		if total := cnt.hits + cnt.misses; total > 0 {
+			s.HitRate = float64(cnt.hits) / float64(total)
+		}
+		c.Lines = append(c.Lines, s)
+	}
+	sort.Slice(c.Lines, func(i, j int) bool {
+		return c.Lines[i].Name < c.Lines[j].Name
+	})
+}
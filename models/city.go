@@ -36,6 +36,22 @@ type CityBatch struct {
 	Lines []CityLine `json:"City"`
 }
 
+// cityMapping defines the columns of the XLSX or CSV file of a city batch
+var cityMapping = BatchMapping{
+	Key: "City",
+	Columns: []BatchColumn{
+		{"InseeCode", ColInt, []string{"Code INSEE", "INSEE", "Code commune"}},
+		{"Name", ColText, []string{"Nom", "Commune", "Ville", "Nom commune"}},
+		{"CommunityCode", ColText, []string{"Code interco", "Code EPCI", "SIREN EPCI", "EPCI"}},
+		{"QPV", ColBool, []string{"Quartier prioritaire"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (c *CityBatch) Mapping() *BatchMapping {
+	return &cityMapping
+}
+
 // PaginatedCity is used to fetch a city with community full name
 type PaginatedCity struct {
 	InseeCode     int64      `json:"InseeCode"`
@@ -54,8 +70,11 @@ type PaginatedCities struct {
 
 // Validate checks if City's fields are correctly filled
 func (c *City) Validate() error {
-	if c.InseeCode == 0 || c.Name == "" {
-		return errors.New("Champ incorrect")
+	if c.InseeCode == 0 {
+		return &ValidationError{Field: "InseeCode", Message: "Champ incorrect"}
+	}
+	if c.Name == "" {
+		return &ValidationError{Field: "Name", Message: "Champ incorrect"}
 	}
 	return nil
 }
@@ -67,6 +86,7 @@ func (c *City) Create(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("city")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
@@ -100,12 +120,13 @@ func (c *City) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("city")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Ville introuvable")
+		return notFound("Ville")
 	}
 	if c.CommunityID.Valid {
 		err = db.QueryRow(`SELECT name FROM community WHERE id=$1`, c.CommunityID).
@@ -144,61 +165,54 @@ func (c *City) Delete(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("city")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Ville introuvable")
+		return notFound("Ville")
 	}
 	return nil
 }
 
 // Save insert a batch of CityLine into database
-func (c *CityBatch) Save(db *sql.DB) (err error) {
+func (c *CityBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(c.Lines))
 	for i, r := range c.Lines {
-		if r.InseeCode == 0 || r.Name == "" {
-			return fmt.Errorf("Ligne %d, champ incorrect", i+1)
+		if r.InseeCode == 0 {
+			report.addError(i, "InseeCode", "code INSEE nul")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_city", "insee_code", "name",
-		"community_code", "qpv"))
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-	for _, r := range c.Lines {
-		if _, err = stmt.Exec(r.InseeCode, r.Name, r.CommunityCode, r.QPV); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+		if r.Name == "" {
+			report.addError(i, "Name", "nom vide")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement exec flush %v", err)
-	}
-	queries := []string{`UPDATE city SET name=q.name,community_id=q.id,qpv=q.qpv 
+	return report, report.run(db, "city", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_city", "insee_code", "name",
+			"community_code", "qpv"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		for _, r := range c.Lines {
+			if _, err = stmt.Exec(r.InseeCode, r.Name, r.CommunityCode, r.QPV); err != nil {
+				return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement exec flush %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE city SET name=q.name,community_id=q.id,qpv=q.qpv 
 	FROM (SELECT t.*, c.id FROM temp_city t 
 					LEFT JOIN community c ON t.community_code = c.code) q 
 	WHERE q.insee_code = city.insee_code`,
-		`INSERT INTO city (insee_code,name,community_id,qpv)
+			`INSERT INTO city (insee_code,name,community_id,qpv)
 	SELECT t.insee_code,t.name,c.id,t.qpv from temp_city t 
 		LEFT JOIN community c ON t.community_code = c.code
 	WHERE insee_code NOT IN (SELECT DISTINCT insee_code from city)`,
-		`DELETE FROM temp_city`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE FROM temp_city`,
+		})
+	})
 }
 
 // Get fetches all cities that matches the search pattern
@@ -20,7 +20,24 @@ type CityReport struct {
 }
 
 // GetAll fetches commitments and payments per policy and year in a city
-func (c *CityReport) GetAll(db *sql.DB, inseeCode, firstYear, lastYear int64) (err error) {
+func (c *CityReport) GetAll(db *sql.DB, inseeCode, firstYear, lastYear int64) error {
+	v, err := aggregates.get(&cityReportCache,
+		fmt.Sprintf("%d|%d|%d", inseeCode, firstYear, lastYear),
+		func() (interface{}, error) {
+			var r CityReport
+			err := r.fetch(db, inseeCode, firstYear, lastYear)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*c = v.(CityReport)
+	return nil
+}
+
+// fetch computes the city report from database
+func (c *CityReport) fetch(db *sql.DB, inseeCode, firstYear,
+	lastYear int64) (err error) {
 	qry := fmt.Sprintf(`WITH
 	housingCmt AS (SELECT cmt.year,SUM(cmt.value) cmt
 		FROM cumulated_commitment cmt
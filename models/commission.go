@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 )
 
 // Commission model
@@ -20,7 +19,7 @@ type Commissions struct {
 // Validate checks if Commission's fields are correctly filled
 func (c *Commission) Validate() error {
 	if c.Name == "" {
-		return errors.New("Champ name vide")
+		return &ValidationError{Field: "Name", Message: "Champ name vide"}
 	}
 	return nil
 }
@@ -39,6 +38,7 @@ func (c *Commission) Get(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("commission")
 	return nil
 }
 
@@ -49,12 +49,13 @@ func (c *Commission) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("commission")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Commission introuvable")
+		return notFound("Commission")
 	}
 	return err
 }
@@ -86,12 +87,13 @@ func (c *Commission) Delete(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("commission")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Commission introuvable")
+		return notFound("Commission")
 	}
 	return nil
 }
@@ -84,12 +84,33 @@ type CommitmentBatch struct {
 	Lines []CommitmentLine `json:"Commitment"`
 }
 
-// PaginatedQuery embeddes the request to fetch some commitments from database
-// according to the given pattern
-type PaginatedQuery struct {
-	Page   int64  `json:"Page"`
-	Year   int64  `json:"Year"`
-	Search string `json:"Search"`
+// commitmentMapping defines the columns of the XLSX or CSV file of a commitment
+// batch
+var commitmentMapping = BatchMapping{
+	Key: "Commitment",
+	Columns: []BatchColumn{
+		{"Year", ColInt, []string{"Exercice", "Année"}},
+		{"Code", ColText, []string{"Code engagement", "Code EJ"}},
+		{"Number", ColInt, []string{"Numéro", "N° engagement", "Numéro engagement"}},
+		{"Line", ColInt, []string{"Ligne", "N° ligne"}},
+		{"CreationDate", ColDateYMD, []string{"Date de création", "Date création"}},
+		{"ModificationDate", ColDateYMD, []string{"Date de modification", "Date modification"}},
+		{"CaducityDate", ColDateYMD, []string{"Date de caducité", "Date caducité"}},
+		{"Name", ColText, []string{"Objet", "Libellé", "Nom"}},
+		{"Value", ColInt, []string{"Montant", "Montant engagé"}},
+		{"SoldOut", ColText, []string{"Soldé"}},
+		{"BeneficiaryCode", ColInt, []string{"Code bénéficiaire", "Code tiers"}},
+		{"BeneficiaryName", ColText, []string{"Bénéficiaire", "Nom bénéficiaire", "Tiers"}},
+		{"IrisCode", ColText, []string{"Code IRIS", "N° IRIS"}},
+		{"Sector", ColText, []string{"Secteur"}},
+		{"ActionCode", ColInt, []string{"Code action"}},
+		{"ActionName", ColText, []string{"Action", "Libellé action"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (c *CommitmentBatch) Mapping() *BatchMapping {
+	return &commitmentMapping
 }
 
 // PaginatedCommitments embeddes the query results of a PaginatedQuery
@@ -99,11 +120,19 @@ type PaginatedCommitments struct {
 	ItemsCount  int64                 `json:"ItemsCount"`
 }
 
+// CursorCommitments embeddes a page of commitments fetched after the cursor
+// of a PaginatedQuery and the cursor of the next page, empty for the last one
+type CursorCommitments struct {
+	Commitments []PaginatedCommitment `json:"Commitment"`
+	NextCursor  string                `json:"NextCursor"`
+}
+
 // ExportQuery embeddes the request to fetch some commitments from database
 // according to the given pattern for export purpose
 type ExportQuery struct {
 	Year   int64  `json:"Year"`
 	Search string `json:"Search"`
+	Scope  *Scope `json:"-"`
 }
 
 // ExportedCommitment is dedicated to commitments exports with explicit fields
@@ -230,27 +259,54 @@ type HousingLinkedCommitments struct {
 	Commitments []HousingLinkedCommitment `json:"Commitment"`
 }
 
+// commitmentQueryFields are the fields of the paginated commitments that can
+// be used to sort or filter them
+var commitmentQueryFields = queryFields{
+	"ID":               {"c.id", idField},
+	"Year":             {"c.year", numberField},
+	"Code":             {"c.code", textField},
+	"Number":           {"c.number", numberField},
+	"CreationDate":     {"c.creation_date", dateField},
+	"ModificationDate": {"c.modification_date", dateField},
+	"CaducityDate":     {"c.caducity_date", dateField},
+	"Name":             {"c.name", textField},
+	"Value":            {"c.value", numberField},
+	"BeneficiaryID":    {"c.beneficiary_id", idField},
+	"BeneficiaryName":  {"b.name", textField},
+	"ActionID":         {"c.action_id", idField},
+	"ActionName":       {"a.name", textField},
+	"SectorID":         {"a.sector_id", idField},
+	"Sector":           {"s.name", textField},
+}
+
 // Get fetches the results of a paginated commitment query
 func (p *PaginatedCommitments) Get(db *sql.DB, c *PaginatedQuery) error {
 	var count int64
+	filters, order, args, err := c.clauses(commitmentQueryFields, "1",
+		[]interface{}{c.Year, "%" + c.Search + "%"})
+	if err != nil {
+		return err
+	}
 	commonQryPart := `FROM commitment c 
 	JOIN beneficiary b on c.beneficiary_id=b.id
 	JOIN budget_action a ON a.id = c.action_id
 	JOIN budget_sector s ON s.id=a.sector_id 
 	WHERE year >= $1 AND
 		(c.name ILIKE $2 OR c.code ILIKE $2 OR c.number::varchar ILIKE $2 
-			OR b.name ILIKE $2 OR a.name ILIKE $2 OR iris_code ILIKE $2)`
-	if err := db.QueryRow("SELECT count(1) "+commonQryPart, c.Year, "%"+c.Search+"%").
+			OR b.name ILIKE $2 OR a.name ILIKE $2 OR iris_code ILIKE $2)` +
+		c.Scope.commitmentFilter("c") + filters + " "
+	if err := db.QueryRow("SELECT count(1) "+commonQryPart, args...).
 		Scan(&count); err != nil {
 		return fmt.Errorf("count query failed %v", err)
 	}
-	offset, newPage := GetPaginateParams(c.Page, count)
+	size := c.size()
+	offset, newPage := getPageParams(c.Page, count, size)
 	rows, err := db.Query(`SELECT c.id,c.year,c.code,c.number,c.line,
 	c.creation_date,c.modification_date,c.caducity_date,c.name,c.value,c.sold_out,
 	c.beneficiary_id,b.name,c.iris_code,a.name,s.name,c.housing_id,
 	c.renew_project_id,c.copro_id `+commonQryPart+
-		`ORDER BY 1 LIMIT `+strconv.Itoa(PageSize)+` OFFSET $3`,
-		c.Year, "%"+c.Search+"%", offset)
+		`ORDER BY `+order+` LIMIT `+strconv.FormatInt(size, 10)+` OFFSET $`+
+		strconv.Itoa(len(args)+1), append(args, offset)...)
 	if err != nil {
 		return err
 	}
@@ -275,10 +331,69 @@ func (p *PaginatedCommitments) Get(db *sql.DB, c *PaginatedQuery) error {
 	return err
 }
 
+// Get fetches the commitments following the cursor of the query in the order
+// of their IDs, the keyset pagination avoiding to count and skip the rows of
+// the previous pages
+func (p *CursorCommitments) Get(db *sql.DB, c *PaginatedQuery) error {
+	filters, _, args, err := c.clauses(commitmentQueryFields, "1",
+		[]interface{}{c.Year, "%" + c.Search + "%"})
+	if err != nil {
+		return err
+	}
+	keyset, args, err := c.keysetClause([]string{"c.id"}, args)
+	if err != nil {
+		return err
+	}
+	size := c.size()
+	rows, err := db.Query(`SELECT c.id,c.year,c.code,c.number,c.line,
+	c.creation_date,c.modification_date,c.caducity_date,c.name,c.value,c.sold_out,
+	c.beneficiary_id,b.name,c.iris_code,a.name,s.name,c.housing_id,
+	c.renew_project_id,c.copro_id FROM commitment c
+	JOIN beneficiary b on c.beneficiary_id=b.id
+	JOIN budget_action a ON a.id = c.action_id
+	JOIN budget_sector s ON s.id=a.sector_id
+	WHERE year >= $1 AND
+		(c.name ILIKE $2 OR c.code ILIKE $2 OR c.number::varchar ILIKE $2
+			OR b.name ILIKE $2 OR a.name ILIKE $2 OR iris_code ILIKE $2)`+
+		c.Scope.commitmentFilter("c")+filters+keyset+` ORDER BY c.id LIMIT `+
+		strconv.FormatInt(size+1, 10), args...)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row PaginatedCommitment
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&row.ID, &row.Year, &row.Code, &row.Number, &row.Line,
+			&row.CreationDate, &row.ModificationDate, &row.CaducityDate, &row.Name,
+			&row.Value, &row.SoldOut, &row.BeneficiaryID, &row.BeneficiaryName,
+			&row.IrisCode, &row.ActionName, &row.Sector, &row.HousingID,
+			&row.RenewProjectID, &row.CoproID); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		p.Commitments = append(p.Commitments, row)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if int64(len(p.Commitments)) > size {
+		p.Commitments = p.Commitments[:size]
+		p.NextCursor = encodeCursor(p.Commitments[size-1].ID)
+	}
+	if len(p.Commitments) == 0 {
+		p.Commitments = []PaginatedCommitment{}
+	}
+	return nil
+}
+
 // GetUnlinked fetches the commitments whose housing_id, copro_id and
 // renew_project_id are null and that matches the query using paginated format
 func (p *PaginatedCommitments) GetUnlinked(db *sql.DB, c *PaginatedQuery) error {
 	var count int64
+	filters, order, args, err := c.clauses(commitmentQueryFields, "1",
+		[]interface{}{c.Year, "%" + c.Search + "%"})
+	if err != nil {
+		return err
+	}
 	commonQryPart := `FROM commitment c
 	JOIN beneficiary b on c.beneficiary_id=b.id
 	JOIN budget_action a ON a.id=c.action_id
@@ -286,17 +401,18 @@ func (p *PaginatedCommitments) GetUnlinked(db *sql.DB, c *PaginatedQuery) error
 	WHERE year>=$1 AND housing_id IS NULL AND renew_project_id IS NULL AND
 		copro_id IS NULL AND (c.name ILIKE $2 OR c.code ILIKE $2 OR
 			c.number::varchar ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2 OR 
-			iris_code ILIKE $2) `
-	if err := db.QueryRow(`SELECT count(1) `+commonQryPart, c.Year, "%"+c.Search+"%").
+			iris_code ILIKE $2) ` + c.Scope.commitmentFilter("c") + filters + " "
+	if err := db.QueryRow(`SELECT count(1) `+commonQryPart, args...).
 		Scan(&count); err != nil {
 		return fmt.Errorf("count query failed %v", err)
 	}
-	offset, newPage := GetPaginateParams(c.Page, count)
+	size := c.size()
+	offset, newPage := getPageParams(c.Page, count, size)
 	rows, err := db.Query(`SELECT c.id,c.year,c.code,c.number,c.line,
 	c.creation_date,c.modification_date,c.caducity_date,c.name,c.value,c.sold_out,
 	c.beneficiary_id,b.name,c.iris_code,a.name,s.name `+commonQryPart+
-		`ORDER BY 1 LIMIT `+strconv.Itoa(PageSize)+` OFFSET $3`,
-		c.Year, "%"+c.Search+"%", offset)
+		`ORDER BY `+order+` LIMIT `+strconv.FormatInt(size, 10)+` OFFSET $`+
+		strconv.Itoa(len(args)+1), append(args, offset)...)
 	if err != nil {
 		return err
 	}
@@ -320,8 +436,10 @@ func (p *PaginatedCommitments) GetUnlinked(db *sql.DB, c *PaginatedQuery) error
 	return err
 }
 
-// Get fetches the results of exported commitments
-func (e *ExportedCommitments) Get(db *sql.DB, q *ExportQuery) error {
+// Stream fetches the exported commitments and calls send for each row as
+// soon as it's read, the row being reused
+func (e *ExportedCommitments) Stream(db *sql.DB, q *ExportQuery,
+	send func(*ExportedCommitment) error) error {
 	rows, err := db.Query(`SELECT c.id,c.year,c.code,c.number,c.line,
 	c.creation_date,c.modification_date,c.caducity_date,c.name,c.value*0.01,
 	c.sold_out, b.name, c.iris_code,a.name,s.name,copro.name,housing.address,
@@ -334,8 +452,8 @@ func (e *ExportedCommitments) Get(db *sql.DB, q *ExportQuery) error {
 	LEFT JOIN housing ON housing.id = c.housing_id
 	LEFT JOIN renew_project ON renew_project.id = c.renew_project_id
 	WHERE year >= $1 AND (c.name ILIKE $2  OR c.number::varchar ILIKE $2 OR 
-		c.code ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)
-	ORDER BY 2,6,7,3,4,5`, q.Year, "%"+q.Search+"%")
+		c.code ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)`+
+		q.Scope.commitmentFilter("c")+` ORDER BY 2,6,7,3,4,5`, q.Year, "%"+q.Search+"%")
 	if err != nil {
 		return err
 	}
@@ -349,13 +467,11 @@ func (e *ExportedCommitments) Get(db *sql.DB, q *ExportQuery) error {
 			&row.RenewProjectName); err != nil {
 			return err
 		}
-		e.ExportedCommitments = append(e.ExportedCommitments, row)
-	}
-	err = rows.Err()
-	if len(e.ExportedCommitments) == 0 {
-		e.ExportedCommitments = []ExportedCommitment{}
+		if err = send(&row); err != nil {
+			return err
+		}
 	}
-	return err
+	return rows.Err()
 }
 
 // GetAll fetches all Commitments from database
@@ -473,61 +589,78 @@ func (c *HousingLinkedCommitments) Get(ID int64, db *sql.DB) (err error) {
 }
 
 // Save insert a batch of CommitmentLine into database
-func (c *CommitmentBatch) Save(db *sql.DB) (err error) {
+func (c *CommitmentBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(c.Lines))
 	for i, r := range c.Lines {
-		if r.Year < 2009 || r.Number == 0 || r.Line == 0 ||
-			r.CreationDate < 20090101 || r.ModificationDate < 20090101 ||
-			r.Name == "" || r.BeneficiaryCode == 0 || r.BeneficiaryName == "" ||
-			r.Sector == "" {
-			return fmt.Errorf("Ligne %d : champs incorrects dans %+v", i+1, r)
+		if r.Year < 2009 {
+			report.addError(i, "Year", "année antérieure à 2009")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_commitment", "year", "code", "number",
-		"line", "creation_date", "modification_date", "caducity_date", "name", "value",
-		"sold_out", "beneficiary_code", "beneficiary_name", "iris_code", "sector",
-		"action_code", "action_name"))
-	if err != nil {
-		return fmt.Errorf("Statement creation %v", err)
-	}
-	defer stmt.Close()
-	var cd, md, ed time.Time
-	for i, r := range c.Lines {
-		cd = time.Date(int(r.CreationDate/10000), time.Month(r.CreationDate/100%100),
-			int(r.CreationDate%100), 0, 0, 0, 0, time.UTC)
-		md = time.Date(int(r.ModificationDate/10000),
-			time.Month(r.ModificationDate/100%100), int(r.ModificationDate%100), 0, 0,
-			0, 0, time.UTC)
-		ed = time.Date(int(r.CaducityDate/10000), time.Month(r.CaducityDate/100%100),
-			int(r.CreationDate%100), 0, 0, 0, 0, time.UTC)
-		if _, err = stmt.Exec(r.Year, r.Code, r.Number, r.Line, cd, md, ed,
-			strings.TrimSpace(r.Name), r.Value, r.SoldOut == "O", r.BeneficiaryCode,
-			strings.TrimSpace(r.BeneficiaryName), r.IrisCode,
-			strings.TrimSpace(r.Sector), r.ActionCode,
-			r.ActionName.TrimSpace()); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("Ligne %d statement execution %v", i+1, err)
+		if r.Number == 0 {
+			report.addError(i, "Number", "numéro nul")
+		}
+		if r.Line == 0 {
+			report.addError(i, "Line", "ligne nulle")
+		}
+		if r.CreationDate < 20090101 {
+			report.addError(i, "CreationDate", "date antérieure à 2009")
+		}
+		if r.ModificationDate < 20090101 {
+			report.addError(i, "ModificationDate", "date antérieure à 2009")
+		}
+		if r.Name == "" {
+			report.addError(i, "Name", "nom vide")
+		}
+		if r.BeneficiaryCode == 0 {
+			report.addError(i, "BeneficiaryCode", "code nul")
+		}
+		if r.BeneficiaryName == "" {
+			report.addError(i, "BeneficiaryName", "nom vide")
+		}
+		if r.Sector == "" {
+			report.addError(i, "Sector", "secteur vide")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement exec flush %v", err)
-	}
-	queries := []string{`INSERT INTO beneficiary (code,name)
+	return report, report.run(db, "commitment", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_commitment", "year", "code", "number",
+			"line", "creation_date", "modification_date", "caducity_date", "name", "value",
+			"sold_out", "beneficiary_code", "beneficiary_name", "iris_code", "sector",
+			"action_code", "action_name"))
+		if err != nil {
+			return fmt.Errorf("Statement creation %v", err)
+		}
+		defer stmt.Close()
+		var cd, md, ed time.Time
+		for i, r := range c.Lines {
+			cd = time.Date(int(r.CreationDate/10000), time.Month(r.CreationDate/100%100),
+				int(r.CreationDate%100), 0, 0, 0, 0, time.UTC)
+			md = time.Date(int(r.ModificationDate/10000),
+				time.Month(r.ModificationDate/100%100), int(r.ModificationDate%100), 0, 0,
+				0, 0, time.UTC)
+			ed = time.Date(int(r.CaducityDate/10000), time.Month(r.CaducityDate/100%100),
+				int(r.CreationDate%100), 0, 0, 0, 0, time.UTC)
+			if _, err = stmt.Exec(r.Year, r.Code, r.Number, r.Line, cd, md, ed,
+				strings.TrimSpace(r.Name), r.Value, r.SoldOut == "O", r.BeneficiaryCode,
+				strings.TrimSpace(r.BeneficiaryName), r.IrisCode,
+				strings.TrimSpace(r.Sector), r.ActionCode,
+				r.ActionName.TrimSpace()); err != nil {
+				return fmt.Errorf("Ligne %d statement execution %v", i+1, err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement exec flush %v", err)
+		}
+		return execQueries(tx, []string{`INSERT INTO beneficiary (code,name)
 		SELECT DISTINCT beneficiary_code,beneficiary_name
 		FROM temp_commitment
 		WHERE beneficiary_code not in (SELECT code from beneficiary)`,
-		`INSERT INTO budget_sector (name) SELECT DISTINCT sector
+			`INSERT INTO budget_sector (name) SELECT DISTINCT sector
 			FROM temp_commitment WHERE sector not in (SELECT name from budget_sector)`,
-		`INSERT INTO budget_action (code,name,sector_id)
+			`INSERT INTO budget_action (code,name,sector_id)
 			SELECT DISTINCT ic.action_code,ic.action_name, s.id
 			FROM temp_commitment ic
 			LEFT JOIN budget_sector s ON ic.sector = s.name
 			WHERE action_code not in (SELECT code from budget_action)`,
-		`INSERT INTO commitment (year,code,number,line,creation_date,
+			`INSERT INTO commitment (year,code,number,line,creation_date,
 			modification_date,caducity_date,name,value,sold_out,beneficiary_id,iris_code,action_id)
 			(SELECT ic.year,ic.code,ic.number,ic.line,ic.creation_date,
 				ic.modification_date,ic.caducity_date,ic.name,ic.value,ic.sold_out,b.id,
@@ -539,19 +672,28 @@ func (c *CommitmentBatch) Save(db *sql.DB) (err error) {
 				ic.modification_date,ic.name, ic.value) NOT IN
 					(SELECT year,code,number,line,creation_date,modification_date,
 						name,value FROM commitment))`,
-		`DELETE FROM temp_commitment`}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %v", i, err)
-		}
-	}
-	return tx.Commit()
+			`DELETE FROM temp_commitment`})
+	})
 }
 
 // Get fetches all commitments per year for the current and the previous years
 func (t *TwoYearsCommitments) Get(db *sql.DB) error {
+	v, err := aggregates.get(&twoYearsCommitmentsCache, "",
+		func() (interface{}, error) {
+			var r TwoYearsCommitments
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*t = v.(TwoYearsCommitments)
+	return nil
+}
+
+// fetch computes all commitments per year for the current and the previous
+// years from database
+func (t *TwoYearsCommitments) fetch(db *sql.DB) error {
 	query := `WITH cmt_month as 
 	(SELECT MAX(EXTRACT(month FROM creation_date))::int max_month
   	FROM commitment WHERE year=$1)
@@ -23,10 +23,10 @@ type CommitmentUnlink struct {
 // Validate checks commitments fields are correctly filled
 func (c *CommitmentLink) Validate() error {
 	if c.Type != "Housing" && c.Type != "Copro" && c.Type != "RenewProject" {
-		return errors.New("Type incorrect")
+		return &ValidationError{Field: "Type", Message: "Type incorrect"}
 	}
 	if c.DestID == 0 {
-		return errors.New("ID d'engagement incorrect")
+		return &ValidationError{Field: "DestID", Message: "ID d'engagement incorrect"}
 	}
 	return nil
 }
@@ -63,7 +63,11 @@ func (c *CommitmentLink) Set(db *sql.DB) error {
 		tx.Rollback()
 		return errors.New("Impossible de lier tous les engagements")
 	}
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	invalidateCache("commitment")
+	return nil
 }
 
 // Set updates the commitment table to set all copro, renew projects and housings
@@ -88,5 +92,9 @@ func (c *CommitmentUnlink) Set(db *sql.DB) error {
 		tx.Rollback()
 		return errors.New("Impossible de supprimer tous les liens")
 	}
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	invalidateCache("commitment")
+	return nil
 }
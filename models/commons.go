@@ -11,24 +11,45 @@ import (
 
 var b = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
 
+// DefaultPageSize and MaxPageSize are the default and the maximum numbers of
+// rows of a paginated query
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
 // PageSize defines the number of row of a paginated query
-const PageSize = 10
+var PageSize = DefaultPageSize
+
+// SetPageSize sets the number of rows of the paginated queries, the values
+// outside of 1 to MaxPageSize being ignored
+func SetPageSize(size int) {
+	if size > 0 && size <= MaxPageSize {
+		PageSize = size
+	}
+}
 
 var nullBytes []byte = []byte("null")
 
 // GetPaginateParams returns the correct offset and page according to the total number of rows.
 func GetPaginateParams(page int64, count int64) (offset int64, newPage int64) {
+	return getPageParams(page, count, int64(PageSize))
+}
+
+// getPageParams returns the offset and the page according to the total number
+// of rows and the size of a page
+func getPageParams(page, count, size int64) (offset int64, newPage int64) {
 	if count == 0 {
 		return 0, 1
 	}
-	offset = (page - 1) * PageSize
+	offset = (page - 1) * size
 	if offset < 0 {
 		offset = 0
 	}
 	if offset >= count {
-		offset = (count - 1) - ((count - 1) % PageSize)
+		offset = (count - 1) - ((count - 1) % size)
 	}
-	newPage = offset/PageSize + 1
+	newPage = offset/size + 1
 	return offset, newPage
 }
 
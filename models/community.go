@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -33,13 +32,29 @@ type CommunityBatch struct {
 	Lines []CommunityLine `json:"Community"`
 }
 
+// communityMapping defines the columns of the XLSX or CSV file of a community
+// batch
+var communityMapping = BatchMapping{
+	Key: "Community",
+	Columns: []BatchColumn{
+		{"Code", ColText, []string{"Code EPCI", "SIREN", "SIREN EPCI"}},
+		{"Name", ColText, []string{"Nom", "Interco", "EPCI", "Nom EPCI"}},
+		{"DepartmentCode", ColInt, []string{"Département", "Code département"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (c *CommunityBatch) Mapping() *BatchMapping {
+	return &communityMapping
+}
+
 // Validate checks if Community's fields are correctly filled
 func (c *Community) Validate() error {
 	if c.Code == "" {
-		return errors.New("Champ code incorrect")
+		return &ValidationError{Field: "Code", Message: "Champ code incorrect"}
 	}
 	if c.Name == "" {
-		return errors.New("Champ name incorrect")
+		return &ValidationError{Field: "Name", Message: "Champ name incorrect"}
 	}
 	return nil
 }
@@ -48,6 +63,9 @@ func (c *Community) Validate() error {
 func (c *Community) Create(db *sql.DB) (err error) {
 	err = db.QueryRow(`INSERT INTO community (code,name,department_id)
  VALUES($1,$2,$3) RETURNING id`, &c.Code, &c.Name, &c.DepartmentID).Scan(&c.ID)
+	if err == nil {
+		invalidateCache("community")
+	}
 	return err
 }
 
@@ -65,12 +83,13 @@ func (c *Community) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("community")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %d", err)
 	}
 	if count != 1 {
-		return errors.New("Interco introuvable")
+		return notFound("Interco")
 	}
 	return nil
 }
@@ -119,52 +138,51 @@ func (c *Community) Delete(db *sql.DB) (err error) {
 	}
 	if count != 1 {
 		tx.Rollback()
-		return errors.New("Interco introuvable")
+		return notFound("Interco")
+	}
+	if err = tx.Commit(); err != nil {
+		return err
 	}
-	return tx.Commit()
+	invalidateCache("community", "city")
+	return nil
 }
 
 // Save insert a batch of CommunityLine into database
-func (c *CommunityBatch) Save(db *sql.DB) (err error) {
+func (c *CommunityBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(c.Lines))
 	for i, r := range c.Lines {
-		if r.Code == "" || r.Name == "" || r.DepartmentCode == 0 {
-			return fmt.Errorf("ligne %d, champ incorrect", i+1)
+		if r.Code == "" {
+			report.addError(i, "Code", "code vide")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_community", "code", "name", "department_code"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range c.Lines {
-		if _, err = stmt.Exec(r.Code, r.Name, r.DepartmentCode); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+		if r.Name == "" {
+			report.addError(i, "Name", "nom vide")
+		}
+		if r.DepartmentCode == 0 {
+			report.addError(i, "DepartmentCode", "code département nul")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{`UPDATE community SET name=t.name, department_id=d.id 
+	return report, report.run(db, "community", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_community", "code", "name", "department_code"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		for _, r := range c.Lines {
+			if _, err = stmt.Exec(r.Code, r.Name, r.DepartmentCode); err != nil {
+				return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE community SET name=t.name, department_id=d.id 
 	FROM temp_community t, department d 
 	WHERE t.code = community.code AND d.code=t.department_code`,
-		`INSERT INTO community (code,name,department_id)
+			`INSERT INTO community (code,name,department_id)
 	SELECT t.code,t.name,d.id FROM temp_community t 
 	LEFT OUTER JOIN department d ON d.code=t.department_code
 		WHERE t.code NOT IN (SELECT DISTINCT code from community)`,
-		`DELETE FROM temp_community`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE FROM temp_community`,
+		})
+	})
 }
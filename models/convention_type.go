@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -27,7 +26,7 @@ func (r *ConventionType) Create(db *sql.DB) (err error) {
 // Valid checks if fields complies with database constraints
 func (r *ConventionType) Valid() error {
 	if r.Name == "" {
-		return fmt.Errorf("Nom vide")
+		return &ValidationError{Field: "Name", Message: "Nom vide"}
 	}
 	return nil
 }
@@ -51,7 +50,7 @@ func (r *ConventionType) Update(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type de convention introuvable")
+		return notFound("Type de convention")
 	}
 	return nil
 }
@@ -91,7 +90,7 @@ func (r *ConventionType) Delete(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type de convention introuvable")
+		return notFound("Type de convention")
 	}
 	return nil
 }
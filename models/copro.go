@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 
@@ -41,14 +40,32 @@ type CoproBatch struct {
 	Lines []CoproLine `json:"Copro"`
 }
 
+// coproMapping defines the columns of the XLSX or CSV file of a copro batch
+var coproMapping = BatchMapping{
+	Key: "Copro",
+	Columns: []BatchColumn{
+		{"Reference", ColText, []string{"Référence"}},
+		{"Name", ColText, []string{"Nom", "Copropriété"}},
+		{"Address", ColText, []string{"Adresse"}},
+		{"ZipCode", ColInt, []string{"Code postal", "CP"}},
+		{"LabelDate", ColDateExcel, []string{"Date de labellisation", "Date label"}},
+		{"Budget", ColInt, []string{"Budget"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (c *CoproBatch) Mapping() *BatchMapping {
+	return &coproMapping
+}
+
 // Validate checks copro's fields and return an error if they don't
 // fit with database constraints
 func (c *Copro) Validate() error {
 	if c.Reference == "" {
-		return errors.New("champ reference vide")
+		return &ValidationError{Field: "Reference", Message: "champ reference vide"}
 	}
 	if c.Name == "" {
-		return errors.New("champ name vide")
+		return &ValidationError{Field: "Name", Message: "champ name vide"}
 	}
 	return nil
 }
@@ -61,6 +78,7 @@ func (c *Copro) Create(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("insert %V", err)
 	}
+	invalidateCache("copro")
 	if c.ZipCode.Valid {
 		err = db.QueryRow(`SELECT city.name FROM copro 
 		JOIN city ON copro.zip_code=city.insee_code 
@@ -77,12 +95,13 @@ func (c *Copro) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("copro")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Copro introuvable")
+		return notFound("Copro")
 	}
 	if c.ZipCode.Valid {
 		err = db.QueryRow(`SELECT city.name FROM copro 
@@ -98,12 +117,13 @@ func (c *Copro) Delete(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("delete %v", err)
 	}
+	invalidateCache("copro")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Copro introuvable")
+		return notFound("Copro")
 	}
 	return nil
 }
@@ -120,9 +140,15 @@ func (c *Copro) Get(db *sql.DB) (err error) {
 
 // GetAll fetches all Copros from database
 func (c *Copros) GetAll(db *sql.DB) (err error) {
+	return c.GetInScope(db, nil)
+}
+
+// GetInScope fetches the Copros located in the scope from database
+func (c *Copros) GetInScope(db *sql.DB, scope *Scope) (err error) {
 	rows, err := db.Query(`SELECT co.id,co.reference,co.name,co.address,co.zip_code,
 	ci.name,co.label_date,co.budget FROM copro co
-	LEFT OUTER JOIN city ci ON co.zip_code=ci.insee_code`)
+	LEFT OUTER JOIN city ci ON co.zip_code=ci.insee_code WHERE TRUE` +
+		scope.cityFilter("co.zip_code"))
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -159,46 +185,44 @@ func nullExcel2NullTime(i NullInt64) NullTime {
 }
 
 // Save insert a batch of CoproLine into database
-func (c *CoproBatch) Save(db *sql.DB) (err error) {
+func (c *CoproBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(c.Lines))
 	for i, r := range c.Lines {
-		if r.Reference == "" || r.Name == "" || r.Address == "" || r.ZipCode == 0 {
-			return fmt.Errorf("ligne %d : champs incorrects", i+1)
+		if r.Reference == "" {
+			report.addError(i, "Reference", "référence vide")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_copro", "reference", "name", "address",
-		"zip_code", "label_date", "budget"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range c.Lines {
-		if _, err = stmt.Exec(r.Reference, r.Name, r.Address, r.ZipCode,
-			nullExcel2NullTime(r.LabelDate), r.Budget); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+		if r.Name == "" {
+			report.addError(i, "Name", "nom vide")
+		}
+		if r.Address == "" {
+			report.addError(i, "Address", "adresse vide")
+		}
+		if r.ZipCode == 0 {
+			report.addError(i, "ZipCode", "code postal nul")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statementflush exec %v", err)
-	}
-	queries := []string{`UPDATE copro SET name=t.name, address=t.address, zip_code=t.zip_code,
+	return report, report.run(db, "copro", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_copro", "reference", "name", "address",
+			"zip_code", "label_date", "budget"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		for _, r := range c.Lines {
+			if _, err = stmt.Exec(r.Reference, r.Name, r.Address, r.ZipCode,
+				nullExcel2NullTime(r.LabelDate), r.Budget); err != nil {
+				return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statementflush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE copro SET name=t.name, address=t.address, zip_code=t.zip_code,
 	label_date=t.label_date,budget=t.budget FROM temp_copro t WHERE t.reference = copro.reference`,
-		`INSERT INTO copro (reference, name,address,zip_code,label_date,budget)
+			`INSERT INTO copro (reference, name,address,zip_code,label_date,budget)
 	SELECT reference,name,address,zip_code,label_date,budget from temp_copro 
 		WHERE reference NOT IN (SELECT reference from copro)`,
-		`DELETE from temp_copro`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_copro`,
+		})
+	})
 }
@@ -21,10 +21,10 @@ type CoproDocs struct {
 // validate check if fields respect database constraint
 func (c *CoproDoc) validate() error {
 	if c.Name == "" {
-		return fmt.Errorf("nom vide")
+		return &ValidationError{Field: "Name", Message: "nom vide"}
 	}
 	if c.Link == "" {
-		return fmt.Errorf("link vide")
+		return &ValidationError{Field: "Link", Message: "link vide"}
 	}
 	return nil
 }
@@ -79,7 +79,7 @@ func (c *CoproDoc) Update(db *sql.DB) error {
 		return fmt.Errorf("RowsAffected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("copro introuvable")
+		return notFound("copro")
 	}
 	return nil
 }
@@ -95,7 +95,7 @@ func (c *CoproDoc) Delete(db *sql.DB) error {
 		return fmt.Errorf("RowsAffected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("copro introuvable")
+		return notFound("copro")
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 )
@@ -53,10 +52,10 @@ func (r *CoproEvent) Create(db *sql.DB) (err error) {
 // Validate check if fields complies with database constraints
 func (r *CoproEvent) Validate() error {
 	if r.CoproID == 0 {
-		return fmt.Errorf("CoproID vide")
+		return &ValidationError{Field: "CoproID", Message: "CoproID vide"}
 	}
 	if r.CoproEventTypeID == 0 {
-		return fmt.Errorf("CoproEventTypeID vide")
+		return &ValidationError{Field: "CoproEventTypeID", Message: "CoproEventTypeID vide"}
 	}
 	return nil
 }
@@ -82,7 +81,7 @@ func (r *CoproEvent) Update(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Événement introuvable")
+		return notFound("Événement")
 	}
 	err = db.QueryRow(`SELECT name FROM copro_event_type WHERE id=$1`,
 		r.CoproEventTypeID).Scan(&r.Name)
@@ -127,7 +126,7 @@ func (r *CoproEvent) Delete(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Événement introuvable")
+		return notFound("Événement")
 	}
 	return nil
 }
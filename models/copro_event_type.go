@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -27,7 +26,7 @@ func (r *CoproEventType) Create(db *sql.DB) (err error) {
 // Validate check if fields complies with database constraints
 func (r *CoproEventType) Validate() error {
 	if r.Name == "" {
-		return fmt.Errorf("name vide")
+		return &ValidationError{Field: "Name", Message: "name vide"}
 	}
 	return nil
 }
@@ -51,7 +50,7 @@ func (r *CoproEventType) Update(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type d'événement introuvable")
+		return notFound("Type d'événement")
 	}
 	return nil
 }
@@ -91,7 +90,7 @@ func (r *CoproEventType) Delete(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type d'événement introuvable")
+		return notFound("Type d'événement")
 	}
 	return nil
 }
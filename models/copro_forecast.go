@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -44,10 +43,39 @@ type CoproForecastBatch struct {
 	Lines []CoproForecastLine `json:"CoproForecast"`
 }
 
+// coproForecastMapping defines the columns of the XLSX or CSV file of a copro
+// forecast batch
+var coproForecastMapping = BatchMapping{
+	Key: "CoproForecast",
+	Columns: []BatchColumn{
+		{"ID", ColInt, nil},
+		{"CommissionID", ColInt, []string{"Commission", "ID commission"}},
+		{"Value", ColInt, []string{"Montant", "Prévision"}},
+		{"Project", ColText, []string{"Projet"}},
+		{"Comment", ColText, []string{"Commentaire"}},
+		{"CoproID", ColInt, []string{"Copropriété", "ID copropriété"}},
+		{"ActionCode", ColInt, []string{"Code action"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (c *CoproForecastBatch) Mapping() *BatchMapping {
+	return &coproForecastMapping
+}
+
 // Validate checks if CoproForecast's fields are correctly filled
 func (r *CoproForecast) Validate() error {
-	if r.CommissionID == 0 || r.Value == 0 || r.CoproID == 0 || r.ActionID == 0 {
-		return errors.New("Champ incorrect")
+	if r.CommissionID == 0 {
+		return &ValidationError{Field: "CommissionID", Message: "Champ incorrect"}
+	}
+	if r.Value == 0 {
+		return &ValidationError{Field: "Value", Message: "Champ incorrect"}
+	}
+	if r.CoproID == 0 {
+		return &ValidationError{Field: "CoproID", Message: "Champ incorrect"}
+	}
+	if r.ActionID == 0 {
+		return &ValidationError{Field: "ActionID", Message: "Champ incorrect"}
 	}
 	return nil
 }
@@ -62,6 +90,7 @@ func (r *CoproForecast) Create(db *sql.DB) (err error) {
 		return fmt.Errorf("insert %v", err)
 
 	}
+	invalidateCache("copro_forecast")
 	err = db.QueryRow(`SELECT c.name, c.date, b.code, b.name 
 	FROM commission c, budget_action b WHERE c.id=$1 AND b.id=$2`,
 		r.CommissionID, r.ActionID).Scan(&r.CommissionName, &r.CommissionDate,
@@ -93,12 +122,13 @@ func (r *CoproForecast) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("copro_forecast")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Prévision copro introuvable")
+		return notFound("Prévision copro")
 	}
 	err = db.QueryRow(`SELECT c.name, c.date, b.code, b.name 
 	FROM commission c, budget_action b WHERE c.id=$1 AND b.id=$2`, r.CommissionID,
@@ -108,11 +138,17 @@ func (r *CoproForecast) Update(db *sql.DB) (err error) {
 
 // GetAll fetches all CoproForecasts from database
 func (r *CoproForecasts) GetAll(db *sql.DB) (err error) {
+	return r.GetInScope(db, nil)
+}
+
+// GetInScope fetches the CoproForecasts of the scope from database
+func (r *CoproForecasts) GetInScope(db *sql.DB, scope *Scope) (err error) {
 	rows, err := db.Query(`SELECT cf.id, cf.commission_id,c.date,c.name, 
 		cf.value,cf.project,cf.comment,cf.copro_id, b.code, b.name
 	FROM copro_forecast cf
 	JOIN commission c ON c.id=cf.commission_id
-	JOIN budget_action b ON b.id = cf.action_id`)
+	JOIN budget_action b ON b.id = cf.action_id WHERE TRUE` +
+		scope.coproForecastFilter("cf"))
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -173,60 +209,59 @@ func (r *CoproForecast) Delete(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("delete %v", err)
 	}
+	invalidateCache("copro_forecast")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Prévision copro introuvable")
+		return notFound("Prévision copro")
 	}
 	return nil
 }
 
 // Save insert a batch of CoproForecastLine into database
-func (r *CoproForecastBatch) Save(db *sql.DB) (err error) {
-	for i, r := range r.Lines {
-		if r.CommissionID == 0 || r.Value == 0 || r.CoproID == 0 || r.ActionCode == 0 {
-			return fmt.Errorf("ligne %d, champs incorrects", i+1)
+func (r *CoproForecastBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(r.Lines))
+	for i, l := range r.Lines {
+		if l.CommissionID == 0 {
+			report.addError(i, "CommissionID", "commission nulle")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_copro_forecast", "id", "commission_id",
-		"value", "project", "comment", "copro_id", "action_code"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range r.Lines {
-		if _, err = stmt.Exec(r.ID, r.CommissionID, r.Value, r.Project, r.Comment, r.CoproID,
-			r.ActionCode); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("exec %v", err)
+		if l.Value == 0 {
+			report.addError(i, "Value", "montant nul")
+		}
+		if l.CoproID == 0 {
+			report.addError(i, "CoproID", "copropriété nulle")
+		}
+		if l.ActionCode == 0 {
+			report.addError(i, "ActionCode", "code action nul")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{`UPDATE copro_forecast SET commission_id=t.commission_id,
+	return report, report.run(db, "copro_forecast", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_copro_forecast", "id", "commission_id",
+			"value", "project", "comment", "copro_id", "action_code"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		for _, r := range r.Lines {
+			if _, err = stmt.Exec(r.ID, r.CommissionID, r.Value, r.Project, r.Comment, r.CoproID,
+				r.ActionCode); err != nil {
+				return fmt.Errorf("exec %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE copro_forecast SET commission_id=t.commission_id,
 	value=t.value,project=t.project,comment=t.comment,copro_id=t.copro_id, action_id=b.id
 	FROM temp_copro_forecast t JOIN budget_action b ON t.action_code = b.code
 	WHERE t.id = copro_forecast.id`,
-		`INSERT INTO copro_forecast (commission_id,value,project,comment,copro_id,action_id)
+			`INSERT INTO copro_forecast (commission_id,value,project,comment,copro_id,action_id)
 	SELECT t.commission_id,t.value,t.project,t.comment,t.copro_id,b.id from temp_copro_forecast t
 		JOIN budget_action b ON t.action_code = b.code
 		WHERE t.id NOT IN (SELECT id from copro_forecast)`,
-		`DELETE from temp_copro_forecast`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d, %v", i, err)
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_copro_forecast`,
+		})
+	})
 }
@@ -27,6 +27,20 @@ type CoproReports struct {
 
 // GetAll fetches all lines of the copro report from database
 func (c *CoproReports) GetAll(db *sql.DB) error {
+	v, err := aggregates.get(&coproReportCache, "", func() (interface{}, error) {
+		var r CoproReports
+		err := r.fetch(db)
+		return r, err
+	})
+	if err != nil {
+		return err
+	}
+	*c = v.(CoproReports)
+	return nil
+}
+
+// fetch computes the copro report from database
+func (c *CoproReports) fetch(db *sql.DB) error {
 	qry := `
   WITH max_cmt_date AS (SELECT MAX(creation_date) AS d FROM commitment)
   SELECT ci.insee_code,ci.name,co.name,co.budget,cmt.value,prg.value,
@@ -3,7 +3,6 @@ package models
 import (
 	"database/sql"
 	"fmt"
-	"sync"
 )
 
 // CsfWeekTrend model fetches the trend of payment demands with no csf from one
@@ -13,22 +12,24 @@ type CsfWeekTrend struct {
 	ThisWeekCount NullInt64
 }
 
-var cachedCWT CsfWeekTrend
-
-func (c *CsfWeekTrend) copy(src *CsfWeekTrend) {
-	c.LastWeekCount.Valid = src.LastWeekCount.Valid
-	c.LastWeekCount.Int64 = src.LastWeekCount.Int64
-	c.ThisWeekCount.Valid = src.ThisWeekCount.Valid
-	c.ThisWeekCount.Int64 = src.ThisWeekCount.Int64
-}
-
 // Get fetches count of payments demands with no csf from last and current week
 // from database
 func (c *CsfWeekTrend) Get(db *sql.DB) error {
-	if !needUpdate(csfWeekTrendUpdate, paymentDemandsUpdate) {
-		c.copy(&cachedCWT)
-		return nil
+	v, err := aggregates.get(&csfWeekTrendCache, "", func() (interface{}, error) {
+		var r CsfWeekTrend
+		err := r.fetch(db)
+		return r, err
+	})
+	if err != nil {
+		return err
 	}
+	*c = v.(CsfWeekTrend)
+	return nil
+}
+
+// fetch computes count of payments demands with no csf from last and current
+// week from database
+func (c *CsfWeekTrend) fetch(db *sql.DB) error {
 	if err := db.QueryRow(`SELECT last_week.c,this_week.c
 	 FROM (SELECT count(1) c FROM payment_demands 
 		WHERE receipt_date<= CURRENT_DATE-7 AND excluded!=TRUE
@@ -38,10 +39,5 @@ func (c *CsfWeekTrend) Get(db *sql.DB) error {
 		&c.ThisWeekCount); err != nil {
 		return fmt.Errorf("select %v", err)
 	}
-	var mutex = &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
-	cachedCWT.copy(c)
-	update(csfWeekTrendUpdate)
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -21,10 +20,10 @@ type Departments struct {
 // Validate checks if Department's fields are correctly filled
 func (c *Department) Validate() error {
 	if c.Code == 0 {
-		return errors.New("Champ code incorrect")
+		return &ValidationError{Field: "Code", Message: "Champ code incorrect"}
 	}
 	if c.Name == "" {
-		return errors.New("Champ name incorrect")
+		return &ValidationError{Field: "Name", Message: "Champ name incorrect"}
 	}
 	return nil
 }
@@ -48,12 +47,13 @@ func (c *Department) Update(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("department")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Département introuvable")
+		return notFound("Département")
 	}
 	return nil
 }
@@ -105,7 +105,11 @@ func (c *Department) Delete(db *sql.DB) (err error) {
 	}
 	if count != 1 {
 		tx.Rollback()
-		return errors.New("Département introuvable")
+		return notFound("Département")
 	}
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	invalidateCache("department", "community")
+	return nil
 }
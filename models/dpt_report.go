@@ -21,7 +21,22 @@ type DptReport struct {
 }
 
 // GetAll fetches the commitment and payment per department from database
-func (d *DptReport) GetAll(db *sql.DB, firstYear, lastYear int64) (err error) {
+func (d *DptReport) GetAll(db *sql.DB, firstYear, lastYear int64) error {
+	v, err := aggregates.get(&dptReportCache,
+		fmt.Sprintf("%d|%d", firstYear, lastYear), func() (interface{}, error) {
+			var r DptReport
+			err := r.fetch(db, firstYear, lastYear)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*d = v.(DptReport)
+	return nil
+}
+
+// fetch computes the department report from database
+func (d *DptReport) fetch(db *sql.DB, firstYear, lastYear int64) (err error) {
 	qry := fmt.Sprintf(`WITH
 	housingCmt AS (SELECT d.code as dpt_code,cmt.year,SUM(cmt.value) as cmt
 		FROM cumulated_commitment cmt
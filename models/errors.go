@@ -0,0 +1,57 @@
+package models
+
+import "errors"
+
+// The kinds of the domain errors. The errors returned by the models wrap one
+// of them so that the caller can tell them apart using errors.Is.
+var (
+	// ErrNotFound is the kind of the errors of a missing row
+	ErrNotFound = errors.New("introuvable")
+	// ErrConflict is the kind of the errors of a row conflicting with an
+	// existing one or with the current state
+	ErrConflict = errors.New("conflit")
+	// ErrForbidden is the kind of the errors of an operation not allowed to
+	// the user
+	ErrForbidden = errors.New("interdit")
+	// ErrUnauthorized is the kind of the errors of a missing or invalid
+	// credential
+	ErrUnauthorized = errors.New("authentification requise")
+)
+
+// kindError is a domain error whose message is sent back to the user
+type kindError struct {
+	kind    error
+	message string
+}
+
+// Error implements the error interface
+func (e *kindError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the kind of the error
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// NewKindError returns an error of the kind with the message
+func NewKindError(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+// notFound returns the error of a missing entity
+func notFound(entity string) error {
+	return NewKindError(ErrNotFound, entity+" introuvable")
+}
+
+// ValidationError is returned when a field of a model is incorrect. Field is
+// the JSON name of the field.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Message
+}
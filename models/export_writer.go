@@ -0,0 +1,387 @@
+package models
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The formats of the streamed exports
+const (
+	JSONExport   = "json"
+	NDJSONExport = "ndjson"
+	CSVExport    = "csv"
+	XLSXExport   = "xlsx"
+)
+
+// exportContentTypes gives the content type of each export format
+var exportContentTypes = map[string]string{
+	JSONExport:   "application/json; charset=utf-8",
+	NDJSONExport: "application/x-ndjson; charset=utf-8",
+	CSVExport:    "text/csv; charset=utf-8",
+	XLSXExport:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportBufferSize is the size of the buffer of an export, the rows being
+// sent to the client each time it's full
+const exportBufferSize = 32 * 1024
+
+// exportDateLayout is the layout of the dates of the CSV exports
+const exportDateLayout = "2006-01-02"
+
+// The static parts of the XLSX exports, the only sheet being written last
+// while the rows are fetched. The second style is used by the dates.
+var xlsxExportParts = []struct {
+	name, content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// xlsxSheetName is the name of the sheet of the XLSX exports
+const xlsxSheetName = "xl/worksheets/sheet1.xml"
+
+// excelEpoch is the origin of the serial numbers of the Excel dates
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// countWriter counts the bytes written to the client to know if the response
+// has begun
+type countWriter struct {
+	w     io.Writer
+	count int64
+}
+
+// Write implements the io.Writer interface
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += int64(n)
+	return n, err
+}
+
+// ExportWriter streams the rows of an export to the client in JSON, NDJSON,
+// CSV or XLSX. The rows are structs whose JSON names are used as column
+// headers. Nothing is sent before the first buffer is full so that an error
+// of the query can still be sent back instead of the export.
+type ExportWriter struct {
+	format  string
+	name    string
+	columns []string
+	client  *countWriter
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	zip     *zip.Writer
+	sheet   io.Writer
+	rows    int64
+}
+
+// NewExportWriter returns the writer of an export in the given format. Name
+// is the key of the array of the rows in the JSON format and row is a row of
+// the export giving its columns.
+func NewExportWriter(format, name string, row interface{},
+	w io.Writer) (*ExportWriter, error) {
+	if _, ok := exportContentTypes[format]; !ok {
+		return nil, &ValidationError{Field: "Format",
+			Message: fmt.Sprintf("format %q inconnu, json, ndjson, csv ou xlsx attendu",
+				format)}
+	}
+	client := &countWriter{w: w}
+	return &ExportWriter{format: format, name: name, columns: exportColumns(row),
+		client: client, buf: bufio.NewWriterSize(client, exportBufferSize)}, nil
+}
+
+// ContentType returns the content type of the export
+func (e *ExportWriter) ContentType() string {
+	return exportContentTypes[e.format]
+}
+
+// Format returns the format of the export which is also the extension of the
+// exported file
+func (e *ExportWriter) Format() string {
+	return e.format
+}
+
+// Started returns true if a part of the export has been sent to the client
+func (e *ExportWriter) Started() bool {
+	return e.client.count > 0
+}
+
+// start writes the beginning of the export and the headers of the CSV and
+// XLSX formats
+func (e *ExportWriter) start() error {
+	switch e.format {
+	case JSONExport:
+		_, err := fmt.Fprintf(e.buf, `{%q:[`, e.name)
+		return err
+	case CSVExport:
+		e.csv = csv.NewWriter(e.buf)
+		e.csv.Comma = ';'
+		if _, err := e.buf.WriteString("\xef\xbb\xbf"); err != nil {
+			return err
+		}
+		return e.csv.Write(e.columns)
+	case XLSXExport:
+		e.zip = zip.NewWriter(e.buf)
+		for _, p := range xlsxExportParts {
+			w, err := e.zip.Create(p.name)
+			if err != nil {
+				return err
+			}
+			if _, err = io.WriteString(w, p.content); err != nil {
+				return err
+			}
+		}
+		var err error
+		if e.sheet, err = e.zip.Create(xlsxSheetName); err != nil {
+			return err
+		}
+		if _, err = io.WriteString(e.sheet, xml.Header+
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+			return err
+		}
+		cells := make([]exportCell, len(e.columns))
+		for i, c := range e.columns {
+			cells[i] = exportCell{kind: textCell, text: c}
+		}
+		return e.writeXLSXRow(cells)
+	}
+	return nil
+}
+
+// WriteRow writes a row of the export, the row being a struct or a pointer to
+// a struct
+func (e *ExportWriter) WriteRow(row interface{}) error {
+	if e.rows == 0 {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	e.rows++
+	switch e.format {
+	case JSONExport, NDJSONExport:
+		b, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if e.format == JSONExport && e.rows > 1 {
+			b = append([]byte{','}, b...)
+		}
+		if e.format == NDJSONExport {
+			b = append(b, '\n')
+		}
+		_, err = e.buf.Write(b)
+		return err
+	case CSVExport:
+		cells := exportCells(row)
+		record := make([]string, len(cells))
+		for i, c := range cells {
+			record[i] = c.csvText()
+		}
+		return e.csv.Write(record)
+	}
+	return e.writeXLSXRow(exportCells(row))
+}
+
+// writeXLSXRow writes a row of the sheet, the cells being positioned by
+// their order
+func (e *ExportWriter) writeXLSXRow(cells []exportCell) error {
+	var b strings.Builder
+	b.WriteString("<row>")
+	for _, c := range cells {
+		c.writeXLSX(&b)
+	}
+	b.WriteString("</row>")
+	_, err := io.WriteString(e.sheet, b.String())
+	return err
+}
+
+// Close ends the export and sends the rest of the buffer to the client
+func (e *ExportWriter) Close() error {
+	if e.rows == 0 {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	switch e.format {
+	case JSONExport:
+		if _, err := e.buf.WriteString("]}"); err != nil {
+			return err
+		}
+	case CSVExport:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	case XLSXExport:
+		if _, err := io.WriteString(e.sheet, `</sheetData></worksheet>`); err != nil {
+			return err
+		}
+		if err := e.zip.Close(); err != nil {
+			return err
+		}
+	}
+	return e.buf.Flush()
+}
+
+// cellKind defines how a cell is written in the XLSX exports
+type cellKind int
+
+// The kinds of the cells of the exports
+const (
+	emptyCell cellKind = iota
+	textCell
+	numberCell
+	dateCell
+	boolCell
+)
+
+// exportCell is a value of a row of a CSV or XLSX export
+type exportCell struct {
+	kind   cellKind
+	text   string
+	number float64
+	date   time.Time
+	flag   bool
+}
+
+// csvText returns the value of the cell written in the CSV exports
+func (c *exportCell) csvText() string {
+	switch c.kind {
+	case textCell:
+		return c.text
+	case numberCell:
+		return strconv.FormatFloat(c.number, 'f', -1, 64)
+	case dateCell:
+		return c.date.Format(exportDateLayout)
+	case boolCell:
+		return strconv.FormatBool(c.flag)
+	}
+	return ""
+}
+
+// writeXLSX writes the cell of a XLSX sheet, the dates being converted to
+// Excel serial numbers
+func (c *exportCell) writeXLSX(b *strings.Builder) {
+	switch c.kind {
+	case textCell:
+		b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(b, []byte(c.text))
+		b.WriteString(`</t></is></c>`)
+	case numberCell:
+		fmt.Fprintf(b, `<c><v>%s</v></c>`, strconv.FormatFloat(c.number, 'f', -1, 64))
+	case dateCell:
+		days := float64(c.date.Unix()-excelEpoch.Unix()) / 86400
+		fmt.Fprintf(b, `<c s="1"><v>%s</v></c>`, strconv.FormatFloat(days, 'f', -1, 64))
+	case boolCell:
+		v := 0
+		if c.flag {
+			v = 1
+		}
+		fmt.Fprintf(b, `<c t="b"><v>%d</v></c>`, v)
+	default:
+		b.WriteString(`<c/>`)
+	}
+}
+
+// exportColumns returns the JSON names of the fields of a row
+func exportColumns(row interface{}) []string {
+	t := reflect.Indirect(reflect.ValueOf(row)).Type()
+	columns := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// exportCells returns the cells of a row in the order of its columns
+func exportCells(row interface{}) []exportCell {
+	v := reflect.Indirect(reflect.ValueOf(row))
+	t := v.Type()
+	cells := make([]exportCell, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == "-" {
+			continue
+		}
+		cells = append(cells, exportCellOf(v.Field(i).Interface()))
+	}
+	return cells
+}
+
+// exportCellOf converts the value of a field to a cell, the invalid nullable
+// values giving empty cells
+func exportCellOf(value interface{}) exportCell {
+	switch v := value.(type) {
+	case string:
+		return exportCell{kind: textCell, text: v}
+	case int64:
+		return exportCell{kind: numberCell, number: float64(v)}
+	case int:
+		return exportCell{kind: numberCell, number: float64(v)}
+	case float64:
+		return exportCell{kind: numberCell, number: v}
+	case bool:
+		return exportCell{kind: boolCell, flag: v}
+	case time.Time:
+		return exportCell{kind: dateCell, date: v}
+	case NullString:
+		if v.Valid {
+			return exportCell{kind: textCell, text: v.String}
+		}
+	case NullInt64:
+		if v.Valid {
+			return exportCell{kind: numberCell, number: float64(v.Int64)}
+		}
+	case NullFloat64:
+		if v.Valid {
+			return exportCell{kind: numberCell, number: v.Float64}
+		}
+	case NullTime:
+		if v.Valid {
+			return exportCell{kind: dateCell, date: v.Time}
+		}
+	case NullBool:
+		if v.Valid {
+			return exportCell{kind: boolCell, flag: v.Bool}
+		}
+	default:
+		return exportCell{kind: textCell, text: fmt.Sprint(v)}
+	}
+	return exportCell{}
+}
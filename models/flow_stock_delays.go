@@ -3,7 +3,6 @@ package models
 import (
 	"database/sql"
 	"fmt"
-	"sync"
 )
 
 // FlowStockDelays model
@@ -18,35 +17,23 @@ type FlowStockDelays struct {
 	FormerFlowAverageDelay  NullFloat64 `json:"FormerFlowAverageDelay"`
 }
 
-var cachedFSD FlowStockDelays
-
-func (f *FlowStockDelays) copy(src *FlowStockDelays) {
-	f.ActualStockCount.Valid = src.ActualStockCount.Valid
-	f.ActualStockCount.Int64 = src.ActualStockCount.Int64
-	f.ActualStockAverageDelay.Valid = src.ActualStockAverageDelay.Valid
-	f.ActualStockAverageDelay.Float64 = src.ActualStockAverageDelay.Float64
-	f.ActualFlowCount.Valid = src.ActualFlowCount.Valid
-	f.ActualFlowCount.Int64 = src.ActualFlowCount.Int64
-	f.ActualFlowAverageDelay.Valid = src.ActualFlowAverageDelay.Valid
-	f.ActualFlowAverageDelay.Float64 = src.ActualFlowAverageDelay.Float64
-	f.FormerStockCount.Valid = src.FormerStockCount.Valid
-	f.FormerStockCount.Int64 = src.FormerStockCount.Int64
-	f.FormerStockAverageDelay.Valid = src.FormerStockAverageDelay.Valid
-	f.FormerStockAverageDelay.Float64 = src.FormerStockAverageDelay.Float64
-	f.FormerFlowCount.Valid = src.FormerFlowCount.Valid
-	f.FormerFlowCount.Int64 = src.FormerFlowCount.Int64
-	f.FormerFlowAverageDelay.Valid = src.FormerFlowAverageDelay.Valid
-	f.FormerFlowAverageDelay.Float64 = src.FormerFlowAverageDelay.Float64
-}
-
 // Get fetches from database flow and stock count and average delay
 func (f *FlowStockDelays) Get(days int64, db *sql.DB) error {
-	if !needUpdate(flowStockDelaysUpdate, paymentDemandsUpdate, paymentUpdate,
-		everyDayUpdate) {
-		f.copy(&cachedFSD)
-		return nil
+	v, err := aggregates.get(&flowStockDelaysCache, fmt.Sprint(days),
+		func() (interface{}, error) {
+			var r FlowStockDelays
+			err := r.fetch(days, db)
+			return r, err
+		})
+	if err != nil {
+		return err
 	}
+	*f = v.(FlowStockDelays)
+	return nil
+}
 
+// fetch computes flow and stock count and average delay from database
+func (f *FlowStockDelays) fetch(days int64, db *sql.DB) error {
 	query := fmt.Sprintf(`SELECT actual_stock.c,actual_stock.avg,actual_flow.c,
 	actual_flow.avg, former_stock.c,former_stock.avg,former_flow.c,former_flow.avg FROM 
 	(SELECT count(1) c,avg(CURRENT_DATE-receipt_date) 
@@ -65,10 +52,5 @@ func (f *FlowStockDelays) Get(days int64, db *sql.DB) error {
 		&f.FormerFlowAverageDelay); err != nil {
 		return fmt.Errorf("select %v ", err)
 	}
-	var mutex = &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
-	cachedFSD.copy(f)
-	update(csfWeekTrendUpdate)
 	return nil
 }
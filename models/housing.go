@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"strconv"
 
@@ -46,6 +45,25 @@ type HousingBatch struct {
 	Lines []HousingLine `json:"Housing"`
 }
 
+// housingMapping defines the columns of the XLSX or CSV file of a housing batch
+var housingMapping = BatchMapping{
+	Key: "Housing",
+	Columns: []BatchColumn{
+		{"Reference", ColText, []string{"Référence"}},
+		{"Address", ColText, []string{"Adresse"}},
+		{"ZipCode", ColInt, []string{"Code postal", "CP"}},
+		{"PLAI", ColInt, []string{"Nombre PLAI"}},
+		{"PLUS", ColInt, []string{"Nombre PLUS"}},
+		{"PLS", ColInt, []string{"Nombre PLS"}},
+		{"ANRU", ColBool, nil},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (h *HousingBatch) Mapping() *BatchMapping {
+	return &housingMapping
+}
+
 // PaginatedHousings embeddes an array of housing for paginated get request
 type PaginatedHousings struct {
 	Housings   []Housing `json:"Housing"`
@@ -56,7 +74,7 @@ type PaginatedHousings struct {
 // Validate checks if Housing's fields are correctly filled
 func (h *Housing) Validate() error {
 	if h.Reference == "" {
-		return errors.New("Champ Reference incorrect")
+		return &ValidationError{Field: "Reference", Message: "Champ Reference incorrect"}
 	}
 	return nil
 }
@@ -82,6 +100,7 @@ func (h *Housing) Create(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("insert %v", err)
 	}
+	invalidateCache("housing")
 	err = db.QueryRow(`SELECT name FROM city WHERE insee_code=$1`, h.ZipCode).
 		Scan(&h.CityName)
 	if err != nil {
@@ -105,12 +124,13 @@ func (h *Housing) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("housing")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Logement introuvable")
+		return notFound("Logement")
 	}
 	err = db.QueryRow(`SELECT name FROM city WHERE insee_code=$1`, h.ZipCode).
 		Scan(&h.CityName)
@@ -168,85 +188,96 @@ func (h *Housing) Delete(db *sql.DB) (err error) {
 	if err != nil {
 		return fmt.Errorf("delete %v", err)
 	}
+	invalidateCache("housing")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Logement introuvable")
+		return notFound("Logement")
 	}
 	return nil
 }
 
 // Save insert a batch of HousingLine into database
-func (h *HousingBatch) Save(db *sql.DB) (err error) {
+func (h *HousingBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(h.Lines))
 	for i, r := range h.Lines {
 		if r.Reference == "" {
-			return fmt.Errorf("ligne %d, champ Reference incorrect", i)
+			report.addError(i, "Reference", "référence vide")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_housing", "reference", "address",
-		"zip_code", "plai", "plus", "pls", "anru"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range h.Lines {
-		if _, err = stmt.Exec(r.Reference, r.Address, r.ZipCode, r.PLAI, r.PLUS,
-			r.PLS, r.ANRU); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+	return report, report.run(db, "housing", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_housing", "reference", "address",
+			"zip_code", "plai", "plus", "pls", "anru"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement exec flush %v", err)
-	}
-	queries := []string{`UPDATE housing SET address=t.address,zip_code=t.zip_code,
+		defer stmt.Close()
+		for _, r := range h.Lines {
+			if _, err = stmt.Exec(r.Reference, r.Address, r.ZipCode, r.PLAI, r.PLUS,
+				r.PLS, r.ANRU); err != nil {
+				return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement exec flush %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE housing SET address=t.address,zip_code=t.zip_code,
 	plai=t.plai,plus=t.plus,pls=t.pls,anru=t.anru FROM temp_housing t 
 	WHERE t.reference = housing.reference`,
-		`INSERT INTO housing
+			`INSERT INTO housing
 	(reference,address,zip_code,plai,plus,pls,anru)
 	SELECT reference,address,zip_code,plai,plus,pls,anru from temp_housing 
 		WHERE reference NOT IN (SELECT reference from housing)`,
-		`DELETE from temp_housing`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_housing`,
+		})
+	})
+}
+
+// housingQueryFields are the fields of the paginated housings that can be
+// used to sort or filter them
+var housingQueryFields = queryFields{
+	"ID":            {"h.id", idField},
+	"Reference":     {"h.reference", textField},
+	"Address":       {"h.address", textField},
+	"ZipCode":       {"h.zip_code", idField},
+	"CityName":      {"c.name", textField},
+	"PLAI":          {"h.plai", numberField},
+	"PLUS":          {"h.plus", numberField},
+	"PLS":           {"h.pls", numberField},
+	"ANRU":          {"h.anru", numberField},
+	"HousingTypeID": {"h.housing_type_id", idField},
 }
 
 // Get fetches a bath of paginated housings form database that fetch a search
 // pattern
 func (p *PaginatedHousings) Get(db *sql.DB, q *PaginatedQuery) error {
 	var count int64
+	filters, order, args, err := q.clauses(housingQueryFields, "1",
+		[]interface{}{"%" + q.Search + "%"})
+	if err != nil {
+		return err
+	}
+	scopeQry := q.Scope.cityFilter("h.zip_code") + filters
 	if err := db.QueryRow(`SELECT count(1) FROM housing h
 		LEFT JOIN city c ON h.zip_code=c.insee_code
-		WHERE reference ILIKE $1 OR address ILIKE $1 OR zip_code::varchar ILIKE $1
-			OR c.name ILIKE $1`,
-		"%"+q.Search+"%").Scan(&count); err != nil {
+		WHERE (reference ILIKE $1 OR address ILIKE $1 OR zip_code::varchar ILIKE $1
+			OR c.name ILIKE $1)`+scopeQry, args...).Scan(&count); err != nil {
 		return fmt.Errorf("select count %v", err)
 	}
-	offset, newPage := GetPaginateParams(q.Page, count)
+	size := q.size()
+	offset, newPage := getPageParams(q.Page, count, size)
 
 	rows, err := db.Query(`SELECT h.id,h.reference,h.address,h.zip_code,c.name,
 	h.plai,h.plus,h.pls,h.anru,h.housing_type_id,ht.short_name,ht.long_name 
 	FROM housing h
 	LEFT JOIN city c ON h.zip_code=c.insee_code
 	LEFT JOIN housing_type ht ON h.housing_type_id=ht.id
-	WHERE reference ILIKE $1 OR address ILIKE $1 OR zip_code::varchar ILIKE $1
-		OR c.name ILIKE $1
-	ORDER BY 1 LIMIT `+strconv.Itoa(PageSize)+` OFFSET $2`,
-		"%"+q.Search+"%", offset)
+	WHERE (reference ILIKE $1 OR address ILIKE $1 OR zip_code::varchar ILIKE $1
+		OR c.name ILIKE $1)`+scopeQry+` ORDER BY `+order+` LIMIT `+
+		strconv.FormatInt(size, 10)+` OFFSET $`+strconv.Itoa(len(args)+1),
+		append(args, offset)...)
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -26,7 +25,7 @@ func (r *HousingComment) Create(db *sql.DB) (err error) {
 // Valid checks if fields complies with database constraints
 func (r *HousingComment) Valid() error {
 	if r.Name == "" {
-		return fmt.Errorf("Nom vide")
+		return &ValidationError{Field: "Name", Message: "Nom vide"}
 	}
 	return nil
 }
@@ -46,7 +45,7 @@ func (r *HousingComment) Update(db *sql.DB) (err error) {
 		return fmt.Errorf("update %v", err)
 	}
 	if count != 1 {
-		return errors.New("Commentaire introuvable")
+		return notFound("Commentaire")
 	}
 	return nil
 }
@@ -87,7 +86,7 @@ func (r *HousingComment) Delete(db *sql.DB) (err error) {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Commentaire introuvable")
+		return notFound("Commentaire")
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -26,7 +25,7 @@ func (r *HousingConvention) Create(db *sql.DB) error {
 // Valid checks if fields complies with database constraints
 func (r *HousingConvention) Valid() error {
 	if r.Name == "" {
-		return fmt.Errorf("Nom vide")
+		return &ValidationError{Field: "Name", Message: "Nom vide"}
 	}
 	return nil
 }
@@ -46,7 +45,7 @@ func (r *HousingConvention) Update(db *sql.DB) error {
 		return fmt.Errorf("update %v", err)
 	}
 	if count != 1 {
-		return errors.New("Convention introuvable")
+		return notFound("Convention")
 	}
 	return nil
 }
@@ -86,7 +85,7 @@ func (r *HousingConvention) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Convention introuvable")
+		return notFound("Convention")
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -39,16 +38,34 @@ type HousingForecastBatch struct {
 	Lines []HousingForecastLine `json:"HousingForecast"`
 }
 
+// housingForecastMapping defines the columns of the XLSX or CSV file of a
+// housing forecast batch
+var housingForecastMapping = BatchMapping{
+	Key: "HousingForecast",
+	Columns: []BatchColumn{
+		{"ID", ColInt, nil},
+		{"CommissionID", ColInt, []string{"Commission", "ID commission"}},
+		{"Value", ColInt, []string{"Montant", "Prévision"}},
+		{"Comment", ColText, []string{"Commentaire"}},
+		{"ActionID", ColInt, []string{"Action", "ID action"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (h *HousingForecastBatch) Mapping() *BatchMapping {
+	return &housingForecastMapping
+}
+
 // Validate checks if HousingForecast's fields are correctly filled
 func (r *HousingForecast) Validate() error {
 	if r.CommissionID == 0 {
-		return errors.New("Champ CommissionID incorrect")
+		return &ValidationError{Field: "CommissionID", Message: "Champ CommissionID incorrect"}
 	}
 	if r.Value == 0 {
-		return errors.New("Champ Value incorrect")
+		return &ValidationError{Field: "Value", Message: "Champ Value incorrect"}
 	}
 	if r.ActionID == 0 {
-		return errors.New("Champ ActionID incorrect")
+		return &ValidationError{Field: "ActionID", Message: "Champ ActionID incorrect"}
 	}
 	return nil
 }
@@ -92,7 +109,7 @@ func (r *HousingForecast) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Prévision housing introuvable")
+		return notFound("Prévision housing")
 	}
 	return db.QueryRow(`SELECT c.name,c.date,b.name FROM housing_forecast h
 	JOIN commission c ON c.id=h.commission_id
@@ -172,58 +189,47 @@ func (r *HousingForecast) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Prévision housing introuvable")
+		return notFound("Prévision housing")
 	}
 	return nil
 }
 
 // Save insert a batch of HousingForecastLine into database
-func (r *HousingForecastBatch) Save(db *sql.DB) error {
-	for i, r := range r.Lines {
-		if r.CommissionID == 0 {
-			return fmt.Errorf("ligne %d, CommissionID nul", i+1)
+func (r *HousingForecastBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(r.Lines))
+	for i, l := range r.Lines {
+		if l.CommissionID == 0 {
+			report.addError(i, "CommissionID", "commission nulle")
 		}
-		if r.Value == 0 {
-			return fmt.Errorf("ligne %d, Value nul", i+1)
+		if l.Value == 0 {
+			report.addError(i, "Value", "montant nul")
 		}
-		if r.ActionID == 0 {
-			return fmt.Errorf("ligne %d, ActionID nul", i+1)
+		if l.ActionID == 0 {
+			report.addError(i, "ActionID", "action nulle")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("début de transaction %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_housing_forecast", "id", "commission_id",
-		"value", "comment", "action_id"))
-	if err != nil {
-		return fmt.Errorf("insert statement %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range r.Lines {
-		if _, err = stmt.Exec(r.ID, r.CommissionID, r.Value, r.Comment, r.ActionID); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("statement execution %v", err)
+	return report, report.run(db, "housing_forecast", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_housing_forecast", "id", "commission_id",
+			"value", "comment", "action_id"))
+		if err != nil {
+			return fmt.Errorf("insert statement %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{`UPDATE housing_forecast SET commission_id=t.commission_id,
+		defer stmt.Close()
+		for _, r := range r.Lines {
+			if _, err = stmt.Exec(r.ID, r.CommissionID, r.Value, r.Comment, r.ActionID); err != nil {
+				return fmt.Errorf("statement execution %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE housing_forecast SET commission_id=t.commission_id,
 	value=t.value,comment=t.comment,action_id=t.action_id 
 	FROM temp_housing_forecast t WHERE t.id = housing_forecast.id`,
-		`INSERT INTO housing_forecast (commission_id,value,comment,action_id)
+			`INSERT INTO housing_forecast (commission_id,value,comment,action_id)
 	SELECT commission_id,value,comment,action_id from temp_housing_forecast 
 		WHERE id NOT IN (SELECT id from housing_forecast)`,
-		`DELETE from temp_housing_forecast`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_housing_forecast`,
+		})
+	})
 }
@@ -27,26 +27,46 @@ type HousingSummary struct {
 	Lines []HousingSummaryLine `json:"HousingSummary"`
 }
 
+// housingSummaryMapping defines the columns of the XLSX or CSV file of a
+// housing summary batch
+var housingSummaryMapping = BatchMapping{
+	Key: "HousingSummary",
+	Columns: []BatchColumn{
+		{"InseeCode", ColInt, []string{"Code INSEE", "INSEE"}},
+		{"Address", ColText, []string{"Adresse"}},
+		{"PLS", ColInt, []string{"Nombre PLS"}},
+		{"PLAI", ColInt, []string{"Nombre PLAI"}},
+		{"PLUS", ColInt, []string{"Nombre PLUS"}},
+		{"IRISCode", ColText, []string{"Code IRIS", "N° IRIS"}},
+		{"ReferenceCode", ColText, []string{"Référence", "Code référence"}},
+		{"Anru", ColBool, []string{"ANRU"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (h *HousingSummary) Mapping() *BatchMapping {
+	return &housingSummaryMapping
+}
+
 // validate checks if fields are correctly filled
-func (h *HousingSummary) validate() error {
+func (h *HousingSummary) validate(r *BatchReport) {
 	for i, l := range h.Lines {
 		if l.InseeCode == 0 {
-			return fmt.Errorf("line %d InseeCode nul", i+1)
+			r.addError(i, "InseeCode", "code INSEE nul")
 		}
 		if l.Address == "" {
-			return fmt.Errorf("line %d Address nul", i+1)
+			r.addError(i, "Address", "adresse vide")
 		}
 		if l.PLS == 0 && l.PLAI == 0 && l.PLUS == 0 {
-			return fmt.Errorf("line %d PLS PLAI and PLUS nul", i+1)
+			r.addError(i, "PLS", "PLS, PLAI et PLUS nuls")
 		}
 		if l.IRISCode == "" {
-			return fmt.Errorf("line %d IrisCode nul", i+1)
+			r.addError(i, "IRISCode", "code IRIS vide")
 		}
 		if l.ReferenceCode == "" {
-			return fmt.Errorf("line %d ReferenceCode nul", i+1)
+			r.addError(i, "ReferenceCode", "référence vide")
 		}
 	}
-	return nil
 }
 
 // fetchMaxDptRef calculates the max number of the housing reference in the
@@ -95,90 +115,73 @@ type refsIRIS struct {
 
 // Save import a housing summary batch, validates it and process it to create
 // new housing lines
-func (h *HousingSummary) Save(db *sql.DB) error {
-	if err := h.validate(); err != nil {
-		return err
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_housing_summary", "insee_code",
-		"address", "pls", "plai", "plus", "iris_code", "reference_code", "anru"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for i, l := range h.Lines {
-		if _, err = stmt.Exec(l.InseeCode, l.Address, l.PLS, l.PLAI, l.PLUS,
-			l.IRISCode, l.ReferenceCode, l.ANRU); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("line %d %v", i, err)
+func (h *HousingSummary) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(h.Lines))
+	h.validate(report)
+	return report, report.run(db, "housing_summary", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_housing_summary", "insee_code",
+			"address", "pls", "plai", "plus", "iris_code", "reference_code", "anru"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	year := time.Now().Year()
-	decade := year % 100
-	maxDptRef, err := fetchMaxDptRef(decade, tx)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-	rows, err := tx.Query(`SELECT reference_code,insee_code,max(address),SUM(pls),
+		defer stmt.Close()
+		for i, l := range h.Lines {
+			if _, err = stmt.Exec(l.InseeCode, l.Address, l.PLS, l.PLAI, l.PLUS,
+				l.IRISCode, l.ReferenceCode, l.ANRU); err != nil {
+				return fmt.Errorf("line %d %v", i, err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		year := time.Now().Year()
+		decade := year % 100
+		maxDptRef, err := fetchMaxDptRef(decade, tx)
+		if err != nil {
+			return err
+		}
+		rows, err := tx.Query(`SELECT reference_code,insee_code,max(address),SUM(pls),
 		SUM(plai),SUM(plus),bool_or(anru) FROM temp_housing_summary 
 	WHERE reference_code NOT IN
 		(SELECT import_ref FROM housing_summary WHERE year=$1)
 		GROUP BY 1,2`, year)
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("select temp_housing_summary %v", err)
-	}
-	defer rows.Close()
-	var hsg composedHousing
-	var hh []composedHousing
-	var dpt int
-	for rows.Next() {
-		if err = rows.Scan(&hsg.SummaryRef, &hsg.ZipCode, &hsg.Address,
-			&hsg.PLS, &hsg.PLAI, &hsg.PLUS, &hsg.ANRU); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("scan temp_housing_summary %v", err)
+		if err != nil {
+			return fmt.Errorf("select temp_housing_summary %v", err)
 		}
-		dpt = int(hsg.ZipCode / 1000)
-		hsg.HousingRef = fmt.Sprintf("LLS%d%d%03d", dpt, decade, maxDptRef[dpt]+1)
-		hh = append(hh, hsg)
-		maxDptRef[dpt]++
-	}
-	for _, hsg = range hh {
-		if _, err = tx.Exec(`INSERT INTO housing (reference,address,zip_code,plai,
-			plus,pls,anru) VALUES($1,$2,$3,$4,$5,$6,$7)`, hsg.HousingRef, hsg.Address,
-			hsg.ZipCode, hsg.PLAI, hsg.PLUS, hsg.PLS, hsg.ANRU); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insert housing %v", err)
+		defer rows.Close()
+		var hsg composedHousing
+		var hh []composedHousing
+		var dpt int
+		for rows.Next() {
+			if err = rows.Scan(&hsg.SummaryRef, &hsg.ZipCode, &hsg.Address,
+				&hsg.PLS, &hsg.PLAI, &hsg.PLUS, &hsg.ANRU); err != nil {
+				return fmt.Errorf("scan temp_housing_summary %v", err)
+			}
+			dpt = int(hsg.ZipCode / 1000)
+			hsg.HousingRef = fmt.Sprintf("LLS%d%d%03d", dpt, decade, maxDptRef[dpt]+1)
+			hh = append(hh, hsg)
+			maxDptRef[dpt]++
 		}
-		if _, err = tx.Exec(`INSERT INTO housing_summary (year,housing_ref,
+		for _, hsg = range hh {
+			if _, err = tx.Exec(`INSERT INTO housing (reference,address,zip_code,plai,
+			plus,pls,anru) VALUES($1,$2,$3,$4,$5,$6,$7)`, hsg.HousingRef, hsg.Address,
+				hsg.ZipCode, hsg.PLAI, hsg.PLUS, hsg.PLS, hsg.ANRU); err != nil {
+				return fmt.Errorf("insert housing %v", err)
+			}
+			if _, err = tx.Exec(`INSERT INTO housing_summary (year,housing_ref,
 			import_ref,iris_code) SELECT $1,$2::varchar,$3::varchar,iris_code FROM temp_housing_summary 
 			WHERE reference_code=$3`, year, hsg.HousingRef, hsg.SummaryRef); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insert housing_summary %v", err)
+				return fmt.Errorf("insert housing_summary %v", err)
+			}
 		}
-	}
-	queries := []string{
-		`UPDATE commitment SET housing_id=q.housing_id, 
+		return execQueries(tx, []string{
+			`UPDATE commitment SET housing_id=q.housing_id, 
 	copro_id=NULL, renew_project_id=NULL FROM
 		(SELECT c.id AS commitment_id, h.id AS housing_id FROM housing h 
 			JOIN housing_summary hs ON h.reference = hs.housing_ref
 			JOIN commitment c ON hs.iris_code=c.iris_code) q 
 	WHERE commitment.id=q.commitment_id`,
-		`DELETE FROM temp_housing_summary`,
-	}
-	for i, q := range queries {
-		if _, err := tx.Exec(q); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("query %d commitment query %v", i, err)
-		}
-	}
-	return tx.Commit()
+			`DELETE FROM temp_housing_summary`,
+		})
+	})
 }
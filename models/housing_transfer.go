@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -26,7 +25,7 @@ func (r *HousingTransfer) Create(db *sql.DB) error {
 // Valid checks if fields complies with database constraints
 func (r *HousingTransfer) Valid() error {
 	if r.Name == "" {
-		return fmt.Errorf("Nom vide")
+		return &ValidationError{Field: "Name", Message: "Nom vide"}
 	}
 	return nil
 }
@@ -50,7 +49,7 @@ func (r *HousingTransfer) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Transfert introuvable")
+		return notFound("Transfert")
 	}
 	return nil
 }
@@ -90,7 +89,7 @@ func (r *HousingTransfer) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Transfert introuvable")
+		return notFound("Transfert")
 	}
 	return nil
 }
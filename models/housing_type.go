@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -29,7 +28,7 @@ func (r *HousingType) Create(db *sql.DB) error {
 // Valid checks if fields complies with database constraints
 func (r *HousingType) Valid() error {
 	if r.ShortName == "" {
-		return fmt.Errorf("Nom court vide")
+		return &ValidationError{Field: "ShortName", Message: "Nom court vide"}
 	}
 	return nil
 }
@@ -49,7 +48,7 @@ func (r *HousingType) Update(db *sql.DB) error {
 		return fmt.Errorf("update %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type introuvable")
+		return notFound("Type")
 	}
 	return nil
 }
@@ -89,7 +88,7 @@ func (r *HousingType) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type introuvable")
+		return notFound("Type")
 	}
 	return nil
 }
@@ -105,55 +104,59 @@ type IRISHousingTypes struct {
 	Lines []IRISHousingType `json:"IRISHousingType"`
 }
 
+// irisHousingTypeMapping defines the columns of the XLSX or CSV file linking
+// IRIS commitments to housing types
+var irisHousingTypeMapping = BatchMapping{
+	Key: "IRISHousingType",
+	Columns: []BatchColumn{
+		{"IRISCode", ColText, []string{"Code IRIS", "N° IRIS"}},
+		{"HousingTypeShortName", ColText, []string{"Type de logement", "Type"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (i *IRISHousingTypes) Mapping() *BatchMapping {
+	return &irisHousingTypeMapping
+}
+
 // Save import a batch of IRISHousingTypes, update the HousingType database and
 // update all Housings with the housing types
-func (i *IRISHousingTypes) Save(db *sql.DB) error {
+func (i *IRISHousingTypes) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(i.Lines))
 	for j, ii := range i.Lines {
 		if ii.IRISCode == "" {
-			return fmt.Errorf("line %d IrisCode vide", j+1)
+			report.addError(j, "IRISCode", "code IRIS vide")
 		}
 		if ii.HousingTypeShortName == "" {
-			return fmt.Errorf("ligne %d HousingTypeShortName vide", j+1)
+			report.addError(j, "HousingTypeShortName", "type de logement vide")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_iris_housing_type", "iris_code",
-		"housing_type_short_name"))
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-	for _, ii := range i.Lines {
-		if _, err = stmt.Exec(ii.IRISCode, ii.HousingTypeShortName); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("stmt exec %v", err)
+	return report, report.run(db, "housing", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_iris_housing_type", "iris_code",
+			"housing_type_short_name"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{
-		`INSERT INTO housing_type(short_name,long_name)
+		defer stmt.Close()
+		for _, ii := range i.Lines {
+			if _, err = stmt.Exec(ii.IRISCode, ii.HousingTypeShortName); err != nil {
+				return fmt.Errorf("stmt exec %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{
+			`INSERT INTO housing_type(short_name,long_name)
 			SELECT DISTINCT t.housing_type_short_name,NULL FROM temp_iris_housing_type t
 			WHERE t.housing_type_short_name NOT IN 
 				(SELECT short_name FROM housing_type)`,
-		`UPDATE housing SET housing_type_id=q.id
+			`UPDATE housing SET housing_type_id=q.id
 			FROM (SELECT ht.id,hs.housing_ref
 			FROM temp_iris_housing_type t
 			JOIN housing_type ht ON t.housing_type_short_name=ht.short_name
 			JOIN housing_summary hs ON t.iris_code=hs.iris_code) q
 			WHERE housing.reference=q.housing_ref`,
-	}
-	for j, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d: %v", j+1, err)
-		}
-	}
-	return tx.Commit()
+		})
+	})
 }
@@ -2,14 +2,29 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"time"
 )
 
-// ImportLog model
+// Import kinds stored in import_logs. Commitment and payment imports are
+// stamped by database triggers, the others by the automatic ingestion.
+const (
+	CommitmentImport    = 1
+	PaymentImport       = 2
+	PaymentDemandImport = 3
+	PlacementImport     = 4
+)
+
+// ImportLog model. Date is the one of the last successful import and the
+// other fields give the outcome of the last automatic import.
 type ImportLog struct {
-	Kind int64     `json:"Kind"`
-	Date time.Time `json:"Date"`
+	Kind     int64           `json:"Kind"`
+	Date     NullTime        `json:"Date"`
+	FileName NullString      `json:"FileName"`
+	Status   NullString      `json:"Status"`
+	Report   json.RawMessage `json:"Report"`
+	Error    NullString      `json:"Error"`
+	RanAt    NullTime        `json:"RanAt"`
 }
 
 // ImportLogs embeddes an array of ImportLog for json export
@@ -17,18 +32,31 @@ type ImportLogs struct {
 	Logs []ImportLog `json:"ImportLog"`
 }
 
+// ingestionOK and ingestionError are the status of an automatic import
+const (
+	ingestionOK    = "ok"
+	ingestionError = "erreur"
+)
+
 // GetAll fetches all import logs from database
 func (i *ImportLogs) GetAll(db *sql.DB) error {
-	rows, err := db.Query(`SELECT kind,date FROM import_logs`)
+	rows, err := db.Query(`SELECT kind,date,file_name,status,report,error,ran_at
+	FROM import_logs`)
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
 	defer rows.Close()
-	var row ImportLog
+	var report []byte
 	for rows.Next() {
-		if err = rows.Scan(&row.Kind, &row.Date); err != nil {
+		var row ImportLog
+		if err = rows.Scan(&row.Kind, &row.Date, &row.FileName, &row.Status,
+			&report, &row.Error, &row.RanAt); err != nil {
 			return fmt.Errorf("scan %v", err)
 		}
+		row.Report = json.RawMessage("null")
+		if report != nil {
+			row.Report = append(json.RawMessage{}, report...)
+		}
 		i.Logs = append(i.Logs, row)
 	}
 	err = rows.Err()
@@ -40,3 +68,32 @@ func (i *ImportLogs) GetAll(db *sql.DB) error {
 	}
 	return nil
 }
+
+// logIngestion stores the outcome of an automatic import. The date of the
+// import kind is only updated if the import succeeded.
+func logIngestion(db *sql.DB, kind int64, fileName string, r *BatchReport,
+	ingestErr error) error {
+	var report, errMsg NullString
+	if r != nil {
+		j, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("marshal %v", err)
+		}
+		report.String, report.Valid = string(j), true
+	}
+	status := ingestionOK
+	if ingestErr != nil {
+		status = ingestionError
+		errMsg.String, errMsg.Valid = ingestErr.Error(), true
+	}
+	if _, err := db.Exec(`INSERT INTO import_logs
+		(kind,date,file_name,status,report,error,ran_at)
+	VALUES($1,CASE WHEN $6 THEN CURRENT_DATE END,$2,$4,$3,$5,now())
+	ON CONFLICT (kind) DO UPDATE SET
+		date=CASE WHEN $6 THEN CURRENT_DATE ELSE import_logs.date END,
+		file_name=$2,status=$4,report=$3,error=$5,ran_at=now()`,
+		kind, fileName, report, status, errMsg, ingestErr == nil); err != nil {
+		return fmt.Errorf("upsert %v", err)
+	}
+	return nil
+}
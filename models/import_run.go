@@ -0,0 +1,318 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ImportRun model stores a batch import with the counts of its report. Kind is
+// the name of the main table of the batch.
+type ImportRun struct {
+	ID        int64      `json:"ID"`
+	Kind      string     `json:"Kind"`
+	UserID    NullInt64  `json:"UserID"`
+	UserName  NullString `json:"UserName"`
+	FileName  NullString `json:"FileName"`
+	Lines     int64      `json:"Lines"`
+	Inserted  int64      `json:"Inserted"`
+	Updated   int64      `json:"Updated"`
+	Deleted   int64      `json:"Deleted"`
+	Unchanged int64      `json:"Unchanged"`
+	Created   time.Time  `json:"Created"`
+	Reverted  NullTime   `json:"Reverted"`
+}
+
+// PaginatedImportRuns embeddes the import runs of a kind using paginated format
+type PaginatedImportRuns struct {
+	Lines      []ImportRun `json:"ImportRun"`
+	Page       int64       `json:"Page"`
+	ItemsCount int64       `json:"ItemsCount"`
+}
+
+// ImportRunRow model stores a row changed by an import run. For an update,
+// Before and After only contain the changed fields.
+type ImportRunRow struct {
+	ID        int64           `json:"ID"`
+	Entity    string          `json:"Entity"`
+	EntityID  NullString      `json:"EntityID"`
+	Operation string          `json:"Operation"`
+	Before    json.RawMessage `json:"Before"`
+	After     json.RawMessage `json:"After"`
+}
+
+// PaginatedImportRunRows embeddes the rows changed by an import run using
+// paginated format
+type PaginatedImportRunRows struct {
+	Lines      []ImportRunRow `json:"ImportRunRow"`
+	Page       int64          `json:"Page"`
+	ItemsCount int64          `json:"ItemsCount"`
+}
+
+// RevertableImports are the kinds of import runs that can be reverted
+var RevertableImports = []string{"commitment", "payment", "payment_demands"}
+
+// ErrNoImportRun is returned when there's no import run to revert
+var ErrNoImportRun = NewKindError(ErrNotFound, "aucun import à annuler")
+
+// beginImportRun creates the import run of a batch and sets the parameter used
+// by the triggers of the imported tables to log the changed rows in the run
+func beginImportRun(tx *sql.Tx, kind string, fileName string) (int64, error) {
+	var ID int64
+	if err := tx.QueryRow(`INSERT INTO import_run (kind,user_id,file_name,created)
+	VALUES($1,NULLIF(current_setting('preloru.user_id',true),'')::int,
+		NULLIF($2,''),now()) RETURNING id`, kind, fileName).Scan(&ID); err != nil {
+		return 0, fmt.Errorf("import run insert %v", err)
+	}
+	if _, err := tx.Exec(`SELECT set_config('preloru.import_run_id',$1,true)`,
+		strconv.FormatInt(ID, 10)); err != nil {
+		return 0, fmt.Errorf("import run set %v", err)
+	}
+	return ID, nil
+}
+
+// endImportRun stores the counts of the report in the import run, stops the
+// logging of the changed rows and returns the tables changed by the run
+func endImportRun(tx *sql.Tx, ID int64, r *BatchReport) ([]string, error) {
+	if _, err := tx.Exec(`UPDATE import_run SET lines=$2,inserted=$3,updated=$4,
+	deleted=$5,unchanged=$6 WHERE id=$1`, ID, r.Lines, r.Inserted, r.Updated,
+		r.Deleted, r.Unchanged); err != nil {
+		return nil, fmt.Errorf("import run update %v", err)
+	}
+	if _, err := tx.Exec(`SELECT set_config('preloru.import_run_id','',true)`); err != nil {
+		return nil, fmt.Errorf("import run reset %v", err)
+	}
+	rows, err := tx.Query(`SELECT DISTINCT entity FROM import_run_row
+	WHERE run_id=$1`, ID)
+	if err != nil {
+		return nil, fmt.Errorf("import run entities %v", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var t string
+		if err = rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("import run entity scan %v", err)
+		}
+		tables = append(tables, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("import run entities rows %v", err)
+	}
+	return tables, nil
+}
+
+// Get fetches the import runs of a kind, all if kind is empty, the most recent
+// first using paginated format
+func (p *PaginatedImportRuns) Get(db *sql.DB, page int64, kind string) error {
+	var count int64
+	if err := db.QueryRow(`SELECT count(1) FROM import_run
+	WHERE $1='' OR kind=$1`, kind).Scan(&count); err != nil {
+		return fmt.Errorf("count %v", err)
+	}
+	offset, newPage := GetPaginateParams(page, count)
+	rows, err := db.Query(`SELECT r.id,r.kind,r.user_id,u.name,r.file_name,r.lines,
+	r.inserted,r.updated,r.deleted,r.unchanged,r.created,r.reverted
+	FROM import_run r LEFT JOIN users u ON r.user_id=u.id
+	WHERE $1='' OR r.kind=$1
+	ORDER BY r.id DESC LIMIT `+strconv.Itoa(PageSize)+` OFFSET $2`, kind, offset)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row ImportRun
+		if err = rows.Scan(&row.ID, &row.Kind, &row.UserID, &row.UserName,
+			&row.FileName, &row.Lines, &row.Inserted, &row.Updated, &row.Deleted,
+			&row.Unchanged, &row.Created, &row.Reverted); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		p.Lines = append(p.Lines, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(p.Lines) == 0 {
+		p.Lines = []ImportRun{}
+	}
+	p.Page = newPage
+	p.ItemsCount = count
+	return nil
+}
+
+// Get fetches the rows changed by an import run using paginated format. The
+// fields of an updated row that didn't change are removed.
+func (p *PaginatedImportRunRows) Get(db *sql.DB, runID int64, page int64) error {
+	var count int64
+	if err := db.QueryRow(`SELECT count(1) FROM import_run_row WHERE run_id=$1`,
+		runID).Scan(&count); err != nil {
+		return fmt.Errorf("count %v", err)
+	}
+	offset, newPage := GetPaginateParams(page, count)
+	rows, err := db.Query(`SELECT r.id,r.entity,r.entity_id,r.operation,
+	CASE WHEN r.operation='UPDATE' THEN
+		(SELECT jsonb_object_agg(b.key,b.value) FROM jsonb_each(r.before) b
+		WHERE b.value IS DISTINCT FROM r.after->b.key)
+		ELSE r.before END,
+	CASE WHEN r.operation='UPDATE' THEN
+		(SELECT jsonb_object_agg(a.key,a.value) FROM jsonb_each(r.after) a
+		WHERE a.value IS DISTINCT FROM r.before->a.key)
+		ELSE r.after END
+	FROM import_run_row r WHERE r.run_id=$1
+	ORDER BY r.id LIMIT `+strconv.Itoa(PageSize)+` OFFSET $2`, runID, offset)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	defer rows.Close()
+	var before, after []byte
+	for rows.Next() {
+		var row ImportRunRow
+		if err = rows.Scan(&row.ID, &row.Entity, &row.EntityID, &row.Operation,
+			&before, &after); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		row.Before, row.After = json.RawMessage("null"), json.RawMessage("null")
+		if before != nil {
+			row.Before = append(json.RawMessage{}, before...)
+		}
+		if after != nil {
+			row.After = append(json.RawMessage{}, after...)
+		}
+		p.Lines = append(p.Lines, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(p.Lines) == 0 {
+		p.Lines = []ImportRunRow{}
+	}
+	p.Page = newPage
+	p.ItemsCount = count
+	return nil
+}
+
+// RevertLastImport restores the tables changed by the last import run of the
+// kind that wasn't already reverted, using the rows logged at import time:
+// inserted rows are deleted, updated rows get back their previous values and
+// deleted rows are inserted again. The entities are processed in the reverse
+// order of their changes. Changes made to the rows after the import are lost.
+func (r *ImportRun) RevertLastImport(db *sql.DB, kind string) error {
+	revertable := false
+	for _, k := range RevertableImports {
+		revertable = revertable || k == kind
+	}
+	if !revertable {
+		return fmt.Errorf("import %s non annulable", kind)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	err = tx.QueryRow(`SELECT id FROM import_run WHERE kind=$1 AND reverted IS NULL
+	ORDER BY id DESC LIMIT 1 FOR UPDATE`, kind).Scan(&r.ID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrNoImportRun
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("select run %v", err)
+	}
+	rows, err := tx.Query(`SELECT entity FROM import_run_row WHERE run_id=$1
+	GROUP BY 1 ORDER BY max(id) DESC`, r.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("select entities %v", err)
+	}
+	var entities []string
+	for rows.Next() {
+		var e string
+		if err = rows.Scan(&e); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return fmt.Errorf("scan entity %v", err)
+		}
+		entities = append(entities, e)
+	}
+	rows.Close()
+	for _, e := range entities {
+		if err = revertEntity(tx, r.ID, e); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s %v", e, err)
+		}
+	}
+	if err = tx.QueryRow(`UPDATE import_run SET reverted=now(),
+	reverted_by=NULLIF(current_setting('preloru.user_id',true),'')::int
+	WHERE id=$1 RETURNING id,kind,user_id,file_name,lines,inserted,updated,deleted,
+		unchanged,created,reverted`, r.ID).Scan(&r.ID, &r.Kind, &r.UserID,
+		&r.FileName, &r.Lines, &r.Inserted, &r.Updated, &r.Deleted, &r.Unchanged,
+		&r.Created, &r.Reverted); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update run %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit %v", err)
+	}
+	invalidateCache(entities...)
+	return nil
+}
+
+// revertEntity restores the rows of a table changed by an import run
+func revertEntity(tx *sql.Tx, runID int64, entity string) error {
+	var key string
+	rows, err := tx.Query(`SELECT column_name FROM information_schema.columns
+	WHERE table_schema='public' AND table_name=$1 ORDER BY ordinal_position`,
+		entity)
+	if err != nil {
+		return fmt.Errorf("select columns %v", err)
+	}
+	var columns, values []string
+	for rows.Next() {
+		var c string
+		if err = rows.Scan(&c); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan column %v", err)
+		}
+		if key == "" && (c == "id" || c == "insee_code") {
+			key = c
+			continue
+		}
+		columns = append(columns, pq.QuoteIdentifier(c))
+		values = append(values, "r."+pq.QuoteIdentifier(c))
+	}
+	rows.Close()
+	if key == "" || len(columns) == 0 {
+		return fmt.Errorf("table sans identifiant")
+	}
+	table := pq.QuoteIdentifier(entity)
+	// The earliest change of each row gives its state before the run, a row
+	// first inserted by the run having no former state
+	earliest := `SELECT DISTINCT ON (entity_id) entity_id AS run_entity_id,
+		operation AS run_operation,
+		(jsonb_populate_record(NULL::` + table + `,before)).*
+	FROM import_run_row WHERE run_id=$1 AND entity=$2 ORDER BY entity_id,id`
+	queries := []string{`DELETE FROM ` + table + ` WHERE ` + key +
+		`::text IN (SELECT run_entity_id FROM (` + earliest + `) f
+		WHERE run_operation='INSERT')`,
+		`UPDATE ` + table + ` SET (` + strings.Join(columns, ",") + `)=ROW(` +
+			strings.Join(values, ",") + `) FROM (` + earliest + `) r
+		WHERE r.run_operation<>'INSERT' AND ` + table + `.` + key + `=r.` + key,
+		`INSERT INTO ` + table + ` (` + key + `,` + strings.Join(columns, ",") +
+			`) SELECT r.` + key + `,` + strings.Join(values, ",") + ` FROM (` +
+			earliest + `) r
+		WHERE r.run_operation<>'INSERT' AND NOT EXISTS (SELECT 1 FROM ` + table +
+			` WHERE ` + table + `.` + key + `=r.` + key + `)`}
+	for i, q := range queries {
+		if _, err = tx.Exec(q, runID, entity); err != nil {
+			return fmt.Errorf("requête %d : %v", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IngestionLogger is used by the ingestion to log its activity
+type IngestionLogger interface {
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// ingestionKind defines a kind of IRIS export that can be ingested. The file
+// is recognized by its headers that must contain the required fields.
+type ingestionKind struct {
+	kind     int64
+	name     string
+	mapping  *BatchMapping
+	required []string
+	save     func(name string, r io.ReaderAt, size int64, db *sql.DB) (*BatchReport, error)
+}
+
+// ingestionKinds are the IRIS exports handled by the ingestion
+var ingestionKinds = []ingestionKind{
+	{CommitmentImport, "engagements", &commitmentMapping,
+		[]string{"Year", "Code", "Number", "Line", "BeneficiaryCode"},
+		func(name string, r io.ReaderAt, size int64, db *sql.DB) (*BatchReport, error) {
+			var b CommitmentBatch
+			if err := DecodeBatchFile(name, r, size, nil, &b); err != nil {
+				return nil, err
+			}
+			return b.Save(BatchOptions{FileName: name}, db)
+		}},
+	{PaymentImport, "paiements", &paymentMapping,
+		[]string{"CommitmentYear", "CommitmentNumber", "Number", "Value"},
+		func(name string, r io.ReaderAt, size int64, db *sql.DB) (*BatchReport, error) {
+			var b PaymentBatch
+			if err := DecodeBatchFile(name, r, size, nil, &b); err != nil {
+				return nil, err
+			}
+			return b.Save(BatchOptions{FileName: name}, db)
+		}},
+	{PaymentDemandImport, "demandes de paiement", &paymentDemandMapping,
+		[]string{"IrisCode", "DemandNumber", "DemandDate"},
+		func(name string, r io.ReaderAt, size int64, db *sql.DB) (*BatchReport, error) {
+			var b PaymentDemandBatch
+			if err := DecodeBatchFile(name, r, size, map[string]string{
+				"ImportDate": time.Now().Format("2006-01-02")}, &b); err != nil {
+				return nil, err
+			}
+			return b.Save(BatchOptions{FileName: name}, db)
+		}},
+	{PlacementImport, "stages", &placementMapping,
+		[]string{"IrisCode", "Count"},
+		func(name string, r io.ReaderAt, size int64, db *sql.DB) (*BatchReport, error) {
+			var b Placements
+			if err := DecodeBatchFile(name, r, size, nil, &b); err != nil {
+				return nil, err
+			}
+			return b.Save(BatchOptions{FileName: name}, db)
+		}},
+}
+
+// Ingestor polls a drop directory for IRIS exports, imports them using the
+// batch matching their headers and moves them to the archive directory or to
+// the error directory. Files modified for less than MinAge are skipped as
+// they may still be written.
+type Ingestor struct {
+	Dir        string
+	ArchiveDir string
+	ErrorDir   string
+	Interval   time.Duration
+	MinAge     time.Duration
+	DB         *sql.DB
+	Logger     IngestionLogger
+	stop       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// Start creates the archive and error directories and launches the polling
+// of the drop directory in background
+func (i *Ingestor) Start() error {
+	for _, d := range []string{i.ArchiveDir, i.ErrorDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return fmt.Errorf("création du répertoire %s %v", d, err)
+		}
+	}
+	i.stop = make(chan struct{})
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		ticker := time.NewTicker(i.Interval)
+		defer ticker.Stop()
+		for {
+			i.Scan()
+			select {
+			case <-i.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends the polling, waiting for the current import to finish
+func (i *Ingestor) Stop() {
+	if i.stop == nil {
+		return
+	}
+	close(i.stop)
+	i.wg.Wait()
+	i.stop = nil
+}
+
+// Scan imports every file of the drop directory
+func (i *Ingestor) Scan() {
+	files, err := ioutil.ReadDir(i.Dir)
+	if err != nil {
+		i.Logger.Errorf("Ingestion, lecture de %s : %v", i.Dir, err)
+		return
+	}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") ||
+			strings.HasPrefix(f.Name(), "~$") ||
+			time.Since(f.ModTime()) < i.MinAge {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(f.Name())) {
+		case ".csv", ".txt", ".xlsx":
+			i.ingest(f.Name())
+		}
+	}
+}
+
+// detectKind returns the kind of IRIS export matching the headers of the file
+// with the most recognized columns
+func detectKind(name string, r io.ReaderAt, size int64) (*ingestionKind, error) {
+	rows, err := readSpreadsheet(name, r, size)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := headerIndex(rows)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		kind *ingestionKind
+		best int
+	)
+	for j := range ingestionKinds {
+		count, ok := ingestionKinds[j].mapping.matchCount(rows[idx],
+			ingestionKinds[j].required)
+		if ok && count > best {
+			kind, best = &ingestionKinds[j], count
+		}
+	}
+	if kind == nil {
+		return nil, fmt.Errorf("type de fichier non reconnu")
+	}
+	return kind, nil
+}
+
+// ingest imports a file of the drop directory, logs the outcome and moves the
+// file according to the result
+func (i *Ingestor) ingest(name string) {
+	path := filepath.Join(i.Dir, name)
+	report, kind, err := i.importFile(path)
+	dest := i.ArchiveDir
+	if err != nil {
+		dest = i.ErrorDir
+		i.Logger.Errorf("Ingestion de %s : %v", name, err)
+	} else {
+		i.Logger.Infof("Ingestion de %s (%s) : %d ligne(s), %d insérée(s), "+
+			"%d modifiée(s)", name, kind.name, report.Lines, report.Inserted,
+			report.Updated)
+	}
+	if kind != nil {
+		if err = logIngestion(i.DB, kind.kind, name, report, err); err != nil {
+			i.Logger.Errorf("Ingestion de %s, journal : %v", name, err)
+		}
+	}
+	target := filepath.Join(dest, time.Now().Format("20060102150405")+"_"+name)
+	if err = os.Rename(path, target); err != nil {
+		i.Logger.Errorf("Ingestion de %s, déplacement : %v", name, err)
+	}
+}
+
+// importFile detects the kind of the file and saves it with the matching batch
+func (i *Ingestor) importFile(path string) (*BatchReport, *ingestionKind, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ouverture %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stat %v", err)
+	}
+	name := filepath.Base(path)
+	kind, err := detectKind(name, f, info.Size())
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := kind.save(name, f, info.Size(), i.DB)
+	if err == ErrBatchInvalid && report != nil && len(report.Errors) > 0 {
+		e := report.Errors[0]
+		err = fmt.Errorf("%v, %d erreur(s) dont ligne %d %s : %s", err,
+			len(report.Errors), e.Line, e.Field, e.Message)
+	}
+	return report, kind, err
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testLogger logs the activity of the ingestion in the test output
+type testLogger struct {
+	t *testing.T
+}
+
+// Infof implements the IngestionLogger interface
+func (l testLogger) Infof(format string, args ...interface{}) {
+	l.t.Logf(format, args...)
+}
+
+// Errorf implements the IngestionLogger interface
+func (l testLogger) Errorf(format string, args ...interface{}) {
+	l.t.Logf(format, args...)
+}
+
+// TestDetectKind checks the kind of an export is found using its headers
+func TestDetectKind(t *testing.T) {
+	tcc := []struct {
+		name    string
+		content string
+		kind    string
+	}{
+		{"engagements.csv", "Exercice;Code engagement;Numéro;Ligne;Code tiers;Montant\n" +
+			"2019;IRIS;1;1;10;100\n", "engagements"},
+		{"paiements.csv", "Exercice engagement;Numéro engagement;Exercice;Numéro;Montant\n" +
+			"2019;1;2019;2;100\n", "paiements"},
+		{"demandes.txt", "Code IRIS\tNuméro de demande\tDate de demande\n" +
+			"IRIS\t1\t43466\n", "demandes de paiement"},
+		{"stages.csv", "Code IRIS,Nombre\nIRIS,2\n", "stages"},
+		{"inconnu.csv", "Colonne;Autre\n1;2\n", ""},
+		{"stages.pdf", "Code IRIS,Nombre\nIRIS,2\n", ""},
+	}
+	for _, tc := range tcc {
+		kind, err := detectKind(tc.name, strings.NewReader(tc.content),
+			int64(len(tc.content)))
+		switch {
+		case tc.kind == "" && err == nil:
+			t.Errorf("%s : erreur attendue, reçu %s", tc.name, kind.name)
+		case tc.kind != "" && err != nil:
+			t.Errorf("%s : %s attendu, reçu erreur %v", tc.name, tc.kind, err)
+		case tc.kind != "" && kind.name != tc.kind:
+			t.Errorf("%s : %s attendu, reçu %s", tc.name, tc.kind, kind.name)
+		}
+	}
+}
+
+// TestIngestorScan checks the recent files are skipped unless MinAge is null
+// and that an unrecognized file is moved to the error directory
+func TestIngestorScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ingestion")
+	if err != nil {
+		t.Fatalf("Répertoire temporaire : %v", err)
+	}
+	defer os.RemoveAll(dir)
+	i := &Ingestor{Dir: dir, ArchiveDir: filepath.Join(dir, "archive"),
+		ErrorDir: filepath.Join(dir, "erreur"), MinAge: time.Hour,
+		Logger: testLogger{t}}
+	for _, d := range []string{i.ArchiveDir, i.ErrorDir} {
+		if err = os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("Création de %s : %v", d, err)
+		}
+	}
+	files := map[string]string{"inconnu.csv": "Colonne;Autre\n1;2\n",
+		".cache.csv": "Code IRIS,Nombre\n", "notes.doc": "Code IRIS,Nombre\n"}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content),
+			0644); err != nil {
+			t.Fatalf("Écriture de %s : %v", name, err)
+		}
+	}
+	count := func(d string) int {
+		f, err := ioutil.ReadDir(d)
+		if err != nil {
+			t.Fatalf("Lecture de %s : %v", d, err)
+		}
+		return len(f)
+	}
+	i.Scan()
+	if n := count(i.ErrorDir); n != 0 {
+		t.Errorf("Fichier récent : aucun fichier en erreur attendu, reçu %d", n)
+	}
+	i.MinAge = 0
+	i.Scan()
+	errFiles, err := ioutil.ReadDir(i.ErrorDir)
+	if err != nil {
+		t.Fatalf("Lecture de %s : %v", i.ErrorDir, err)
+	}
+	if len(errFiles) != 1 || !strings.HasSuffix(errFiles[0].Name(), "_inconnu.csv") {
+		t.Errorf("Fichier non reconnu : déplacement en erreur attendu, reçu %v", errFiles)
+	}
+	if n := count(i.ArchiveDir); n != 0 {
+		t.Errorf("Aucun fichier archivé attendu, reçu %d", n)
+	}
+	for _, name := range []string{".cache.csv", "notes.doc"} {
+		if _, err = os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s ignoré attendu : %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// Mailer sends e-mails through a SMTP relay. The relay is used without
+// authentication if UserName is empty. BaseURL is the address of the frontend
+// used to build the links sent by e-mail.
+type Mailer struct {
+	Host     string
+	Port     int
+	UserName string
+	Password string
+	From     string
+	BaseURL  string
+}
+
+// Send sends a plain text e-mail to the given address
+func (m *Mailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.UserName != "" {
+		auth = smtp.PlainAuth("", m.UserName, m.Password, m.Host)
+	}
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+		"",
+		strings.Replace(body, "\n", "\r\n", -1)}, "\r\n")
+	addr := m.Host + ":" + strconv.Itoa(m.Port)
+	if err := smtp.SendMail(addr, auth, m.From, []string{to},
+		[]byte(msg)); err != nil {
+		return fmt.Errorf("envoi du courriel à %s : %v", to, err)
+	}
+	return nil
+}
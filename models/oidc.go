@@ -0,0 +1,350 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// OIDCLoginTTL is the time left to the user to authenticate with the identity
+// provider
+const OIDCLoginTTL = 10 * time.Minute
+
+// ErrBadOIDCState is returned when the state sent back by the identity
+// provider is unknown, expired or already used
+var ErrBadOIDCState = NewKindError(ErrUnauthorized, "état invalide ou expiré")
+
+// OIDCProvider is an OpenID Connect identity provider used for the single
+// sign-on with the authorization code flow and PKCE. The endpoints and the
+// signing keys are fetched from the discovery document of the issuer and
+// cached.
+type OIDCProvider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       string
+	GroupsClaim  string
+	GroupRights  map[string]int64
+	Provision    bool
+	// Client is used for the requests to the provider, a client with a
+	// timeout if nil
+	Client *http.Client
+
+	mutex     sync.Mutex
+	endpoints *oidcEndpoints
+	keys      map[string]*rsa.PublicKey
+}
+
+// oidcEndpoints decodes the discovery document of the provider
+type oidcEndpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCIdentity gives the claims of a verified ID token used to match or
+// create the user
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// client returns the HTTP client used for the provider requests
+func (p *OIDCProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// getJSON fetches and decodes a JSON document of the provider
+func (p *OIDCProvider) getJSON(u string, v interface{}) error {
+	resp, err := p.client().Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s : statut %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// discover returns the endpoints of the provider, fetching them once
+func (p *OIDCProvider) discover() (*oidcEndpoints, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+	var e oidcEndpoints
+	if err := p.getJSON(p.Issuer+"/.well-known/openid-configuration", &e); err != nil {
+		return nil, fmt.Errorf("découverte %v", err)
+	}
+	if strings.TrimSuffix(e.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("découverte : émetteur %s inattendu", e.Issuer)
+	}
+	p.endpoints = &e
+	return p.endpoints, nil
+}
+
+// key returns the RSA public key of the provider with the kid, fetching again
+// the keys if it is unknown to handle key rotations
+func (p *OIDCProvider) key(kid string) (*rsa.PublicKey, error) {
+	e, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err = p.getJSON(e.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("clés %v", err)
+	}
+	p.keys = make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		p.keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("clé %s inconnue", kid)
+}
+
+// codeChallenge returns the S256 PKCE challenge of the verifier
+func codeChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+// AuthURL returns the address of the provider where the user is sent to
+// authenticate
+func (p *OIDCProvider) AuthURL(state string, nonce string,
+	verifier string) (string, error) {
+	e, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", p.ClientID)
+	v.Set("redirect_uri", p.RedirectURL)
+	v.Set("scope", p.Scopes)
+	v.Set("state", state)
+	v.Set("nonce", nonce)
+	v.Set("code_challenge", codeChallenge(verifier))
+	v.Set("code_challenge_method", "S256")
+	sep := "?"
+	if strings.Contains(e.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return e.AuthorizationEndpoint + sep + v.Encode(), nil
+}
+
+// Exchange sends the authorization code with the PKCE verifier to the token
+// endpoint and returns the ID token
+func (p *OIDCProvider) Exchange(code string, verifier string) (string, error) {
+	e, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", p.RedirectURL)
+	v.Set("client_id", p.ClientID)
+	v.Set("code_verifier", verifier)
+	req, err := http.NewRequest(http.MethodPost, e.TokenEndpoint,
+		strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var t struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", fmt.Errorf("décodage %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || t.Error != "" {
+		return "", fmt.Errorf("statut %d %s %s", resp.StatusCode, t.Error,
+			t.ErrorDescription)
+	}
+	if t.IDToken == "" {
+		return "", errors.New("id_token absent")
+	}
+	return t.IDToken, nil
+}
+
+// hasAudience returns true if the aud claim, a string or an array, contains
+// the client ID
+func hasAudience(aud interface{}, clientID string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == clientID
+	case []interface{}:
+		for _, s := range a {
+			if s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyIDToken checks the signature, the issuer, the audience, the validity
+// and the nonce of the ID token and returns its identity
+func (p *OIDCProvider) VerifyIDToken(raw string, nonce string) (*OIDCIdentity, error) {
+	parser := jwt.Parser{ValidMethods: []string{"RS256"}}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("id_token %v", err)
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != p.Issuer {
+		return nil, errors.New("id_token : émetteur invalide")
+	}
+	if !hasAudience(claims["aud"], p.ClientID) {
+		return nil, errors.New("id_token : audience invalide")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("id_token : expiration absente")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("id_token : nonce invalide")
+	}
+	var id OIDCIdentity
+	id.Subject, _ = claims["sub"].(string)
+	id.Email, _ = claims["email"].(string)
+	id.Name, _ = claims["name"].(string)
+	id.EmailVerified, _ = claims["email_verified"].(bool)
+	switch g := claims[p.GroupsClaim].(type) {
+	case string:
+		id.Groups = []string{g}
+	case []interface{}:
+		for _, s := range g {
+			if s, ok := s.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	}
+	return &id, nil
+}
+
+// Rights returns the rights bits mapped to the groups and false if none of
+// the groups is mapped
+func (p *OIDCProvider) Rights(groups []string) (int64, bool) {
+	var rights int64
+	found := false
+	for _, g := range groups {
+		if r, ok := p.GroupRights[g]; ok {
+			rights, found = rights|r, true
+		}
+	}
+	return rights & RightsMask &^ SuperAdminBit, found
+}
+
+// randomString returns a random hexadecimal string of n bytes
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("random %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateOIDCLogin stores a new pending single sign-on login and returns its
+// state, nonce and PKCE code verifier
+func CreateOIDCLogin(db *sql.DB) (state string, nonce string, verifier string,
+	err error) {
+	if state, err = randomString(32); err != nil {
+		return "", "", "", err
+	}
+	if nonce, err = randomString(16); err != nil {
+		return "", "", "", err
+	}
+	if verifier, err = randomString(48); err != nil {
+		return "", "", "", err
+	}
+	if _, err = db.Exec(`DELETE FROM oidc_login WHERE expires<now()`); err != nil {
+		return "", "", "", fmt.Errorf("delete %v", err)
+	}
+	if _, err = db.Exec(`INSERT INTO oidc_login (state_hash,nonce,code_verifier,
+	expires) VALUES($1,$2,$3,now()+$4*interval '1 second')`,
+		hashPasswordToken(state), nonce, verifier,
+		OIDCLoginTTL.Seconds()); err != nil {
+		return "", "", "", fmt.Errorf("insert %v", err)
+	}
+	return state, nonce, verifier, nil
+}
+
+// ConsumeOIDCLogin removes the pending login of the state and returns its
+// nonce and PKCE code verifier
+func ConsumeOIDCLogin(db *sql.DB, state string) (nonce string, verifier string,
+	err error) {
+	err = db.QueryRow(`DELETE FROM oidc_login WHERE state_hash=$1
+	AND expires>now() RETURNING nonce,code_verifier`, hashPasswordToken(state)).
+		Scan(&nonce, &verifier)
+	if err == sql.ErrNoRows {
+		return "", "", ErrBadOIDCState
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("delete %v", err)
+	}
+	return nonce, verifier, nil
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PaginatedQuery embeddes the request to fetch a page of rows from database
+// according to the given pattern. PageSize, Sort and Filters are checked
+// against the fields allowed by each paginated model. Cursor is used instead
+// of Page by the keyset paginations, an empty one fetching the first page.
+type PaginatedQuery struct {
+	Page     int64       `json:"Page"`
+	Year     int64       `json:"Year"`
+	Search   string      `json:"Search"`
+	PageSize int64       `json:"PageSize"`
+	Sort     []SortField `json:"Sort"`
+	Filters  []Filter    `json:"Filters"`
+	Cursor   string      `json:"Cursor"`
+	Scope    *Scope      `json:"-"`
+}
+
+// SortField is a field of the sort of a paginated query
+type SortField struct {
+	Field string `json:"Field"`
+	Desc  bool   `json:"Desc"`
+}
+
+// Filter restricts the rows of a paginated query to a range of a number or a
+// date field, the bounds being optional, or to a list of IDs
+type Filter struct {
+	Field string  `json:"Field"`
+	Min   string  `json:"Min"`
+	Max   string  `json:"Max"`
+	In    []int64 `json:"In"`
+}
+
+// fieldKind defines the filters allowed on a field
+type fieldKind int
+
+// The kinds of the fields of a paginated query, a text field being only
+// sortable
+const (
+	textField fieldKind = iota
+	numberField
+	dateField
+	idField
+)
+
+// queryField gives the SQL column of a field of a paginated query and its kind
+type queryField struct {
+	column string
+	kind   fieldKind
+}
+
+// queryFields is the whitelist of the fields of a paginated model, indexed by
+// their JSON name
+type queryFields map[string]queryField
+
+// filterDateLayout is the layout of the bounds of the date filters
+const filterDateLayout = "2006-01-02"
+
+// size returns the page size of the query, the global one being used if not
+// set and the size being capped to MaxPageSize
+func (q *PaginatedQuery) size() int64 {
+	switch {
+	case q.PageSize <= 0:
+		return int64(PageSize)
+	case q.PageSize > MaxPageSize:
+		return MaxPageSize
+	}
+	return q.PageSize
+}
+
+// bound returns the parameter of a bound of a range filter according to the
+// kind of the field
+func bound(f queryField, name, value string) (interface{}, error) {
+	switch f.kind {
+	case numberField:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, &ValidationError{Field: "Filters",
+				Message: fmt.Sprintf("filtre %s : nombre %q incorrect", name, value)}
+		}
+		return v, nil
+	case dateField:
+		v, err := time.Parse(filterDateLayout, value)
+		if err != nil {
+			return nil, &ValidationError{Field: "Filters",
+				Message: fmt.Sprintf("filtre %s : date %q incorrecte", name, value)}
+		}
+		return v, nil
+	}
+	return nil, &ValidationError{Field: "Filters",
+		Message: fmt.Sprintf("filtre %s : intervalle non autorisé", name)}
+}
+
+// clauses translates the filters and the sort of the query into SQL using the
+// fields whitelist. The filters are returned as conditions beginning with AND
+// whose parameters are appended to args and numbered accordingly. The order
+// ends with the default one to keep the pages stable.
+func (q *PaginatedQuery) clauses(fields queryFields, defaultOrder string,
+	args []interface{}) (where string, order string, newArgs []interface{},
+	err error) {
+	var b strings.Builder
+	for _, f := range q.Filters {
+		field, ok := fields[f.Field]
+		if !ok {
+			return "", "", nil, &ValidationError{Field: "Filters",
+				Message: fmt.Sprintf("filtre sur le champ %s non autorisé", f.Field)}
+		}
+		for _, r := range []struct {
+			value, operator string
+		}{{f.Min, ">="}, {f.Max, "<="}} {
+			if r.value == "" {
+				continue
+			}
+			v, err := bound(field, f.Field, r.value)
+			if err != nil {
+				return "", "", nil, err
+			}
+			args = append(args, v)
+			cast := "numeric"
+			if field.kind == dateField {
+				cast = "date"
+			}
+			fmt.Fprintf(&b, " AND %s%s$%d::%s", field.column, r.operator, len(args),
+				cast)
+		}
+		if f.In != nil {
+			if field.kind != idField {
+				return "", "", nil, &ValidationError{Field: "Filters",
+					Message: fmt.Sprintf("filtre %s : liste non autorisée", f.Field)}
+			}
+			args = append(args, pq.Array(f.In))
+			fmt.Fprintf(&b, " AND %s=ANY($%d)", field.column, len(args))
+		}
+	}
+	var orders []string
+	for _, s := range q.Sort {
+		field, ok := fields[s.Field]
+		if !ok {
+			return "", "", nil, &ValidationError{Field: "Sort",
+				Message: fmt.Sprintf("tri sur le champ %s non autorisé", s.Field)}
+		}
+		if s.Desc {
+			orders = append(orders, field.column+" DESC")
+		} else {
+			orders = append(orders, field.column)
+		}
+	}
+	orders = append(orders, defaultOrder)
+	return b.String(), strings.Join(orders, ","), args, nil
+}
+
+// keysetClause translates the cursor of the query into a condition beginning
+// with AND that selects the rows after the cursor in the order of the keys.
+// The keys are integer columns, the last one being unique, whose values of
+// the last row of the previous page are encoded in the cursor. The
+// parameters are appended to args.
+func (q *PaginatedQuery) keysetClause(keys []string,
+	args []interface{}) (where string, newArgs []interface{}, err error) {
+	if len(q.Sort) > 0 {
+		return "", nil, &ValidationError{Field: "Sort",
+			Message: "tri incompatible avec la pagination par curseur"}
+	}
+	if q.Cursor == "" {
+		return "", args, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	values := strings.Split(string(b), ",")
+	if err != nil || len(values) != len(keys) {
+		return "", nil, &ValidationError{Field: "Cursor", Message: "curseur incorrect"}
+	}
+	params := make([]string, len(values))
+	for i, v := range values {
+		key, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", nil, &ValidationError{Field: "Cursor", Message: "curseur incorrect"}
+		}
+		args = append(args, key)
+		params[i] = "$" + strconv.Itoa(len(args))
+	}
+	return fmt.Sprintf(" AND (%s)>(%s)", strings.Join(keys, ","),
+		strings.Join(params, ",")), args, nil
+}
+
+// encodeCursor returns the cursor of the row whose keys have the given values
+func encodeCursor(values ...int64) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.FormatInt(v, 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(s, ",")))
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Kinds of password tokens with their times to live
+const (
+	ResetPasswordToken  = "reset"
+	InvitePasswordToken = "invite"
+	ResetTokenTTL       = time.Hour
+	InviteTokenTTL      = 7 * 24 * time.Hour
+)
+
+// ErrBadPasswordToken is returned when a password token is unknown, expired
+// or already used
+var ErrBadPasswordToken error = &ValidationError{Field: "Token",
+	Message: "jeton invalide ou expiré"}
+
+// hashPasswordToken returns the hash stored in database for a token
+func hashPasswordToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// CreatePasswordToken stores a new single-use token for the user, cancelling
+// the former unused ones, and returns it
+func CreatePasswordToken(db *sql.DB, userID int64, kind string,
+	ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("random %v", err)
+	}
+	token := hex.EncodeToString(b)
+	tx, err := db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("tx begin %v", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM password_token
+	WHERE user_id=$1 AND used IS NULL`, userID); err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("delete %v", err)
+	}
+	if _, err = tx.Exec(`INSERT INTO password_token (user_id,token_hash,kind,
+	expires) VALUES($1,$2,$3,now()+$4*interval '1 second')`, userID,
+		hashPasswordToken(token), kind, ttl.Seconds()); err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("insert %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("tx commit %v", err)
+	}
+	return token, nil
+}
+
+// ResetPassword uses the token to replace the password of its user by the
+// crypted one and returns the ID of the user. The token can't be used again.
+func ResetPassword(db *sql.DB, token string, cryptedPwd string) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("tx begin %v", err)
+	}
+	var userID int64
+	err = tx.QueryRow(`UPDATE password_token SET used=now()
+	WHERE token_hash=$1 AND used IS NULL AND expires>now() RETURNING user_id`,
+		hashPasswordToken(token)).Scan(&userID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return 0, ErrBadPasswordToken
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("update token %v", err)
+	}
+	if _, err = tx.Exec(`UPDATE users SET password=$1 WHERE id=$2`, cryptedPwd,
+		userID); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("update user %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("tx commit %v", err)
+	}
+	return userID, nil
+}
@@ -49,6 +49,28 @@ type PaymentBatch struct {
 	Lines []PaymentLine `json:"Payment"`
 }
 
+// paymentMapping defines the columns of the XLSX or CSV file of a payment batch
+var paymentMapping = BatchMapping{
+	Key: "Payment",
+	Columns: []BatchColumn{
+		{"CommitmentYear", ColInt, []string{"Exercice engagement", "Année engagement"}},
+		{"CommitmentCode", ColText, []string{"Code engagement"}},
+		{"CommitmentNumber", ColInt, []string{"Numéro engagement", "N° engagement"}},
+		{"CommitmentLine", ColInt, []string{"Ligne engagement", "N° ligne engagement"}},
+		{"Year", ColInt, []string{"Exercice", "Année"}},
+		{"CreationDate", ColDateYMD, []string{"Date de création", "Date création"}},
+		{"ModificationDate", ColDateYMD, []string{"Date de modification", "Date modification"}},
+		{"Value", ColInt, []string{"Montant", "Montant mandaté"}},
+		{"Number", ColInt, []string{"Numéro", "N° mandat", "Numéro mandat"}},
+		{"ReceiptDate", ColDateYMD, []string{"Date de réception", "Date réception"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *PaymentBatch) Mapping() *BatchMapping {
+	return &paymentMapping
+}
+
 // PaginatedPayment is used for paginated request to fetch some payments that
 // match a search pattern using PaginatedQuery
 type PaginatedPayment struct {
@@ -74,6 +96,13 @@ type PaginatedPayments struct {
 	ItemsCount int64              `json:"ItemsCount"`
 }
 
+// CursorPayments embeddes a page of payments fetched after the cursor of a
+// PaginatedQuery and the cursor of the next page, empty for the last one
+type CursorPayments struct {
+	Payments   []PaginatedPayment `json:"Payments"`
+	NextCursor string             `json:"NextCursor"`
+}
+
 // ExportedPayment is used for the excel export query to fetch payment with all
 // fields according to a certain search pattern
 type ExportedPayment struct {
@@ -230,54 +259,69 @@ func (p *Payments) GetLinkedToHousing(ID int64, db *sql.DB) error {
 }
 
 // Save insert a batch of PaymentLine into database
-func (p *PaymentBatch) Save(db *sql.DB) error {
-	for _, r := range p.Lines {
-		if r.CommitmentYear == 0 || r.CommitmentCode == "" || r.CommitmentNumber == 0 ||
-			r.CommitmentLine == 0 || r.Year == 0 || r.CreationDate < 20090101 ||
-			r.ModificationDate < 20090101 || r.Number == 0 {
-			return fmt.Errorf("Champ incorrect dans %+v", r)
+func (p *PaymentBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
+	for i, r := range p.Lines {
+		if r.CommitmentYear == 0 {
+			report.addError(i, "CommitmentYear", "année d'engagement nulle")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_payment", "commitment_year",
-		"commitment_code", "commitment_number", "commitment_line", "year",
-		"creation_date", "modification_date", "number", "value", "receipt_date"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	var (
-		cd, md time.Time
-		rd     NullTime
-	)
-	for _, r := range p.Lines {
-		cd = time.Date(int(r.CreationDate/10000), time.Month(r.CreationDate/100%100),
-			int(r.CreationDate%100), 0, 0, 0, 0, time.UTC)
-		md = time.Date(int(r.ModificationDate/10000),
-			time.Month(r.ModificationDate/100%100), int(r.ModificationDate%100), 0, 0,
-			0, 0, time.UTC)
-		if r.ReceiptDate == 0 {
-			rd.Valid = false
-		} else {
-			rd.Valid = true
-			rd.Time = time.Date(int(r.ReceiptDate/10000),
-				time.Month(r.ReceiptDate/100%100), int(r.ReceiptDate%100), 0, 0, 0, 0,
-				time.UTC)
+		if r.CommitmentCode == "" {
+			report.addError(i, "CommitmentCode", "code d'engagement vide")
 		}
-		if _, err = stmt.Exec(r.CommitmentYear, r.CommitmentCode, r.CommitmentNumber,
-			r.CommitmentLine, r.Year, cd, md, r.Number, r.Value, rd); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("statement exec %v", err)
+		if r.CommitmentNumber == 0 {
+			report.addError(i, "CommitmentNumber", "numéro d'engagement nul")
+		}
+		if r.CommitmentLine == 0 {
+			report.addError(i, "CommitmentLine", "ligne d'engagement nulle")
+		}
+		if r.Year == 0 {
+			report.addError(i, "Year", "année nulle")
+		}
+		if r.CreationDate < 20090101 {
+			report.addError(i, "CreationDate", "date antérieure à 2009")
+		}
+		if r.ModificationDate < 20090101 {
+			report.addError(i, "ModificationDate", "date antérieure à 2009")
+		}
+		if r.Number == 0 {
+			report.addError(i, "Number", "numéro nul")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{`UPDATE payment SET value=t.value, 
+	return report, report.run(db, "payment", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_payment", "commitment_year",
+			"commitment_code", "commitment_number", "commitment_line", "year",
+			"creation_date", "modification_date", "number", "value", "receipt_date"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		var (
+			cd, md time.Time
+			rd     NullTime
+		)
+		for _, r := range p.Lines {
+			cd = time.Date(int(r.CreationDate/10000), time.Month(r.CreationDate/100%100),
+				int(r.CreationDate%100), 0, 0, 0, 0, time.UTC)
+			md = time.Date(int(r.ModificationDate/10000),
+				time.Month(r.ModificationDate/100%100), int(r.ModificationDate%100), 0, 0,
+				0, 0, time.UTC)
+			if r.ReceiptDate == 0 {
+				rd.Valid = false
+			} else {
+				rd.Valid = true
+				rd.Time = time.Date(int(r.ReceiptDate/10000),
+					time.Month(r.ReceiptDate/100%100), int(r.ReceiptDate%100), 0, 0, 0, 0,
+					time.UTC)
+			}
+			if _, err = stmt.Exec(r.CommitmentYear, r.CommitmentCode, r.CommitmentNumber,
+				r.CommitmentLine, r.Year, cd, md, r.Number, r.Value, rd); err != nil {
+				return fmt.Errorf("statement exec %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE payment SET value=t.value, 
 		modification_date=t.modification_date, receipt_date=t.receipt_date
 	FROM temp_payment t WHERE t.commitment_year=payment.commitment_year AND 
 		t.commitment_code=payment.commitment_code AND
@@ -285,7 +329,7 @@ func (p *PaymentBatch) Save(db *sql.DB) error {
 		t.commitment_line=payment.commitment_line AND t.year=payment.year AND 
 		t.creation_date=payment.creation_date AND
 		t.number=payment.number`,
-		`INSERT INTO payment (commitment_id,commitment_year,commitment_code,
+			`INSERT INTO payment (commitment_id,commitment_year,commitment_code,
 			commitment_number,commitment_line,year,creation_date,modification_date,
 			number, value, receipt_date)
 		SELECT c.id,t.commitment_year,t.commitment_code,t.commitment_number,
@@ -299,39 +343,114 @@ func (p *PaymentBatch) Save(db *sql.DB) error {
 				t.commitment_line,t.year,t.creation_date,t.modification_date) 
 			NOT IN (SELECT DISTINCT commitment_year,commitment_code,commitment_number,
 				commitment_line,year,creation_date,modification_date FROM payment)`,
-		`DELETE FROM temp_payment`,
+			`DELETE FROM temp_payment`,
+		})
+	})
+}
+
+// paymentQueryFields are the fields of the paginated payments that can be
+// used to sort or filter them
+var paymentQueryFields = queryFields{
+	"ID":              {"p.id", idField},
+	"Year":            {"p.year", numberField},
+	"CreationDate":    {"p.creation_date", dateField},
+	"Value":           {"p.value", numberField},
+	"Number":          {"p.number", numberField},
+	"ReceiptDate":     {"p.receipt_date", dateField},
+	"CommitmentID":    {"p.commitment_id", idField},
+	"CommitmentDate":  {"c.creation_date", dateField},
+	"CommitmentName":  {"c.name", textField},
+	"CommitmentValue": {"c.value", numberField},
+	"BeneficiaryID":   {"c.beneficiary_id", idField},
+	"Beneficiary":     {"b.name", textField},
+	"ActionID":        {"c.action_id", idField},
+	"ActionName":      {"a.name", textField},
+	"SectorID":        {"a.sector_id", idField},
+	"Sector":          {"s.name", textField},
+}
+
+// Get fetches the payments following the cursor of the query ordered by year,
+// number and ID, the keyset pagination avoiding to count and skip the rows of
+// the previous pages
+func (p *CursorPayments) Get(db *sql.DB, q *PaginatedQuery) error {
+	filters, _, args, err := q.clauses(paymentQueryFields, "1",
+		[]interface{}{q.Year, "%" + q.Search + "%"})
+	if err != nil {
+		return err
 	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
+	keyset, args, err := q.keysetClause([]string{"p.year", "p.number", "p.id"},
+		args)
+	if err != nil {
+		return err
+	}
+	size := q.size()
+	rows, err := db.Query(`SELECT p.id,p.year,p.creation_date,p.value,p.number,
+	c.creation_date,c.name,c.value,b.name,s.name,a.name,p.receipt_date
+	FROM payment p
+	LEFT JOIN cumulated_commitment c on p.commitment_id=c.id
+	JOIN budget_action a ON a.id = c.action_id
+	JOIN budget_sector s ON s.id=a.sector_id
+	JOIN beneficiary b ON c.beneficiary_id = b.id
+	WHERE p.year >= $1 AND
+		(c.name ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)`+
+		q.Scope.commitmentFilter("c")+filters+keyset+
+		` ORDER BY p.year,p.number,p.id LIMIT `+strconv.FormatInt(size+1, 10),
+		args...)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row PaginatedPayment
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&row.ID, &row.Year, &row.CreationDate, &row.Value,
+			&row.Number, &row.CommitmentDate, &row.CommitmentName, &row.CommitmentValue,
+			&row.Beneficiary, &row.Sector, &row.ActionName, &row.ReceiptDate); err != nil {
+			return fmt.Errorf("scan %v", err)
 		}
+		p.Payments = append(p.Payments, row)
 	}
-	return tx.Commit()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if int64(len(p.Payments)) > size {
+		p.Payments = p.Payments[:size]
+		last := p.Payments[size-1]
+		p.NextCursor = encodeCursor(last.Year, last.Number, last.ID)
+	}
+	if len(p.Payments) == 0 {
+		p.Payments = []PaginatedPayment{}
+	}
+	return nil
 }
 
 // Get fetches all paginated payments FROM database that match the paginated query
 func (p *PaginatedPayments) Get(db *sql.DB, q *PaginatedQuery) error {
 	var count int64
-	const commonPmtQry = ` FROM payment p 
+	filters, order, args, err := q.clauses(paymentQueryFields, "2,5,3",
+		[]interface{}{q.Year, "%" + q.Search + "%"})
+	if err != nil {
+		return err
+	}
+	commonPmtQry := ` FROM payment p 
 	LEFT JOIN cumulated_commitment c on p.commitment_id=c.id
 	JOIN budget_action a ON a.id = c.action_id
 	JOIN budget_sector s ON s.id=a.sector_id 
 	JOIN beneficiary b ON c.beneficiary_id = b.id
 	WHERE p.year >= $1 AND
-		(c.name ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)`
+		(c.name ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)` +
+		q.Scope.commitmentFilter("c") + filters
 
-	if err := db.QueryRow(`SELECT count(1)`+commonPmtQry, q.Year, "%"+q.Search+"%").
+	if err := db.QueryRow(`SELECT count(1)`+commonPmtQry, args...).
 		Scan(&count); err != nil {
 		return fmt.Errorf("select count %v", err)
 	}
-	offset, newPage := GetPaginateParams(q.Page, count)
+	size := q.size()
+	offset, newPage := getPageParams(q.Page, count, size)
 
 	rows, err := db.Query(`SELECT p.id,p.year,p.creation_date,p.value,p.number,
 	c.creation_date,c.name,c.value,b.name,s.name,a.name,p.receipt_date`+
-		commonPmtQry+` ORDER BY 2,5,3 LIMIT `+strconv.Itoa(PageSize)+` OFFSET $3`,
-		q.Year, "%"+q.Search+"%", offset)
+		commonPmtQry+` ORDER BY `+order+` LIMIT `+strconv.FormatInt(size, 10)+
+		` OFFSET $`+strconv.Itoa(len(args)+1), append(args, offset)...)
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -357,8 +476,10 @@ func (p *PaginatedPayments) Get(db *sql.DB, q *PaginatedQuery) error {
 	return nil
 }
 
-// Get fetches all exported payments FROM database that match the export query
-func (p *ExportedPayments) Get(db *sql.DB, q *ExportQuery) error {
+// Stream fetches all exported payments FROM database that match the export
+// query and calls send for each row as soon as it's read, the row being reused
+func (p *ExportedPayments) Stream(db *sql.DB, q *ExportQuery,
+	send func(*ExportedPayment) error) error {
 	rows, err := db.Query(`SELECT p.id,p.year,p.creation_date,p.modification_date,
 	p.number,p.value*0.01,p.commitment_year,p.commitment_code,p.commitment_number,
 	c.creation_date,c.value*0.01,c.name,b.name,s.name,a.name,p.receipt_date
@@ -367,8 +488,8 @@ func (p *ExportedPayments) Get(db *sql.DB, q *ExportQuery) error {
 	JOIN beneficiary b ON c.beneficiary_id = b.id
 	JOIN budget_action a ON a.id = c.action_id
 	JOIN budget_sector s ON s.id=a.sector_id 
-	WHERE p.year >= $1 AND (c.name ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)
-	ORDER BY 1 `, q.Year, "%"+q.Search+"%")
+	WHERE p.year >= $1 AND (c.name ILIKE $2 OR b.name ILIKE $2 OR a.name ILIKE $2)`+
+		q.Scope.commitmentFilter("c")+` ORDER BY 1 `, q.Year, "%"+q.Search+"%")
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -382,20 +503,35 @@ func (p *ExportedPayments) Get(db *sql.DB, q *ExportQuery) error {
 			&row.ReceiptDate); err != nil {
 			return fmt.Errorf("scan %v", err)
 		}
-		p.ExportedPayments = append(p.ExportedPayments, row)
+		if err = send(&row); err != nil {
+			return err
+		}
 	}
 	err = rows.Err()
 	if err != nil {
 		return fmt.Errorf("rows err %v", err)
 	}
-	if len(p.ExportedPayments) == 0 {
-		p.ExportedPayments = []ExportedPayment{}
-	}
 	return nil
 }
 
 // Get fetches all payments per year for the current and the previous years
 func (t *TwoYearsPayments) Get(db *sql.DB) error {
+	v, err := aggregates.get(&twoYearsPaymentsCache, "",
+		func() (interface{}, error) {
+			var r TwoYearsPayments
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*t = v.(TwoYearsPayments)
+	return nil
+}
+
+// fetch computes the payments per year for the current and the previous
+// years from database
+func (t *TwoYearsPayments) fetch(db *sql.DB) error {
 	query := `WITH pmt_month as (
 		SELECT max(extract(month FROM creation_date))::int max_month
 		FROM payment WHERE year=$1)
@@ -44,6 +44,26 @@ type PaymentCreditBatch struct {
 	Lines []PaymentCreditLine `json:"PaymentCredit"`
 }
 
+// paymentCreditMapping defines the columns of the XLSX or CSV file of a payment
+// credit batch
+var paymentCreditMapping = BatchMapping{
+	Key: "PaymentCredit",
+	Columns: []BatchColumn{
+		{"Chapter", ColInt, []string{"Chapitre"}},
+		{"Function", ColInt, []string{"Fonction"}},
+		{"Primitive", ColInt, []string{"Primitif", "Budget primitif"}},
+		{"Reported", ColInt, []string{"Reporté", "Reports"}},
+		{"Added", ColInt, []string{"Ajouté", "Budget supplémentaire"}},
+		{"Modified", ColInt, []string{"Modifié", "Décision modificative"}},
+		{"Movement", ColInt, []string{"Mouvement", "Virements"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *PaymentCreditBatch) Mapping() *BatchMapping {
+	return &paymentCreditMapping
+}
+
 // GetAll fetches all PaymentCredits of a year from database
 func (p *PaymentCredits) GetAll(year int, db *sql.DB) error {
 	rows, err := db.Query(`SELECT year,chapter,function,primitive,reported,added,
@@ -71,29 +91,41 @@ func (p *PaymentCredits) GetAll(year int, db *sql.DB) error {
 }
 
 // Save import a batch of payment credits into database
-func (p *PaymentCreditBatch) Save(year int64, db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	if _, err = tx.Exec(`DELETE FROM payment_credit WHERE year=$1`, year); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete %v", err)
-	}
-	for i, l := range p.Lines {
-		if _, err = tx.Exec(`INSERT INTO payment_credit (year,chapter,function,
+func (p *PaymentCreditBatch) Save(opt BatchOptions, year int64, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
+	return report, report.run(db, "payment_credit", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM payment_credit WHERE year=$1`, year); err != nil {
+			return fmt.Errorf("delete %v", err)
+		}
+		for i, l := range p.Lines {
+			if _, err := tx.Exec(`INSERT INTO payment_credit (year,chapter,function,
 			primitive,reported,added,modified,movement) VALUES($1,$2,$3,$4,$5,$6,$7,$8)`,
-			year, l.Chapter, l.Function, l.Primitive, l.Reported, l.Added, l.Modified,
-			l.Movement); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insert %d %v", i, err)
+				year, l.Chapter, l.Function, l.Primitive, l.Reported, l.Added, l.Modified,
+				l.Movement); err != nil {
+				return fmt.Errorf("insert %d %v", i, err)
+			}
 		}
-	}
-	return tx.Commit()
+		return nil
+	})
 }
 
 // Get fetches the payment credit sum of the current year
 func (p *PaymentCreditSum) Get(db *sql.DB) error {
+	v, err := aggregates.get(&paymentCreditSumCache, "",
+		func() (interface{}, error) {
+			var r PaymentCreditSum
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*p = v.(PaymentCreditSum)
+	return nil
+}
+
+// fetch computes the payment credit sum of the current year from database
+func (p *PaymentCreditSum) fetch(db *sql.DB) error {
 	q := `SELECT sum(primitive+reported+added+modified+movement)*0.01 from payment_credit
 	where year=$1 and chapter='905' and function<>52`
 	year := time.Now().Year()
@@ -38,6 +38,25 @@ type PaymentCreditJournalBatch struct {
 	Lines []PaymentCreditJournalLine `json:"PaymentCreditJournal"`
 }
 
+// paymentCreditJournalMapping defines the columns of the XLSX or CSV file of a
+// payment credit journal batch
+var paymentCreditJournalMapping = BatchMapping{
+	Key: "PaymentCreditJournal",
+	Columns: []BatchColumn{
+		{"Chapter", ColInt, []string{"Chapitre"}},
+		{"Function", ColInt, []string{"Fonction"}},
+		{"CreationDate", ColDateYMD, []string{"Date de création", "Date création"}},
+		{"ModificationDate", ColDateYMD, []string{"Date de modification", "Date modification"}},
+		{"Name", ColText, []string{"Libellé", "Nom"}},
+		{"Value", ColInt, []string{"Montant"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *PaymentCreditJournalBatch) Mapping() *BatchMapping {
+	return &paymentCreditJournalMapping
+}
+
 // GetAll fetches all payment credits journal entries of a given year
 func (p *PaymentCreditJournals) GetAll(year int, db *sql.DB) error {
 	rows, err := db.Query(`SELECT id,chapter,function,creation_date,
@@ -64,58 +83,53 @@ func (p *PaymentCreditJournals) GetAll(year int, db *sql.DB) error {
 	return nil
 }
 
-// validate check if a credit batch matches the database constraints
-func (p *PaymentCreditJournalBatch) validate() error {
+// validate check if a credit batch matches the database constraints and adds
+// the errors to the report
+func (p *PaymentCreditJournalBatch) validate(r *BatchReport) {
 	for i, l := range p.Lines {
 		if l.Chapter == 0 {
-			return fmt.Errorf("ligne %d chapter nul", i)
+			r.addError(i, "Chapter", "chapitre nul")
 		}
 		if l.Function == 0 {
-			return fmt.Errorf("ligne %d function nul", i)
+			r.addError(i, "Function", "fonction nulle")
 		}
 		if l.CreationDate == 0 {
-			return fmt.Errorf("ligne %d creation date nul", i)
+			r.addError(i, "CreationDate", "date de création nulle")
 		}
 		if l.ModificationDate == 0 {
-			return fmt.Errorf("ligne %d modification date nul", i)
+			r.addError(i, "ModificationDate", "date de modification nulle")
 		}
 		if l.Name == "" {
-			return fmt.Errorf("ligne %d name nul", i)
+			r.addError(i, "Name", "nom vide")
 		}
 		if l.Value == 0 {
-			return fmt.Errorf("ligne %d value nul", i)
+			r.addError(i, "Value", "montant nul")
 		}
 	}
-	return nil
 }
 
 // Save import a batch of payment credit journal entries into database
-func (p *PaymentCreditJournalBatch) Save(db *sql.DB) error {
-	if err := p.validate(); err != nil {
-		return err
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	if _, err = tx.Exec(`DELETE FROM payment_credit_journal 
+func (p *PaymentCreditJournalBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
+	p.validate(report)
+	return report, report.run(db, "payment_credit_journal", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM payment_credit_journal 
 		WHERE EXTRACT(year FROM creation_date)=EXTRACT(year FROM CURRENT_DATE)`); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("initial delete %v", err)
-	}
-	var c, m time.Time
-	for i, l := range p.Lines {
-		c = time.Date(int(l.CreationDate/10000), time.Month(l.CreationDate/100%100),
-			int(l.CreationDate%100), 0, 0, 0, 0, time.UTC)
-		m = time.Date(int(l.ModificationDate/10000),
-			time.Month(l.ModificationDate/100%100), int(l.ModificationDate%100), 0, 0,
-			0, 0, time.UTC)
-		if _, err = tx.Exec(`INSERT INTO payment_credit_journal (chapter,function,
+			return fmt.Errorf("initial delete %v", err)
+		}
+		var c, m time.Time
+		for i, l := range p.Lines {
+			c = time.Date(int(l.CreationDate/10000), time.Month(l.CreationDate/100%100),
+				int(l.CreationDate%100), 0, 0, 0, 0, time.UTC)
+			m = time.Date(int(l.ModificationDate/10000),
+				time.Month(l.ModificationDate/100%100), int(l.ModificationDate%100), 0, 0,
+				0, 0, time.UTC)
+			if _, err := tx.Exec(`INSERT INTO payment_credit_journal (chapter,function,
 			creation_date,modification_date,name,value) VALUES($1,$2,$3,$4,$5,$6)`,
-			l.Chapter, l.Function, c, m, l.Name, l.Value); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insert %d %v", i, err)
+				l.Chapter, l.Function, c, m, l.Name, l.Value); err != nil {
+				return fmt.Errorf("insert %d %v", i, err)
+			}
 		}
-	}
-	return tx.Commit()
+		return nil
+	})
 }
@@ -30,6 +30,34 @@ type PaymentDemandBatch struct {
 	ImportDate time.Time           `json:"ImportDate"`
 }
 
+// paymentDemandMapping defines the columns of the XLSX or CSV file of a payment
+// demand batch
+var paymentDemandMapping = BatchMapping{
+	Key: "PaymentDemand",
+	Columns: []BatchColumn{
+		{"IrisCode", ColText, []string{"Code IRIS", "N° IRIS"}},
+		{"IrisName", ColText, []string{"Nom IRIS", "Libellé IRIS", "Objet"}},
+		{"CommitmentDate", ColDateExcel, []string{"Date d'engagement", "Date engagement"}},
+		{"BeneficiaryCode", ColInt, []string{"Code bénéficiaire", "Code tiers"}},
+		{"DemandNumber", ColInt, []string{"Numéro de demande", "N° demande"}},
+		{"DemandDate", ColDateExcel, []string{"Date de demande", "Date demande"}},
+		{"ReceiptDate", ColDateExcel, []string{"Date de réception", "Date réception"}},
+		{"DemandValue", ColInt, []string{"Montant", "Montant demandé"}},
+		{"CsfDate", ColDateExcel, []string{"Date CSF"}},
+		{"CsfComment", ColText, []string{"Commentaire CSF"}},
+		{"DemandStatus", ColText, []string{"Statut", "Statut demande"}},
+		{"StatusComment", ColText, []string{"Commentaire statut"}},
+	},
+	Params: []BatchColumn{
+		{"ImportDate", ColTime, []string{"Date d'import"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *PaymentDemandBatch) Mapping() *BatchMapping {
+	return &paymentDemandMapping
+}
+
 // PaymentDemand model
 type PaymentDemand struct {
 	ID              int64      `json:"ID"`
@@ -77,12 +105,13 @@ func (p *PaymentDemand) Update(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("payment_demands")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count == 0 {
-		return fmt.Errorf("demande de paiement introuvable")
+		return notFound("demande de paiement")
 	}
 	return nil
 }
@@ -118,35 +147,36 @@ func (p *PaymentDemands) GetAll(db *sql.DB) error {
 	return nil
 }
 
-// Validate checks if a payment batch has correct fields
-func (p *PaymentDemandBatch) Validate() error {
+// validate checks if a payment batch has correct fields and adds the errors
+// to the report
+func (p *PaymentDemandBatch) validate(r *BatchReport) {
 	for i, l := range p.Lines {
 		if l.IrisCode == "" {
-			return fmt.Errorf("ligne %d IrisCode vide", i+1)
+			r.addError(i, "IrisCode", "code IRIS vide")
 		}
 		if l.IrisName == "" {
-			return fmt.Errorf("ligne %d IrisName vide", i+1)
+			r.addError(i, "IrisName", "nom IRIS vide")
 		}
 		if int64(l.CommitmentDate) == 0 {
-			return fmt.Errorf("ligne %d CommitmentDate vide", i+1)
+			r.addError(i, "CommitmentDate", "date d'engagement vide")
 		}
 		if l.BeneficiaryCode == 0 {
-			return fmt.Errorf("ligne %d BeneficiaryCode vide", i+1)
+			r.addError(i, "BeneficiaryCode", "code bénéficiaire vide")
 		}
 		if l.DemandNumber == 0 {
-			return fmt.Errorf("ligne %d DemandNumber vide", i+1)
+			r.addError(i, "DemandNumber", "numéro de demande vide")
 		}
 		if int64(l.DemandDate) == 0 {
-			return fmt.Errorf("ligne %d DemandDate vide", i+1)
+			r.addError(i, "DemandDate", "date de demande vide")
 		}
 		if int64(l.ReceiptDate) == 0 {
-			return fmt.Errorf("ligne %d ReceiptDate vide", i+1)
+			r.addError(i, "ReceiptDate", "date de réception vide")
 		}
 	}
 	if p.ImportDate.IsZero() {
-		return fmt.Errorf("date d'import non définie")
+		r.Errors = append(r.Errors, BatchRowError{Field: "ImportDate",
+			Message: "date d'import non définie"})
 	}
-	return nil
 }
 
 // excel2Time convert a int64 corresponding to an Excel integer date to time.Time
@@ -156,8 +186,6 @@ func excel2Time(i int64) time.Time {
 }
 
 // Save import a batch of PaymentDemandLine and update the database accordingly.
-// The batch must be valid i.e. the Validate() function should be called before
-// using Save().
 // The import process uses a temporary table to store the batch. This batch is
 // first modified using a view to select the last beneficiary in case of
 // duplicated lines due to the query the generates the batch. Only lines
@@ -168,44 +196,39 @@ func excel2Time(i int64) time.Time {
 // and demand_value are updated.
 // The null process_date are updated when the corresponding row in the database
 // is missing in the batch.
-func (p *PaymentDemandBatch) Save(db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-
-	if _, err := tx.Exec("DELETE from temp_payment_demands"); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete %v", err)
-	}
+func (p *PaymentDemandBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
+	p.validate(report)
+	return report, report.run(db, "payment_demands", func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE from temp_payment_demands"); err != nil {
+			return fmt.Errorf("delete %v", err)
+		}
 
-	stmt, err := tx.Prepare(pq.CopyIn("temp_payment_demands", "iris_code",
-		"iris_name", "commitment_date", "beneficiary_code", "demand_number",
-		"demand_date", "receipt_date", "demand_value", "csf_date", "csf_comment",
-		"demand_status", "status_comment"))
-	if err != nil {
-		return fmt.Errorf("prepare stmt %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range p.Lines {
-		if _, err = stmt.Exec(r.IrisCode, r.IrisName, excel2Time(r.CommitmentDate),
-			r.BeneficiaryCode, r.DemandNumber, excel2Time(r.DemandDate),
-			excel2Time(r.ReceiptDate), r.DemandValue, nullExcel2NullTime(r.CsfDate), r.CsfComment,
-			r.DemandStatus, r.StatusComment); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v  %v", r, err)
+		stmt, err := tx.Prepare(pq.CopyIn("temp_payment_demands", "iris_code",
+			"iris_name", "commitment_date", "beneficiary_code", "demand_number",
+			"demand_date", "receipt_date", "demand_value", "csf_date", "csf_comment",
+			"demand_status", "status_comment"))
+		if err != nil {
+			return fmt.Errorf("prepare stmt %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement exec flush %v", err)
-	}
-	type query struct {
-		Query string
-		Args  []interface{}
-	}
-	queries := []query{
-		{Query: `INSERT INTO payment_demands (import_date,iris_code,
+		defer stmt.Close()
+		for _, r := range p.Lines {
+			if _, err = stmt.Exec(r.IrisCode, r.IrisName, excel2Time(r.CommitmentDate),
+				r.BeneficiaryCode, r.DemandNumber, excel2Time(r.DemandDate),
+				excel2Time(r.ReceiptDate), r.DemandValue, nullExcel2NullTime(r.CsfDate), r.CsfComment,
+				r.DemandStatus, r.StatusComment); err != nil {
+				return fmt.Errorf("insertion de %+v  %v", r, err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement exec flush %v", err)
+		}
+		type query struct {
+			Query string
+			Args  []interface{}
+		}
+		queries := []query{
+			{Query: `INSERT INTO payment_demands (import_date,iris_code,
 			iris_name,beneficiary_id,demand_number,demand_date,receipt_date,demand_value,
 			csf_date,csf_comment,demand_status,status_comment,excluded,excluded_comment,
 			processed_date)
@@ -216,9 +239,9 @@ func (p *PaymentDemandBatch) Save(db *sql.DB) error {
 		JOIN beneficiary b ON b.code=t.beneficiary_code
 		WHERE (t.iris_code,t.beneficiary_code,t.demand_number) NOT IN 
 		(SELECT iris_code,beneficiary_code,demand_number FROM payment_demands)`,
-			Args: []interface{}{p.ImportDate}},
-		{
-			Query: `UPDATE payment_demands SET csf_date=t.csf_date,csf_comment=t.csf_comment,
+				Args: []interface{}{p.ImportDate}},
+			{
+				Query: `UPDATE payment_demands SET csf_date=t.csf_date,csf_comment=t.csf_comment,
 			demand_status=t.demand_status,status_comment=t.status_comment,
 			demand_value=t.demand_value
 			FROM (SELECT t.*,b.id AS beneficiary_id FROM imported_payment_demands t
@@ -226,25 +249,25 @@ func (p *PaymentDemandBatch) Save(db *sql.DB) error {
 			WHERE (payment_demands.iris_code=t.iris_code AND
 			payment_demands.beneficiary_id=t.beneficiary_id AND
 			payment_demands.demand_number=t.demand_number)`,
-			Args: []interface{}{}},
-		{
-			Query: `UPDATE payment_demands SET processed_date=$1
+				Args: []interface{}{}},
+			{
+				Query: `UPDATE payment_demands SET processed_date=$1
 			WHERE (iris_code,beneficiary_id,demand_number) NOT IN 	
 				(SELECT t.iris_code,b.id,t.demand_number FROM imported_payment_demands t
 					JOIN beneficiary b ON t.beneficiary_code=b.code)
 				AND processed_date IS NULL`,
-			Args: []interface{}{p.ImportDate}},
-		{
-			Query: `DELETE from temp_payment_demands`,
-			Args:  []interface{}{}},
-	}
-	for i, q := range queries {
-		if _, err := tx.Exec(q.Query, q.Args...); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d %v", i+1, err)
+				Args: []interface{}{p.ImportDate}},
+			{
+				Query: `DELETE from temp_payment_demands`,
+				Args:  []interface{}{}},
 		}
-	}
-	return tx.Commit()
+		for i, q := range queries {
+			if _, err := tx.Exec(q.Query, q.Args...); err != nil {
+				return fmt.Errorf("requête %d %v", i+1, err)
+			}
+		}
+		return nil
+	})
 }
 
 // GetAll fetches the count of the unprocessed or uncontrolled payment demands
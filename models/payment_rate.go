@@ -3,7 +3,6 @@ package models
 import (
 	"database/sql"
 	"fmt"
-	"sync"
 )
 
 // PaymentRate model is used to calculate the ratio of payment over the last
@@ -13,20 +12,23 @@ type PaymentRate struct {
 	ActualRate NullFloat64 `json:"ActualRate"`
 }
 
-var cachedPR PaymentRate
-
-func (p *PaymentRate) copy(src *PaymentRate) {
-	p.PastRate = src.PastRate
-	p.ActualRate = src.ActualRate
-}
-
 // Get fetches the ratios from database using the cache technique to avoid
 // launching unnecessary queries
 func (p *PaymentRate) Get(db *sql.DB) error {
-	if !needUpdate(paymentRateUpdate, paymentDemandsUpdate, everyDayUpdate) {
-		p.copy(&cachedPR)
-		return nil
+	v, err := aggregates.get(&paymentRateCache, "", func() (interface{}, error) {
+		var r PaymentRate
+		err := r.fetch(db)
+		return r, err
+	})
+	if err != nil {
+		return err
 	}
+	*p = v.(PaymentRate)
+	return nil
+}
+
+// fetch computes the payment ratios from database
+func (p *PaymentRate) fetch(db *sql.DB) error {
 	query := `SELECT past_dow_payment.s/past_payment.s,actual_payment.s/available_credits.s
 	FROM
 	(SELECT sum(value) s FROM payment 
@@ -41,10 +43,5 @@ func (p *PaymentRate) Get(db *sql.DB) error {
 	if err := db.QueryRow(query).Scan(&p.PastRate, &p.ActualRate); err != nil {
 		return fmt.Errorf("select %v", err)
 	}
-	var mutex = &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
-	cachedPR.copy(p)
-	update(paymentRateUpdate)
 	return nil
 }
@@ -28,6 +28,23 @@ type Placements struct {
 	Lines []Placement `json:"Placement"`
 }
 
+// placementMapping defines the columns of the XLSX or CSV file of a placement
+// batch
+var placementMapping = BatchMapping{
+	Key: "Placement",
+	Columns: []BatchColumn{
+		{"IrisCode", ColText, []string{"Code IRIS", "N° IRIS"}},
+		{"Count", ColInt, []string{"Nombre", "Nombre de stages"}},
+		{"ContractYear", ColInt, []string{"Année du contrat", "Année contrat"}},
+		{"Comment", ColText, []string{"Commentaire"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *Placements) Mapping() *BatchMapping {
+	return &placementMapping
+}
+
 // Update changes the comment of a placement
 func (p *Placement) Update(db *sql.DB) error {
 	_, err := db.Exec(`UPDATE placement SET comment=$1 WHERE id=$2`, p.Comment, p.ID)
@@ -164,53 +181,42 @@ func (p *Placements) GetByBeneficiaryGroup(bID int64, db *sql.DB) error {
 }
 
 // Save update the database with a set of Placement
-func (p *Placements) Save(db *sql.DB) error {
+func (p *Placements) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
 	for i, r := range p.Lines {
 		if r.IrisCode == "" {
-			return fmt.Errorf("Ligne %d, iris_code vide", i+1)
+			report.addError(i, "IrisCode", "code IRIS vide")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_placement", "iris_code", "count",
-		"contract_year"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range p.Lines {
-		if _, err = stmt.Exec(r.IrisCode, r.Count, r.ContractYear); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+	return report, report.run(db, "placement", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_placement", "iris_code", "count",
+			"contract_year"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement exec flush %v", err)
-	}
-	queries := []string{`INSERT INTO placement(iris_code,count,contract_year,
+		defer stmt.Close()
+		for _, r := range p.Lines {
+			if _, err = stmt.Exec(r.IrisCode, r.Count, r.ContractYear); err != nil {
+				return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement exec flush %v", err)
+		}
+		return execQueries(tx, []string{`INSERT INTO placement(iris_code,count,contract_year,
 		commitment_id) 
 	SELECT t.iris_code,t.count,t.contract_year,MIN(c.id) FROM temp_placement t
 	LEFT OUTER JOIN commitment c ON t.iris_code=c.iris_code
 	WHERE t.iris_code NOT IN (SELECT DISTINCT iris_code FROM placement)
   GROUP BY 1,2,3`,
-		`UPDATE placement SET count=t.count,contract_year=t.contract_year,
+			`UPDATE placement SET count=t.count,contract_year=t.contract_year,
 		commitment_id=t.id FROM
 		(SELECT t.iris_code,t.count,t.contract_year,MIN(c.id) id FROM temp_placement t
 			LEFT OUTER JOIN commitment c ON t.iris_code=c.iris_code
 			WHERE t.iris_code IN (SELECT DISTINCT iris_code FROM placement)
 			GROUP BY 1,2,3) t
 		WHERE placement.iris_code=t.iris_code`,
-		`DELETE FROM temp_placement`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE FROM temp_placement`,
+		})
+	})
 }
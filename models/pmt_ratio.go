@@ -28,6 +28,25 @@ type PmtRatioBatch struct {
 	Ratios []PmtRatio `json:"Ratios"`
 }
 
+// pmtRatioMapping defines the columns of the XLSX or CSV file of a payment
+// ratio batch
+var pmtRatioMapping = BatchMapping{
+	Key: "Ratios",
+	Columns: []BatchColumn{
+		{"Index", ColInt, []string{"Indice", "Année"}},
+		{"SectorID", ColInt, []string{"Secteur", "ID secteur"}},
+		{"Ratio", ColFloat, []string{"Ratio", "Taux"}},
+	},
+	Params: []BatchColumn{
+		{"Year", ColInt, []string{"Année"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *PmtRatioBatch) Mapping() *BatchMapping {
+	return &pmtRatioMapping
+}
+
 // PmtRatiosYears is used to fetch years with ratios payments from database
 type PmtRatiosYears struct {
 	Years []int `json:"PmtRatiosYear"`
@@ -70,33 +89,28 @@ func (p *PmtRatios) Get(db *sql.DB, year int) error {
 }
 
 // Save updates or inserts the payment ratios of a given year
-func (p *PmtRatioBatch) Save(db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	if _, err = tx.Exec(`DELETE FROM ratio WHERE year=$1`, p.Year); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("ratio", "year", "index", "sector_id",
-		"ratio"))
-	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement creation %v", err)
-	}
-	defer stmt.Close()
-	for _, r := range p.Ratios {
-		if _, err = stmt.Exec(p.Year, r.Index, r.SectorID, r.Ratio); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("statement execution %v", err)
+func (p *PmtRatioBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Ratios))
+	return report, report.run(db, "ratio", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM ratio WHERE year=$1`, p.Year); err != nil {
+			return fmt.Errorf("delete %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	return tx.Commit()
+		stmt, err := tx.Prepare(pq.CopyIn("ratio", "year", "index", "sector_id",
+			"ratio"))
+		if err != nil {
+			return fmt.Errorf("statement creation %v", err)
+		}
+		defer stmt.Close()
+		for _, r := range p.Ratios {
+			if _, err = stmt.Exec(p.Year, r.Index, r.SectorID, r.Ratio); err != nil {
+				return fmt.Errorf("statement execution %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return nil
+	})
 }
 
 // Get fetches all years from ratio table in the database
@@ -177,6 +177,26 @@ type PreProgBatch struct {
 	Lines []PreProgLine `json:"PreProg"`
 }
 
+// preProgMapping defines the columns of the XLSX or CSV file of a pre
+// programmation batch
+var preProgMapping = BatchMapping{
+	Key: "PreProg",
+	Columns: []BatchColumn{
+		{"CommissionID", ColInt, []string{"Commission", "ID commission"}},
+		{"Year", ColInt, []string{"Année"}},
+		{"Value", ColInt, []string{"Montant"}},
+		{"KindID", ColInt, []string{"ID type"}},
+		{"Comment", ColText, []string{"Commentaire"}},
+		{"Project", ColText, []string{"Projet"}},
+		{"ActionID", ColInt, []string{"Action", "ID action"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *PreProgBatch) Mapping() *BatchMapping {
+	return &preProgMapping
+}
+
 // GetAll fetches all PreProg of a given year from the database
 func (p *PreProgs) GetAll(year int64, db *sql.DB) error {
 	rows, err := db.Query(preProgQry, year)
@@ -278,55 +298,43 @@ func (p *FcPreProgs) GetAllOfKind(year int64, kind int64, db *sql.DB) error {
 // batch includes only one year, otherwise throw an error. It replaces all
 // the datas of the given year and kinds, deleting PreProgData of that year and
 // kind in the database
-func (p *PreProgBatch) Save(kind int64, year int64, db *sql.DB) error {
+func (p *PreProgBatch) Save(opt BatchOptions, kind int64, year int64, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
 	for i, l := range p.Lines {
 		if l.CommissionID == 0 {
-			return fmt.Errorf("ligne %d, CommissionID nul", i+1)
+			report.addError(i, "CommissionID", "commission nulle")
 		}
 		if l.Value == 0 {
-			return fmt.Errorf("ligne %d, Value nul", i+1)
+			report.addError(i, "Value", "montant nul")
 		}
 		if l.ActionID == 0 {
-			return fmt.Errorf("ligne %d, ActionID nul", i+1)
+			report.addError(i, "ActionID", "action nulle")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_pre_prog", "commission_id",
-		"year", "value", "kind", "kind_id", "project", "comment", "action_id"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, l := range p.Lines {
-		if _, err = stmt.Exec(l.CommissionID, year, l.Value, kind, l.KindID,
-			l.Project, l.Comment, l.ActionID); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("statement execution %v", err)
+	return report, report.run(db, "pre_prog", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_pre_prog", "commission_id",
+			"year", "value", "kind", "kind_id", "project", "comment", "action_id"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	if _, err = tx.Exec(`DELETE FROM pre_prog WHERE year=$1 AND kind=$2`,
-		year, kind); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete query %v", err)
-	}
-	queries := []string{`INSERT INTO pre_prog (commission_id,year,value,kind,kind_id,
+		defer stmt.Close()
+		for _, l := range p.Lines {
+			if _, err = stmt.Exec(l.CommissionID, year, l.Value, kind, l.KindID,
+				l.Project, l.Comment, l.ActionID); err != nil {
+				return fmt.Errorf("statement execution %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		if _, err = tx.Exec(`DELETE FROM pre_prog WHERE year=$1 AND kind=$2`,
+			year, kind); err != nil {
+			return fmt.Errorf("delete query %v", err)
+		}
+		return execQueries(tx, []string{`INSERT INTO pre_prog (commission_id,year,value,kind,kind_id,
 		project,comment,action_id) SELECT DISTINCT commission_id,year,value,kind,kind_id,
 		project,comment,action_id FROM temp_pre_prog `,
-		`DELETE from temp_pre_prog`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_pre_prog`,
+		})
+	})
 }
@@ -49,6 +49,25 @@ type ProgBatch struct {
 	Lines []ProgLine `json:"Prog"`
 }
 
+// progMapping defines the columns of the XLSX or CSV file of a programmation
+// batch
+var progMapping = BatchMapping{
+	Key: "Prog",
+	Columns: []BatchColumn{
+		{"CommissionID", ColInt, []string{"Commission", "ID commission"}},
+		{"Value", ColInt, []string{"Montant"}},
+		{"Kind", ColInt, []string{"Type"}},
+		{"KindID", ColInt, []string{"ID type"}},
+		{"Comment", ColText, []string{"Commentaire"}},
+		{"ActionID", ColInt, []string{"Action", "ID action"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (p *ProgBatch) Mapping() *BatchMapping {
+	return &progMapping
+}
+
 // ProgYears embeddes an array of int64 for json export fetching the available
 // years with programmation data in the database
 type ProgYears struct {
@@ -194,61 +213,49 @@ func (p *Progs) GetAll(year int64, db *sql.DB) error {
 
 // Save insert a batch of ProgLine into the database. It checks if the
 // batch includes only one year , otherwise throw an error. It replaces all the
-//  datas of the given year, deleting the programming data of that year
+// datas of the given year, deleting the programming data of that year
 // in the database
-func (p *ProgBatch) Save(year int64, db *sql.DB) error {
+func (p *ProgBatch) Save(opt BatchOptions, year int64, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(p.Lines))
 	for i, l := range p.Lines {
 		if l.CommissionID == 0 {
-			return fmt.Errorf("ligne %d, CommissionID nul", i+1)
+			report.addError(i, "CommissionID", "commission nulle")
 		}
 		if l.Value == 0 {
-			return fmt.Errorf("ligne %d, Value nul", i+1)
+			report.addError(i, "Value", "montant nul")
 		}
 		if l.ActionID == 0 {
-			return fmt.Errorf("ligne %d, ActionID nul", i+1)
+			report.addError(i, "ActionID", "action nulle")
 		}
 		if l.Kind != KindCopro && l.Kind != KindRenewProject && l.Kind != KindHousing {
-			return fmt.Errorf("linge %d, Kind de mauvais type", i+1)
+			report.addError(i, "Kind", "type incorrect")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_prog", "commission_id",
-		"year", "value", "kind", "kind_id", "comment", "action_id"))
-	if err != nil {
-		return fmt.Errorf("insert statement %v", err)
-	}
-	defer stmt.Close()
-	for _, l := range p.Lines {
-		if _, err = stmt.Exec(l.CommissionID, year, l.Value, l.Kind, l.KindID,
-			l.Comment, l.ActionID); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("statement execution %v", err)
+	return report, report.run(db, "prog", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_prog", "commission_id",
+			"year", "value", "kind", "kind_id", "comment", "action_id"))
+		if err != nil {
+			return fmt.Errorf("insert statement %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	if _, err = tx.Exec(`DELETE FROM prog WHERE year=$1`, year); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete query %v", err)
-	}
-	queries := []string{`INSERT INTO prog (commission_id,year,value,kind,kind_id,
+		defer stmt.Close()
+		for _, l := range p.Lines {
+			if _, err = stmt.Exec(l.CommissionID, year, l.Value, l.Kind, l.KindID,
+				l.Comment, l.ActionID); err != nil {
+				return fmt.Errorf("statement execution %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		if _, err = tx.Exec(`DELETE FROM prog WHERE year=$1`, year); err != nil {
+			return fmt.Errorf("delete query %v", err)
+		}
+		return execQueries(tx, []string{`INSERT INTO prog (commission_id,year,value,kind,kind_id,
 		comment,action_id) SELECT DISTINCT commission_id,year,value,kind,kind_id,
 		comment,action_id FROM temp_prog `,
-		`DELETE from temp_prog`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_prog`,
+		})
+	})
 }
 
 // GetAll fetches all programmation years in the database
@@ -277,6 +284,21 @@ func (p *ProgYears) GetAll(db *sql.DB) error {
 
 // GetAll fetches all cumulated programmation value of the current year
 func (c *CumulatedProgrammation) GetAll(db *sql.DB) error {
+	v, err := aggregates.get(&cumulatedProgrammationCache, "",
+		func() (interface{}, error) {
+			var r CumulatedProgrammation
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*c = v.(CumulatedProgrammation)
+	return nil
+}
+
+// fetch computes the cumulated programmation of the current year from database
+func (c *CumulatedProgrammation) fetch(db *sql.DB) error {
 	query := `WITH prg_month as (
 		select max(extract(month from c.date))::int as max_month
 		from prog,commission c 
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TracedDriverName is the name of the PostgreSQL driver timing the queries
+// sent by the models in order to log the slow ones
+const TracedDriverName = "postgres-traced"
+
+// DefaultSlowQueryThreshold is the duration above which a query is logged if
+// not configured
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+func init() {
+	sql.Register(TracedDriverName, tracedDriver{&pq.Driver{}})
+}
+
+// QueryTracer logs as JSON the queries lasting more than Threshold with the
+// function of the models that sent them
+type QueryTracer struct {
+	Threshold time.Duration
+	Logger    *log.Logger
+}
+
+// queryTracer is the tracer used by the driver, nil if the queries aren't
+// traced
+var queryTracer *QueryTracer
+
+// SetQueryTracer sets the tracer used by the driver, nil to disable it
+func SetQueryTracer(t *QueryTracer) {
+	queryTracer = t
+}
+
+// slowQueryEntry is the log entry of a slow query. The duration is the one
+// of the execution or, for a select, the time until the first rows are
+// received.
+type slowQueryEntry struct {
+	Time     string  `json:"time"`
+	Level    string  `json:"level"`
+	Message  string  `json:"msg"`
+	Duration float64 `json:"durationMs"`
+	Caller   string  `json:"caller"`
+	Query    string  `json:"query"`
+}
+
+// maxLoggedQueryLength is the length above which the logged query is cut
+const maxLoggedQueryLength = 1000
+
+// trace logs the query if it lasted more than the threshold
+func (t *QueryTracer) trace(query string, start time.Time) {
+	d := time.Since(start)
+	if d < t.Threshold {
+		return
+	}
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQueryLength {
+		query = query[:maxLoggedQueryLength] + "..."
+	}
+	b, err := json.Marshal(slowQueryEntry{
+		Time:     start.Format(time.RFC3339),
+		Level:    "warn",
+		Message:  "requête lente",
+		Duration: float64(d.Microseconds()) / 1000,
+		Caller:   queryCaller(),
+		Query:    query})
+	if err != nil {
+		return
+	}
+	t.Logger.Println(string(b))
+}
+
+// queryCaller returns the first function of the call stack outside of the
+// database/sql package and of the driver, with its file and line
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "database/sql.") &&
+			!strings.HasSuffix(f.File, "query_trace.go") {
+			return fmt.Sprintf("%s (%s:%d)", path.Base(f.Function),
+				path.Base(f.File), f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// tracedDriver opens the connections of the driver wrapping them into traced
+// connections
+type tracedDriver struct {
+	driver.Driver
+}
+
+// Open implements the driver.Driver interface
+func (d tracedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return tracedConn{c}, nil
+}
+
+// tracedConn times the queries sent directly on the connection, the prepared
+// statements used by the batches not being traced
+type tracedConn struct {
+	driver.Conn
+}
+
+// ExecContext implements the driver.ExecerContext interface
+func (c tracedConn) ExecContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	t, start := queryTracer, time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	if t != nil {
+		t.trace(query, start)
+	}
+	return res, err
+}
+
+// QueryContext implements the driver.QueryerContext interface
+func (c tracedConn) QueryContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	t, start := queryTracer, time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if t != nil {
+		t.trace(query, start)
+	}
+	return rows, err
+}
+
+// BeginTx implements the driver.ConnBeginTx interface
+func (c tracedConn) BeginTx(ctx context.Context,
+	opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// Ping implements the driver.Pinger interface
+func (c tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -54,19 +53,44 @@ type RenewProjectBatch struct {
 	Lines []RenewProjectLine `json:"RenewProject"`
 }
 
+// renewProjectMapping defines the columns of the XLSX or CSV file of a renew
+// project batch
+var renewProjectMapping = BatchMapping{
+	Key: "RenewProject",
+	Columns: []BatchColumn{
+		{"Reference", ColText, []string{"Référence"}},
+		{"Name", ColText, []string{"Nom", "Site"}},
+		{"Budget", ColInt, []string{"Budget"}},
+		{"PRIN", ColBool, nil},
+		{"CityCode1", ColInt, []string{"Code INSEE 1", "Ville 1"}},
+		{"BudgetCity1", ColInt, []string{"Budget ville 1"}},
+		{"CityCode2", ColInt, []string{"Code INSEE 2", "Ville 2"}},
+		{"BudgetCity2", ColInt, []string{"Budget ville 2"}},
+		{"CityCode3", ColInt, []string{"Code INSEE 3", "Ville 3"}},
+		{"BudgetCity3", ColInt, []string{"Budget ville 3"}},
+		{"Population", ColInt, []string{"Population"}},
+		{"CompositeIndex", ColInt, []string{"Indice composite"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (r *RenewProjectBatch) Mapping() *BatchMapping {
+	return &renewProjectMapping
+}
+
 // Validate checks if the fields of a renew project are correctly filled
 func (r *RenewProject) Validate() error {
 	if r.Reference == "" {
-		return errors.New("Champ reference incorrect")
+		return &ValidationError{Field: "Reference", Message: "Champ reference incorrect"}
 	}
 	if r.Name == "" {
-		return errors.New("Champ name incorrect")
+		return &ValidationError{Field: "Name", Message: "Champ name incorrect"}
 	}
 	if r.Budget == 0 {
-		return errors.New("Champ budget incorrect")
+		return &ValidationError{Field: "Budget", Message: "Champ budget incorrect"}
 	}
 	if r.CityCode1 == 0 {
-		return errors.New("Champ citycode1 incorrect")
+		return &ValidationError{Field: "CityCode1", Message: "Champ citycode1 incorrect"}
 	}
 	return nil
 }
@@ -98,6 +122,7 @@ func (r *RenewProject) Create(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("insert query %v", err)
 	}
+	invalidateCache("renew_project")
 	return db.QueryRow(`SELECT c1.name,c2.name,c3.name FROM renew_project r
 	JOIN city c1 ON r.city_code1=c1.insee_code
 	LEFT JOIN city c2 ON r.city_code2=c2.insee_code
@@ -116,12 +141,13 @@ func (r *RenewProject) Update(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("renew_project")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Projet de renouvellement introuvable")
+		return notFound("Projet de renouvellement")
 	}
 	return db.QueryRow(`SELECT c1.name,c2.name,c3.name FROM renew_project r
 	JOIN city c1 ON r.city_code1=c1.insee_code
@@ -132,13 +158,20 @@ func (r *RenewProject) Update(db *sql.DB) error {
 
 // GetAll fetches all renew projects from database
 func (r *RenewProjects) GetAll(db *sql.DB) error {
+	return r.GetInScope(db, nil)
+}
+
+// GetInScope fetches the renew projects having a city in the scope from
+// database
+func (r *RenewProjects) GetInScope(db *sql.DB, scope *Scope) error {
 	rows, err := db.Query(`SELECT r.id,r.reference,r.name,r.budget,r.prin,
 	r.city_code1,c1.name,r.city_code2,c2.name,r.city_code3,c3.name,
 	r.population,r.composite_index,r.budget_city_1,r.budget_city_2,r.budget_city_3
 	FROM renew_project r
 	JOIN city c1 ON c1.insee_code= r.city_code1
 	LEFT JOIN city c2 ON c2.insee_code= r.city_code2
-	LEFT JOIN city c3 ON c3.insee_code= r.city_code3`)
+	LEFT JOIN city c3 ON c3.insee_code= r.city_code3 WHERE TRUE` +
+		scope.renewProjectFilter("r"))
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -169,69 +202,67 @@ func (r *RenewProject) Delete(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("delete %v", err)
 	}
+	invalidateCache("renew_project")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Projet de renouvellement introuvable")
+		return notFound("Projet de renouvellement")
 	}
 	return nil
 }
 
 // Save validate the array of project and update or save all renew projects
 // against the database
-func (r *RenewProjectBatch) Save(db *sql.DB) error {
+func (r *RenewProjectBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(r.Lines))
 	for i, l := range r.Lines {
-		if l.Name == "" || l.Reference == "" || l.Budget == 0 || l.CityCode1 == 0 {
-			return fmt.Errorf("ligne %d : champs incorrects", i+1)
+		if l.Name == "" {
+			report.addError(i, "Name", "nom vide")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_renew_project", "reference", "name",
-		"budget", "prin", "city_code1", "city_code2", "city_code3", "population",
-		"composite_index", "budget_city_1", "budget_city_2", "budget_city_3"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for _, l := range r.Lines {
-		if _, err = stmt.Exec(l.Reference, l.Name, l.Budget, l.PRIN, l.CityCode1,
-			l.CityCode2, l.CityCode3, l.Population, l.CompositeIndex, l.BudgetCity1,
-			l.BudgetCity2, l.BudgetCity3); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insertion de %+v : %s", r, err.Error())
+		if l.Reference == "" {
+			report.addError(i, "Reference", "référence vide")
+		}
+		if l.Budget == 0 {
+			report.addError(i, "Budget", "budget nul")
+		}
+		if l.CityCode1 == 0 {
+			report.addError(i, "CityCode1", "code ville nul")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{`UPDATE renew_project SET name=t.name,budget=t.budget,
+	return report, report.run(db, "renew_project", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_renew_project", "reference", "name",
+			"budget", "prin", "city_code1", "city_code2", "city_code3", "population",
+			"composite_index", "budget_city_1", "budget_city_2", "budget_city_3"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		for _, l := range r.Lines {
+			if _, err = stmt.Exec(l.Reference, l.Name, l.Budget, l.PRIN, l.CityCode1,
+				l.CityCode2, l.CityCode3, l.Population, l.CompositeIndex, l.BudgetCity1,
+				l.BudgetCity2, l.BudgetCity3); err != nil {
+				return fmt.Errorf("insertion de %+v : %s", l, err.Error())
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE renew_project SET name=t.name,budget=t.budget,
 	prin=t.prin,city_code1=t.city_code1,city_code2=t.city_code2,
 	city_code3=t.city_code3,population=t.population,composite_index=t.composite_index,
 	budget_city_1=t.budget_city_1,budget_city_2=t.budget_city_2,
 	budget_city_3=t.budget_city_3
 	FROM temp_renew_project t WHERE t.reference = renew_project.reference`,
-		`INSERT INTO renew_project (reference,name,budget,prin,city_code1,city_code2,
+			`INSERT INTO renew_project (reference,name,budget,prin,city_code1,city_code2,
 			city_code3,population,composite_index,budget_city_1,budget_city_2,
 			budget_city_3)
 	SELECT reference,name,budget, prin,city_code1,city_code2,city_code3,population,
 		composite_Index,budget_city_1,budget_city_2,budget_city_3
 		FROM temp_renew_project 
 		WHERE reference NOT IN (SELECT reference from renew_project)`,
-		`DELETE from temp_renew_project`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-
-	}
-	return tx.Commit()
+			`DELETE from temp_renew_project`,
+		})
+	})
 }
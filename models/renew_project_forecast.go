@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -44,19 +43,39 @@ type RenewProjectForecastBatch struct {
 	Lines []RenewProjectForecastLine `json:"RenewProjectForecast"`
 }
 
+// renewProjectForecastMapping defines the columns of the XLSX or CSV file of a
+// renew project forecast batch
+var renewProjectForecastMapping = BatchMapping{
+	Key: "RenewProjectForecast",
+	Columns: []BatchColumn{
+		{"ID", ColInt, nil},
+		{"CommissionID", ColInt, []string{"Commission", "ID commission"}},
+		{"Value", ColInt, []string{"Montant", "Prévision"}},
+		{"Project", ColText, []string{"Projet"}},
+		{"Comment", ColText, []string{"Commentaire"}},
+		{"RenewProjectID", ColInt, []string{"Projet RU", "ID projet"}},
+		{"ActionCode", ColInt, []string{"Code action"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (r *RenewProjectForecastBatch) Mapping() *BatchMapping {
+	return &renewProjectForecastMapping
+}
+
 // Validate checks if RenewProjectForecast's fields are correctly filled
 func (r *RenewProjectForecast) Validate() error {
 	if r.CommissionID == 0 {
-		return errors.New("Champ commissionID incorrect")
+		return &ValidationError{Field: "CommissionID", Message: "Champ commissionID incorrect"}
 	}
 	if r.Value == 0 {
-		return errors.New("Champ value incorrect")
+		return &ValidationError{Field: "Value", Message: "Champ value incorrect"}
 	}
 	if r.RenewProjectID == 0 {
-		return errors.New("Champ renewprojectID incorrect")
+		return &ValidationError{Field: "RenewProjectID", Message: "Champ renewprojectID incorrect"}
 	}
 	if r.ActionID == 0 {
-		return errors.New("Champ actionID incorrect")
+		return &ValidationError{Field: "ActionID", Message: "Champ actionID incorrect"}
 	}
 	return nil
 }
@@ -70,6 +89,7 @@ func (r *RenewProjectForecast) Create(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("renew_project_forecast")
 	err = db.QueryRow(`SELECT c.name, c.date, b.code, b.name 
 		FROM commission c, budget_action b WHERE c.id=$1 AND b.id=$2`,
 		r.CommissionID, r.ActionID).Scan(&r.CommissionName, &r.CommissionDate,
@@ -101,12 +121,13 @@ func (r *RenewProjectForecast) Update(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("renew_project_forecast")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Prévision RU introuvable")
+		return notFound("Prévision RU")
 	}
 	err = db.QueryRow(`SELECT c.name, c.date, b.code, b.name 
 		FROM commission c, budget_action b WHERE c.id=$1 AND b.id=$2`,
@@ -176,64 +197,59 @@ func (r *RenewProjectForecast) Delete(db *sql.DB) (err error) {
 	if err != nil {
 		return err
 	}
+	invalidateCache("renew_project_forecast")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if count != 1 {
-		return errors.New("Prévision RU introuvable")
+		return notFound("Prévision RU")
 	}
 	return nil
 }
 
 // Save insert a batch of RenewProjectForecastLine into database
-func (r *RenewProjectForecastBatch) Save(db *sql.DB) (err error) {
-	for i, r := range r.Lines {
-		if r.CommissionID == 0 || r.Value == 0 || r.RenewProjectID == 0 {
-			return fmt.Errorf("ligne %d, champs incorrects", i+1)
+func (r *RenewProjectForecastBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(r.Lines))
+	for i, l := range r.Lines {
+		if l.CommissionID == 0 {
+			report.addError(i, "CommissionID", "commission nulle")
 		}
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_renew_project_forecast", "id",
-		"commission_id", "value", "project", "comment", "renew_project_id", "action_code"))
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-	for _, r := range r.Lines {
-		if _, err = stmt.Exec(r.ID, r.CommissionID, r.Value, r.Project, r.Comment,
-			r.RenewProjectID, &r.ActionCode); err != nil {
-			tx.Rollback()
-			return err
+		if l.Value == 0 {
+			report.addError(i, "Value", "montant nul")
+		}
+		if l.RenewProjectID == 0 {
+			report.addError(i, "RenewProjectID", "projet nul")
 		}
 	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{`UPDATE renew_project_forecast SET commission_id=t.commission_id,
+	return report, report.run(db, "renew_project_forecast", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_renew_project_forecast", "id",
+			"commission_id", "value", "project", "comment", "renew_project_id", "action_code"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
+		}
+		defer stmt.Close()
+		for _, r := range r.Lines {
+			if _, err = stmt.Exec(r.ID, r.CommissionID, r.Value, r.Project, r.Comment,
+				r.RenewProjectID, &r.ActionCode); err != nil {
+				return fmt.Errorf("statement execution %v", err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE renew_project_forecast SET commission_id=t.commission_id,
 	value=t.value,comment=t.comment,project=t.project,renew_project_id=t.renew_project_id,
 	action_id=b.id
 	FROM temp_renew_project_forecast t, budget_action b 
 	WHERE t.id = renew_project_forecast.id AND t.action_code=b.code`,
-		`INSERT INTO renew_project_forecast (commission_id,value,project,comment,
+			`INSERT INTO renew_project_forecast (commission_id,value,project,comment,
 			renew_project_id,action_id)
 	SELECT t.commission_id,t.value,t.project,t.comment,t.renew_project_id, b.id
 	FROM temp_renew_project_forecast t
 	JOIN budget_action b ON t.action_code=b.code
 		WHERE t.id NOT IN (SELECT id from renew_project_forecast)`,
-		`DELETE FROM temp_renew_project_forecast`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %s", i, err.Error())
-		}
-	}
-	tx.Commit()
-	return nil
+			`DELETE FROM temp_renew_project_forecast`,
+		})
+	})
 }
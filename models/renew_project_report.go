@@ -36,6 +36,21 @@ type RenewProjectReport struct {
 
 // Get fetches all line of the renew project report
 func (r *RenewProjectReport) Get(db *sql.DB) error {
+	v, err := aggregates.get(&renewProjectReportCache, "",
+		func() (interface{}, error) {
+			var r RenewProjectReport
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*r = v.(RenewProjectReport)
+	return nil
+}
+
+// fetch computes the renew project report from database
+func (r *RenewProjectReport) fetch(db *sql.DB) error {
 	rows, err := db.Query(`WITH city_state AS (SELECT city.name,
 		co.name AS community_name,city.insee_code,SUM(c.value) AS cmt,
 		SUM(p.value) AS pmt FROM city
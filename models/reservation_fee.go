@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -75,13 +76,45 @@ type ReservationFeeBatch struct {
 	Lines []ReservationFeeLine `json:"ReservationFee"`
 }
 
+// reservationFeeMapping defines the columns of the XLSX or CSV file of a
+// reservation fee batch
+var reservationFeeMapping = BatchMapping{
+	Key: "ReservationFee",
+	Columns: []BatchColumn{
+		{"CurrentBeneficiary", ColText, []string{"Bénéficiaire actuel", "Bailleur actuel"}},
+		{"FirstBeneficiary", ColText, []string{"Bénéficiaire initial", "Bailleur initial"}},
+		{"City", ColText, []string{"Ville", "Commune"}},
+		{"AddressNumber", ColText, []string{"Numéro", "N°"}},
+		{"AddressStreet", ColText, []string{"Rue", "Voie", "Adresse"}},
+		{"Convention", ColText, []string{"Convention", "N° convention"}},
+		{"Typology", ColText, []string{"Typologie"}},
+		{"RPLS", ColText, []string{"N° RPLS"}},
+		{"ConventionType", ColText, []string{"Type de convention"}},
+		{"Transfer", ColText, []string{"Transfert", "Motif de transfert"}},
+		{"TransferDate", ColDateExcel, []string{"Date de transfert"}},
+		{"PMR", ColBool, nil},
+		{"Comment", ColText, []string{"Commentaire"}},
+		{"ConventionDate", ColDateExcel, []string{"Date de convention", "Date convention"}},
+		{"Area", ColFloat, []string{"Surface"}},
+		{"EndYear", ColInt, []string{"Année de fin", "Fin"}},
+		{"Loan", ColFloat, []string{"Prêt"}},
+		{"Charges", ColFloat, []string{"Charges"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (r *ReservationFeeBatch) Mapping() *BatchMapping {
+	return &reservationFeeMapping
+}
+
 // ReservationFeeBatchResults embeddes the datas of importing a batch of reservation
 // fees
 type ReservationFeeBatchResults struct {
-	BatchSize            int64    `json:"BatchSize"`
-	AddedItems           int64    `json:"AddedItems"`
-	MissingCities        []string `json:"MissingCities"`
-	MissingBeneficiaries []string `json:"MissingBeneficiaries"`
+	BatchSize            int64        `json:"BatchSize"`
+	AddedItems           int64        `json:"AddedItems"`
+	MissingCities        []string     `json:"MissingCities"`
+	MissingBeneficiaries []string     `json:"MissingBeneficiaries"`
+	Report               *BatchReport `json:"BatchReport"`
 }
 
 // PaginatedReservationFees embeddes an array of ReservationFees for json export
@@ -125,10 +158,10 @@ type ExportedReservationFees struct {
 // Valid checks if fields complies with database constraints
 func (r *ReservationFee) Valid() error {
 	if r.CurrentBeneficiaryID == 0 {
-		return fmt.Errorf("CurrentBeneficiaryID null")
+		return &ValidationError{Field: "CurrentBeneficiaryID", Message: "CurrentBeneficiaryID null"}
 	}
 	if r.CityCode == 0 {
-		return fmt.Errorf("CityCode null")
+		return &ValidationError{Field: "CityCode", Message: "CityCode null"}
 	}
 	return nil
 }
@@ -186,7 +219,7 @@ func (r *ReservationFee) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("réservation non trouvée")
+		return NewKindError(ErrNotFound, "réservation non trouvée")
 	}
 	if err := r.getOuterFields(db); err != nil {
 		return fmt.Errorf("select %v", err)
@@ -205,96 +238,88 @@ func (r *ReservationFee) Delete(db *sql.DB) error {
 		return fmt.Errorf("count %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("réservation non trouvée")
+		return NewKindError(ErrNotFound, "réservation non trouvée")
 	}
 	return nil
 }
 
 // Save import a batch of reservation fee, updating the housing transfer, housing
-// convention, housing typology, housing comment and convention type tables
-func (r *ReservationFeeBatch) Save(test bool, db *sql.DB) (*ReservationFeeBatchResults, error) {
+// convention, housing typology, housing comment and convention type tables. In
+// dry run, nothing is saved and the results give the lines that would have been
+// added and the missing cities and beneficiaries.
+func (r *ReservationFeeBatch) Save(opt BatchOptions, db *sql.DB) (*ReservationFeeBatchResults, error) {
+	report := newBatchReport(opt, len(r.Lines))
 	for i, l := range r.Lines {
 		if l.CurrentBeneficiary == "" {
-			return nil, fmt.Errorf("line %d, CurrentBeneficiary empty", i+1)
+			report.addError(i, "CurrentBeneficiary", "bénéficiaire vide")
 		}
 		if l.City == "" {
-			return nil, fmt.Errorf("line %d, City empty", i+1)
+			report.addError(i, "City", "ville vide")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_reservation_fee", "current_beneficiary",
-		"first_beneficiary", "city", "address_number", "address_street", "convention",
-		"typology", "rpls", "convention_type", "transfer", "transfer_date",
-		"pmr", "comment", "convention_date", "area", "end_year", "loan", "charges"))
-	if err != nil {
-		return nil, fmt.Errorf("statement prepare %v", err)
-	}
-	defer stmt.Close()
-	var (
-		transferDate, conventionDate NullTime
-		typology                     NullString
-		b                            = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
-	)
-	for _, r := range r.Lines {
-		transferDate.Valid = r.TransferDate.Valid
-		conventionDate.Valid = r.ConventionDate.Valid
-		typology.Valid = r.Typology.Valid
-		if r.TransferDate.Valid {
-			transferDate.Time = b.Add(time.Duration(r.TransferDate.Int64*24) * time.Hour)
-		}
-		if r.ConventionDate.Valid {
-			conventionDate.Time = b.Add(time.Duration(r.ConventionDate.Int64*24) * time.Hour)
+	var results = ReservationFeeBatchResults{
+		BatchSize:            int64(len(r.Lines)),
+		MissingCities:        []string{},
+		MissingBeneficiaries: []string{},
+		Report:               report}
+	return &results, report.run(db, "reservation_fee", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_reservation_fee", "current_beneficiary",
+			"first_beneficiary", "city", "address_number", "address_street", "convention",
+			"typology", "rpls", "convention_type", "transfer", "transfer_date",
+			"pmr", "comment", "convention_date", "area", "end_year", "loan", "charges"))
+		if err != nil {
+			return fmt.Errorf("statement prepare %v", err)
 		}
-		if r.Typology.Valid {
-			typology.String = strings.TrimSpace(r.Typology.String)
+		defer stmt.Close()
+		var (
+			transferDate, conventionDate NullTime
+			typology                     NullString
+			b                            = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+		)
+		for _, r := range r.Lines {
+			transferDate.Valid = r.TransferDate.Valid
+			conventionDate.Valid = r.ConventionDate.Valid
+			typology.Valid = r.Typology.Valid
+			if r.TransferDate.Valid {
+				transferDate.Time = b.Add(time.Duration(r.TransferDate.Int64*24) * time.Hour)
+			}
+			if r.ConventionDate.Valid {
+				conventionDate.Time = b.Add(time.Duration(r.ConventionDate.Int64*24) * time.Hour)
+			}
+			if r.Typology.Valid {
+				typology.String = strings.TrimSpace(r.Typology.String)
+			}
+			if _, err = stmt.Exec(r.CurrentBeneficiary, r.FirstBeneficiary, r.City,
+				r.AddressNumber, r.AddressStreet, r.Convention, typology,
+				r.RPLS, r.ConventionType, r.Transfer, transferDate, r.PMR, r.Comment,
+				conventionDate, r.Area, r.EndYear, r.Loan, r.Charges); err != nil {
+				return fmt.Errorf("statement exec %v", err)
+			}
 		}
-		if _, err = stmt.Exec(r.CurrentBeneficiary, r.FirstBeneficiary, r.City,
-			r.AddressNumber, r.AddressStreet, r.Convention, typology,
-			r.RPLS, r.ConventionType, r.Transfer, transferDate, r.PMR, r.Comment,
-			conventionDate, r.Area, r.EndYear, r.Loan, r.Charges); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("statement exec %v", err)
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement flush exec %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("statement flush exec %v", err)
-	}
-	queries := []string{
-		`INSERT INTO housing_typology(name)
+		if err = execQueries(tx, []string{
+			`INSERT INTO housing_typology(name)
 			SELECT DISTINCT typology FROM temp_reservation_fee WHERE typology NOTNULL
 			ON CONFLICT DO NOTHING`, // 0
-		`INSERT INTO housing_transfer(name) 
+			`INSERT INTO housing_transfer(name) 
 			SELECT DISTINCT transfer FROM temp_reservation_fee WHERE transfer NOTNULL
 			ON CONFLICT DO NOTHING`, // 1
-		`INSERT INTO convention_type(name)
+			`INSERT INTO convention_type(name)
 			SELECT DISTINCT convention_type FROM temp_reservation_fee 
 			WHERE convention_type NOTNULL
 			ON CONFLICT DO NOTHING`, // 2
-		`INSERT INTO housing_comment(name)
+			`INSERT INTO housing_comment(name)
 			SELECT DISTINCT comment FROM temp_reservation_fee WHERE comment NOTNULL
 			ON CONFLICT DO NOTHING`, // 3
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("requête %d : %s", i, err.Error())
+		}); err != nil {
+			return err
 		}
-	}
-	var results = ReservationFeeBatchResults{
-		MissingCities:        []string{},
-		MissingBeneficiaries: []string{}}
-	results.BatchSize = int64(len(r.Lines))
-	if !test {
-		insertQry :=
-			`INSERT INTO reservation_fee (current_beneficiary_id, first_beneficiary_id,
-			city_code,address_number,address_street,rpls,convention,convention_type_id,
-			transfer_date,transfer_id,pmr,comment_id,convention_date,elise_ref,
-			area,end_year,loan,charges,typology_id)
+		res, err := tx.Exec(`INSERT INTO reservation_fee (current_beneficiary_id,
+			first_beneficiary_id,city_code,address_number,address_street,rpls,
+			convention,convention_type_id,transfer_date,transfer_id,pmr,comment_id,
+			convention_date,elise_ref,area,end_year,loan,charges,typology_id)
 		SELECT b1.id,b2.id,c.insee_code,rf.address_number,rf.address_street,rf.rpls,
 			rf.convention,ct.id,rf.transfer_date,ht.id,rf.pmr,hc.id,
 			rf.convention_date,NULL,rf.area,rf.end_year,rf.loan,rf.charges,ty.id
@@ -305,71 +330,86 @@ func (r *ReservationFeeBatch) Save(test bool, db *sql.DB) (*ReservationFeeBatchR
 		LEFT JOIN convention_type ct ON ct.name=rf.convention_type
 		LEFT JOIN housing_transfer ht ON ht.name=rf.transfer
 		LEFT JOIN housing_comment hc ON hc.name=rf.comment
-		LEFT JOIN housing_typology ty ON ty.name=rf.typology`
-		res, err := tx.Exec(insertQry)
+		LEFT JOIN housing_typology ty ON ty.name=rf.typology`)
 		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("insert %v", err)
+			return fmt.Errorf("insert %v", err)
 		}
-		results.AddedItems, err = res.RowsAffected()
-	}
-	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("count %v", err)
-	}
-	rows, err := tx.Query(`SELECT DISTINCT city FROM temp_reservation_fee 
-		WHERE city NOT IN (SELECT name FROM city)`)
-	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("select city %v", err)
-	}
-	var city string
-	for rows.Next() {
-		if err = rows.Scan(&city); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("city scan %v", err)
+		if results.AddedItems, err = res.RowsAffected(); err != nil {
+			return fmt.Errorf("count %v", err)
 		}
-		results.MissingCities = append(results.MissingCities, city)
-	}
-	rows, err = tx.Query(`SELECT DISTINCT current_beneficiary FROM temp_reservation_fee
-		WHERE current_beneficiary NOT IN (SELECT name FROM beneficiary)`)
-	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("select current beneficiary %v", err)
-	}
-	var beneficiary string
-	for rows.Next() {
-		if err = rows.Scan(&beneficiary); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("current beneficiary scan %v", err)
+		if results.MissingCities, err = missingNames(tx, `SELECT DISTINCT city
+		FROM temp_reservation_fee WHERE city NOT IN (SELECT name FROM city)`,
+			results.MissingCities); err != nil {
+			return fmt.Errorf("select city %v", err)
 		}
-		results.MissingBeneficiaries = append(results.MissingBeneficiaries, beneficiary)
-	}
-	rows, err = tx.Query(`SELECT DISTINCT first_beneficiary FROM temp_reservation_fee
-		WHERE first_beneficiary NOT IN (SELECT name FROM beneficiary)`)
+		if results.MissingBeneficiaries, err = missingNames(tx,
+			`SELECT DISTINCT current_beneficiary FROM temp_reservation_fee
+		WHERE current_beneficiary NOT IN (SELECT name FROM beneficiary)`,
+			results.MissingBeneficiaries); err != nil {
+			return fmt.Errorf("select current beneficiary %v", err)
+		}
+		if results.MissingBeneficiaries, err = missingNames(tx,
+			`SELECT DISTINCT first_beneficiary FROM temp_reservation_fee
+		WHERE first_beneficiary NOT IN (SELECT name FROM beneficiary)`,
+			results.MissingBeneficiaries); err != nil {
+			return fmt.Errorf("select first beneficiary %v", err)
+		}
+		if _, err = tx.Exec(`DELETE FROM temp_reservation_fee`); err != nil {
+			return fmt.Errorf("delete %v", err)
+		}
+		return nil
+	})
+}
+
+// missingNames appends to names the ones fetched by the query
+func missingNames(tx *sql.Tx, qry string, names []string) ([]string, error) {
+	rows, err := tx.Query(qry)
 	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("select first beneficiary %v", err)
+		return nil, err
 	}
+	defer rows.Close()
+	var name string
 	for rows.Next() {
-		if err = rows.Scan(&beneficiary); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("first beneficiary scan %v", err)
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan %v", err)
 		}
-		results.MissingBeneficiaries = append(results.MissingBeneficiaries, beneficiary)
+		names = append(names, name)
 	}
-	if _, err = tx.Exec(`DELETE FROM temp_reservation_fee`); err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("delete %v", err)
-	}
-	return &results, tx.Commit()
+	return names, rows.Err()
+}
+
+// reservationFeeQueryFields are the fields of the paginated reservation fees
+// that can be used to sort or filter them
+var reservationFeeQueryFields = queryFields{
+	"ID":                   {"rf.id", idField},
+	"CurrentBeneficiaryID": {"rf.current_beneficiary_id", idField},
+	"CurrentBeneficiary":   {"b1.name", textField},
+	"FirstBeneficiaryID":   {"rf.first_beneficiary_id", idField},
+	"FirstBeneficiary":     {"b2.name", textField},
+	"CityCode":             {"rf.city_code", idField},
+	"City":                 {"c.name", textField},
+	"ConventionTypeID":     {"rf.convention_type_id", idField},
+	"TransferDate":         {"rf.transfer_date", dateField},
+	"CommentID":            {"rf.comment_id", idField},
+	"TransferID":           {"rf.transfer_id", idField},
+	"ConventionDate":       {"rf.convention_date", dateField},
+	"Area":                 {"rf.area", numberField},
+	"EndYear":              {"rf.end_year", numberField},
+	"Loan":                 {"rf.loan", numberField},
+	"Charges":              {"rf.charges", numberField},
+	"TypologyID":           {"rf.typology_id", idField},
 }
 
 // Get fetches all paginated reservation fees from database that match the
 // paginated query
 func (p *PaginatedReservationFees) Get(db *sql.DB, q *PaginatedQuery) error {
 	var count int64
-	const commonQryPart = ` FROM reservation_fee rf
+	filters, order, args, err := q.clauses(reservationFeeQueryFields, "1",
+		[]interface{}{"%" + q.Search + "%"})
+	if err != nil {
+		return err
+	}
+	commonQryPart := ` FROM reservation_fee rf
 	JOIN beneficiary b1 ON b1.id=rf.current_beneficiary_id
 	LEFT JOIN beneficiary b2 ON b2.id=rf.first_beneficiary_id
 	JOIN city c ON rf.city_code=c.insee_code
@@ -380,12 +420,13 @@ func (p *PaginatedReservationFees) Get(db *sql.DB, q *PaginatedQuery) error {
 	WHERE (b1.name ILIKE $1 OR b2.name ILIKE $1 OR c.name ILIKE $1 OR 
 		rf.convention ILIKE $1 OR cmt.name ILIKE $1 OR rf.address_street ILIKE $1 OR
 		rf.address_number ILIKE $1 OR ht.name ILIKE $1 OR rf.elise_ref ILIKE $1 OR
-		ty.name ILIKE $1)`
-	if err := db.QueryRow(`SELECT count(1)`+commonQryPart, "%"+q.Search+"%").
+		ty.name ILIKE $1)` + filters
+	if err := db.QueryRow(`SELECT count(1)`+commonQryPart, args...).
 		Scan(&count); err != nil {
 		return fmt.Errorf("count query %v", err)
 	}
-	offset, newPage := GetPaginateParams(q.Page, count)
+	size := q.size()
+	offset, newPage := getPageParams(q.Page, count, size)
 
 	rows, err := db.Query(`SELECT rf.id,rf.current_beneficiary_id,b1.name,
 		rf.first_beneficiary_id,b2.name,rf.city_code,c.name,rf.address_number,
@@ -393,7 +434,8 @@ func (p *PaginatedReservationFees) Get(db *sql.DB, q *PaginatedQuery) error {
 		rf.transfer_date,rf.comment_id,cmt.name,rf.transfer_id,ht.name,
 		rf.pmr,rf.convention_date,rf.elise_ref,rf.area,rf.end_year,rf.loan,
 		rf.charges,rf.typology_id,ty.name`+commonQryPart+
-		` ORDER BY 1 LIMIT $2 OFFSET $3`, "%"+q.Search+"%", PageSize, offset)
+		` ORDER BY `+order+` LIMIT $`+strconv.Itoa(len(args)+1)+` OFFSET $`+
+		strconv.Itoa(len(args)+2), append(args, size, offset)...)
 	if err != nil {
 		return fmt.Errorf("select %v", err)
 	}
@@ -423,9 +465,11 @@ func (p *PaginatedReservationFees) Get(db *sql.DB, q *PaginatedQuery) error {
 	return nil
 }
 
-// Get fetches all exported reservation fees from database that match the
-// paginated query
-func (p *ExportedReservationFees) Get(db *sql.DB, q *PaginatedQuery) error {
+// Stream fetches all exported reservation fees from database that match the
+// paginated query and calls send for each row as soon as it's read, the row
+// being reused
+func (p *ExportedReservationFees) Stream(db *sql.DB, q *PaginatedQuery,
+	send func(*ExportedReservationFee) error) error {
 	rows, err := db.Query(`SELECT b1.name,b2.name,rf.city_code,c.name,
 		rf.address_number,rf.address_street,rf.rpls,rf.convention,ct.name,
 		rf.transfer_date,cmt.name,ht.name,rf.pmr,rf.convention_date,
@@ -456,14 +500,13 @@ func (p *ExportedReservationFees) Get(db *sql.DB, q *PaginatedQuery) error {
 			&row.Area, &row.EndYear, &row.Loan, &row.Charges, &row.Typology); err != nil {
 			return fmt.Errorf("scan %v", err)
 		}
-		p.Lines = append(p.Lines, row)
+		if err = send(&row); err != nil {
+			return err
+		}
 	}
 	err = rows.Err()
 	if err != nil {
 		return fmt.Errorf("rows err %v", err)
 	}
-	if len(p.Lines) == 0 {
-		p.Lines = []ExportedReservationFee{}
-	}
 	return nil
 }
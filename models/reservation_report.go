@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -26,10 +25,10 @@ type ReservationReports struct {
 // Valid checks if fields respects database constraints
 func (r *ReservationReport) Valid() error {
 	if r.BeneficiaryID == 0 {
-		return errors.New("BeneficiaryID vide")
+		return &ValidationError{Field: "BeneficiaryID", Message: "BeneficiaryID vide"}
 	}
 	if r.SourceIRISCode == "" {
-		return errors.New("SourceIRISCode vide")
+		return &ValidationError{Field: "SourceIRISCode", Message: "SourceIRISCode vide"}
 	}
 	return nil
 }
@@ -59,7 +58,7 @@ func (r *ReservationReport) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count == 0 {
-		return errors.New("report de réservation introuvable")
+		return notFound("report de réservation")
 	}
 	return db.QueryRow(`SELECT name from beneficiary WHERE id=$1`, r.BeneficiaryID).
 		Scan(&r.Beneficiary)
@@ -76,7 +75,7 @@ func (r *ReservationReport) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count == 0 {
-		return errors.New("report de réservation introuvable")
+		return notFound("report de réservation")
 	}
 	return nil
 }
@@ -0,0 +1,470 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Role model gathers permissions such as copro_forecast:write or prog:read.
+// A permission is made of a resource and an action, read or write, and the
+// star can be used for both of them. The write action implies the read one.
+type Role struct {
+	ID          int64      `json:"ID"`
+	Name        string     `json:"Name"`
+	Description NullString `json:"Description"`
+	Permissions []string   `json:"Permissions"`
+}
+
+// Roles embeddes an array of Role for json export
+type Roles struct {
+	Lines []Role `json:"Role"`
+}
+
+// UserRole model is the assignment of a role to a user. The assignment can be
+// limited to a department, a budget sector or a set of communities.
+type UserRole struct {
+	ID             int64     `json:"ID"`
+	RoleID         int64     `json:"RoleID"`
+	RoleName       string    `json:"RoleName"`
+	DepartmentID   NullInt64 `json:"DepartmentID"`
+	BudgetSectorID NullInt64 `json:"BudgetSectorID"`
+	CommunityIDs   []int64   `json:"CommunityIDs"`
+}
+
+// UserRoles embeddes the role assignments of a user for json export
+type UserRoles struct {
+	Lines []UserRole `json:"UserRole"`
+}
+
+// Scope gives the departments, budget sectors and communities to which the
+// rows fetched by a user are limited. A nil scope doesn't limit the rows.
+type Scope struct {
+	DepartmentIDs   []int64
+	BudgetSectorIDs []int64
+	CommunityIDs    []int64
+}
+
+// ErrOutOfScope is returned when a row isn't in the scope of the user
+var ErrOutOfScope = NewKindError(ErrForbidden, "hors du périmètre de l'utilisateur")
+
+// permissionRegexp checks the format of a permission
+var permissionRegexp = regexp.MustCompile(`^([a-z_]+|\*):(read|write|\*)$`)
+
+// Validate checks if the role's fields are correctly filled
+func (r *Role) Validate() error {
+	if r.Name == "" {
+		return &ValidationError{Field: "Name", Message: "Name vide"}
+	}
+	for _, p := range r.Permissions {
+		if !permissionRegexp.MatchString(p) {
+			return &ValidationError{Field: "Permissions",
+				Message: "permission " + p + " incorrecte"}
+		}
+	}
+	return nil
+}
+
+// setPermissions replaces the permissions of the role
+func (r *Role) setPermissions(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM role_permission WHERE role_id=$1`,
+		r.ID); err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	if len(r.Permissions) == 0 {
+		r.Permissions = []string{}
+		return nil
+	}
+	if _, err := tx.Exec(`INSERT INTO role_permission (role_id,permission)
+	SELECT DISTINCT $1::int,p FROM unnest($2::varchar[]) p`, r.ID,
+		pq.Array(r.Permissions)); err != nil {
+		return fmt.Errorf("insert permissions %v", err)
+	}
+	return nil
+}
+
+// Create inserts a new role and its permissions into database
+func (r *Role) Create(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	if err = tx.QueryRow(`INSERT INTO role (name,description) VALUES($1,$2)
+	RETURNING id`, r.Name, r.Description).Scan(&r.ID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert %v", err)
+	}
+	if err = r.setPermissions(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx commit %v", err)
+	}
+	return nil
+}
+
+// Update modifies a role and replaces its permissions in database
+func (r *Role) Update(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	res, err := tx.Exec(`UPDATE role SET name=$1,description=$2 WHERE id=$3`,
+		r.Name, r.Description, r.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("rows affected %v", err)
+	}
+	if count != 1 {
+		tx.Rollback()
+		return notFound("ID")
+	}
+	if err = r.setPermissions(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx commit %v", err)
+	}
+	return nil
+}
+
+// Delete removes a role, its permissions and its assignments from database
+func (r *Role) Delete(db *sql.DB) error {
+	res, err := db.Exec(`DELETE FROM role WHERE id=$1`, r.ID)
+	if err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected %v", err)
+	}
+	if count != 1 {
+		return notFound("ID")
+	}
+	return nil
+}
+
+// GetAll fetches all roles with their permissions from database
+func (r *Roles) GetAll(db *sql.DB) error {
+	rows, err := db.Query(`SELECT r.id,r.name,r.description,
+	COALESCE(array_agg(p.permission ORDER BY p.permission COLLATE "C")
+		FILTER (WHERE p.permission IS NOT NULL),'{}')
+	FROM role r LEFT JOIN role_permission p ON p.role_id=r.id
+	GROUP BY 1,2,3 ORDER BY 2`)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row Role
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&row.ID, &row.Name, &row.Description,
+			pq.Array(&row.Permissions)); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		r.Lines = append(r.Lines, row)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(r.Lines) == 0 {
+		r.Lines = []Role{}
+	}
+	return nil
+}
+
+// Validate checks if the assignment is limited to one kind of scope at most
+func (u *UserRole) Validate() error {
+	if u.RoleID == 0 {
+		return &ValidationError{Field: "RoleID", Message: "RoleID nul"}
+	}
+	scopes := 0
+	if u.DepartmentID.Valid {
+		scopes++
+	}
+	if u.BudgetSectorID.Valid {
+		scopes++
+	}
+	if len(u.CommunityIDs) > 0 {
+		scopes++
+	}
+	if scopes > 1 {
+		return &ValidationError{Message: "un seul périmètre par affectation"}
+	}
+	return nil
+}
+
+// Get fetches the role assignments of a user from database
+func (u *UserRoles) Get(db *sql.DB, userID int64) error {
+	rows, err := db.Query(`SELECT ur.id,ur.role_id,r.name,ur.department_id,
+	ur.budget_sector_id,ur.community_ids
+	FROM user_role ur JOIN role r ON ur.role_id=r.id
+	WHERE ur.user_id=$1 ORDER BY 1`, userID)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row UserRole
+	defer rows.Close()
+	for rows.Next() {
+		row.CommunityIDs = nil
+		if err = rows.Scan(&row.ID, &row.RoleID, &row.RoleName, &row.DepartmentID,
+			&row.BudgetSectorID, pq.Array(&row.CommunityIDs)); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		u.Lines = append(u.Lines, row)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(u.Lines) == 0 {
+		u.Lines = []UserRole{}
+	}
+	return nil
+}
+
+// Set replaces the role assignments of a user in database and fetches them
+// back
+func (u *UserRoles) Set(db *sql.DB, userID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM user_role WHERE user_id=$1`,
+		userID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delete %v", err)
+	}
+	for _, r := range u.Lines {
+		var communityIDs interface{}
+		if len(r.CommunityIDs) > 0 {
+			communityIDs = pq.Array(r.CommunityIDs)
+		}
+		if _, err = tx.Exec(`INSERT INTO user_role (user_id,role_id,department_id,
+		budget_sector_id,community_ids) VALUES($1,$2,$3,$4,$5)`, userID, r.RoleID,
+			r.DepartmentID, r.BudgetSectorID, communityIDs); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("insert %v", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx commit %v", err)
+	}
+	u.Lines = nil
+	return u.Get(db, userID)
+}
+
+// grantingPermissions returns the permissions that grant the given one,
+// taking into account the stars and the write action implying the read one
+func grantingPermissions(permission string) []string {
+	i := strings.Index(permission, ":")
+	if i < 0 {
+		return []string{permission}
+	}
+	actions := []string{permission[i+1:], "*"}
+	if actions[0] == "read" {
+		actions = append(actions, "write")
+	}
+	var perms []string
+	for _, resource := range []string{permission[:i], "*"} {
+		for _, action := range actions {
+			perms = append(perms, resource+":"+action)
+		}
+	}
+	return perms
+}
+
+// GetUserScope checks if a role assigned to the user grants the permission.
+// If so, it returns the scope of the granting assignments, nil if one of them
+// is not limited.
+func GetUserScope(db *sql.DB, userID int64, permission string) (scope *Scope,
+	granted bool, err error) {
+	rows, err := db.Query(`SELECT DISTINCT ur.id,ur.department_id,
+	ur.budget_sector_id,ur.community_ids
+	FROM user_role ur JOIN role_permission rp ON rp.role_id=ur.role_id
+	WHERE ur.user_id=$1 AND rp.permission=ANY($2)`, userID,
+		pq.Array(grantingPermissions(permission)))
+	if err != nil {
+		return nil, false, fmt.Errorf("select %v", err)
+	}
+	defer rows.Close()
+	s, unlimited := Scope{}, false
+	for rows.Next() {
+		var (
+			ID                           int64
+			departmentID, budgetSectorID NullInt64
+			communityIDs                 []int64
+		)
+		if err = rows.Scan(&ID, &departmentID, &budgetSectorID,
+			pq.Array(&communityIDs)); err != nil {
+			return nil, false, fmt.Errorf("scan %v", err)
+		}
+		granted = true
+		switch {
+		case departmentID.Valid:
+			s.DepartmentIDs = append(s.DepartmentIDs, departmentID.Int64)
+		case budgetSectorID.Valid:
+			s.BudgetSectorIDs = append(s.BudgetSectorIDs, budgetSectorID.Int64)
+		case len(communityIDs) > 0:
+			s.CommunityIDs = append(s.CommunityIDs, communityIDs...)
+		default:
+			unlimited = true
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("rows err %v", err)
+	}
+	if !granted || unlimited {
+		return nil, granted, nil
+	}
+	return &s, true, nil
+}
+
+// idList returns the comma separated list of the IDs for a SQL IN clause
+func idList(IDs []int64) string {
+	s := make([]string, len(IDs))
+	for i, ID := range IDs {
+		s[i] = strconv.FormatInt(ID, 10)
+	}
+	return strings.Join(s, ",")
+}
+
+// cityCondition returns the condition checking if the insee code of the
+// column belongs to the communities or the departments of the scope
+func (s *Scope) cityCondition(column string) string {
+	var conds []string
+	if len(s.CommunityIDs) > 0 {
+		conds = append(conds, "community_id IN ("+idList(s.CommunityIDs)+")")
+	}
+	if len(s.DepartmentIDs) > 0 {
+		conds = append(conds, "community_id IN (SELECT id FROM community "+
+			"WHERE department_id IN ("+idList(s.DepartmentIDs)+"))")
+	}
+	if len(conds) == 0 {
+		return "FALSE"
+	}
+	return column + " IN (SELECT insee_code FROM city WHERE " +
+		strings.Join(conds, " OR ") + ")"
+}
+
+// cityFilter returns the clause limiting the rows to the cities of the scope
+// according to the insee code of the column
+func (s *Scope) cityFilter(column string) string {
+	if s == nil {
+		return ""
+	}
+	return " AND " + s.cityCondition(column)
+}
+
+// renewProjectFilter returns the clause limiting the renew projects of the
+// alias to those having a city in the scope
+func (s *Scope) renewProjectFilter(alias string) string {
+	if s == nil {
+		return ""
+	}
+	return " AND (" + s.cityCondition(alias+".city_code1") + " OR " +
+		s.cityCondition(alias+".city_code2") + " OR " +
+		s.cityCondition(alias+".city_code3") + ")"
+}
+
+// commitmentFilter returns the clause limiting the commitments of the alias to
+// those of the budget sectors of the scope or linked to a copro, a housing or
+// a renew project located in the scope
+func (s *Scope) commitmentFilter(alias string) string {
+	if s == nil {
+		return ""
+	}
+	conds := []string{
+		alias + ".copro_id IN (SELECT id FROM copro WHERE " +
+			s.cityCondition("zip_code") + ")",
+		alias + ".housing_id IN (SELECT id FROM housing WHERE " +
+			s.cityCondition("zip_code") + ")",
+		alias + ".renew_project_id IN (SELECT id FROM renew_project WHERE " +
+			s.cityCondition("city_code1") + " OR " + s.cityCondition("city_code2") +
+			" OR " + s.cityCondition("city_code3") + ")"}
+	if len(s.BudgetSectorIDs) > 0 {
+		conds = append(conds, s.sectorCondition(alias+".action_id"))
+	}
+	return " AND (" + strings.Join(conds, " OR ") + ")"
+}
+
+// sectorCondition returns the condition checking if the budget action of the
+// column belongs to the budget sectors of the scope
+func (s *Scope) sectorCondition(column string) string {
+	if len(s.BudgetSectorIDs) == 0 {
+		return "FALSE"
+	}
+	return column + " IN (SELECT id FROM budget_action WHERE sector_id IN (" +
+		idList(s.BudgetSectorIDs) + "))"
+}
+
+// coproForecastCondition returns the condition checking if the copro forecast
+// of the alias is linked to a copro located in the scope or to a budget action
+// of the sectors of the scope
+func (s *Scope) coproForecastCondition(alias string) string {
+	return "(" + alias + ".copro_id IN (SELECT id FROM copro WHERE " +
+		s.cityCondition("zip_code") + ") OR " +
+		s.sectorCondition(alias+".action_id") + ")"
+}
+
+// coproForecastFilter returns the clause limiting the copro forecasts of the
+// alias to those of the scope
+func (s *Scope) coproForecastFilter(alias string) string {
+	if s == nil {
+		return ""
+	}
+	return " AND " + s.coproForecastCondition(alias)
+}
+
+// checkScope returns ErrOutOfScope if the query checking the scope returns
+// false
+func checkScope(db *sql.DB, query string, args ...interface{}) error {
+	var in bool
+	if err := db.QueryRow(query, args...).Scan(&in); err != nil {
+		return fmt.Errorf("select scope %v", err)
+	}
+	if !in {
+		return ErrOutOfScope
+	}
+	return nil
+}
+
+// CheckCopro returns ErrOutOfScope if the copro isn't located in the scope
+func (s *Scope) CheckCopro(db *sql.DB, coproID int64) error {
+	if s == nil {
+		return nil
+	}
+	return checkScope(db, `SELECT EXISTS(SELECT 1 FROM copro WHERE id=$1 AND `+
+		s.cityCondition("zip_code")+`)`, coproID)
+}
+
+// CheckCoproForecast returns ErrOutOfScope if the copro forecast stored in the
+// database isn't in the scope
+func (s *Scope) CheckCoproForecast(db *sql.DB, ID int64) error {
+	if s == nil {
+		return nil
+	}
+	return checkScope(db, `SELECT EXISTS(SELECT 1 FROM copro_forecast cf
+	WHERE cf.id=$1`+s.coproForecastFilter("cf")+`)`, ID)
+}
+
+// CheckCoproForecastLinks returns ErrOutOfScope if a copro forecast linked to
+// the copro and the budget action wouldn't be in the scope
+func (s *Scope) CheckCoproForecastLinks(db *sql.DB, coproID int64,
+	actionID int64) error {
+	if s == nil {
+		return nil
+	}
+	return checkScope(db, `SELECT EXISTS(SELECT 1 FROM
+	(SELECT $1::int AS copro_id,$2::int AS action_id) f
+	WHERE `+s.coproForecastCondition("f")+`)`, coproID, actionID)
+}
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -22,18 +21,22 @@ type RPCmtCityJoins struct {
 // Validate checks if RPCmtCityJoin's fields are correctly filled
 func (r *RPCmtCityJoin) Validate() error {
 	if r.CommitmentID == 0 {
-		return errors.New("Champ CommitmentID incorrect")
+		return &ValidationError{Field: "CommitmentID", Message: "Champ CommitmentID incorrect"}
 	}
 	if r.CityCode == 0 {
-		return errors.New("Champ CityCode incorrect")
+		return &ValidationError{Field: "CityCode", Message: "Champ CityCode incorrect"}
 	}
 	return nil
 }
 
 // Create insert a new RPCmtCityJoin into database
 func (r *RPCmtCityJoin) Create(db *sql.DB) error {
-	return db.QueryRow(`INSERT INTO rp_cmt_city_join (commitment_id,city_code)
- VALUES($1,$2) RETURNING id`, &r.CommitmentID, &r.CityCode).Scan(&r.ID)
+	if err := db.QueryRow(`INSERT INTO rp_cmt_city_join (commitment_id,city_code)
+ VALUES($1,$2) RETURNING id`, &r.CommitmentID, &r.CityCode).Scan(&r.ID); err != nil {
+		return err
+	}
+	invalidateCache("rp_cmt_city_join")
+	return nil
 }
 
 // Get fetches a RPCmtCityJoin from database using ID field
@@ -49,12 +52,13 @@ func (r *RPCmtCityJoin) Update(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("rp_cmt_city_join")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Lien introuvable")
+		return notFound("Lien")
 	}
 	return nil
 }
@@ -89,12 +93,13 @@ func (r *RPCmtCityJoin) Delete(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("delete %v", err)
 	}
+	invalidateCache("rp_cmt_city_join")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Lien introuvable")
+		return notFound("Lien")
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 )
@@ -45,6 +44,7 @@ func (r *RPEvent) Create(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("insert %v", err)
 	}
+	invalidateCache("rp_event")
 	return db.QueryRow(`SELECT name FROM rp_event_type WHERE id=$1`,
 		r.RPEventTypeID).Scan(&r.Name)
 }
@@ -52,10 +52,10 @@ func (r *RPEvent) Create(db *sql.DB) error {
 // Validate check if fields complies with database constraints
 func (r *RPEvent) Validate() error {
 	if r.RenewProjectID == 0 {
-		return fmt.Errorf("Champ RenewProjectID vide")
+		return &ValidationError{Field: "RenewProjectID", Message: "Champ RenewProjectID vide"}
 	}
 	if r.RPEventTypeID == 0 {
-		return fmt.Errorf("Champ RPEventTypeID vide")
+		return &ValidationError{Field: "RPEventTypeID", Message: "Champ RPEventTypeID vide"}
 	}
 	return nil
 }
@@ -78,12 +78,13 @@ func (r *RPEvent) Update(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("update %v", err)
 	}
+	invalidateCache("rp_event")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Événement introuvable")
+		return notFound("Événement")
 	}
 	return db.QueryRow(`SELECT name FROM rp_event_type WHERE id=$1`,
 		r.RPEventTypeID).Scan(&r.Name)
@@ -122,12 +123,13 @@ func (r *RPEvent) Delete(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("delete %v", err)
 	}
+	invalidateCache("rp_event")
 	count, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Événement introuvable")
+		return notFound("Événement")
 	}
 	return nil
 }
@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -26,7 +25,7 @@ func (r *RPEventType) Create(db *sql.DB) error {
 // Validate check if fields complies with database constraints
 func (r *RPEventType) Validate() error {
 	if r.Name == "" {
-		return fmt.Errorf("Nom vide")
+		return &ValidationError{Field: "Name", Message: "Nom vide"}
 	}
 	return nil
 }
@@ -49,7 +48,7 @@ func (r *RPEventType) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type d'événement introuvable")
+		return notFound("Type d'événement")
 	}
 	return nil
 }
@@ -89,7 +88,7 @@ func (r *RPEventType) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Type d'événement introuvable")
+		return notFound("Type d'événement")
 	}
 	return nil
 }
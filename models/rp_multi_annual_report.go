@@ -28,6 +28,21 @@ type RPMultiAnnualReports struct {
 
 // GetAll fetches all lines of the copro report from database
 func (c *RPMultiAnnualReports) GetAll(db *sql.DB) error {
+	v, err := aggregates.get(&rpMultiAnnualReportCache, "",
+		func() (interface{}, error) {
+			var r RPMultiAnnualReports
+			err := r.fetch(db)
+			return r, err
+		})
+	if err != nil {
+		return err
+	}
+	*c = v.(RPMultiAnnualReports)
+	return nil
+}
+
+// fetch computes the renew project multi annual report from database
+func (c *RPMultiAnnualReports) fetch(db *sql.DB) error {
 	qry := `
   WITH max_cmt_dat AS (SELECT MAX(creation_date) AS d FROM commitment)
   SELECT ci.insee_code,ci.name,rp.name,rp.budget,cmt.value,prg.value,
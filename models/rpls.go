@@ -33,6 +33,21 @@ type RPLSBatch struct {
 	Lines []RPLSLine `json:"RPLS"`
 }
 
+// rplsMapping defines the columns of the XLSX or CSV file of a RPLS batch
+var rplsMapping = BatchMapping{
+	Key: "RPLS",
+	Columns: []BatchColumn{
+		{"InseeCode", ColInt, []string{"Code INSEE", "INSEE"}},
+		{"Year", ColInt, []string{"Année"}},
+		{"Ratio", ColFloat, []string{"Taux", "Taux RPLS"}},
+	},
+}
+
+// Mapping implements the UploadableBatch interface
+func (r *RPLSBatch) Mapping() *BatchMapping {
+	return &rplsMapping
+}
+
 // RPLSYears embeddes the distinct years in the rpls table
 type RPLSYears struct {
 	Lines []int64 `json:"RPLSYear"`
@@ -41,10 +56,10 @@ type RPLSYears struct {
 // Validate checks of fields can be inserted into database
 func (r *RPLS) Validate() error {
 	if r.InseeCode == 0 {
-		return fmt.Errorf("InseeCode nul")
+		return &ValidationError{Field: "InseeCode", Message: "InseeCode nul"}
 	}
 	if r.Year == 0 {
-		return fmt.Errorf("Year nul")
+		return &ValidationError{Field: "Year", Message: "Year nul"}
 	}
 	return nil
 }
@@ -68,7 +83,7 @@ func (r *RPLS) Update(db *sql.DB) error {
 		return err
 	}
 	if r.ID == 0 {
-		return fmt.Errorf("ID nul")
+		return &ValidationError{Field: "ID", Message: "ID nul"}
 	}
 	res, err := db.Exec(`UPDATE rpls SET insee_code=$1, year=$2, ratio=$3
 	WHERE id=$4`, r.InseeCode, r.Year, r.Ratio, r.ID)
@@ -80,7 +95,7 @@ func (r *RPLS) Update(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("RPLS introuvable")
+		return notFound("RPLS")
 	}
 	return db.QueryRow(`SELECT name FROM city WHERE insee_code=$1`, r.InseeCode).
 		Scan(&r.CityName)
@@ -97,55 +112,44 @@ func (r *RPLS) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return fmt.Errorf("RPLS introuvable")
+		return notFound("RPLS")
 	}
 	return nil
 }
 
 // Save insert a batch of rpls into database
-func (r *RPLSBatch) Save(db *sql.DB) error {
+func (r *RPLSBatch) Save(opt BatchOptions, db *sql.DB) (*BatchReport, error) {
+	report := newBatchReport(opt, len(r.Lines))
 	for i, l := range r.Lines {
 		if l.InseeCode == 0 {
-			return fmt.Errorf("ligne %d InseeCode null", i+1)
+			report.addError(i, "InseeCode", "code INSEE nul")
 		}
 		if l.Year == 0 {
-			return fmt.Errorf("ligne %d Year null", i+1)
+			report.addError(i, "Year", "année nulle")
 		}
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("tx begin %v", err)
-	}
-	stmt, err := tx.Prepare(pq.CopyIn("temp_rpls", "insee_code", "year", "ratio"))
-	if err != nil {
-		return fmt.Errorf("copy in %v", err)
-	}
-	defer stmt.Close()
-	for i, l := range r.Lines {
-		if _, err = stmt.Exec(l.InseeCode, l.Year, l.Ratio); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("ligne %d %v", i+1, err)
+	return report, report.run(db, "rpls", func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(pq.CopyIn("temp_rpls", "insee_code", "year", "ratio"))
+		if err != nil {
+			return fmt.Errorf("copy in %v", err)
 		}
-	}
-	if _, err = stmt.Exec(); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("statement exec flush %v", err)
-	}
-	queries := []string{`UPDATE rpls SET ratio=t.ratio FROM temp_rpls t 
+		defer stmt.Close()
+		for i, l := range r.Lines {
+			if _, err = stmt.Exec(l.InseeCode, l.Year, l.Ratio); err != nil {
+				return fmt.Errorf("ligne %d %v", i+1, err)
+			}
+		}
+		if _, err = stmt.Exec(); err != nil {
+			return fmt.Errorf("statement exec flush %v", err)
+		}
+		return execQueries(tx, []string{`UPDATE rpls SET ratio=t.ratio FROM temp_rpls t 
 	WHERE t.insee_code=rpls.insee_code AND t.year=rpls.year`,
-		`INSERT INTO rpls (insee_code,year,ratio)
+			`INSERT INTO rpls (insee_code,year,ratio)
 	SELECT insee_code,year,ratio from temp_rpls 
 		WHERE (insee_code,year) NOT IN (SELECT insee_code,year from rpls)`,
-		`DELETE from temp_rpls`,
-	}
-	for i, q := range queries {
-		_, err = tx.Exec(q)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("requête %d : %v", i, err)
-		}
-	}
-	return tx.Commit()
+			`DELETE from temp_rpls`,
+		})
+	})
 }
 
 // GetAll fetches all RPLS from database and add CityName
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Events of the security log
+const (
+	LoginSuccessEvent   = "login_success"
+	LoginFailureEvent   = "login_failure"
+	LoginThrottledEvent = "login_throttled"
+	LockoutEvent        = "lockout"
+	UnlockEvent         = "unlock"
+)
+
+// LoginPolicy defines the protection of the login against brute force
+// attacks. The failures are counted over Window per account, since the last
+// success or unlock, and per IP address. Once FreeFailures failures are
+// reached, an attempt is rejected until a delay starting at DelayStep and
+// doubling with each failure, up to MaxDelay, has elapsed since the last
+// failure.
+type LoginPolicy struct {
+	MaxAccountFailures int64
+	MaxIPFailures      int64
+	FreeFailures       int64
+	DelayStep          time.Duration
+	MaxDelay           time.Duration
+	LockoutDuration    time.Duration
+	Window             time.Duration
+}
+
+// DefaultLoginPolicy is used for the fields of the policy that aren't
+// configured
+var DefaultLoginPolicy = LoginPolicy{
+	MaxAccountFailures: 5,
+	MaxIPFailures:      50,
+	FreeFailures:       2,
+	DelayStep:          time.Second,
+	MaxDelay:           30 * time.Second,
+	LockoutDuration:    15 * time.Minute,
+	Window:             15 * time.Minute,
+}
+
+// Delay returns the delay that must elapse after the last failure before a
+// new attempt is accepted
+func (p *LoginPolicy) Delay(failures int64) time.Duration {
+	if failures < p.FreeFailures || failures < 1 {
+		return 0
+	}
+	d := p.DelayStep
+	for i := p.FreeFailures; i < failures && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// LoginState gives the failed login attempts of an account or of an IP
+// address. SinceLastFailure and Locked are durations computed by the database
+// to avoid time zone issues.
+type LoginState struct {
+	Failures         int64
+	SinceLastFailure time.Duration
+	Locked           time.Duration
+}
+
+// SecurityEvent model is a line of the security log
+type SecurityEvent struct {
+	ID        int64      `json:"ID"`
+	Created   time.Time  `json:"Created"`
+	Event     string     `json:"Event"`
+	UserID    NullInt64  `json:"UserID"`
+	UserName  NullString `json:"UserName"`
+	ActorID   NullInt64  `json:"ActorID"`
+	Email     NullString `json:"Email"`
+	IP        NullString `json:"IP"`
+	UserAgent NullString `json:"UserAgent"`
+}
+
+// PaginatedSecurityEvents embeddes a page of the security log
+type PaginatedSecurityEvents struct {
+	Lines      []SecurityEvent `json:"SecurityEvent"`
+	Page       int64           `json:"Page"`
+	ItemsCount int64           `json:"ItemsCount"`
+}
+
+// loginLockClass is the first key of the PostgreSQL advisory locks taken
+// during the login attempts
+const loginLockClass = 5240118
+
+// LoginLock holds the advisory locks serializing the login attempts of an IP
+// address or of an account, so that the failures are counted and logged
+// atomically
+type LoginLock struct {
+	tx *sql.Tx
+}
+
+// NewLoginLock begins the transaction holding the advisory locks
+func NewLoginLock(db *sql.DB) (*LoginLock, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("tx begin %v", err)
+	}
+	return &LoginLock{tx: tx}, nil
+}
+
+// Lock waits for the advisory lock of the key, held until the release
+func (l *LoginLock) Lock(key string) error {
+	if _, err := l.tx.Exec(`SELECT pg_advisory_xact_lock($1,hashtext($2))`,
+		loginLockClass, key); err != nil {
+		return fmt.Errorf("advisory lock %v", err)
+	}
+	return nil
+}
+
+// Release releases the advisory locks
+func (l *LoginLock) Release() {
+	l.tx.Rollback()
+}
+
+// seconds converts a number of seconds computed by the database to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Log inserts the event into the security log
+func (e *SecurityEvent) Log(db *sql.DB) error {
+	if _, err := db.Exec(`INSERT INTO security_log (event,user_id,actor_id,email,
+	ip,user_agent) VALUES($1,$2,$3,$4,$5,$6)`, e.Event, e.UserID, e.ActorID,
+		e.Email, e.IP, e.UserAgent); err != nil {
+		return fmt.Errorf("insert security_log %v", err)
+	}
+	return nil
+}
+
+// GetByUser fetches the failed attempts of the account since the last success
+// or unlock within the window and the remaining lockout duration
+func (s *LoginState) GetByUser(db *sql.DB, userID int64, window time.Duration) error {
+	var since, locked NullFloat64
+	if err := db.QueryRow(`SELECT count(1),
+		EXTRACT(EPOCH FROM now()-max(created)),
+		(SELECT EXTRACT(EPOCH FROM locked_until-now()) FROM users WHERE id=$1)
+	FROM security_log WHERE user_id=$1 AND event=$2
+		AND created > GREATEST(now()-$3*interval '1 second',
+			COALESCE((SELECT max(created) FROM security_log
+				WHERE user_id=$1 AND event IN ($4,$5)),'-infinity'))`,
+		userID, LoginFailureEvent, window.Seconds(), LoginSuccessEvent,
+		UnlockEvent).Scan(&s.Failures, &since, &locked); err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	s.SinceLastFailure = seconds(since.Float64)
+	s.Locked = 0
+	if locked.Valid && locked.Float64 > 0 {
+		s.Locked = seconds(locked.Float64)
+	}
+	return nil
+}
+
+// GetByIP fetches the failed attempts from the IP address within the window
+func (s *LoginState) GetByIP(db *sql.DB, IP string, window time.Duration) error {
+	var since NullFloat64
+	if err := db.QueryRow(`SELECT count(1),EXTRACT(EPOCH FROM now()-max(created))
+	FROM security_log WHERE ip=$1 AND event=$2
+		AND created > now()-$3*interval '1 second'`, IP, LoginFailureEvent,
+		window.Seconds()).Scan(&s.Failures, &since); err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	s.SinceLastFailure = seconds(since.Float64)
+	return nil
+}
+
+// LockUser locks the account of the user for the duration
+func LockUser(db *sql.DB, userID int64, d time.Duration) error {
+	if _, err := db.Exec(`UPDATE users
+	SET locked_until=now()+$1*interval '1 second' WHERE id=$2`, d.Seconds(),
+		userID); err != nil {
+		return fmt.Errorf("update %v", err)
+	}
+	return nil
+}
+
+// UnlockUser unlocks the account of the user and resets its failure count by
+// logging the unlock event
+func UnlockUser(db *sql.DB, userID int64, actorID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	res, err := tx.Exec(`UPDATE users SET locked_until=NULL WHERE id=$1`, userID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("rows affected %v", err)
+	}
+	if count != 1 {
+		tx.Rollback()
+		return notFound("Utilisateur")
+	}
+	if _, err = tx.Exec(`INSERT INTO security_log (event,user_id,actor_id)
+	VALUES($1,$2,$3)`, UnlockEvent, userID, actorID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert security_log %v", err)
+	}
+	return tx.Commit()
+}
+
+// Get fetches a page of the security log, optionally filtered by event and
+// user, the last events first
+func (p *PaginatedSecurityEvents) Get(db *sql.DB, page int64, event string,
+	userID int64) error {
+	commonQry := ` FROM security_log l LEFT JOIN users u ON l.user_id=u.id
+	WHERE ($1='' OR l.event=$1) AND ($2=0 OR l.user_id=$2)`
+	if err := db.QueryRow(`SELECT count(1)`+commonQry, event, userID).
+		Scan(&p.ItemsCount); err != nil {
+		return fmt.Errorf("select count %v", err)
+	}
+	offset, newPage := GetPaginateParams(page, p.ItemsCount)
+	rows, err := db.Query(`SELECT l.id,l.created,l.event,l.user_id,u.name,
+	l.actor_id,l.email,l.ip,l.user_agent`+commonQry+` ORDER BY 1 DESC LIMIT `+
+		strconv.Itoa(PageSize)+` OFFSET $3`, event, userID, offset)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row SecurityEvent
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&row.ID, &row.Created, &row.Event, &row.UserID,
+			&row.UserName, &row.ActorID, &row.Email, &row.IP,
+			&row.UserAgent); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		p.Lines = append(p.Lines, row)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(p.Lines) == 0 {
+		p.Lines = []SecurityEvent{}
+	}
+	p.Page = newPage
+	return nil
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// xlsxWorkbook is used to fetch the relationship ID of the first sheet
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is used to fetch the file of a sheet
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxRichText is a string of the shared strings or of an inline cell that
+// may be splitted in formatted runs
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// xlsxSharedStrings is the table of the strings used by the cells
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxCell is a cell of a sheet. Ref is the A1 style reference of the cell.
+type xlsxCell struct {
+	Ref    string       `xml:"r,attr"`
+	Type   string       `xml:"t,attr"`
+	Value  string       `xml:"v"`
+	Inline xlsxRichText `xml:"is"`
+}
+
+// xlsxSheet is the content of a sheet
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// text returns the string concatenating the runs of the rich text
+func (r *xlsxRichText) text() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var s strings.Builder
+	s.WriteString(r.T)
+	for _, run := range r.Runs {
+		s.WriteString(run.T)
+	}
+	return s.String()
+}
+
+// column returns the index starting at 0 of the column of an A1 style
+// reference or -1 if the reference is empty
+func (c *xlsxCell) column() int {
+	col := 0
+	for _, r := range c.Ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+// maxXLSXEntrySize is the maximum decompressed size of a file of an Excel
+// archive, to prevent a small archive from exhausting the memory
+var maxXLSXEntrySize uint64 = 100 << 20
+
+// decodeZipXML unmarshals a file of the archive. If the file doesn't exist,
+// v is left untouched.
+func decodeZipXML(z *zip.Reader, name string, v interface{}) error {
+	for _, f := range z.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > maxXLSXEntrySize {
+			return fmt.Errorf("%s trop volumineux", name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("ouverture de %s %v", name, err)
+		}
+		defer r.Close()
+		if err = xml.NewDecoder(io.LimitReader(r,
+			int64(maxXLSXEntrySize))).Decode(v); err != nil {
+			return fmt.Errorf("lecture de %s %v", name, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// readXLSX returns the cells of the first sheet of an Excel file as text.
+// Numbers and dates are given as stored by Excel, dates being serial numbers.
+func readXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("fichier xlsx incorrect %v", err)
+	}
+	var (
+		wb   xlsxWorkbook
+		rels xlsxRelationships
+		sst  xlsxSharedStrings
+		ws   xlsxSheet
+	)
+	if err = decodeZipXML(z, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, fmt.Errorf("fichier xlsx sans feuille")
+	}
+	if err = decodeZipXML(z, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetName := "xl/worksheets/sheet1.xml"
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			sheetName = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			sheetName = path.Join("xl", rel.Target)
+		}
+	}
+	if err = decodeZipXML(z, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	if err = decodeZipXML(z, sheetName, &ws); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, wr := range ws.Rows {
+		var row []string
+		for i, c := range wr.Cells {
+			col := c.column()
+			if col < 0 {
+				col = i
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				var idx int
+				if _, err = fmt.Sscan(c.Value, &idx); err != nil ||
+					idx < 0 || idx >= len(sst.Items) {
+					return nil, fmt.Errorf("cellule %s : chaîne partagée incorrecte", c.Ref)
+				}
+				row[col] = sst.Items[idx].text()
+			case "inlineStr":
+				row[col] = c.Inline.text()
+			case "e":
+				row[col] = ""
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readCSV returns the cells of a CSV file. The separator is guessed from the
+// first line and files that are not UTF-8 encoded are read as Latin-1.
+func readCSV(r io.Reader) ([][]string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("lecture %v", err)
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(content) {
+		runes := make([]rune, len(content))
+		for i, c := range content {
+			runes[i] = rune(c)
+		}
+		content = []byte(string(runes))
+	}
+	firstLine := content
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		firstLine = content[:i]
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = ','
+	count := bytes.Count(firstLine, []byte(","))
+	for _, sep := range []rune{';', '\t'} {
+		if c := bytes.Count(firstLine, []byte(string(sep))); c > count {
+			reader.Comma, count = sep, c
+		}
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("fichier csv incorrect %v", err)
+	}
+	return rows, nil
+}
+
+// readSpreadsheet returns the cells of a XLSX or CSV file according to the
+// extension of its name
+func readSpreadsheet(name string, r io.ReaderAt, size int64) ([][]string, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".xlsx":
+		return readXLSX(r, size)
+	case ".csv", ".txt":
+		return readCSV(io.NewSectionReader(r, 0, size))
+	}
+	return nil, fmt.Errorf("format de fichier %s non géré, xlsx ou csv attendu", name)
+}
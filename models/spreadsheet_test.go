@@ -0,0 +1,50 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// xlsxArchive returns an Excel file with a single sheet whose content is
+// given
+func xlsxArchive(t *testing.T, sheet string) []byte {
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for name, content := range map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="F1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   sheet,
+	} {
+		w, err := z.Create(name)
+		if err != nil {
+			t.Fatalf("Création de %s : %v", name, err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatalf("Écriture de %s : %v", name, err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatalf("Fermeture de l'archive : %v", err)
+	}
+	return b.Bytes()
+}
+
+// TestReadXLSXSizeLimit checks a sheet whose decompressed size exceeds the
+// limit is refused
+func TestReadXLSXSizeLimit(t *testing.T) {
+	sheet := `<worksheet><sheetData><row><c t="inlineStr"><is><t>Code IRIS</t></is></c></row>` +
+		strings.Repeat(`<row><c><v>1</v></c></row>`, 1000) + `</sheetData></worksheet>`
+	b := xlsxArchive(t, sheet)
+	rows, err := readXLSX(bytes.NewReader(b), int64(len(b)))
+	if err != nil || len(rows) != 1001 || rows[0][0] != "Code IRIS" {
+		t.Fatalf("Lecture : 1001 lignes attendues, reçu %d %v", len(rows), err)
+	}
+	defer func(max uint64) { maxXLSXEntrySize = max }(maxXLSXEntrySize)
+	maxXLSXEntrySize = uint64(len(sheet)) - 1
+	if _, err = readXLSX(bytes.NewReader(b), int64(len(b))); err == nil ||
+		!strings.Contains(err.Error(), "trop volumineux") {
+		t.Errorf("Limite : erreur trop volumineux attendue, reçu %v", err)
+	}
+}
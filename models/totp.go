@@ -0,0 +1,361 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameters of the TOTP codes (RFC 6238) and of the second login step
+const (
+	TOTPPeriod         = 30 * time.Second
+	TOTPDigits         = 6
+	PreAuthTokenTTL    = 5 * time.Minute
+	MaxPreAuthAttempts = 5
+	RecoveryCodesCount = 10
+	// totpSkew is the number of time steps accepted before and after the
+	// current one to cope with clock drifts
+	totpSkew = 1
+)
+
+var (
+	// ErrBadPreAuthToken is returned when a pre-authentication token is
+	// unknown, expired or has been used too many times
+	ErrBadPreAuthToken = NewKindError(ErrUnauthorized, "jeton invalide ou expiré")
+	// ErrTOTPEnabled is returned when a secret is requested by a user whose
+	// double authentication is already enabled
+	ErrTOTPEnabled = NewKindError(ErrConflict,
+		"double authentification déjà activée")
+	// ErrTOTPNotSetup is returned when a code is checked without secret
+	ErrTOTPNotSetup = errors.New("double authentification non configurée")
+)
+
+// totpEncoding is used for the secrets as expected by authenticator apps
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorPolicy defines the users who must use the double authentication
+// and the issuer displayed by authenticator apps
+type TwoFactorPolicy struct {
+	Required bool
+	Issuer   string
+}
+
+// DefaultTwoFactorPolicy is used if the double authentication isn't
+// configured
+var DefaultTwoFactorPolicy = TwoFactorPolicy{Issuer: "PreLoRuGo"}
+
+// RequiredFor returns true if the double authentication is mandatory for a
+// user having these rights
+func (p *TwoFactorPolicy) RequiredFor(rights int64) bool {
+	return p.Required && rights&(AdminBit|ReservationBit) != 0
+}
+
+// TwoFactor model gives the double authentication status of a user
+type TwoFactor struct {
+	UserID        int64      `json:"-"`
+	Secret        NullString `json:"-"`
+	Enabled       bool       `json:"Enabled"`
+	LastStep      NullInt64  `json:"-"`
+	RecoveryCodes int64      `json:"RecoveryCodes"`
+}
+
+// NewTOTPSecret returns a random secret encoded in base32
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("random %v", err)
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth URI used by authenticator apps to register the
+// secret, usually displayed as a QR code
+func TOTPURI(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(TOTPDigits))
+	v.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+	u := url.URL{Scheme: "otpauth", Host: "totp",
+		Path: "/" + issuer + ":" + account, RawQuery: v.Encode()}
+	return u.String()
+}
+
+// totpStep returns the time step of the date
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod.Seconds())
+}
+
+// hotp computes the code of a counter as defined by RFC 4226
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod)
+}
+
+// TOTPCode returns the code of the secret at the given time
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("secret %v", err)
+	}
+	return hotp(key, totpStep(t)), nil
+}
+
+// normalizeRecoveryCode removes the separators and the case of a recovery code
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// newRecoveryCodes replaces the recovery codes of the user within the
+// transaction and returns them
+func newRecoveryCodes(tx *sql.Tx, userID int64) ([]string, error) {
+	if _, err := tx.Exec(`DELETE FROM totp_recovery_code WHERE user_id=$1`,
+		userID); err != nil {
+		return nil, fmt.Errorf("delete %v", err)
+	}
+	codes := make([]string, RecoveryCodesCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("random %v", err)
+		}
+		c := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes[i] = c[:4] + "-" + c[4:]
+		if _, err := tx.Exec(`INSERT INTO totp_recovery_code (user_id,code_hash)
+		VALUES($1,$2)`, userID, hashPasswordToken(normalizeRecoveryCode(c))); err != nil {
+			return nil, fmt.Errorf("insert %v", err)
+		}
+	}
+	return codes, nil
+}
+
+// Get fetches the double authentication status of the user
+func (t *TwoFactor) Get(db *sql.DB, userID int64) error {
+	t.UserID = userID
+	if err := db.QueryRow(`SELECT totp_secret,totp_enabled,totp_last_step,
+		(SELECT count(1) FROM totp_recovery_code WHERE user_id=$1 AND used IS NULL)
+	FROM users WHERE id=$1`, userID).Scan(&t.Secret, &t.Enabled, &t.LastStep,
+		&t.RecoveryCodes); err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	return nil
+}
+
+// Setup stores a new secret pending until the first code is checked and
+// returns it. It fails if the double authentication is already enabled.
+func (t *TwoFactor) Setup(db *sql.DB) (string, error) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	res, err := db.Exec(`UPDATE users SET totp_secret=$1,totp_last_step=NULL
+	WHERE id=$2 AND NOT totp_enabled`, secret, t.UserID)
+	if err != nil {
+		return "", fmt.Errorf("update %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("rows affected %v", err)
+	}
+	if count != 1 {
+		return "", ErrTOTPEnabled
+	}
+	t.Secret = NullString{String: secret, Valid: true}
+	return secret, nil
+}
+
+// CheckCode returns true if the code matches the secret at the given time.
+// The time step of an accepted code is stored so that it and the previous
+// ones can't be used again.
+func (t *TwoFactor) CheckCode(db *sql.DB, code string, now time.Time) (bool, error) {
+	if !t.Secret.Valid {
+		return false, ErrTOTPNotSetup
+	}
+	key, err := totpEncoding.DecodeString(t.Secret.String)
+	if err != nil {
+		return false, fmt.Errorf("secret %v", err)
+	}
+	code = strings.TrimSpace(code)
+	current := totpStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if t.LastStep.Valid && step <= t.LastStep.Int64 {
+			continue
+		}
+		if !hmac.Equal([]byte(hotp(key, step)), []byte(code)) {
+			continue
+		}
+		res, err := db.Exec(`UPDATE users SET totp_last_step=$1 WHERE id=$2
+		AND (totp_last_step IS NULL OR totp_last_step<$1)`, step, t.UserID)
+		if err != nil {
+			return false, fmt.Errorf("update %v", err)
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("rows affected %v", err)
+		}
+		if count == 1 {
+			t.LastStep = NullInt64{Int64: step, Valid: true}
+		}
+		return count == 1, nil
+	}
+	return false, nil
+}
+
+// UseRecoveryCode returns true if the code is an unused recovery code of the
+// user and marks it as used
+func (t *TwoFactor) UseRecoveryCode(db *sql.DB, code string) (bool, error) {
+	res, err := db.Exec(`UPDATE totp_recovery_code SET used=now()
+	WHERE user_id=$1 AND code_hash=$2 AND used IS NULL`, t.UserID,
+		hashPasswordToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("update %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected %v", err)
+	}
+	if count > 0 {
+		t.RecoveryCodes--
+	}
+	return count > 0, nil
+}
+
+// Enable enables the double authentication using the pending secret and
+// returns the recovery codes
+func (t *TwoFactor) Enable(db *sql.DB) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("tx begin %v", err)
+	}
+	if _, err = tx.Exec(`UPDATE users SET totp_enabled=true WHERE id=$1`,
+		t.UserID); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("update %v", err)
+	}
+	codes, err := newRecoveryCodes(tx, t.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("tx commit %v", err)
+	}
+	t.Enabled, t.RecoveryCodes = true, RecoveryCodesCount
+	return codes, nil
+}
+
+// RenewRecoveryCodes replaces the recovery codes of the user and returns them
+func (t *TwoFactor) RenewRecoveryCodes(db *sql.DB) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("tx begin %v", err)
+	}
+	codes, err := newRecoveryCodes(tx, t.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("tx commit %v", err)
+	}
+	t.RecoveryCodes = RecoveryCodesCount
+	return codes, nil
+}
+
+// Disable removes the secret and the recovery codes of the user
+func (t *TwoFactor) Disable(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("tx begin %v", err)
+	}
+	res, err := tx.Exec(`UPDATE users SET totp_secret=NULL,totp_enabled=false,
+	totp_last_step=NULL WHERE id=$1`, t.UserID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("rows affected %v", err)
+	}
+	if count != 1 {
+		tx.Rollback()
+		return notFound("Utilisateur")
+	}
+	if _, err = tx.Exec(`DELETE FROM totp_recovery_code WHERE user_id=$1`,
+		t.UserID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delete %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx commit %v", err)
+	}
+	t.Secret, t.Enabled, t.LastStep = NullString{}, false, NullInt64{}
+	t.RecoveryCodes = 0
+	return nil
+}
+
+// CreatePreAuthToken stores a short-lived token for the user who has given the
+// right password and must give a code, and returns it
+func CreatePreAuthToken(db *sql.DB, userID int64) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("random %v", err)
+	}
+	token := hex.EncodeToString(b)
+	if _, err := db.Exec(`DELETE FROM preauth_token WHERE expires<now()`); err != nil {
+		return "", fmt.Errorf("delete %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO preauth_token (user_id,token_hash,expires)
+	VALUES($1,$2,now()+$3*interval '1 second')`, userID,
+		hashPasswordToken(token), PreAuthTokenTTL.Seconds()); err != nil {
+		return "", fmt.Errorf("insert %v", err)
+	}
+	return token, nil
+}
+
+// CheckPreAuthToken counts an attempt with the token and returns the ID of its
+// user if the token is still valid
+func CheckPreAuthToken(db *sql.DB, token string) (int64, error) {
+	var userID int64
+	err := db.QueryRow(`UPDATE preauth_token SET attempts=attempts+1
+	WHERE token_hash=$1 AND expires>now() AND attempts<$2 RETURNING user_id`,
+		hashPasswordToken(token), MaxPreAuthAttempts).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrBadPreAuthToken
+	}
+	if err != nil {
+		return 0, fmt.Errorf("update %v", err)
+	}
+	return userID, nil
+}
+
+// DeletePreAuthToken removes the token once the second step is completed
+func DeletePreAuthToken(db *sql.DB, token string) error {
+	if _, err := db.Exec(`DELETE FROM preauth_token WHERE token_hash=$1`,
+		hashPasswordToken(token)); err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	return nil
+}
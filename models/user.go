@@ -2,7 +2,6 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -15,6 +14,9 @@ type User struct {
 	Email    string `json:"Email"`
 	Password string `json:"-"`
 	Rights   int64  `json:"Rights"`
+	// RightsVersion is incremented each time the rights are changed so that
+	// tokens carrying former rights can be detected
+	RightsVersion int64 `json:"-"`
 }
 
 // Users embeddes an array of User for json export.
@@ -43,7 +45,8 @@ const (
 	// ReservationBit of user's right field specifies the user can modify the reservation fee
 	ReservationBit = 1 << 8
 	// RightsMask is used to check if user's rights field are correctly filled
-	RightsMask = ActiveBit | SuperAdminBit | AdminBit | CoproBit | RenewProjectBit | ObserverBit
+	RightsMask = ActiveBit | SuperAdminBit | AdminBit | CoproBit | RenewProjectBit | ObserverBit |
+		HousingBit | PreProgBit | ReservationBit
 	// ActiveAdminMask is used to check if a user is an active admin
 	ActiveAdminMask = ActiveBit | AdminBit
 	// ActiveObserverMask is used to check if a user is an active observer
@@ -67,21 +70,22 @@ const (
 // Validate checks if field are correctly filled for database constraints
 func (u *User) Validate() error {
 	if u.Name == "" {
-		return errors.New("Champ name vide")
+		return &ValidationError{Field: "Name", Message: "Champ name vide"}
 	}
 	if u.Email == "" {
-		return errors.New("Champ email vide")
+		return &ValidationError{Field: "Email", Message: "Champ email vide"}
 	}
 	if u.Password == "" {
-		return errors.New("Champ password vide")
+		return &ValidationError{Field: "Password", Message: "Champ password vide"}
 	}
 	return nil
 }
 
 // GetByID fetches a user from database using ID.
 func (u *User) GetByID(db *sql.DB) error {
-	return db.QueryRow(`SELECT id,name,email,password,rights FROM users
-		WHERE id=$1`, u.ID).Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Rights)
+	return db.QueryRow(`SELECT id,name,email,password,rights,rights_version
+		FROM users WHERE id=$1`, u.ID).Scan(&u.ID, &u.Name, &u.Email, &u.Password,
+		&u.Rights, &u.RightsVersion)
 }
 
 // CryptPwd crypt not codded password field.
@@ -126,9 +130,16 @@ func (users *Users) GetAll(db *sql.DB) error {
 
 // GetByEmail fetches an user by email.
 func (u *User) GetByEmail(email string, db *sql.DB) error {
-	return db.QueryRow(`SELECT id, name, email, password, rights 
+	return db.QueryRow(`SELECT id, name, email, password, rights, rights_version
 	FROM users WHERE email = $1 LIMIT 1`, email).Scan(&u.ID,
-		&u.Name, &u.Email, &u.Password, &u.Rights)
+		&u.Name, &u.Email, &u.Password, &u.Rights, &u.RightsVersion)
+}
+
+// GetByEmailFold fetches an user by email regardless of the case.
+func (u *User) GetByEmailFold(email string, db *sql.DB) error {
+	return db.QueryRow(`SELECT id, name, email, password, rights, rights_version
+	FROM users WHERE lower(email) = lower($1) ORDER BY id LIMIT 1`, email).Scan(
+		&u.ID, &u.Name, &u.Email, &u.Password, &u.Rights, &u.RightsVersion)
 }
 
 // Exists checks if name or email is already in database.
@@ -139,7 +150,7 @@ func (u *User) Exists(db *sql.DB) error {
 		return err
 	}
 	if count != 0 {
-		return errors.New("Utilisateur existant")
+		return NewKindError(ErrConflict, "Utilisateur existant")
 	}
 	return nil
 }
@@ -151,19 +162,19 @@ func (u *User) Create(db *sql.DB) error {
 		u.Name, u.Email, u.Password, u.Rights).Scan(&u.ID)
 }
 
-// Update modifies a user into database.
+// Update modifies a user into database. The rights version is incremented if
+// the rights are changed.
 func (u *User) Update(db *sql.DB) error {
-	res, err := db.Exec(`UPDATE users SET name=$1, email=$2, password=$3, 
-	rights=$4 WHERE id=$5 `, u.Name, u.Email, u.Password, u.Rights, u.ID)
-	if err != nil {
-		return fmt.Errorf("update %v", err)
+	err := db.QueryRow(`UPDATE users SET name=$1, email=$2, password=$3, 
+	rights=$4, rights_version=CASE WHEN rights<>$4 THEN rights_version+1
+		ELSE rights_version END
+	WHERE id=$5 RETURNING rights_version`, u.Name, u.Email, u.Password, u.Rights,
+		u.ID).Scan(&u.RightsVersion)
+	if err == sql.ErrNoRows {
+		return notFound("Utilisateur")
 	}
-	count, err := res.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("rows affected %v", err)
-	}
-	if count != 1 {
-		return errors.New("Utilisateur introuvable")
+		return fmt.Errorf("update %v", err)
 	}
 	return nil
 }
@@ -179,7 +190,7 @@ func (u *User) Delete(db *sql.DB) error {
 		return fmt.Errorf("rows affected %v", err)
 	}
 	if count != 1 {
-		return errors.New("Utilisateur introuvable")
+		return notFound("Utilisateur")
 	}
 	return nil
 }
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UserSession model is used to persist in the database each session opened
+// by a login, so that sessions survive server crashes and can be shared by
+// several server instances. The ID is the one of the JWT (jti claim).
+type UserSession struct {
+	ID       string    `json:"ID"`
+	UserID   int64     `json:"UserID"`
+	Created  time.Time `json:"Created"`
+	LastSeen time.Time `json:"LastSeen"`
+	Device   string    `json:"Device"`
+	IP       string    `json:"IP"`
+	// Rights and RightsVersion are the current ones of the user
+	Rights        int64 `json:"-"`
+	RightsVersion int64 `json:"-"`
+}
+
+// MaxDeviceLength is the number of characters of the device column
+const MaxDeviceLength = 250
+
+// TokenPolicy defines the JWT tokens of the sessions : the key signing them,
+// their lifetime, the delay since its token was issued during which a session
+// can be refreshed and the issuer claim
+type TokenPolicy struct {
+	SigningKey   []byte
+	Lifetime     time.Duration
+	RefreshDelay time.Duration
+	Issuer       string
+}
+
+// DefaultTokenPolicy is used for the fields of the policy that aren't
+// configured
+var DefaultTokenPolicy = TokenPolicy{
+	Lifetime:     30 * time.Second,
+	RefreshDelay: 15 * 24 * time.Hour,
+	Issuer:       "https://www.propera.net",
+}
+
+// UserSessions embeddes an array of UserSession for json export
+type UserSessions struct {
+	Lines []UserSession `json:"UserSession"`
+}
+
+// Create inserts a new session into the database
+func (u *UserSession) Create(db *sql.DB) error {
+	if _, err := db.Exec(`INSERT INTO user_session (id,user_id,created,last_seen,
+		device,ip) VALUES($1,$2,$3,$4,$5,$6)`, u.ID, u.UserID, u.Created,
+		u.LastSeen, u.Device, u.IP); err != nil {
+		return fmt.Errorf("insert %v", err)
+	}
+	return nil
+}
+
+// Get fetches a session by its ID with the current rights of the user
+func (u *UserSession) Get(db *sql.DB) error {
+	return db.QueryRow(`SELECT s.user_id,s.created,s.last_seen,s.device,s.ip,
+		u.rights,u.rights_version
+	FROM user_session s JOIN users u ON s.user_id=u.id
+	WHERE s.id=$1`, u.ID).Scan(&u.UserID, &u.Created, &u.LastSeen, &u.Device,
+		&u.IP, &u.Rights, &u.RightsVersion)
+}
+
+// Touch updates the last seen time of the session
+func (u *UserSession) Touch(db *sql.DB) error {
+	if _, err := db.Exec(`UPDATE user_session SET last_seen=$1 WHERE id=$2`,
+		u.LastSeen, u.ID); err != nil {
+		return fmt.Errorf("update %v", err)
+	}
+	return nil
+}
+
+// Delete removes the session from the database
+func (u *UserSession) Delete(db *sql.DB) error {
+	if _, err := db.Exec(`DELETE FROM user_session WHERE id=$1`,
+		u.ID); err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	return nil
+}
+
+// GetByUser fetches all active sessions of a user
+func (u *UserSessions) GetByUser(userID int64, db *sql.DB) error {
+	rows, err := db.Query(`SELECT id,user_id,created,last_seen,device,ip
+	FROM user_session WHERE user_id=$1 ORDER BY last_seen DESC`, userID)
+	if err != nil {
+		return fmt.Errorf("select %v", err)
+	}
+	var row UserSession
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&row.ID, &row.UserID, &row.Created, &row.LastSeen,
+			&row.Device, &row.IP); err != nil {
+			return fmt.Errorf("scan %v", err)
+		}
+		u.Lines = append(u.Lines, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("rows err %v", err)
+	}
+	if len(u.Lines) == 0 {
+		u.Lines = []UserSession{}
+	}
+	return nil
+}
+
+// DeleteByUser removes all sessions of a user from the database
+func (u *UserSessions) DeleteByUser(userID int64, db *sql.DB) error {
+	if _, err := db.Exec(`DELETE FROM user_session WHERE user_id=$1`,
+		userID); err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes the sessions that haven't been seen since before
+func (u *UserSessions) DeleteExpired(before time.Time, db *sql.DB) error {
+	if _, err := db.Exec(`DELETE FROM user_session WHERE last_seen<$1`,
+		before); err != nil {
+		return fmt.Errorf("delete %v", err)
+	}
+	return nil
+}